@@ -15,8 +15,17 @@ import (
 	"github.com/microcosm-cc/bluemonday"
 )
 
+var ErrArtworkNotFound = fmt.Errorf("artstation project %w", artworks.ErrNotFound)
+
 type Artstation struct {
-	regex *regexp.Regexp
+	regex       *regexp.Regexp
+	authorRegex *regexp.Regexp
+}
+
+type projectsResponse struct {
+	Data []struct {
+		HashID string `json:"hash_id,omitempty"`
+	} `json:"data,omitempty"`
 }
 
 type ArtstationResponse struct {
@@ -73,7 +82,8 @@ func New() artworks.Provider {
 	r := regexp.MustCompile(`(?i)https:\/\/(?:www\.)?artstation\.com\/artwork\/([\w\-]+)`)
 
 	return &Artstation{
-		regex: r,
+		regex:       r,
+		authorRegex: regexp.MustCompile(`(?i)^https:\/\/(?:www\.)?artstation\.com\/([\w\-]+)\/?(?:\?.*)?$`),
 	}
 }
 
@@ -86,6 +96,14 @@ func (as *Artstation) Find(id string) (artworks.Artwork, error) {
 
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrArtworkNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("artstation project request returned %v", resp.Status)
+	}
+
 	res := &ArtstationResponse{}
 	err = json.NewDecoder(resp.Body).Decode(res)
 	if err != nil {
@@ -104,8 +122,45 @@ func (as *Artstation) Match(url string) (string, bool) {
 	return res[1], true
 }
 
-func (*Artstation) Enabled(g *store.Guild) bool {
-	return g.Artstation
+func (as *Artstation) MatchAuthor(url string) (string, bool) {
+	res := as.authorRegex.FindStringSubmatch(url)
+	if res == nil || res[1] == "artwork" {
+		return "", false
+	}
+
+	return res[1], true
+}
+
+func (as *Artstation) RecentWorks(authorID string) ([]string, error) {
+	reqURL := fmt.Sprintf("https://www.artstation.com/users/%v/projects.json", authorID)
+	resp, err := http.Get(reqURL)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	res := &projectsResponse{}
+	err = json.NewDecoder(resp.Body).Decode(res)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, 0, len(res.Data))
+	for _, project := range res.Data {
+		urls = append(urls, "https://www.artstation.com/artwork/"+project.HashID)
+	}
+
+	return urls, nil
+}
+
+func (*Artstation) Info() artworks.ProviderInfo {
+	return artworks.ProviderInfo{
+		Name:           "artstation",
+		DisplayName:    "ArtStation",
+		DefaultEnabled: false,
+		NSFW:           true,
+	}
 }
 
 func (artwork *ArtstationResponse) StoreArtwork() *store.Artwork {
@@ -115,10 +170,14 @@ func (artwork *ArtstationResponse) StoreArtwork() *store.Artwork {
 	}
 
 	return &store.Artwork{
-		Title:  artwork.Title,
-		Author: artwork.User.Name,
-		URL:    artwork.Permalink,
-		Images: images,
+		Title:    artwork.Title,
+		Author:   artwork.User.Name,
+		URL:      artwork.Permalink,
+		Images:   images,
+		Provider: "artstation",
+		SourceID: artwork.HashID,
+		Tags:     artwork.Tags,
+		NSFW:     artwork.HideAsAdult,
 	}
 }
 
@@ -189,3 +248,8 @@ func (artwork *ArtstationResponse) URL() string {
 func (artwork *ArtstationResponse) Len() int {
 	return len(artwork.Assets)
 }
+
+//LikeCount returns the number of likes of an artwork.
+func (artwork *ArtstationResponse) LikeCount() int {
+	return artwork.LikesCount
+}
@@ -3,6 +3,7 @@ package artstation_test
 import (
 	"testing"
 
+	"github.com/VTGare/boe-tea-go/artworks"
 	"github.com/VTGare/boe-tea-go/artworks/artstation"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -26,3 +27,18 @@ var _ = DescribeTable(
 	Entry("Different domain", "https://www.somethingelse.com/artwork/q98e9N", "", false),
 	Entry("Query params", "https://www.artstation.com/artwork/q98e9N?iw=234", "q98e9N", true),
 )
+
+var _ = DescribeTable(
+	"Match Artstation author URL",
+	func(url string, expectedID string, expectedResult bool) {
+		as := artstation.New().(artworks.Feed)
+
+		id, ok := as.MatchAuthor(url)
+		Expect(id).To(BeEquivalentTo(expectedID))
+		Expect(ok).To(BeEquivalentTo(expectedResult))
+	},
+	Entry("Valid profile", "https://www.artstation.com/some-artist", "some-artist", true),
+	Entry("Trailing slash", "https://www.artstation.com/some-artist/", "some-artist", true),
+	Entry("Artwork URL", "https://www.artstation.com/artwork/q98e9N", "", false),
+	Entry("Different domain", "https://www.somethingelse.com/some-artist", "", false),
+)
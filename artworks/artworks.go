@@ -1,14 +1,29 @@
 package artworks
 
 import (
+	"errors"
+
 	"github.com/VTGare/boe-tea-go/store"
 	"github.com/bwmarrin/discordgo"
 )
 
+//ErrNotFound is wrapped by providers' errors when an artwork doesn't exist or was deleted.
+var ErrNotFound = errors.New("not found")
+
 type Provider interface {
 	Match(url string) (string, bool)
 	Find(id string) (Artwork, error)
-	Enabled(*store.Guild) bool
+	Info() ProviderInfo
+}
+
+//ProviderInfo describes an artwork provider.
+type ProviderInfo struct {
+	//Name is a stable identifier of the provider. It's used as a setting name and a key in guild's provider toggles.
+	Name           string
+	DisplayName    string
+	DefaultEnabled bool
+	//NSFW is true if provider can return NSFW artworks.
+	NSFW bool
 }
 
 type Artwork interface {
@@ -17,3 +32,65 @@ type Artwork interface {
 	URL() string
 	Len() int
 }
+
+//Feed is implemented by providers that can list recent works of an author.
+type Feed interface {
+	//MatchAuthor returns an author ID from a profile URL.
+	MatchAuthor(url string) (string, bool)
+	//RecentWorks returns URLs of author's recent artworks, newest first. Every URL is accepted by provider's Match.
+	RecentWorks(authorID string) ([]string, error)
+}
+
+//Rated is implemented by artworks that know their content rating.
+type Rated interface {
+	IsNSFW() bool
+}
+
+//IsNSFW reports if an artwork is NSFW. Artworks without a content rating are considered safe.
+func IsNSFW(a Artwork) bool {
+	rated, ok := a.(Rated)
+	return ok && rated.IsNSFW()
+}
+
+//Liked is implemented by artworks that know how many likes, favourites or score they have on provider's website.
+type Liked interface {
+	LikeCount() int
+}
+
+//LikeCount returns the number of likes of an artwork. ok is false if the artwork doesn't have a like count.
+func LikeCount(a Artwork) (int, bool) {
+	liked, ok := a.(Liked)
+	if !ok {
+		return 0, false
+	}
+
+	return liked.LikeCount(), true
+}
+
+//Previewed is implemented by artworks that have a downscaled preview of their first image.
+type Previewed interface {
+	PreviewURL() string
+}
+
+//PreviewURL returns a URL of a preview of artwork's first image. Artworks without a preview return their first image.
+//Empty string is returned if an artwork has no images.
+func PreviewURL(a Artwork) string {
+	if previewed, ok := a.(Previewed); ok {
+		if url := previewed.PreviewURL(); url != "" {
+			return url
+		}
+	}
+
+	images := a.StoreArtwork().Images
+	if len(images) == 0 {
+		return ""
+	}
+
+	return images[0]
+}
+
+//Enabled reports if a provider is enabled in a guild.
+func Enabled(p Provider, g *store.Guild) bool {
+	info := p.Info()
+	return g.ProviderEnabled(info.Name, info.DefaultEnabled)
+}
@@ -0,0 +1,366 @@
+package bluesky
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/artworks"
+	"github.com/VTGare/boe-tea-go/internal/arrays"
+	"github.com/VTGare/boe-tea-go/store"
+	"github.com/VTGare/embeds"
+	"github.com/bwmarrin/discordgo"
+)
+
+//AppView is the public Bluesky AppView. It doesn't require authentication.
+const AppView = "https://public.api.bsky.app"
+
+var (
+	ErrPostNotFound = fmt.Errorf("bluesky post %w", artworks.ErrNotFound)
+
+	//nsfwLabels are self-applied and moderation labels that mark adult content.
+	nsfwLabels = []string{"porn", "sexual", "nudity", "graphic-media"}
+)
+
+type Bluesky struct {
+	appview string
+	client  *http.Client
+}
+
+type Artwork struct {
+	ID          string
+	Handle      string
+	DisplayName string
+	Content     string
+	Permalink   string
+	Images      []*Image
+	Video       *Video
+	Labels      []string
+	Likes       int
+	Reposts     int
+	Replies     int
+	Timestamp   time.Time
+	NSFW        bool
+}
+
+type Image struct {
+	Thumbnail string `json:"thumb"`
+	Fullsize  string `json:"fullsize"`
+	Alt       string `json:"alt"`
+}
+
+type Video struct {
+	Playlist  string `json:"playlist"`
+	Thumbnail string `json:"thumbnail"`
+	Alt       string `json:"alt"`
+}
+
+//New creates a Bluesky artwork provider that uses the public AppView.
+func New() artworks.Provider {
+	return NewWithURL(AppView)
+}
+
+//NewWithURL creates a Bluesky artwork provider that uses a custom AppView.
+func NewWithURL(appview string) artworks.Provider {
+	return &Bluesky{
+		appview: strings.TrimSuffix(appview, "/"),
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+//Match matches bsky.app post URLs. Returned ID is a handle or a DID and a record key separated by a slash.
+func (b *Bluesky) Match(s string) (string, bool) {
+	u, err := url.ParseRequestURI(s)
+	if err != nil {
+		return "", false
+	}
+
+	if u.Host != "bsky.app" && u.Host != "www.bsky.app" {
+		return "", false
+	}
+
+	parts := strings.FieldsFunc(u.Path, func(r rune) bool {
+		return r == '/'
+	})
+
+	if len(parts) < 4 || parts[0] != "profile" || parts[2] != "post" {
+		return "", false
+	}
+
+	return parts[1] + "/" + parts[3], true
+}
+
+func (b *Bluesky) Find(id string) (artworks.Artwork, error) {
+	actor, rkey, ok := strings.Cut(id, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid bluesky post id: %v", id)
+	}
+
+	did := actor
+	if !strings.HasPrefix(actor, "did:") {
+		var err error
+		did, err = b.resolveHandle(actor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var res struct {
+		Posts []*postView `json:"posts"`
+	}
+
+	uri := fmt.Sprintf("at://%v/app.bsky.feed.post/%v", did, rkey)
+	if err := b.get("app.bsky.feed.getPosts", url.Values{"uris": {uri}}, &res); err != nil {
+		return nil, err
+	}
+
+	if len(res.Posts) == 0 {
+		return nil, ErrPostNotFound
+	}
+
+	post := res.Posts[0]
+	labels := make([]string, 0)
+	for _, group := range [][]*label{post.Labels, post.Author.Labels, post.Record.Labels.Values} {
+		for _, label := range group {
+			if !label.Negate && !arrays.Any(labels, label.Value) {
+				labels = append(labels, label.Value)
+			}
+		}
+	}
+
+	artwork := &Artwork{
+		ID:          id,
+		Handle:      post.Author.Handle,
+		DisplayName: post.Author.DisplayName,
+		Content:     post.Record.Text,
+		Permalink:   fmt.Sprintf("https://bsky.app/profile/%v/post/%v", post.Author.Handle, rkey),
+		Labels:      labels,
+		Likes:       post.LikeCount,
+		Reposts:     post.RepostCount,
+		Replies:     post.ReplyCount,
+		Timestamp:   post.Record.CreatedAt,
+		NSFW: arrays.AnyFunc(labels, func(label string) bool {
+			return arrays.Any(nsfwLabels, label)
+		}),
+	}
+
+	if artwork.DisplayName == "" {
+		artwork.DisplayName = artwork.Handle
+	}
+
+	if embed := post.Embed; embed != nil {
+		// Posts with a quote and media keep the media in a nested view.
+		if embed.Media != nil {
+			embed = embed.Media
+		}
+
+		switch {
+		case strings.HasPrefix(embed.Type, "app.bsky.embed.images"):
+			artwork.Images = embed.Images
+		case strings.HasPrefix(embed.Type, "app.bsky.embed.video"):
+			artwork.Video = &Video{
+				Playlist:  embed.Playlist,
+				Thumbnail: embed.Thumbnail,
+				Alt:       embed.Alt,
+			}
+		}
+	}
+
+	return artwork, nil
+}
+
+func (b *Bluesky) Info() artworks.ProviderInfo {
+	return artworks.ProviderInfo{
+		Name:           "bluesky",
+		DisplayName:    "Bluesky",
+		DefaultEnabled: true,
+		NSFW:           true,
+	}
+}
+
+func (b *Bluesky) resolveHandle(handle string) (string, error) {
+	var res struct {
+		DID string `json:"did"`
+	}
+
+	if err := b.get("com.atproto.identity.resolveHandle", url.Values{"handle": {handle}}, &res); err != nil {
+		return "", err
+	}
+
+	return res.DID, nil
+}
+
+//get calls an XRPC query method and decodes the response.
+func (b *Bluesky) get(method string, query url.Values, v interface{}) error {
+	resp, err := b.client.Get(fmt.Sprintf("%v/xrpc/%v?%v", b.appview, method, query.Encode()))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var xrpcErr struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		}
+
+		json.NewDecoder(resp.Body).Decode(&xrpcErr)
+		if resp.StatusCode == http.StatusBadRequest && xrpcErr.Error == "InvalidRequest" {
+			return ErrPostNotFound
+		}
+
+		return fmt.Errorf("%v returned %v: %v", method, resp.Status, xrpcErr.Message)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (a *Artwork) StoreArtwork() *store.Artwork {
+	media := make([]string, 0, len(a.Images)+1)
+	for _, image := range a.Images {
+		media = append(media, image.Fullsize)
+	}
+
+	if a.Video != nil {
+		media = append(media, a.Video.Thumbnail)
+	}
+
+	return &store.Artwork{
+		Author:   "@" + a.Handle,
+		URL:      a.Permalink,
+		Images:   media,
+		Provider: "bluesky",
+		SourceID: a.ID,
+		NSFW:     a.NSFW,
+	}
+}
+
+func (a *Artwork) MessageSends(footer string, _ bool) ([]*discordgo.MessageSend, error) {
+	var (
+		length = len(a.Images)
+		pages  = make([]*discordgo.MessageSend, 0, length)
+		eb     = embeds.NewBuilder()
+	)
+
+	if length > 1 {
+		eb.Title(fmt.Sprintf("%v (@%v) | Page %v / %v", a.DisplayName, a.Handle, 1, length))
+	} else {
+		eb.Title(fmt.Sprintf("%v (@%v)", a.DisplayName, a.Handle))
+	}
+
+	eb.URL(a.Permalink).Description(a.Content).Timestamp(a.Timestamp)
+	eb.AddField("Reposts", strconv.Itoa(a.Reposts), true)
+	eb.AddField("Likes", strconv.Itoa(a.Likes), true)
+
+	if footer != "" {
+		eb.Footer(footer, "")
+	}
+
+	switch {
+	case a.Video != nil:
+		// Bluesky serves videos as HLS playlists that can't be attached or embedded.
+		eb.Image(a.Video.Thumbnail)
+		eb.AddField("Video", fmt.Sprintf("[Watch on Bluesky](%v)", a.Permalink), true)
+	case length > 0:
+		eb.Image(a.Images[0].Fullsize)
+	}
+
+	pages = append(pages, &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{eb.Finalize()}})
+	if length > 1 {
+		for ind, image := range a.Images[1:] {
+			eb := embeds.NewBuilder()
+
+			eb.Title(fmt.Sprintf("%v (@%v) | Page %v / %v", a.DisplayName, a.Handle, ind+2, length)).URL(a.Permalink)
+			eb.Image(image.Fullsize).Timestamp(a.Timestamp)
+
+			if footer != "" {
+				eb.Footer(footer, "")
+			}
+
+			pages = append(pages, &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{eb.Finalize()}})
+		}
+	}
+
+	return pages, nil
+}
+
+//IsNSFW reports if an artwork is marked as NSFW.
+func (a *Artwork) IsNSFW() bool {
+	return a.NSFW
+}
+
+//PreviewURL returns a thumbnail of the first image or the video.
+func (a *Artwork) PreviewURL() string {
+	switch {
+	case len(a.Images) > 0:
+		return a.Images[0].Thumbnail
+	case a.Video != nil:
+		return a.Video.Thumbnail
+	default:
+		return ""
+	}
+}
+
+//LikeCount returns the number of likes of a post.
+func (a *Artwork) LikeCount() int {
+	return a.Likes
+}
+
+func (a *Artwork) URL() string {
+	return a.Permalink
+}
+
+func (a *Artwork) Len() int {
+	if a.Video != nil {
+		return 1
+	}
+
+	return len(a.Images)
+}
+
+//postView is app.bsky.feed.defs#postView.
+type postView struct {
+	URI    string `json:"uri"`
+	Author struct {
+		DID         string   `json:"did"`
+		Handle      string   `json:"handle"`
+		DisplayName string   `json:"displayName"`
+		Labels      []*label `json:"labels"`
+	} `json:"author"`
+	Record struct {
+		Text      string    `json:"text"`
+		CreatedAt time.Time `json:"createdAt"`
+		//Labels are self-applied labels, com.atproto.label.defs#selfLabels.
+		Labels struct {
+			Values []*label `json:"values"`
+		} `json:"labels"`
+	} `json:"record"`
+	Embed       *embedView `json:"embed"`
+	Labels      []*label   `json:"labels"`
+	LikeCount   int        `json:"likeCount"`
+	RepostCount int        `json:"repostCount"`
+	ReplyCount  int        `json:"replyCount"`
+}
+
+//embedView is a union of app.bsky.embed.images#view, app.bsky.embed.video#view and app.bsky.embed.recordWithMedia#view.
+type embedView struct {
+	Type string `json:"$type"`
+
+	Images []*Image `json:"images"`
+
+	Playlist  string `json:"playlist"`
+	Thumbnail string `json:"thumbnail"`
+	Alt       string `json:"alt"`
+
+	Media *embedView `json:"media"`
+}
+
+type label struct {
+	Source string `json:"src"`
+	Value  string `json:"val"`
+	Negate bool   `json:"neg"`
+}
@@ -0,0 +1,142 @@
+package bluesky_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/VTGare/boe-tea-go/artworks"
+	"github.com/VTGare/boe-tea-go/artworks/bluesky"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestBluesky(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Bluesky Suite")
+}
+
+//fixtureServer serves recorded AppView responses from testdata.
+func fixtureServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var fixture string
+		switch r.URL.Path {
+		case "/xrpc/com.atproto.identity.resolveHandle":
+			if r.URL.Query().Get("handle") != "artist.bsky.social" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"InvalidRequest","message":"Unable to resolve handle"}`))
+				return
+			}
+
+			fixture = "resolveHandle.json"
+		case "/xrpc/app.bsky.feed.getPosts":
+			uri := r.URL.Query().Get("uris")
+			fixture = uri[strings.LastIndex(uri, "/")+1:] + ".json"
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		body, err := os.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			body, _ = os.ReadFile(filepath.Join("testdata", "notfound.json"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+}
+
+var _ = DescribeTable(
+	"Match Bluesky URL",
+	func(url string, expectedID string, expectedResult bool) {
+		provider := bluesky.New()
+
+		id, ok := provider.Match(url)
+		Expect(id).To(BeEquivalentTo(expectedID))
+		Expect(ok).To(BeEquivalentTo(expectedResult))
+	},
+	Entry("Valid post", "https://bsky.app/profile/artist.bsky.social/post/3kgallery", "artist.bsky.social/3kgallery", true),
+	Entry("DID instead of handle", "https://bsky.app/profile/did:plc:z72i7hdynmk6r22z27h6tvur/post/3kgallery", "did:plc:z72i7hdynmk6r22z27h6tvur/3kgallery", true),
+	Entry("Query params", "https://bsky.app/profile/artist.bsky.social/post/3kgallery?ref=1", "artist.bsky.social/3kgallery", true),
+	Entry("Profile URL", "https://bsky.app/profile/artist.bsky.social", "", false),
+	Entry("Feed URL", "https://bsky.app/profile/artist.bsky.social/feed/art", "", false),
+	Entry("Different domain", "https://twitter.com/profile/artist/post/123", "", false),
+	Entry("Invalid URL", "efe", "", false),
+)
+
+var _ = Describe("Find Bluesky post", func() {
+	var (
+		server   *httptest.Server
+		provider artworks.Provider
+	)
+
+	BeforeEach(func() {
+		server = fixtureServer()
+		provider = bluesky.NewWithURL(server.URL)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should find a gallery", func() {
+		a, err := provider.Find("artist.bsky.social/3kgallery")
+		Expect(err).ToNot(HaveOccurred())
+
+		artwork := a.(*bluesky.Artwork)
+		Expect(artwork.DisplayName).To(Equal("Some Artist"))
+		Expect(artwork.Handle).To(Equal("artist.bsky.social"))
+		Expect(artwork.Content).To(Equal("New sketches!"))
+		Expect(artwork.Likes).To(Equal(150))
+		Expect(artwork.Reposts).To(Equal(12))
+		Expect(artwork.Len()).To(Equal(2))
+		Expect(artwork.URL()).To(Equal("https://bsky.app/profile/artist.bsky.social/post/3kgallery"))
+		Expect(artwork.NSFW).To(BeFalse(), "negated labels should be ignored")
+
+		sends, err := artwork.MessageSends("", false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sends).To(HaveLen(2))
+		Expect(sends[0].Embeds[0].Title).To(Equal("Some Artist (@artist.bsky.social) | Page 1 / 2"))
+		Expect(sends[1].Embeds[0].Image.URL).To(ContainSubstring("bafkreitwo"))
+	})
+
+	It("should mark labelled posts as NSFW and find media of quote posts", func() {
+		a, err := provider.Find("did:plc:z72i7hdynmk6r22z27h6tvur/3knsfw")
+		Expect(err).ToNot(HaveOccurred())
+
+		artwork := a.(*bluesky.Artwork)
+		Expect(artwork.NSFW).To(BeTrue())
+		Expect(artwork.Labels).To(ConsistOf("porn"))
+		Expect(artwork.DisplayName).To(Equal("artist.bsky.social"))
+		Expect(artwork.Len()).To(Equal(1))
+	})
+
+	It("should find a video post", func() {
+		a, err := provider.Find("artist.bsky.social/3kvideo")
+		Expect(err).ToNot(HaveOccurred())
+
+		artwork := a.(*bluesky.Artwork)
+		Expect(artwork.Video).ToNot(BeNil())
+		Expect(artwork.Len()).To(Equal(1))
+		Expect(artwork.StoreArtwork().Images).To(ConsistOf(HaveSuffix("thumbnail.jpg")))
+
+		sends, err := artwork.MessageSends("", false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sends).To(HaveLen(1))
+		Expect(sends[0].Embeds[0].Image.URL).To(HaveSuffix("thumbnail.jpg"))
+	})
+
+	It("should return an error if post doesn't exist", func() {
+		_, err := provider.Find("artist.bsky.social/3kdeleted")
+		Expect(err).To(MatchError(bluesky.ErrPostNotFound))
+	})
+
+	It("should return an error if handle can't be resolved", func() {
+		_, err := provider.Find("unknown.bsky.social/3kgallery")
+		Expect(err).To(HaveOccurred())
+	})
+})
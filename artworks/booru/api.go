@@ -0,0 +1,258 @@
+package booru
+
+import (
+	"html"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type danbooruPost struct {
+	ID                 int    `json:"id"`
+	Rating             string `json:"rating"`
+	Source             string `json:"source"`
+	FileURL            string `json:"file_url"`
+	LargeFileURL       string `json:"large_file_url"`
+	Score              int    `json:"score"`
+	Width              int    `json:"image_width"`
+	Height             int    `json:"image_height"`
+	TagStringArtist    string `json:"tag_string_artist"`
+	TagStringCopyright string `json:"tag_string_copyright"`
+	TagStringCharacter string `json:"tag_string_character"`
+	TagStringGeneral   string `json:"tag_string_general"`
+	CreatedAt          string `json:"created_at"`
+}
+
+func findDanbooru(b *Booru, id string) (*Artwork, error) {
+	var post danbooruPost
+	if err := b.get("/posts/"+id+".json", url.Values{}, &post); err != nil {
+		return nil, err
+	}
+
+	createdAt, _ := time.Parse(time.RFC3339, post.CreatedAt)
+	return &Artwork{
+		Tags: &Tags{
+			Artists:    strings.Fields(post.TagStringArtist),
+			Copyrights: strings.Fields(post.TagStringCopyright),
+			Characters: strings.Fields(post.TagStringCharacter),
+			General:    strings.Fields(post.TagStringGeneral),
+		},
+		Rating:    normaliseRating(post.Rating),
+		Source:    post.Source,
+		FileURL:   post.FileURL,
+		SampleURL: post.LargeFileURL,
+		Score:     post.Score,
+		Width:     post.Width,
+		Height:    post.Height,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+type gelbooruPost struct {
+	ID        int    `json:"id"`
+	Rating    string `json:"rating"`
+	Source    string `json:"source"`
+	FileURL   string `json:"file_url"`
+	SampleURL string `json:"sample_url"`
+	Score     int    `json:"score"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Tags      string `json:"tags"`
+	CreatedAt string `json:"created_at"`
+}
+
+//Gelbooru tag types returned by the tag API. Unknown tags are considered general.
+const (
+	gelbooruTagArtist    = 1
+	gelbooruTagCopyright = 3
+	gelbooruTagCharacter = 4
+	gelbooruTagMetadata  = 5
+)
+
+func findGelbooru(b *Booru, id string) (*Artwork, error) {
+	var res struct {
+		Posts []*gelbooruPost `json:"post"`
+	}
+
+	query := url.Values{"page": {"dapi"}, "s": {"post"}, "q": {"index"}, "json": {"1"}, "id": {id}}
+	if err := b.get("/index.php", query, &res); err != nil {
+		return nil, err
+	}
+
+	if len(res.Posts) == 0 {
+		return nil, ErrPostNotFound
+	}
+
+	post := res.Posts[0]
+	tags, err := gelbooruTags(b, strings.Fields(html.UnescapeString(post.Tags)))
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt, _ := time.Parse(time.RubyDate, post.CreatedAt)
+	return &Artwork{
+		Tags:      tags,
+		Rating:    normaliseRating(post.Rating),
+		Source:    post.Source,
+		FileURL:   post.FileURL,
+		SampleURL: post.SampleURL,
+		Score:     post.Score,
+		Width:     post.Width,
+		Height:    post.Height,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+//gelbooruTags splits tags by category. Gelbooru posts only have a flat list of tags, categories are fetched from the tag API.
+func gelbooruTags(b *Booru, names []string) (*Tags, error) {
+	tags := &Tags{}
+	if len(names) == 0 {
+		return tags, nil
+	}
+
+	var res struct {
+		Tags []struct {
+			Name string `json:"name"`
+			Type int    `json:"type"`
+		} `json:"tag"`
+	}
+
+	query := url.Values{"page": {"dapi"}, "s": {"tag"}, "q": {"index"}, "json": {"1"}, "names": {strings.Join(names, " ")}}
+	if err := b.get("/index.php", query, &res); err != nil {
+		return nil, err
+	}
+
+	types := make(map[string]int, len(res.Tags))
+	for _, tag := range res.Tags {
+		types[html.UnescapeString(tag.Name)] = tag.Type
+	}
+
+	for _, name := range names {
+		switch types[name] {
+		case gelbooruTagArtist:
+			tags.Artists = append(tags.Artists, name)
+		case gelbooruTagCopyright:
+			tags.Copyrights = append(tags.Copyrights, name)
+		case gelbooruTagCharacter:
+			tags.Characters = append(tags.Characters, name)
+		case gelbooruTagMetadata:
+		default:
+			tags.General = append(tags.General, name)
+		}
+	}
+
+	return tags, nil
+}
+
+type safebooruPost struct {
+	ID        int    `json:"id"`
+	Rating    string `json:"rating"`
+	Source    string `json:"source"`
+	Directory string `json:"directory"`
+	Image     string `json:"image"`
+	Sample    bool   `json:"sample"`
+	Score     int    `json:"score"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Tags      string `json:"tags"`
+	Change    int64  `json:"change"`
+}
+
+//findSafebooru fetches a Safebooru post. Safebooru's JSON API doesn't expose tag categories, all tags are general.
+func findSafebooru(b *Booru, id string) (*Artwork, error) {
+	var posts []*safebooruPost
+
+	query := url.Values{"page": {"dapi"}, "s": {"post"}, "q": {"index"}, "json": {"1"}, "id": {id}}
+	if err := b.get("/index.php", query, &posts); err != nil {
+		return nil, err
+	}
+
+	if len(posts) == 0 {
+		return nil, ErrPostNotFound
+	}
+
+	post := posts[0]
+	artwork := &Artwork{
+		Tags:      &Tags{General: strings.Fields(html.UnescapeString(post.Tags))},
+		Rating:    normaliseRating(post.Rating),
+		Source:    post.Source,
+		FileURL:   b.site.baseURL + "/images/" + post.Directory + "/" + post.Image,
+		Score:     post.Score,
+		Width:     post.Width,
+		Height:    post.Height,
+		CreatedAt: time.Unix(post.Change, 0),
+	}
+
+	if post.Sample {
+		name := strings.TrimSuffix(post.Image, post.Image[strings.LastIndex(post.Image, "."):])
+		artwork.SampleURL = b.site.baseURL + "/samples/" + post.Directory + "/sample_" + name + ".jpg"
+	}
+
+	return artwork, nil
+}
+
+type yanderePost struct {
+	ID        int    `json:"id"`
+	Rating    string `json:"rating"`
+	Source    string `json:"source"`
+	FileURL   string `json:"file_url"`
+	SampleURL string `json:"sample_url"`
+	Score     int    `json:"score"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Tags      string `json:"tags"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+//yandereTagTypes are Moebooru tag types returned with include_tags.
+var yandereTagTypes = map[string]func(*Tags, string){
+	"artist":    func(t *Tags, name string) { t.Artists = append(t.Artists, name) },
+	"copyright": func(t *Tags, name string) { t.Copyrights = append(t.Copyrights, name) },
+	"character": func(t *Tags, name string) { t.Characters = append(t.Characters, name) },
+	"general":   func(t *Tags, name string) { t.General = append(t.General, name) },
+}
+
+func findYandere(b *Booru, id string) (*Artwork, error) {
+	var res struct {
+		Posts []*yanderePost    `json:"posts"`
+		Tags  map[string]string `json:"tags"`
+	}
+
+	query := url.Values{"tags": {"id:" + id}, "api_version": {"2"}, "include_tags": {"1"}}
+	if err := b.get("/post.json", query, &res); err != nil {
+		return nil, err
+	}
+
+	if len(res.Posts) == 0 {
+		return nil, ErrPostNotFound
+	}
+
+	post := res.Posts[0]
+	tags := &Tags{}
+	for _, name := range strings.Fields(post.Tags) {
+		add, ok := yandereTagTypes[res.Tags[name]]
+		if !ok {
+			add = yandereTagTypes["general"]
+		}
+
+		add(tags, name)
+	}
+
+	//Moebooru's "s" rating means safe, unlike Danbooru's sensitive.
+	rating := post.Rating
+	if rating == "s" {
+		rating = "safe"
+	}
+
+	return &Artwork{
+		Tags:      tags,
+		Rating:    normaliseRating(rating),
+		Source:    post.Source,
+		FileURL:   post.FileURL,
+		SampleURL: post.SampleURL,
+		Score:     post.Score,
+		Width:     post.Width,
+		Height:    post.Height,
+		CreatedAt: time.Unix(post.CreatedAt, 0),
+	}, nil
+}
@@ -0,0 +1,418 @@
+package booru
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/artworks"
+	"github.com/VTGare/boe-tea-go/internal/arrays"
+	"github.com/VTGare/boe-tea-go/messages"
+	"github.com/VTGare/boe-tea-go/store"
+	"github.com/VTGare/embeds"
+	"github.com/bwmarrin/discordgo"
+)
+
+//Site is an imageboard supported by the booru provider.
+type Site int
+
+const (
+	Danbooru Site = iota
+	Gelbooru
+	Safebooru
+	Yandere
+)
+
+//Rating is a normalised content rating of a booru post.
+type Rating string
+
+const (
+	RatingGeneral      Rating = "general"
+	RatingSensitive    Rating = "sensitive"
+	RatingQuestionable Rating = "questionable"
+	RatingExplicit     Rating = "explicit"
+)
+
+var ErrPostNotFound = fmt.Errorf("booru post %w", artworks.ErrNotFound)
+
+//siteInfo describes an imageboard: where its API lives, how its post URLs look and how to fetch a post.
+type siteInfo struct {
+	info    artworks.ProviderInfo
+	baseURL string
+	//hosts are hostnames of post URLs matched by the provider.
+	hosts []string
+	//match extracts a post ID from a post URL with one of the hosts.
+	match func(u *url.URL) (string, bool)
+	//find fetches a post using booru's API.
+	find func(b *Booru, id string) (*Artwork, error)
+	//postURL and tagURL are format strings for post and tag search links.
+	postURL string
+	tagURL  string
+}
+
+var sites = map[Site]*siteInfo{
+	Danbooru: {
+		info:    artworks.ProviderInfo{Name: "danbooru", DisplayName: "Danbooru", DefaultEnabled: true, NSFW: true},
+		baseURL: "https://danbooru.donmai.us",
+		hosts:   []string{"danbooru.donmai.us", "safebooru.donmai.us"},
+		match:   matchPath("posts"),
+		find:    findDanbooru,
+		postURL: "https://danbooru.donmai.us/posts/%v",
+		tagURL:  "https://danbooru.donmai.us/posts?tags=%v",
+	},
+	Gelbooru: {
+		info:    artworks.ProviderInfo{Name: "gelbooru", DisplayName: "Gelbooru", DefaultEnabled: true, NSFW: true},
+		baseURL: "https://gelbooru.com",
+		hosts:   []string{"gelbooru.com", "www.gelbooru.com"},
+		match:   matchQuery,
+		find:    findGelbooru,
+		postURL: "https://gelbooru.com/index.php?page=post&s=view&id=%v",
+		tagURL:  "https://gelbooru.com/index.php?page=post&s=list&tags=%v",
+	},
+	Safebooru: {
+		info:    artworks.ProviderInfo{Name: "safebooru", DisplayName: "Safebooru", DefaultEnabled: true, NSFW: false},
+		baseURL: "https://safebooru.org",
+		hosts:   []string{"safebooru.org", "www.safebooru.org"},
+		match:   matchQuery,
+		find:    findSafebooru,
+		postURL: "https://safebooru.org/index.php?page=post&s=view&id=%v",
+		tagURL:  "https://safebooru.org/index.php?page=post&s=list&tags=%v",
+	},
+	Yandere: {
+		info:    artworks.ProviderInfo{Name: "yandere", DisplayName: "Yande.re", DefaultEnabled: true, NSFW: true},
+		baseURL: "https://yande.re",
+		hosts:   []string{"yande.re", "www.yande.re"},
+		match:   matchPath("post", "show"),
+		find:    findYandere,
+		postURL: "https://yande.re/post/show/%v",
+		tagURL:  "https://yande.re/post?tags=%v",
+	},
+}
+
+//Booru is an artwork provider for a booru imageboard.
+type Booru struct {
+	site    *siteInfo
+	baseURL string
+	client  *http.Client
+}
+
+//Artwork is a booru post.
+type Artwork struct {
+	ID        string
+	Site      string
+	Tags      *Tags
+	Rating    Rating
+	Source    string
+	FileURL   string
+	SampleURL string
+	Score     int
+	Width     int
+	Height    int
+	CreatedAt time.Time
+	NSFW      bool
+
+	url      string
+	tagURL   string
+	provider string
+}
+
+//Tags are post's tags split by category.
+type Tags struct {
+	Artists    []string
+	Copyrights []string
+	Characters []string
+	General    []string
+}
+
+//New creates a booru artwork provider for a site.
+func New(site Site) artworks.Provider {
+	return NewWithURL(site, sites[site].baseURL)
+}
+
+//NewWithURL creates a booru artwork provider with a custom API base URL. Post URLs are still matched by site's hostnames.
+func NewWithURL(site Site, baseURL string) artworks.Provider {
+	return &Booru{
+		site:    sites[site],
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (b *Booru) Match(s string) (string, bool) {
+	u, err := url.ParseRequestURI(s)
+	if err != nil {
+		return "", false
+	}
+
+	if !arrays.Any(b.site.hosts, u.Host) {
+		return "", false
+	}
+
+	id, ok := b.site.match(u)
+	if !ok {
+		return "", false
+	}
+
+	if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+		return "", false
+	}
+
+	return id, true
+}
+
+func (b *Booru) Find(id string) (artworks.Artwork, error) {
+	artwork, err := b.site.find(b, id)
+	if err != nil {
+		return nil, err
+	}
+
+	artwork.ID = id
+	artwork.Site = b.site.info.DisplayName
+	artwork.NSFW = artwork.Rating == RatingQuestionable || artwork.Rating == RatingExplicit
+	artwork.url = fmt.Sprintf(b.site.postURL, id)
+	artwork.tagURL = b.site.tagURL
+	artwork.provider = b.site.info.Name
+
+	return artwork, nil
+}
+
+func (b *Booru) Info() artworks.ProviderInfo {
+	return b.site.info
+}
+
+//get sends a GET request to booru's API and decodes a JSON response.
+func (b *Booru) get(path string, query url.Values, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, b.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	//Danbooru rejects requests with the default Go user agent.
+	req.Header.Set("User-Agent", "boe-tea-go")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return ErrPostNotFound
+	default:
+		return fmt.Errorf("%v returned %v", b.site.info.DisplayName, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (a *Artwork) StoreArtwork() *store.Artwork {
+	artwork := &store.Artwork{
+		Title:    a.title(),
+		Author:   a.author(),
+		URL:      a.url,
+		Images:   []string{a.FileURL},
+		Provider: a.provider,
+		SourceID: a.ID,
+		NSFW:     a.NSFW,
+	}
+
+	//Artists are already searchable by author.
+	if a.Tags != nil {
+		artwork.Tags = append(artwork.Tags, a.Tags.Copyrights...)
+		artwork.Tags = append(artwork.Tags, a.Tags.Characters...)
+		artwork.Tags = append(artwork.Tags, a.Tags.General...)
+	}
+
+	return artwork
+}
+
+func (a *Artwork) MessageSends(footer string, hasTags bool) ([]*discordgo.MessageSend, error) {
+	eb := embeds.NewBuilder()
+
+	if a.Len() == 0 {
+		eb.Title("❎ An error has occured.")
+		eb.Description(fmt.Sprintf("%v post has been deleted or is restricted.", a.Site))
+		eb.Footer(footer, "")
+
+		return []*discordgo.MessageSend{
+			{Embeds: []*discordgo.MessageEmbed{eb.Finalize()}},
+		}, nil
+	}
+
+	eb.Title(fmt.Sprintf("%v by %v", a.title(), a.author()))
+	eb.URL(a.url).Image(a.preview()).Timestamp(a.CreatedAt)
+
+	if hasTags {
+		categories := []struct {
+			name string
+			tags []string
+		}{
+			{"Artists", a.Tags.Artists},
+			{"Copyrights", a.Tags.Copyrights},
+			{"Characters", a.Tags.Characters},
+			{"Tags", a.Tags.General},
+		}
+
+		sb := strings.Builder{}
+		for _, category := range categories {
+			if len(category.tags) == 0 {
+				continue
+			}
+
+			links := make([]string, 0, len(category.tags))
+			for _, tag := range category.tags {
+				links = append(links, fmt.Sprintf("[%v](%v)", escapeTag(tag), fmt.Sprintf(a.tagURL, url.QueryEscape(tag))))
+			}
+
+			sb.WriteString(fmt.Sprintf("**%v**\n%v\n", category.name, strings.Join(links, " • ")))
+		}
+
+		eb.Description(truncateDescription(sb.String(), 4096))
+	}
+
+	eb.AddField("Score", strconv.Itoa(a.Score), true)
+	eb.AddField("Rating", string(a.Rating), true)
+	eb.AddField("Original quality", messages.ClickHere(a.FileURL), true)
+	if a.Source != "" {
+		eb.AddField("Source", messages.ClickHere(a.Source), true)
+	}
+
+	if footer != "" {
+		eb.Footer(footer, "")
+	}
+
+	return []*discordgo.MessageSend{
+		{Embeds: []*discordgo.MessageEmbed{eb.Finalize()}},
+	}, nil
+}
+
+//truncateDescription cuts a tag list to fit embed description's limit. Discord counts characters, so the limit is in runes.
+//It's cut at the last whole tag, line or character that fits.
+func truncateDescription(description string, limit int) string {
+	runes := []rune(description)
+	if len(runes) <= limit {
+		return description
+	}
+
+	cut := string(runes[:limit])
+	if i := strings.LastIndex(cut, " • "); i > 0 {
+		return cut[:i]
+	}
+
+	if i := strings.LastIndex(cut, "\n"); i > 0 {
+		return cut[:i]
+	}
+
+	return cut
+}
+
+//IsNSFW reports if an artwork is marked as NSFW.
+func (a *Artwork) IsNSFW() bool {
+	return a.NSFW
+}
+
+//PreviewURL returns a sample of the post's image.
+func (a *Artwork) PreviewURL() string {
+	return a.preview()
+}
+
+//LikeCount returns the score of a post.
+func (a *Artwork) LikeCount() int {
+	return a.Score
+}
+
+func (a *Artwork) URL() string {
+	return a.url
+}
+
+func (a *Artwork) Len() int {
+	if a.FileURL == "" {
+		return 0
+	}
+
+	return 1
+}
+
+func (a *Artwork) title() string {
+	if len(a.Tags.Characters) > 0 {
+		return strings.Join(a.Tags.Characters, ", ")
+	}
+
+	return fmt.Sprintf("%v #%v", a.Site, a.ID)
+}
+
+func (a *Artwork) author() string {
+	if len(a.Tags.Artists) > 0 {
+		return strings.Join(a.Tags.Artists, ", ")
+	}
+
+	return "Unknown"
+}
+
+//preview returns a downscaled image if available. Discord fails to embed very large images.
+func (a *Artwork) preview() string {
+	if a.SampleURL != "" {
+		return a.SampleURL
+	}
+
+	return a.FileURL
+}
+
+//escapeTag escapes markdown in tag names, e.g. underscores in tags are common.
+func escapeTag(tag string) string {
+	return strings.NewReplacer("_", "\\_", "*", "\\*", "[", "\\[", "]", "\\]").Replace(tag)
+}
+
+//matchPath returns a function that matches URLs with a post ID after a path prefix, e.g. /posts/123.
+func matchPath(prefix ...string) func(u *url.URL) (string, bool) {
+	return func(u *url.URL) (string, bool) {
+		parts := strings.FieldsFunc(u.Path, func(r rune) bool {
+			return r == '/'
+		})
+
+		if len(parts) <= len(prefix) {
+			return "", false
+		}
+
+		for ind, part := range prefix {
+			if parts[ind] != part {
+				return "", false
+			}
+		}
+
+		return parts[len(prefix)], true
+	}
+}
+
+//matchQuery matches Gelbooru-style URLs, e.g. index.php?page=post&s=view&id=123.
+func matchQuery(u *url.URL) (string, bool) {
+	query := u.Query()
+	if query.Get("page") != "post" || query.Get("s") != "view" {
+		return "", false
+	}
+
+	id := query.Get("id")
+	return id, id != ""
+}
+
+//normaliseRating converts different rating notations to a Rating.
+func normaliseRating(rating string) Rating {
+	switch rating {
+	case "g", "general", "safe":
+		return RatingGeneral
+	case "s", "sensitive":
+		return RatingSensitive
+	case "q", "questionable":
+		return RatingQuestionable
+	case "e", "explicit":
+		return RatingExplicit
+	}
+
+	return RatingExplicit
+}
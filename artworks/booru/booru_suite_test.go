@@ -0,0 +1,239 @@
+package booru_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/VTGare/boe-tea-go/artworks/booru"
+	"github.com/bwmarrin/discordgo"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestBooru(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Booru Suite")
+}
+
+//fixtureServer serves recorded API responses from testdata. Route returns a fixture name for a request or an empty string for 404.
+func fixtureServer(route func(r *http.Request) string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fixture := route(r)
+		if fixture == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		body, err := os.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+}
+
+var _ = DescribeTable(
+	"Match booru URL",
+	func(site booru.Site, url string, expectedID string, expectedResult bool) {
+		provider := booru.New(site)
+
+		id, ok := provider.Match(url)
+		Expect(id).To(BeEquivalentTo(expectedID))
+		Expect(ok).To(BeEquivalentTo(expectedResult))
+	},
+	Entry("Danbooru post", booru.Danbooru, "https://danbooru.donmai.us/posts/7000000", "7000000", true),
+	Entry("Danbooru post with query", booru.Danbooru, "https://danbooru.donmai.us/posts/7000000?q=gawr_gura", "7000000", true),
+	Entry("Danbooru SFW mirror", booru.Danbooru, "https://safebooru.donmai.us/posts/7000000", "7000000", true),
+	Entry("Danbooru search", booru.Danbooru, "https://danbooru.donmai.us/posts?tags=gawr_gura", "", false),
+	Entry("Danbooru pool", booru.Danbooru, "https://danbooru.donmai.us/pools/123", "", false),
+	Entry("Gelbooru post", booru.Gelbooru, "https://gelbooru.com/index.php?page=post&s=view&id=9000000", "9000000", true),
+	Entry("Gelbooru post with tags", booru.Gelbooru, "https://gelbooru.com/index.php?page=post&s=view&id=9000000&tags=hololive", "9000000", true),
+	Entry("Gelbooru list", booru.Gelbooru, "https://gelbooru.com/index.php?page=post&s=list&tags=hololive", "", false),
+	Entry("Gelbooru link in Safebooru", booru.Safebooru, "https://gelbooru.com/index.php?page=post&s=view&id=9000000", "", false),
+	Entry("Safebooru post", booru.Safebooru, "https://safebooru.org/index.php?page=post&s=view&id=4500000", "4500000", true),
+	Entry("Yande.re post", booru.Yandere, "https://yande.re/post/show/1100000", "1100000", true),
+	Entry("Yande.re post with slug", booru.Yandere, "https://yande.re/post/show/1100000/genshin_impact-hu_tao", "1100000", true),
+	Entry("Yande.re non-numeric ID", booru.Yandere, "https://yande.re/post/show/abc", "", false),
+	Entry("Pixiv URL", booru.Danbooru, "https://pixiv.net/en/artworks/86341538", "", false),
+	Entry("Invalid URL", booru.Danbooru, "efe", "", false),
+)
+
+var _ = Describe("Find booru post", func() {
+	var server *httptest.Server
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should find a Danbooru post", func() {
+		server = fixtureServer(func(r *http.Request) string {
+			if r.URL.Path == "/posts/7000000.json" {
+				return "danbooru_post.json"
+			}
+
+			return ""
+		})
+
+		a, err := booru.NewWithURL(booru.Danbooru, server.URL).Find("7000000")
+		Expect(err).ToNot(HaveOccurred())
+
+		artwork := a.(*booru.Artwork)
+		Expect(artwork.Tags.Artists).To(ConsistOf("artist_name"))
+		Expect(artwork.Tags.Characters).To(ConsistOf("gawr_gura"))
+		Expect(artwork.Tags.Copyrights).To(ConsistOf("hololive", "hololive_english"))
+		Expect(artwork.Tags.General).To(ConsistOf("1girl", "blue_hair", "shark_tail", "solo"))
+		Expect(artwork.Rating).To(Equal(booru.RatingSensitive))
+		Expect(artwork.NSFW).To(BeFalse())
+		Expect(artwork.Source).To(Equal("https://www.pixiv.net/artworks/115000000"))
+		Expect(artwork.URL()).To(Equal("https://danbooru.donmai.us/posts/7000000"))
+
+		sends, err := artwork.MessageSends("", true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sends).To(HaveLen(1))
+
+		embed := sends[0].Embeds[0]
+		Expect(embed.Title).To(Equal("gawr_gura by artist_name"))
+		Expect(embed.Image.URL).To(ContainSubstring("/sample/"))
+		Expect(embed.Description).To(ContainSubstring("**Characters**"))
+		Expect(embed.Fields).To(ContainElement(HaveField("Name", "Source")))
+	})
+
+	It("should return an error if a Danbooru post doesn't exist", func() {
+		server = fixtureServer(func(r *http.Request) string { return "" })
+
+		_, err := booru.NewWithURL(booru.Danbooru, server.URL).Find("1")
+		Expect(err).To(MatchError(booru.ErrPostNotFound))
+	})
+
+	It("should find a Gelbooru post and categorise its tags", func() {
+		server = fixtureServer(func(r *http.Request) string {
+			query := r.URL.Query()
+			switch {
+			case query.Get("s") == "post" && query.Get("id") == "9000000":
+				return "gelbooru_post.json"
+			case query.Get("s") == "post":
+				return "gelbooru_empty.json"
+			case query.Get("s") == "tag":
+				return "gelbooru_tags.json"
+			}
+
+			return ""
+		})
+
+		provider := booru.NewWithURL(booru.Gelbooru, server.URL)
+		a, err := provider.Find("9000000")
+		Expect(err).ToNot(HaveOccurred())
+
+		artwork := a.(*booru.Artwork)
+		Expect(artwork.Tags.Artists).To(ConsistOf("artist_name"))
+		Expect(artwork.Tags.Characters).To(ConsistOf("ninomae_ina'nis"))
+		Expect(artwork.Tags.Copyrights).To(ConsistOf("hololive"))
+		Expect(artwork.Tags.General).To(ConsistOf("1girl", "solo"))
+		Expect(artwork.NSFW).To(BeTrue())
+		Expect(artwork.CreatedAt.Year()).To(Equal(2024))
+
+		_, err = provider.Find("1")
+		Expect(err).To(MatchError(booru.ErrPostNotFound))
+	})
+
+	It("should find a Safebooru post", func() {
+		server = fixtureServer(func(r *http.Request) string {
+			if r.URL.Query().Get("id") == "4500000" {
+				return "safebooru_post.json"
+			}
+
+			return ""
+		})
+
+		a, err := booru.NewWithURL(booru.Safebooru, server.URL).Find("4500000")
+		Expect(err).ToNot(HaveOccurred())
+
+		artwork := a.(*booru.Artwork)
+		Expect(artwork.Tags.General).To(ConsistOf("1girl", "hat", "smile", "solo"))
+		Expect(artwork.FileURL).To(Equal("https://safebooru.org/images/4567/fedcba9876543210fedcba9876543210.png"))
+		Expect(artwork.SampleURL).To(Equal("https://safebooru.org/samples/4567/sample_fedcba9876543210fedcba9876543210.jpg"))
+		Expect(artwork.NSFW).To(BeFalse())
+
+		sends, err := artwork.MessageSends("", true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sends[0].Embeds[0].Fields).ToNot(ContainElement(HaveField("Name", "Source")))
+	})
+
+	It("should find a Yande.re post", func() {
+		server = fixtureServer(func(r *http.Request) string {
+			query := r.URL.Query()
+			if r.URL.Path == "/post.json" && query.Get("tags") == "id:1100000" && query.Get("include_tags") == "1" {
+				return "yandere_post.json"
+			}
+
+			return ""
+		})
+
+		a, err := booru.NewWithURL(booru.Yandere, server.URL).Find("1100000")
+		Expect(err).ToNot(HaveOccurred())
+
+		artwork := a.(*booru.Artwork)
+		Expect(artwork.Tags.Artists).To(ConsistOf("artist_name"))
+		Expect(artwork.Tags.Copyrights).To(ConsistOf("genshin_impact"))
+		Expect(artwork.Tags.Characters).To(ConsistOf("hu_tao"))
+		Expect(artwork.Tags.General).To(ConsistOf("dress"))
+		Expect(artwork.Rating).To(Equal(booru.RatingGeneral))
+		Expect(artwork.NSFW).To(BeFalse())
+		Expect(artwork.StoreArtwork().Author).To(Equal("artist_name"))
+		Expect(artwork.StoreArtwork().Provider).To(Equal("yandere"))
+		Expect(artwork.StoreArtwork().Tags).To(Equal([]string{"genshin_impact", "hu_tao", "dress"}))
+	})
+})
+
+var _ = DescribeTable(
+	"Truncate booru post's tags",
+	func(tags *booru.Tags, kept string) {
+		artwork := &booru.Artwork{
+			ID:      "1",
+			Tags:    tags,
+			Rating:  booru.RatingGeneral,
+			FileURL: "https://danbooru.donmai.us/original/1.png",
+		}
+
+		var sends []*discordgo.MessageSend
+		Expect(func() {
+			var err error
+			sends, err = artwork.MessageSends("", true)
+			Expect(err).ToNot(HaveOccurred())
+		}).ToNot(Panic())
+
+		description := sends[0].Embeds[0].Description
+		Expect(utf8.RuneCountInString(description)).To(BeNumerically("<=", 4096))
+		Expect(utf8.ValidString(description)).To(BeTrue())
+		Expect(description).ToNot(HaveSuffix(" • "))
+		if kept != "" {
+			Expect(description).To(ContainSubstring(kept))
+		}
+	},
+	Entry("short tag list", &booru.Tags{General: []string{"1girl", "solo"}}, "[solo]"),
+	Entry("oversized tag list", &booru.Tags{General: repeatTags("tag", 500)}, "[tag0]"),
+	Entry("oversized tag", &booru.Tags{
+		Artists: []string{"artist"},
+		General: []string{strings.Repeat("a", 5000)},
+	}, "[artist]"),
+	Entry("oversized multibyte tag", &booru.Tags{General: []string{strings.Repeat("星", 5000)}}, ""),
+)
+
+//repeatTags returns n numbered tags with a prefix.
+func repeatTags(prefix string, n int) []string {
+	tags := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		tags = append(tags, prefix+strconv.Itoa(i))
+	}
+
+	return tags
+}
@@ -0,0 +1,29 @@
+package booru
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTruncateDescription(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		limit       int
+		want        string
+	}{
+		{name: "short", description: "[a] • [b]", limit: 10, want: "[a] • [b]"},
+		{name: "whole tags", description: "[a] • [b] • [c]", limit: 12, want: "[a] • [b]"},
+		{name: "whole lines", description: "**Tags**\n[abcdef]", limit: 12, want: "**Tags**"},
+		{name: "multibyte within limit", description: strings.Repeat("星", 10), limit: 10, want: strings.Repeat("星", 10)},
+		{name: "multibyte", description: strings.Repeat("星", 10), limit: 4, want: strings.Repeat("星", 4)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateDescription(tt.description, tt.limit); got != tt.want {
+				t.Errorf("truncateDescription() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
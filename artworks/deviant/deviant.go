@@ -2,6 +2,7 @@ package deviant
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -18,8 +19,17 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
+var ErrDeviationNotFound = fmt.Errorf("deviation %w", artworks.ErrNotFound)
+
 type DeviantArt struct {
-	regex *regexp.Regexp
+	regex       *regexp.Regexp
+	authorRegex *regexp.Regexp
+}
+
+type galleryFeed struct {
+	Items []struct {
+		Link string `xml:"link"`
+	} `xml:"channel>item"`
 }
 
 type Artwork struct {
@@ -32,7 +42,10 @@ type Artwork struct {
 	Favourites   int
 	Comments     int
 	CreatedAt    time.Time
-	url          string
+	//NSFW is true if DeviantArt marks the deviation as mature content.
+	NSFW bool
+	id   string
+	url  string
 }
 
 type Author struct {
@@ -64,7 +77,8 @@ type deviantEmbed struct {
 
 func New() artworks.Provider {
 	return &DeviantArt{
-		regex: regexp.MustCompile(`(?i)https:\/\/(?:www\.)?deviantart\.com\/[\w]+\/art\/([\w\-]+)`),
+		regex:       regexp.MustCompile(`(?i)https:\/\/(?:www\.)?deviantart\.com\/[\w]+\/art\/([\w\-]+)`),
+		authorRegex: regexp.MustCompile(`(?i)^https:\/\/(?:www\.)?deviantart\.com\/([\w\-]+)\/?(?:gallery\/?)?(?:\?.*)?$`),
 	}
 }
 
@@ -76,6 +90,14 @@ func (d *DeviantArt) Find(id string) (artworks.Artwork, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrDeviationNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("deviantart oembed request returned %v", resp.Status)
+	}
+
 	var res deviantEmbed
 	err = json.NewDecoder(resp.Body).Decode(&res)
 	if err != nil {
@@ -95,6 +117,8 @@ func (d *DeviantArt) Find(id string) (artworks.Artwork, error) {
 		Favourites:   res.Community.Statistics.Attributes.Favorites,
 		Comments:     res.Community.Statistics.Attributes.Comments,
 		CreatedAt:    res.Pubdate,
+		NSFW:         res.Safety != "" && res.Safety != "nonadult",
+		id:           id,
 		url:          res.AuthorURL + "/art/" + id,
 	}, nil
 }
@@ -108,8 +132,46 @@ func (d *DeviantArt) Match(s string) (string, bool) {
 	return res[1], true
 }
 
-func (d *DeviantArt) Enabled(g *store.Guild) bool {
-	return g.Deviant
+func (d *DeviantArt) MatchAuthor(s string) (string, bool) {
+	res := d.authorRegex.FindStringSubmatch(s)
+	if res == nil {
+		return "", false
+	}
+
+	return res[1], true
+}
+
+func (d *DeviantArt) RecentWorks(authorID string) ([]string, error) {
+	reqURL := "https://backend.deviantart.com/rss.xml?type=deviation&q=" + url.QueryEscape("gallery:"+authorID)
+	resp, err := http.Get(reqURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var res galleryFeed
+	err = xml.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, 0, len(res.Items))
+	for _, item := range res.Items {
+		if _, ok := d.Match(item.Link); ok {
+			urls = append(urls, item.Link)
+		}
+	}
+
+	return urls, nil
+}
+
+func (d *DeviantArt) Info() artworks.ProviderInfo {
+	return artworks.ProviderInfo{
+		Name:           "deviant",
+		DisplayName:    "DeviantArt",
+		DefaultEnabled: true,
+		NSFW:           true,
+	}
 }
 
 func (a *Artwork) MessageSends(footer string, hasTags bool) ([]*discordgo.MessageSend, error) {
@@ -143,13 +205,32 @@ func (a *Artwork) MessageSends(footer string, hasTags bool) ([]*discordgo.Messag
 
 func (a *Artwork) StoreArtwork() *store.Artwork {
 	return &store.Artwork{
-		Title:  a.Title,
-		Author: a.Author.Name,
-		URL:    a.url,
-		Images: []string{a.ImageURL},
+		Title:    a.Title,
+		Author:   a.Author.Name,
+		URL:      a.url,
+		Images:   []string{a.ImageURL},
+		Provider: "deviant",
+		SourceID: a.id,
+		Tags:     arrays.Filter(a.Tags, func(tag string) bool { return tag != "" }),
+		NSFW:     a.NSFW,
 	}
 }
 
+//IsNSFW reports if an artwork is marked as NSFW.
+func (a *Artwork) IsNSFW() bool {
+	return a.NSFW
+}
+
+//PreviewURL returns a thumbnail of the deviation.
+func (a *Artwork) PreviewURL() string {
+	return a.ThumbnailURL
+}
+
+//LikeCount returns the number of favourites of a deviation.
+func (a *Artwork) LikeCount() int {
+	return a.Favourites
+}
+
 func (a *Artwork) URL() string {
 	return a.url
 }
@@ -3,6 +3,7 @@ package deviant_test
 import (
 	"testing"
 
+	"github.com/VTGare/boe-tea-go/artworks"
 	"github.com/VTGare/boe-tea-go/artworks/deviant"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -27,3 +28,18 @@ var _ = DescribeTable(
 	Entry("Invalid URL", "https://www.deviantart.com/art/Arbor-Vitae-877183179", "", false),
 	Entry("Different domain", "https://www.somethingelse.com/q98e9N", "", false),
 )
+
+var _ = DescribeTable(
+	"Match Deviant author URL",
+	func(url string, expectedID string, expectedResult bool) {
+		provider := deviant.New().(artworks.Feed)
+
+		id, ok := provider.MatchAuthor(url)
+		Expect(id).To(BeEquivalentTo(expectedID))
+		Expect(ok).To(BeEquivalentTo(expectedResult))
+	},
+	Entry("Valid profile", "https://www.deviantart.com/bengeigerart", "bengeigerart", true),
+	Entry("Gallery", "https://www.deviantart.com/bengeigerart/gallery", "bengeigerart", true),
+	Entry("Artwork URL", "https://www.deviantart.com/bengeigerart/art/vt-123", "", false),
+	Entry("Different domain", "https://www.somethingelse.com/bengeigerart", "", false),
+)
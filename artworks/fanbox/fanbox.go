@@ -0,0 +1,238 @@
+package fanbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/artworks"
+	"github.com/VTGare/boe-tea-go/store"
+	"github.com/VTGare/embeds"
+	"github.com/bwmarrin/discordgo"
+)
+
+//API is the public Fanbox API. Post previews don't require authentication.
+const API = "https://api.fanbox.cc"
+
+//excerptLength is the maximum length of a post preview in runes.
+const excerptLength = 300
+
+var (
+	ErrPostNotFound = fmt.Errorf("fanbox post %w", artworks.ErrNotFound)
+
+	regex = regexp.MustCompile(
+		`(?i)https:\/\/(?:(?:[\w\-]+\.)?fanbox\.cc\/(?:@[\w\-]+\/)?)posts\/([0-9]+)`,
+	)
+)
+
+type Fanbox struct {
+	api    string
+	client *http.Client
+}
+
+type Artwork struct {
+	ID        string
+	Title     string
+	Author    string
+	CreatorID string
+	Excerpt   string
+	Tags      []string
+	//Fee is a price of the cheapest plan that unlocks the post in yen. Zero if the post is free.
+	Fee         int
+	Likes       int
+	Cover       string
+	Restricted  bool
+	NSFW        bool
+	PublishedAt time.Time
+}
+
+type postResponse struct {
+	Body *struct {
+		ID                string    `json:"id"`
+		Title             string    `json:"title"`
+		FeeRequired       int       `json:"feeRequired"`
+		PublishedDatetime time.Time `json:"publishedDatetime"`
+		Tags              []string  `json:"tags"`
+		LikeCount         int       `json:"likeCount"`
+		IsRestricted      bool      `json:"isRestricted"`
+		CreatorID         string    `json:"creatorId"`
+		HasAdultContent   bool      `json:"hasAdultContent"`
+		CoverImageURL     string    `json:"coverImageUrl"`
+		Excerpt           string    `json:"excerpt"`
+		User              struct {
+			Name string `json:"name"`
+		} `json:"user"`
+	} `json:"body"`
+	Error string `json:"error"`
+}
+
+//New creates a Fanbox artwork provider that uses the public API.
+func New() artworks.Provider {
+	return NewWithURL(API)
+}
+
+//NewWithURL creates a Fanbox artwork provider that uses a custom API URL.
+func NewWithURL(api string) artworks.Provider {
+	return &Fanbox{
+		api:    strings.TrimSuffix(api, "/"),
+		client: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+//Match matches <creator>.fanbox.cc/posts/<id> and fanbox.cc/@<creator>/posts/<id> URLs.
+func (f *Fanbox) Match(s string) (string, bool) {
+	res := regex.FindStringSubmatch(s)
+	if res == nil {
+		return "", false
+	}
+
+	return res[1], true
+}
+
+func (f *Fanbox) Find(id string) (artworks.Artwork, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/post.info?postId=%v", f.api, id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	//Fanbox API rejects requests without an Origin header.
+	req.Header.Set("Origin", "https://www.fanbox.cc")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrPostNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fanbox post request returned %v", resp.Status)
+	}
+
+	var res postResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+
+	if res.Body == nil {
+		return nil, ErrPostNotFound
+	}
+
+	post := res.Body
+	return &Artwork{
+		ID:          id,
+		Title:       post.Title,
+		Author:      post.User.Name,
+		CreatorID:   post.CreatorID,
+		Excerpt:     excerpt(post.Excerpt),
+		Tags:        post.Tags,
+		Fee:         post.FeeRequired,
+		Likes:       post.LikeCount,
+		Cover:       post.CoverImageURL,
+		Restricted:  post.IsRestricted,
+		NSFW:        post.HasAdultContent,
+		PublishedAt: post.PublishedDatetime,
+	}, nil
+}
+
+func (f *Fanbox) Info() artworks.ProviderInfo {
+	return artworks.ProviderInfo{
+		Name:           "fanbox",
+		DisplayName:    "Fanbox",
+		DefaultEnabled: true,
+		NSFW:           true,
+	}
+}
+
+func excerpt(s string) string {
+	s = strings.TrimSpace(s)
+
+	runes := []rune(s)
+	if len(runes) <= excerptLength {
+		return s
+	}
+
+	return strings.TrimSpace(string(runes[:excerptLength])) + "…"
+}
+
+func (a *Artwork) StoreArtwork() *store.Artwork {
+	images := make([]string, 0, 1)
+	if a.Cover != "" {
+		images = append(images, a.Cover)
+	}
+
+	return &store.Artwork{
+		Title:    a.Title,
+		Author:   a.Author,
+		URL:      a.URL(),
+		Images:   images,
+		Provider: "fanbox",
+		SourceID: a.ID,
+		Tags:     a.Tags,
+		NSFW:     a.NSFW,
+	}
+}
+
+func (a *Artwork) MessageSends(footer string, hasTags bool) ([]*discordgo.MessageSend, error) {
+	eb := embeds.NewBuilder()
+
+	eb.Title(fmt.Sprintf("%v by %v", a.Title, a.Author)).URL(a.URL()).Timestamp(a.PublishedAt)
+
+	description := a.Excerpt
+	if hasTags && len(a.Tags) > 0 {
+		description = strings.TrimSpace(fmt.Sprintf("%v\n\n**Tags**\n%v", description, strings.Join(a.Tags, " • ")))
+	}
+
+	if description != "" {
+		eb.Description(description)
+	}
+
+	eb.AddField("Plan", a.plan(), true)
+	eb.AddField("Likes", strconv.Itoa(a.Likes), true)
+
+	if a.Cover != "" {
+		eb.Image(a.Cover)
+	}
+
+	if footer != "" {
+		eb.Footer(footer, "")
+	}
+
+	return []*discordgo.MessageSend{
+		{Embeds: []*discordgo.MessageEmbed{eb.Finalize()}},
+	}, nil
+}
+
+//plan describes which supporters can view the post.
+func (a *Artwork) plan() string {
+	if a.Fee == 0 {
+		return "Free"
+	}
+
+	return fmt.Sprintf("¥%v / month", a.Fee)
+}
+
+//IsNSFW reports if a creator marked the post as adult content.
+func (a *Artwork) IsNSFW() bool {
+	return a.NSFW
+}
+
+//LikeCount returns the number of likes of a post.
+func (a *Artwork) LikeCount() int {
+	return a.Likes
+}
+
+func (a *Artwork) URL() string {
+	return fmt.Sprintf("https://%v.fanbox.cc/posts/%v", a.CreatorID, a.ID)
+}
+
+//Len returns 1, a post is rendered as a single embed with its public preview.
+func (a *Artwork) Len() int {
+	return 1
+}
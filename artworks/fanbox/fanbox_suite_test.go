@@ -0,0 +1,108 @@
+package fanbox_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/VTGare/boe-tea-go/artworks"
+	"github.com/VTGare/boe-tea-go/artworks/fanbox"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestFanbox(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Fanbox Suite")
+}
+
+var _ = DescribeTable(
+	"Match Fanbox URL",
+	func(url string, expectedID string, expectedResult bool) {
+		provider := fanbox.New()
+
+		id, ok := provider.Match(url)
+		Expect(id).To(BeEquivalentTo(expectedID))
+		Expect(ok).To(BeEquivalentTo(expectedResult))
+	},
+	Entry("Creator subdomain", "https://someartist.fanbox.cc/posts/1001", "1001", true),
+	Entry("Creator path", "https://www.fanbox.cc/@someartist/posts/1001", "1001", true),
+	Entry("Query params", "https://someartist.fanbox.cc/posts/1001?utm_source=twitter", "1001", true),
+	Entry("Creator page", "https://someartist.fanbox.cc/", "", false),
+	Entry("ID with letters", "https://someartist.fanbox.cc/posts/abc", "", false),
+	Entry("Different domain", "https://someartist.example.com/posts/1001", "", false),
+)
+
+var _ = Describe("Find Fanbox post", func() {
+	var (
+		server   *httptest.Server
+		provider artworks.Provider
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/post.info" || r.Header.Get("Origin") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			body, err := os.ReadFile(filepath.Join("testdata", r.URL.Query().Get("postId")+".json"))
+			if err != nil {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":"general_error"}`))
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(body)
+		}))
+
+		provider = fanbox.NewWithURL(server.URL)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should find a restricted post preview", func() {
+		a, err := provider.Find("1001")
+		Expect(err).ToNot(HaveOccurred())
+
+		post := a.(*fanbox.Artwork)
+		Expect(post.Title).To(Equal("Summer illustration"))
+		Expect(post.Author).To(Equal("Some Artist"))
+		Expect(post.Fee).To(Equal(500))
+		Expect(post.Restricted).To(BeTrue())
+		Expect(post.URL()).To(Equal("https://someartist.fanbox.cc/posts/1001"))
+		Expect(post.StoreArtwork().Images).To(ConsistOf(HaveSuffix("cover.jpeg")))
+
+		sends, err := post.MessageSends("", true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sends).To(HaveLen(1))
+
+		embed := sends[0].Embeds[0]
+		Expect(embed.Title).To(Equal("Summer illustration by Some Artist"))
+		Expect(embed.Description).To(ContainSubstring("Full resolution"))
+		Expect(embed.Description).To(ContainSubstring("summer • original"))
+		Expect(embed.Fields[0].Value).To(Equal("¥500 / month"))
+		Expect(embed.Image.URL).To(HaveSuffix("cover.jpeg"))
+	})
+
+	It("should find a free adult post without a cover", func() {
+		a, err := provider.Find("1002")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(artworks.IsNSFW(a)).To(BeTrue())
+
+		sends, err := a.MessageSends("", false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sends[0].Embeds[0].Fields[0].Value).To(Equal("Free"))
+		Expect(sends[0].Embeds[0].Image).To(BeNil())
+	})
+
+	It("should return an error if post doesn't exist", func() {
+		_, err := provider.Find("404")
+		Expect(err).To(MatchError(fanbox.ErrPostNotFound))
+	})
+})
@@ -0,0 +1,250 @@
+package pixiv
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/artworks"
+	"github.com/VTGare/boe-tea-go/internal/arrays"
+	"github.com/VTGare/boe-tea-go/store"
+	"github.com/VTGare/embeds"
+	"github.com/bwmarrin/discordgo"
+	"github.com/microcosm-cc/bluemonday"
+)
+
+//Ajax is the base URL of Pixiv's web API. Novels are public and don't require authentication, unless they're R-18.
+const Ajax = "https://www.pixiv.net/ajax"
+
+//captionLength is the maximum length of a novel caption excerpt in runes.
+const captionLength = 300
+
+var (
+	ErrNovelNotFound = fmt.Errorf("pixiv novel %w", artworks.ErrNotFound)
+
+	novelRegex = regexp.MustCompile(
+		`(?i)http(?:s)?:\/\/(?:www\.)?pixiv\.net\/(?:en\/)?novel\/show\.php\?(?:[^#\s]*&)?id=([0-9]+)`,
+	)
+)
+
+//Novels is an artwork provider for Pixiv novels. It's separate from Pixiv provider, so novels can be toggled on their own.
+type Novels struct {
+	ajax   string
+	client *http.Client
+	proxy  ProxyFunc
+}
+
+type Novel struct {
+	ID         string
+	Title      string
+	Author     string
+	Caption    string
+	Tags       []string
+	Words      int
+	Characters int
+	Likes      int
+	Cover      *Image
+	NSFW       bool
+	CreatedAt  time.Time
+}
+
+//ajaxResponse is a response of Pixiv's web API. Body is an empty array if there's an error.
+type ajaxResponse struct {
+	Error   bool            `json:"error"`
+	Message string          `json:"message"`
+	Body    json.RawMessage `json:"body"`
+}
+
+type novelBody struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	CoverURL    string `json:"coverUrl"`
+	UserName    string `json:"userName"`
+	Tags        struct {
+		Tags []struct {
+			Tag string `json:"tag"`
+		} `json:"tags"`
+	} `json:"tags"`
+	WordCount      int       `json:"wordCount"`
+	CharacterCount int       `json:"characterCount"`
+	BookmarkCount  int       `json:"bookmarkCount"`
+	XRestrict      int       `json:"xRestrict"`
+	CreateDate     time.Time `json:"createDate"`
+}
+
+//NewNovels creates a Pixiv novel provider. If proxy is nil, LegacyProxy is used for cover images.
+func NewNovels(proxy ProxyFunc) artworks.Provider {
+	return NewNovelsWithURL(Ajax, proxy)
+}
+
+//NewNovelsWithURL creates a Pixiv novel provider that uses a custom web API URL.
+func NewNovelsWithURL(ajax string, proxy ProxyFunc) artworks.Provider {
+	return &Novels{
+		ajax:   strings.TrimSuffix(ajax, "/"),
+		client: &http.Client{Timeout: 15 * time.Second},
+		proxy:  proxy,
+	}
+}
+
+func (n *Novels) Match(s string) (string, bool) {
+	res := novelRegex.FindStringSubmatch(s)
+	if res == nil {
+		return "", false
+	}
+
+	return res[1], true
+}
+
+func (n *Novels) Find(id string) (artworks.Artwork, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/novel/%v", n.ajax, id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Referer", "https://www.pixiv.net/")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var res ajaxResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, fmt.Errorf("pixiv novel request returned %v: %w", resp.Status, err)
+	}
+
+	if res.Error {
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNovelNotFound
+		}
+
+		return nil, fmt.Errorf("pixiv novel request failed: %v", res.Message)
+	}
+
+	var body novelBody
+	if err := json.Unmarshal(res.Body, &body); err != nil {
+		return nil, err
+	}
+
+	novel := &Novel{
+		ID:         id,
+		Title:      body.Title,
+		Author:     body.UserName,
+		Caption:    caption(body.Description),
+		Words:      body.WordCount,
+		Characters: body.CharacterCount,
+		Likes:      body.BookmarkCount,
+		NSFW:       body.XRestrict > 0,
+		CreatedAt:  body.CreateDate,
+	}
+
+	for _, tag := range body.Tags.Tags {
+		novel.Tags = append(novel.Tags, tag.Tag)
+	}
+
+	if body.CoverURL != "" {
+		novel.Cover = &Image{Original: body.CoverURL, Preview: body.CoverURL, proxy: n.proxy}
+	}
+
+	return novel, nil
+}
+
+func (n *Novels) Info() artworks.ProviderInfo {
+	return artworks.ProviderInfo{
+		Name:           "pixiv.novel",
+		DisplayName:    "Pixiv Novels",
+		DefaultEnabled: true,
+		NSFW:           true,
+	}
+}
+
+//caption converts an HTML novel caption to an excerpt in plain text.
+func caption(description string) string {
+	description = strings.NewReplacer("<br />", "\n", "<br>", "\n").Replace(description)
+	description = bluemonday.StrictPolicy().Sanitize(description)
+	description = strings.TrimSpace(html.UnescapeString(description))
+
+	runes := []rune(description)
+	if len(runes) <= captionLength {
+		return description
+	}
+
+	return strings.TrimSpace(string(runes[:captionLength])) + "…"
+}
+
+func (n *Novel) StoreArtwork() *store.Artwork {
+	images := make([]string, 0, 1)
+	if n.Cover != nil {
+		images = append(images, n.Cover.originalProxy())
+	}
+
+	return &store.Artwork{
+		Title:    n.Title,
+		Author:   n.Author,
+		URL:      n.URL(),
+		Images:   images,
+		Provider: "pixiv.novel",
+		SourceID: n.ID,
+		Tags:     n.Tags,
+		NSFW:     n.NSFW,
+	}
+}
+
+func (n *Novel) MessageSends(footer string, hasTags bool) ([]*discordgo.MessageSend, error) {
+	eb := embeds.NewBuilder()
+
+	eb.Title(fmt.Sprintf("%v by %v", n.Title, n.Author)).URL(n.URL()).Timestamp(n.CreatedAt)
+
+	description := n.Caption
+	if hasTags && len(n.Tags) > 0 {
+		tags := arrays.Map(n.Tags, func(s string) string {
+			return fmt.Sprintf("[%v](https://pixiv.net/en/tags/%v/novels)", s, s)
+		})
+
+		description = strings.TrimSpace(fmt.Sprintf("%v\n\n**Tags**\n%v", description, strings.Join(tags, " • ")))
+	}
+
+	if description != "" {
+		eb.Description(description)
+	}
+
+	eb.AddField("Words", strconv.Itoa(n.Words), true)
+	eb.AddField("Likes", strconv.Itoa(n.Likes), true)
+
+	if n.Cover != nil {
+		eb.Image(n.Cover.previewProxy())
+	}
+
+	if footer != "" {
+		eb.Footer(footer, "")
+	}
+
+	return []*discordgo.MessageSend{
+		{Embeds: []*discordgo.MessageEmbed{eb.Finalize()}},
+	}, nil
+}
+
+//IsNSFW reports if a novel is marked as R-18 or R-18G.
+func (n *Novel) IsNSFW() bool {
+	return n.NSFW
+}
+
+//LikeCount returns the number of bookmarks of a novel.
+func (n *Novel) LikeCount() int {
+	return n.Likes
+}
+
+func (n *Novel) URL() string {
+	return "https://www.pixiv.net/novel/show.php?id=" + n.ID
+}
+
+//Len returns 1, a novel is rendered as a single embed with its cover.
+func (n *Novel) Len() int {
+	return 1
+}
@@ -0,0 +1,102 @@
+package pixiv_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/VTGare/boe-tea-go/artworks"
+	"github.com/VTGare/boe-tea-go/artworks/pixiv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = DescribeTable(
+	"Match Pixiv novel URL",
+	func(url string, expectedID string, expectedResult bool) {
+		provider := pixiv.NewNovels(nil)
+
+		id, ok := provider.Match(url)
+		Expect(id).To(BeEquivalentTo(expectedID))
+		Expect(ok).To(BeEquivalentTo(expectedResult))
+	},
+	Entry("Valid novel", "https://www.pixiv.net/novel/show.php?id=123456", "123456", true),
+	Entry("English URL", "https://pixiv.net/en/novel/show.php?id=123456", "123456", true),
+	Entry("Query params before ID", "https://pixiv.net/novel/show.php?mode=text&id=123456", "123456", true),
+	Entry("Artwork URL", "https://pixiv.net/artworks/123456", "", false),
+	Entry("Series URL", "https://pixiv.net/novel/series/123456", "", false),
+	Entry("Different domain", "https://google.com/novel/show.php?id=123456", "", false),
+)
+
+var _ = Describe("Find Pixiv novel", func() {
+	var (
+		server   *httptest.Server
+		provider artworks.Provider
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := os.ReadFile(filepath.Join("testdata", "novel-"+strings.TrimPrefix(r.URL.Path, "/novel/")+".json"))
+			if err != nil {
+				w.WriteHeader(http.StatusNotFound)
+				body, _ = os.ReadFile(filepath.Join("testdata", "novel-notfound.json"))
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(body)
+		}))
+
+		provider = pixiv.NewNovelsWithURL(server.URL, func(s string) string {
+			return strings.Replace(s, "https://i.pximg.net", "https://proxy.example.com", 1)
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should find a novel", func() {
+		a, err := provider.Find("2001")
+		Expect(err).ToNot(HaveOccurred())
+
+		novel := a.(*pixiv.Novel)
+		Expect(novel.Title).To(Equal("Tea Party"))
+		Expect(novel.Author).To(Equal("Some Writer"))
+		Expect(novel.Caption).To(Equal("First chapter of a series.\nEnjoy & comment!"))
+		Expect(novel.Tags).To(Equal([]string{"オリジナル", "fantasy"}))
+		Expect(novel.Words).To(Equal(4321))
+		Expect(novel.NSFW).To(BeFalse())
+		Expect(novel.URL()).To(Equal("https://www.pixiv.net/novel/show.php?id=2001"))
+		Expect(novel.StoreArtwork().Images).To(ConsistOf(HavePrefix("https://proxy.example.com")))
+		Expect(novel.StoreArtwork().Provider).To(Equal("pixiv.novel"))
+		Expect(novel.StoreArtwork().SourceID).To(Equal("2001"))
+
+		sends, err := novel.MessageSends("footer", true)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sends).To(HaveLen(1))
+
+		embed := sends[0].Embeds[0]
+		Expect(embed.Title).To(Equal("Tea Party by Some Writer"))
+		Expect(embed.Description).To(ContainSubstring("**Tags**"))
+		Expect(embed.Fields[0].Value).To(Equal("4321"))
+		Expect(embed.Image.URL).To(HavePrefix("https://proxy.example.com"))
+	})
+
+	It("should mark R-18 novels as NSFW and render novels without a cover", func() {
+		a, err := provider.Find("2002")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(artworks.IsNSFW(a)).To(BeTrue())
+		Expect(a.StoreArtwork().Images).To(BeEmpty())
+
+		sends, err := a.MessageSends("", false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sends[0].Embeds[0].Image).To(BeNil())
+	})
+
+	It("should return an error if novel doesn't exist", func() {
+		_, err := provider.Find("404")
+		Expect(err).To(MatchError(pixiv.ErrNovelNotFound))
+	})
+})
@@ -1,7 +1,9 @@
 package pixiv
 
 import (
+	"bytes"
 	"fmt"
+	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
@@ -14,16 +16,27 @@ import (
 	"github.com/VTGare/embeds"
 	"github.com/bwmarrin/discordgo"
 	"github.com/everpcpc/pixiv"
+	goCache "github.com/patrickmn/go-cache"
+	"go.uber.org/zap"
 )
 
 var (
+	ErrArtworkNotFound = fmt.Errorf("pixiv artwork %w", artworks.ErrNotFound)
+
 	regex = regexp.MustCompile(
 		`(?i)http(?:s)?:\/\/(?:www\.)?pixiv\.net\/(?:en\/)?(?:artworks\/|member_illust\.php\?)(?:mode=medium\&)?(?:illust_id=)?([0-9]+)`,
 	)
+	authorRegex = regexp.MustCompile(`(?i)http(?:s)?:\/\/(?:www\.)?pixiv\.net\/(?:en\/)?(?:users\/|member\.php\?id=)([0-9]+)`)
 )
 
 type Pixiv struct {
 	app *pixiv.AppPixivAPI
+
+	client *http.Client
+	proxy  ProxyFunc
+	//ugoiras caches rendered ugoira GIFs by artwork ID.
+	ugoiras *goCache.Cache
+	log     *zap.SugaredLogger
 }
 
 type Artwork struct {
@@ -36,21 +49,39 @@ type Artwork struct {
 	Tags   []string
 	Images []*Image
 	NSFW   bool
+	Ugoira *Ugoira
 	url    string
 }
 
 type Image struct {
 	Preview  string
 	Original string
+
+	proxy ProxyFunc
 }
 
-func New(authToken, refreshToken string) (artworks.Provider, error) {
+//ProxyFunc converts an i.pximg.net URL to a URL that can be embedded in Discord. pximg rejects requests without Pixiv referer.
+type ProxyFunc func(string) string
+
+//LegacyProxy replaces pximg host with the external Heroku proxy.
+func LegacyProxy(pximgURL string) string {
+	return strings.Replace(pximgURL, "https://i.pximg.net", "https://boe-tea-pximg.herokuapp.com", 1)
+}
+
+//New creates a Pixiv artwork provider. If proxy is nil, LegacyProxy is used.
+func New(authToken, refreshToken string, proxy ProxyFunc, log *zap.SugaredLogger) (artworks.Provider, error) {
 	_, err := pixiv.LoadAuth(authToken, refreshToken, time.Now())
 	if err != nil {
 		return nil, err
 	}
 
-	return &Pixiv{app: pixiv.NewApp()}, nil
+	return &Pixiv{
+		app:     pixiv.NewApp(),
+		client:  &http.Client{Timeout: 30 * time.Second},
+		proxy:   proxy,
+		ugoiras: goCache.New(time.Hour, 2*time.Hour),
+		log:     log,
+	}, nil
 }
 
 func (p *Pixiv) Match(s string) (string, bool) {
@@ -73,6 +104,11 @@ func (p *Pixiv) Find(id string) (artworks.Artwork, error) {
 		return nil, err
 	}
 
+	//Pixiv client doesn't return API errors, deleted and private artworks are decoded as empty illusts.
+	if illust.ID == 0 {
+		return nil, ErrArtworkNotFound
+	}
+
 	author := ""
 	if illust.User != nil {
 		author = illust.User.Name
@@ -96,6 +132,7 @@ func (p *Pixiv) Find(id string) (artworks.Artwork, error) {
 			img := &Image{
 				Original: page.OriginalImageURL,
 				Preview:  illust.Images.Large,
+				proxy:    p.proxy,
 			}
 
 			images = append(images, img)
@@ -106,6 +143,7 @@ func (p *Pixiv) Find(id string) (artworks.Artwork, error) {
 		img := &Image{
 			Original: page.Images.Original,
 			Preview:  page.Images.Large,
+			proxy:    p.proxy,
 		}
 
 		images = append(images, img)
@@ -124,19 +162,89 @@ func (p *Pixiv) Find(id string) (artworks.Artwork, error) {
 		Likes:  illust.TotalBookmarks,
 	}
 
+	if illust.Type == "ugoira" {
+		//Ugoira without metadata is posted as a static image.
+		ugoira, err := p.ugoira(id, i)
+		if err != nil {
+			p.log.Infof("Failed to get ugoira %v: %v", id, err)
+		} else {
+			artwork.Ugoira = ugoira
+		}
+	}
+
 	return artwork, nil
 }
 
-func (p *Pixiv) Enabled(g *store.Guild) bool {
-	return g.Pixiv
+func (p *Pixiv) ugoira(id string, illustID uint64) (*Ugoira, error) {
+	meta, err := p.app.UgoiraMetadata(illustID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ugoira metadata: %w", err)
+	}
+
+	frames := make([]*Frame, 0, len(meta.UgoiraMetadataUgoiraMetadata.Frames))
+	for _, frame := range meta.UgoiraMetadataUgoiraMetadata.Frames {
+		frames = append(frames, &Frame{
+			File:  frame.File,
+			Delay: time.Duration(frame.Delay) * time.Millisecond,
+		})
+	}
+
+	return &Ugoira{
+		ZipURL: meta.UgoiraMetadataUgoiraMetadata.ZipURLs.Medium,
+		Frames: frames,
+		id:     id,
+		client: p.client,
+		cache:  p.ugoiras,
+	}, nil
+}
+
+func (p *Pixiv) MatchAuthor(s string) (string, bool) {
+	res := authorRegex.FindStringSubmatch(s)
+	if res == nil {
+		return "", false
+	}
+
+	return res[1], true
+}
+
+func (p *Pixiv) RecentWorks(authorID string) ([]string, error) {
+	uid, err := strconv.ParseUint(authorID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	illusts, _, err := p.app.UserIllusts(uid, "illust", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, 0, len(illusts))
+	for _, illust := range illusts {
+		urls = append(urls, "https://pixiv.net/en/artworks/"+strconv.FormatUint(illust.ID, 10))
+	}
+
+	return urls, nil
+}
+
+func (p *Pixiv) Info() artworks.ProviderInfo {
+	return artworks.ProviderInfo{
+		Name:           "pixiv",
+		DisplayName:    "Pixiv",
+		DefaultEnabled: true,
+		NSFW:           true,
+	}
 }
 
 func (a *Artwork) StoreArtwork() *store.Artwork {
 	return &store.Artwork{
-		Title:  a.Title,
-		Author: a.Author,
-		URL:    a.url,
-		Images: a.imageURLs(),
+		Title:    a.Title,
+		Author:   a.Author,
+		URL:      a.url,
+		Images:   a.imageURLs(),
+		Provider: "pixiv",
+		SourceID: a.ID,
+		Tags:     a.Tags,
+		NSFW:     a.NSFW,
 	}
 }
 
@@ -180,8 +288,25 @@ func (a *Artwork) MessageSends(footer string, hasTags bool) ([]*discordgo.Messag
 		eb.Footer(footer, "")
 	}
 
+	first := &discordgo.MessageSend{}
 	eb.Image(a.Images[0].previewProxy())
-	pages = append(pages, &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{eb.Finalize()}})
+
+	//Ugoira are attached as animated GIFs. Static preview is used if a GIF is too large or failed to render.
+	if a.Ugoira != nil {
+		if data, err := a.Ugoira.GIF(); err == nil {
+			name := a.ID + ".gif"
+
+			eb.Image("attachment://" + name)
+			first.Files = []*discordgo.File{{
+				Name:        name,
+				ContentType: "image/gif",
+				Reader:      bytes.NewReader(data),
+			}}
+		}
+	}
+
+	first.Embeds = []*discordgo.MessageEmbed{eb.Finalize()}
+	pages = append(pages, first)
 	if length > 1 {
 		for ind, image := range a.Images[1:] {
 			eb := embeds.NewBuilder()
@@ -204,6 +329,25 @@ func (a *Artwork) MessageSends(footer string, hasTags bool) ([]*discordgo.Messag
 	return pages, nil
 }
 
+//IsNSFW reports if an artwork is marked as NSFW.
+func (a *Artwork) IsNSFW() bool {
+	return a.NSFW
+}
+
+//PreviewURL returns a proxied large preview of the first page.
+func (a *Artwork) PreviewURL() string {
+	if len(a.Images) == 0 {
+		return ""
+	}
+
+	return a.Images[0].previewProxy()
+}
+
+//LikeCount returns the number of bookmarks of an artwork.
+func (a *Artwork) LikeCount() int {
+	return a.Likes
+}
+
 func (a *Artwork) URL() string {
 	return a.url
 }
@@ -223,9 +367,17 @@ func (a *Artwork) imageURLs() []string {
 }
 
 func (i Image) originalProxy() string {
-	return strings.Replace(i.Original, "https://i.pximg.net", "https://boe-tea-pximg.herokuapp.com", 1)
+	return i.proxyURL(i.Original)
 }
 
 func (i Image) previewProxy() string {
-	return strings.Replace(i.Preview, "https://i.pximg.net", "https://boe-tea-pximg.herokuapp.com", 1)
+	return i.proxyURL(i.Preview)
+}
+
+func (i Image) proxyURL(pximgURL string) string {
+	if i.proxy == nil {
+		return LegacyProxy(pximgURL)
+	}
+
+	return i.proxy(pximgURL)
 }
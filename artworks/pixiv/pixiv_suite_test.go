@@ -31,3 +31,19 @@ var _ = DescribeTable(
 	Entry("ID with letters", "https://pixiv.net/artworks/qwerty", "", false),
 	Entry("Different domain", "https://google.com/artworks/123456", "", false),
 )
+
+var _ = DescribeTable(
+	"Match Pixiv author URL",
+	func(url string, expectedID string, expectedResult bool) {
+		provider := pixiv.Pixiv{}
+
+		id, ok := provider.MatchAuthor(url)
+		Expect(id).To(BeEquivalentTo(expectedID))
+		Expect(ok).To(BeEquivalentTo(expectedResult))
+	},
+	Entry("Valid profile", "https://www.pixiv.net/users/123456", "123456", true),
+	Entry("English URL", "https://pixiv.net/en/users/123456", "123456", true),
+	Entry("Legacy URL", "https://pixiv.net/member.php?id=123456", "123456", true),
+	Entry("Artwork URL", "https://pixiv.net/artworks/123456", "", false),
+	Entry("Different domain", "https://google.com/users/123456", "", false),
+)
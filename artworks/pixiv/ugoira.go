@@ -0,0 +1,210 @@
+package pixiv
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"net/http"
+	"time"
+
+	_ "image/jpeg"
+	_ "image/png"
+
+	goCache "github.com/patrickmn/go-cache"
+)
+
+//MaxUgoiraSize is the largest GIF that's attached to a message. Discord rejects larger uploads.
+const MaxUgoiraSize = 8 * 1024 * 1024
+
+//Limits of ugoira archives. Frames are decoded in memory before they're encoded to a GIF,
+//so frame dimensions are limited per frame and in total.
+const (
+	maxArchiveSize  = 64 * 1024 * 1024
+	maxFrameSize    = 16 * 1024 * 1024
+	maxFramePixels  = 4096 * 4096
+	maxUgoiraPixels = 256 * 1024 * 1024
+)
+
+var (
+	ErrUgoiraTooLarge = errors.New("ugoira is too large")
+	ErrMissingFrame   = errors.New("ugoira frame is missing from the archive")
+)
+
+//Ugoira is an animated Pixiv artwork. Its frames are stored in a ZIP archive.
+type Ugoira struct {
+	ZipURL string
+	Frames []*Frame
+
+	id     string
+	client *http.Client
+	cache  *goCache.Cache
+}
+
+//Frame is a ugoira frame. File is a file name in the ZIP archive.
+type Frame struct {
+	File  string
+	Delay time.Duration
+}
+
+//ugoiraRender is a cached render result. Renders that failed because they're too large are cached as well.
+type ugoiraRender struct {
+	gif []byte
+	err error
+}
+
+//GIF downloads ugoira's frames and renders them to an animated GIF. Results are cached by artwork ID.
+func (u *Ugoira) GIF() ([]byte, error) {
+	if r, ok := u.cache.Get(u.id); ok {
+		render := r.(*ugoiraRender)
+		return render.gif, render.err
+	}
+
+	archive, err := u.download()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := EncodeGIF(archive, u.Frames, MaxUgoiraSize)
+	if err == nil || errors.Is(err, ErrUgoiraTooLarge) {
+		u.cache.Set(u.id, &ugoiraRender{data, err}, 0)
+	}
+
+	return data, err
+}
+
+func (u *Ugoira) download() (*zip.Reader, error) {
+	req, err := http.NewRequest(http.MethodGet, u.ZipURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	//pximg rejects requests without Pixiv referer.
+	req.Header.Set("Referer", "https://www.pixiv.net/")
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download ugoira: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download ugoira: %v", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxArchiveSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ugoira: %w", err)
+	}
+
+	if len(body) > maxArchiveSize {
+		return nil, ErrUgoiraTooLarge
+	}
+
+	return zip.NewReader(bytes.NewReader(body), int64(len(body)))
+}
+
+//EncodeGIF encodes ugoira frames from a ZIP archive to an animated GIF.
+//ErrUgoiraTooLarge is returned as soon as the output exceeds limit bytes.
+func EncodeGIF(archive *zip.Reader, frames []*Frame, limit int) ([]byte, error) {
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	var (
+		anim = &gif.GIF{
+			Image: make([]*image.Paletted, 0, len(frames)),
+			Delay: make([]int, 0, len(frames)),
+		}
+		pixels int64
+	)
+
+	for _, frame := range frames {
+		f, ok := files[frame.File]
+		if !ok {
+			return nil, fmt.Errorf("%w: %v", ErrMissingFrame, frame.File)
+		}
+
+		img, err := decodeFrame(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode frame %v: %w", frame.File, err)
+		}
+
+		pixels += int64(img.Bounds().Dx()) * int64(img.Bounds().Dy())
+		if pixels > maxUgoiraPixels {
+			return nil, ErrUgoiraTooLarge
+		}
+
+		paletted := image.NewPaletted(img.Bounds(), palette.Plan9)
+		draw.FloydSteinberg.Draw(paletted, img.Bounds(), img, image.Point{})
+
+		anim.Image = append(anim.Image, paletted)
+		//GIF delays are in hundredths of a second.
+		anim.Delay = append(anim.Delay, int(frame.Delay/(10*time.Millisecond)))
+	}
+
+	buf := &limitedBuffer{limit: limit}
+	if err := gif.EncodeAll(buf, anim); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+//decodeFrame decodes a frame if it's within size limits. Sizes in the archive aren't trusted, the frame is read through a limit.
+func decodeFrame(f *zip.File) (image.Image, error) {
+	if f.UncompressedSize64 > maxFrameSize {
+		return nil, ErrUgoiraTooLarge
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxFrameSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxFrameSize {
+		return nil, ErrUgoiraTooLarge
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > maxFramePixels {
+		return nil, ErrUgoiraTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+//limitedBuffer is a bytes.Buffer that fails writes past the limit.
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.buf.Len()+len(p) > b.limit {
+		return 0, ErrUgoiraTooLarge
+	}
+
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
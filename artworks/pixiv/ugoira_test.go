@@ -0,0 +1,111 @@
+package pixiv_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/artworks/pixiv"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+//ugoiraArchive creates a ZIP archive with solid colour PNG frames named like Pixiv's, e.g. 000000.png.
+func ugoiraArchive(colors ...color.Color) *zip.Reader {
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+
+	for ind, c := range colors {
+		img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+		for x := 0; x < 16; x++ {
+			for y := 0; y < 16; y++ {
+				img.Set(x, y, c)
+			}
+		}
+
+		w, err := zw.Create(frameName(ind))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(png.Encode(w, img)).To(Succeed())
+	}
+
+	Expect(zw.Close()).To(Succeed())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	Expect(err).ToNot(HaveOccurred())
+
+	return zr
+}
+
+func frameName(ind int) string {
+	return fmt.Sprintf("%06d.png", ind)
+}
+
+var _ = Describe("Ugoira GIF encoding", func() {
+	var frames []*pixiv.Frame
+
+	BeforeEach(func() {
+		frames = []*pixiv.Frame{
+			{File: frameName(0), Delay: 100 * time.Millisecond},
+			{File: frameName(1), Delay: 250 * time.Millisecond},
+			{File: frameName(2), Delay: 40 * time.Millisecond},
+		}
+	})
+
+	It("should encode frames with their delays", func() {
+		archive := ugoiraArchive(color.White, color.Black, color.RGBA{R: 255, A: 255})
+
+		data, err := pixiv.EncodeGIF(archive, frames, pixiv.MaxUgoiraSize)
+		Expect(err).ToNot(HaveOccurred())
+
+		anim, err := gif.DecodeAll(bytes.NewReader(data))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(anim.Image).To(HaveLen(3))
+		Expect(anim.Delay).To(Equal([]int{10, 25, 4}))
+	})
+
+	It("should fail if the render exceeds the limit", func() {
+		archive := ugoiraArchive(color.White, color.Black, color.White)
+
+		_, err := pixiv.EncodeGIF(archive, frames, 64)
+		Expect(err).To(MatchError(pixiv.ErrUgoiraTooLarge))
+	})
+
+	It("should fail if a frame declares huge dimensions", func() {
+		buf := &bytes.Buffer{}
+		Expect(png.Encode(buf, image.NewGray(image.Rect(0, 0, 1, 1)))).To(Succeed())
+
+		//IHDR chunk follows the 8-byte signature: length, type, width, height, ..., CRC of type and data.
+		frame := buf.Bytes()
+		binary.BigEndian.PutUint32(frame[16:], 100000)
+		binary.BigEndian.PutUint32(frame[20:], 100000)
+		binary.BigEndian.PutUint32(frame[29:], crc32.ChecksumIEEE(frame[12:29]))
+
+		archive := &bytes.Buffer{}
+		zw := zip.NewWriter(archive)
+		w, err := zw.Create(frameName(0))
+		Expect(err).ToNot(HaveOccurred())
+		_, err = w.Write(frame)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(zw.Close()).To(Succeed())
+
+		zr, err := zip.NewReader(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = pixiv.EncodeGIF(zr, frames[:1], pixiv.MaxUgoiraSize)
+		Expect(err).To(MatchError(pixiv.ErrUgoiraTooLarge))
+	})
+
+	It("should fail if a frame is missing", func() {
+		archive := ugoiraArchive(color.White, color.Black)
+
+		_, err := pixiv.EncodeGIF(archive, frames, pixiv.MaxUgoiraSize)
+		Expect(err).To(MatchError(pixiv.ErrMissingFrame))
+	})
+})
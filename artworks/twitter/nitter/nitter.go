@@ -2,6 +2,7 @@ package nitter
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -25,6 +26,8 @@ const (
 	MediaTypeVideo
 )
 
+var ErrTweetNotFound = fmt.Errorf("tweet %w", artworks.ErrNotFound)
+
 type Nitter struct {
 	nitter []string
 }
@@ -97,6 +100,10 @@ func (t *Nitter) Find(snowflake string) (artworks.Artwork, error) {
 	var lastError error
 	for _, nitter := range t.nitter {
 		a, err := t.scrapeTwitter(snowflake, nitter)
+		if errors.Is(err, ErrTweetNotFound) {
+			return nil, err
+		}
+
 		if err != nil {
 			lastError = err
 			continue
@@ -108,8 +115,15 @@ func (t *Nitter) Find(snowflake string) (artworks.Artwork, error) {
 	return nil, lastError
 }
 
-func (t *Nitter) Enabled(g *store.Guild) bool {
-	return g.Twitter
+//Info describes Nitter as a provider. Nitter is only used as Twitter's fallback, so it doesn't share Twitter's name
+//and guild toggles.
+func (t *Nitter) Info() artworks.ProviderInfo {
+	return artworks.ProviderInfo{
+		Name:           "nitter",
+		DisplayName:    "Nitter",
+		DefaultEnabled: true,
+		NSFW:           true,
+	}
 }
 
 func (t *Nitter) scrapeTwitter(snowflake, baseURL string) (*Artwork, error) {
@@ -125,6 +139,10 @@ func (t *Nitter) scrapeTwitter(snowflake, baseURL string) (*Artwork, error) {
 	// 	retryAfter := resp.Header.Get("Retry-After")
 	// }
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrTweetNotFound
+	}
+
 	if resp.StatusCode != 200 {
 		return nil, fmt.Errorf("unexpected status code: %v", resp.Status)
 	}
@@ -189,10 +207,13 @@ func parseCount(s string) int {
 //StoreArtwork transforms an artwork to an insertable to database artwork model.
 func (a *Artwork) StoreArtwork() *store.Artwork {
 	return &store.Artwork{
-		Title:  "",
-		Author: a.Username,
-		URL:    a.url,
-		Images: a.Gallery.Strings(),
+		Title:    "",
+		Author:   a.Username,
+		URL:      a.url,
+		Images:   a.Gallery.Strings(),
+		Provider: "twitter",
+		SourceID: a.Snowflake,
+		NSFW:     a.IsNSFW(),
 	}
 }
 
@@ -245,7 +266,7 @@ func (a *Artwork) MessageSends(footer string, _ bool) ([]*discordgo.MessageSend,
 		}
 	}
 
-	msg.Embed = eb.Finalize()
+	msg.Embeds = []*discordgo.MessageEmbed{eb.Finalize()}
 	tweets = append(tweets, msg)
 
 	if length > 1 {
@@ -266,6 +287,11 @@ func (a *Artwork) MessageSends(footer string, _ bool) ([]*discordgo.MessageSend,
 	return tweets, nil
 }
 
+//IsNSFW reports if a tweet is NSFW. Nitter is only used for tweets Twitter hides behind a sensitive content warning.
+func (a *Artwork) IsNSFW() bool {
+	return true
+}
+
 func (a *Artwork) URL() string {
 	return a.url
 }
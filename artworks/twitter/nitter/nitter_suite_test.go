@@ -3,6 +3,7 @@ package nitter_test
 import (
 	"testing"
 
+	"github.com/VTGare/boe-tea-go/artworks"
 	"github.com/VTGare/boe-tea-go/artworks/twitter/nitter"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -33,3 +34,23 @@ var _ = DescribeTable(
 	Entry("Different domain", "https://google.com/i/status/123456", "", false),
 	Entry("Invalid URL", "efe", "", false),
 )
+
+var _ = Describe("Nitter Artwork", func() {
+	It("should have its own provider name and store artworks as tweets", func() {
+		Expect(nitter.New().Info().Name).To(Equal("nitter"))
+		Expect((&nitter.Artwork{}).StoreArtwork().Provider).To(Equal("twitter"))
+	})
+
+	It("should be rated NSFW", func() {
+		Expect(artworks.IsNSFW(&nitter.Artwork{})).To(BeTrue())
+	})
+
+	It("should send the tweet in embeds", func() {
+		sends, err := (&nitter.Artwork{Gallery: nitter.Gallery{{URL: "https://example.com/1.png"}}}).MessageSends("", false)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(sends).To(HaveLen(1))
+		Expect(sends[0].Embed).To(BeNil())
+		Expect(sends[0].Embeds).To(HaveLen(1))
+		Expect(sends[0].Embeds[0].Image.URL).To(Equal("https://example.com/1.png"))
+	})
+})
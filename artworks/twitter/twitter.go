@@ -132,8 +132,63 @@ func (t *Twitter) Match(s string) (string, bool) {
 	return snowflake, true
 }
 
-func (Twitter) Enabled(g *store.Guild) bool {
-	return g.Twitter
+//reservedPaths are Twitter paths that look like a profile URL but aren't.
+var reservedPaths = map[string]bool{
+	"home": true, "explore": true, "search": true, "settings": true, "messages": true,
+	"notifications": true, "i": true, "hashtag": true, "intent": true, "share": true,
+}
+
+func (t *Twitter) MatchAuthor(s string) (string, bool) {
+	u, err := url.ParseRequestURI(s)
+	if err != nil {
+		return "", false
+	}
+
+	if u.Host != "twitter.com" && u.Host != "mobile.twitter.com" {
+		return "", false
+	}
+
+	parts := strings.FieldsFunc(u.Path, func(r rune) bool {
+		return r == '/'
+	})
+
+	if len(parts) != 1 && (len(parts) != 2 || parts[1] != "media") {
+		return "", false
+	}
+
+	username := parts[0]
+	if reservedPaths[strings.ToLower(username)] {
+		return "", false
+	}
+
+	return username, true
+}
+
+func (t *Twitter) RecentWorks(authorID string) ([]string, error) {
+	tweets, _, err := t.scraper.FetchTweets(authorID, 20, "")
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, 0, len(tweets))
+	for _, tweet := range tweets {
+		if tweet.IsRetweet || tweet.IsReply || len(tweet.Photos)+len(tweet.Videos) == 0 {
+			continue
+		}
+
+		urls = append(urls, fmt.Sprintf("https://twitter.com/%v/status/%v", tweet.Username, tweet.ID))
+	}
+
+	return urls, nil
+}
+
+func (Twitter) Info() artworks.ProviderInfo {
+	return artworks.ProviderInfo{
+		Name:           "twitter",
+		DisplayName:    "Twitter",
+		DefaultEnabled: true,
+		NSFW:           true,
+	}
 }
 
 func (artwork *Artwork) StoreArtwork() *store.Artwork {
@@ -145,9 +200,12 @@ func (artwork *Artwork) StoreArtwork() *store.Artwork {
 	}
 
 	return &store.Artwork{
-		Author: artwork.Username,
-		URL:    artwork.Permalink,
-		Images: media,
+		Author:   artwork.Username,
+		URL:      artwork.Permalink,
+		Images:   media,
+		Provider: "twitter",
+		SourceID: artwork.ID,
+		NSFW:     artwork.NSFW,
 	}
 }
 
@@ -231,6 +289,29 @@ func (a *Artwork) MessageSends(footer string, _ bool) ([]*discordgo.MessageSend,
 	return tweets, nil
 }
 
+//IsNSFW reports if an artwork is marked as NSFW.
+func (a *Artwork) IsNSFW() bool {
+	return a.NSFW
+}
+
+//PreviewURL returns a small version of the first photo.
+func (a *Artwork) PreviewURL() string {
+	if len(a.Photos) == 0 {
+		return ""
+	}
+
+	if strings.Contains(a.Photos[0], "?") {
+		return a.Photos[0]
+	}
+
+	return a.Photos[0] + "?name=small"
+}
+
+//LikeCount returns the number of likes of a tweet.
+func (a *Artwork) LikeCount() int {
+	return a.Likes
+}
+
 func (a *Artwork) URL() string {
 	return a.Permalink
 }
@@ -3,6 +3,7 @@ package twitter_test
 import (
 	"testing"
 
+	"github.com/VTGare/boe-tea-go/artworks"
 	"github.com/VTGare/boe-tea-go/artworks/twitter"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -34,3 +35,20 @@ var _ = DescribeTable(
 	Entry("Invalid URL", "efe", "", false),
 	Entry("vxtwitter link", "https://vxtwitter.com/i/status/1234", "", false),
 )
+
+var _ = DescribeTable(
+	"Match Twitter author URL",
+	func(url string, expectedID string, expectedResult bool) {
+		provider := twitter.New().(artworks.Feed)
+
+		id, ok := provider.MatchAuthor(url)
+		Expect(id).To(BeEquivalentTo(expectedID))
+		Expect(ok).To(BeEquivalentTo(expectedResult))
+	},
+	Entry("Valid profile", "https://twitter.com/watsonameliaEN", "watsonameliaEN", true),
+	Entry("Media tab", "https://twitter.com/watsonameliaEN/media", "watsonameliaEN", true),
+	Entry("Mobile URL", "https://mobile.twitter.com/watsonameliaEN", "watsonameliaEN", true),
+	Entry("Artwork URL", "https://twitter.com/watsonameliaEN/status/1371674594675937282", "", false),
+	Entry("Reserved path", "https://twitter.com/home", "", false),
+	Entry("Different domain", "https://google.com/watsonameliaEN", "", false),
+)
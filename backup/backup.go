@@ -0,0 +1,355 @@
+//Package backup exports user's favourites joined with their artworks and imports them back.
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/artworks"
+	"github.com/VTGare/boe-tea-go/internal/arrays"
+	"github.com/VTGare/boe-tea-go/internal/ratelimit"
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+//Supported export formats.
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatHTML = "html"
+)
+
+//batchSize is the number of artworks requested from the store at once.
+const batchSize = 500
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+//File is a JSON export of user's favourites.
+type File struct {
+	UserID     string    `json:"user_id"`
+	ExportedAt time.Time `json:"exported_at"`
+	Favourites []*Entry  `json:"favourites"`
+}
+
+//Entry is a bookmark joined with its artwork.
+type Entry struct {
+	ID         int       `json:"id"`
+	Title      string    `json:"title"`
+	Author     string    `json:"author"`
+	URL        string    `json:"url"`
+	Images     []string  `json:"images"`
+	NSFW       bool      `json:"nsfw"`
+	Collection string    `json:"collection,omitempty"`
+	Tags       []string  `json:"tags,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+//Result is a summary of an import.
+type Result struct {
+	//Added is the number of new bookmarks.
+	Added int
+	//Existing is the number of artworks that were already bookmarked. Existing bookmarks aren't changed.
+	Existing int
+	//Failed are URLs that couldn't be resolved by any provider.
+	Failed []string
+}
+
+//ContentType returns a MIME type of an export format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv"
+	case FormatHTML:
+		return "text/html"
+	default:
+		return "application/json"
+	}
+}
+
+//Export returns user's bookmarks joined with their artworks, newest first. Bookmarks of deleted artworks are skipped.
+func Export(ctx context.Context, st store.Store, userID string) ([]*Entry, error) {
+	bookmarks, err := st.ListBookmarks(ctx, userID, store.BookmarkQuery{}, store.Descending)
+	if err != nil {
+		return nil, err
+	}
+
+	artworks := make(map[int]*store.Artwork, len(bookmarks))
+	for start := 0; start < len(bookmarks); start += batchSize {
+		end := start + batchSize
+		if end > len(bookmarks) {
+			end = len(bookmarks)
+		}
+
+		ids := make([]int, 0, end-start)
+		for _, bookmark := range bookmarks[start:end] {
+			ids = append(ids, bookmark.ArtworkID)
+		}
+
+		found, err := st.SearchArtworks(ctx, store.ArtworkFilter{IDs: ids}, store.ArtworkSearchOptions{
+			Limit: int64(len(ids)),
+			Order: store.Descending,
+			Sort:  store.ByTime,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, artwork := range found {
+			artworks[artwork.ID] = artwork
+		}
+	}
+
+	entries := make([]*Entry, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		artwork, ok := artworks[bookmark.ArtworkID]
+		if !ok {
+			continue
+		}
+
+		entries = append(entries, &Entry{
+			ID:         artwork.ID,
+			Title:      artwork.Title,
+			Author:     artwork.Author,
+			URL:        artwork.URL,
+			Images:     artwork.Images,
+			NSFW:       bookmark.NSFW,
+			Collection: bookmark.Collection,
+			Tags:       bookmark.Tags,
+			CreatedAt:  bookmark.CreatedAt,
+		})
+	}
+
+	return entries, nil
+}
+
+//Encode writes exported favourites in one of the supported formats.
+func Encode(w io.Writer, format string, file *File) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(file)
+	case FormatCSV:
+		return encodeCSV(w, file.Favourites)
+	case FormatHTML:
+		return htmlTemplate.Execute(w, file)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+func encodeCSV(w io.Writer, entries []*Entry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"id", "title", "author", "url", "images", "nsfw", "collection", "tags", "created_at"}); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		err := cw.Write([]string{
+			strconv.Itoa(e.ID),
+			e.Title,
+			e.Author,
+			e.URL,
+			strings.Join(e.Images, " "),
+			strconv.FormatBool(e.NSFW),
+			e.Collection,
+			strings.Join(e.Tags, " "),
+			e.CreatedAt.UTC().Format(time.RFC3339),
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+var htmlTemplate = template.Must(template.New("favourites").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Boe Tea favourites</title>
+<style>
+body { font-family: sans-serif; }
+td { padding: 4px 8px; vertical-align: top; }
+img { max-width: 150px; max-height: 150px; }
+</style>
+</head>
+<body>
+<h1>Favourites</h1>
+<p>Exported at {{ .ExportedAt.UTC.Format "2006-01-02 15:04 MST" }}.</p>
+<table>
+<tr><th>Preview</th><th>Title</th><th>Author</th><th>NSFW</th><th>Collection</th><th>Tags</th><th>Added</th></tr>
+{{- range .Favourites }}
+<tr>
+<td>{{ if .Images }}<img src="{{ index .Images 0 }}" loading="lazy">{{ end }}</td>
+<td><a href="{{ .URL }}">{{ if .Title }}{{ .Title }}{{ else }}{{ .URL }}{{ end }}</a></td>
+<td>{{ .Author }}</td>
+<td>{{ if .NSFW }}Yes{{ else }}No{{ end }}</td>
+<td>{{ .Collection }}</td>
+<td>{{ range $i, $tag := .Tags }}{{ if $i }}, {{ end }}{{ $tag }}{{ end }}</td>
+<td>{{ .CreatedAt.UTC.Format "2006-01-02" }}</td>
+</tr>
+{{- end }}
+</table>
+</body>
+</html>
+`))
+
+//Parse parses an import. It accepts a JSON export, a JSON array of entries or a plain list of artwork URLs separated by whitespace.
+func Parse(data []byte) ([]*Entry, error) {
+	data = bytes.TrimSpace(data)
+
+	switch {
+	case bytes.HasPrefix(data, []byte("{")):
+		var file File
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to decode an export: %w", err)
+		}
+
+		return file.Favourites, nil
+	case bytes.HasPrefix(data, []byte("[")):
+		entries := make([]*Entry, 0)
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("failed to decode an export: %w", err)
+		}
+
+		return entries, nil
+	}
+
+	entries := make([]*Entry, 0)
+	for _, field := range strings.Fields(string(data)) {
+		if strings.HasPrefix(field, "http://") || strings.HasPrefix(field, "https://") {
+			entries = append(entries, &Entry{URL: field})
+		}
+	}
+
+	return entries, nil
+}
+
+//Import bookmarks artworks for a user. Artworks that aren't in the store are resolved through providers and created,
+//requests to providers are spaced out by the limiter. Missing collections are created. Entries that can't be resolved
+//are reported in Result, import stops only if the store fails or the context is cancelled.
+func Import(ctx context.Context, st store.Store, providers []artworks.Provider, limiter *ratelimit.Limiter, userID string, entries []*Entry) (*Result, error) {
+	collections, err := st.ListCollections(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(collections))
+	for _, col := range collections {
+		known[col.Name] = true
+	}
+
+	res := &Result{Failed: make([]string, 0)}
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+
+		artwork, nsfw, err := resolve(ctx, st, providers, limiter, entry.URL)
+		if err != nil {
+			return res, err
+		}
+
+		if artwork == nil {
+			res.Failed = append(res.Failed, entry.URL)
+			continue
+		}
+
+		collection := strings.ToLower(entry.Collection)
+		if collection != "" && !known[collection] {
+			_, err := st.CreateCollection(ctx, &store.Collection{UserID: userID, Name: collection, CreatedAt: time.Now()})
+			if err != nil {
+				return res, err
+			}
+
+			known[collection] = true
+		}
+
+		bookmark := &store.Bookmark{
+			UserID:     userID,
+			ArtworkID:  artwork.ID,
+			NSFW:       entry.NSFW || nsfw,
+			Collection: collection,
+			Tags:       arrays.Map(entry.Tags, strings.ToLower),
+			CreatedAt:  entry.CreatedAt,
+		}
+
+		if bookmark.CreatedAt.IsZero() {
+			bookmark.CreatedAt = time.Now()
+		}
+
+		added, err := st.AddBookmark(ctx, bookmark)
+		if err != nil {
+			return res, err
+		}
+
+		if added {
+			res.Added++
+		} else {
+			res.Existing++
+		}
+	}
+
+	return res, nil
+}
+
+//resolve finds an artwork in the store by URL or creates it. Nil artwork is returned if no provider can find the artwork.
+//nsfw is true if the artwork was fetched from a provider that rated it NSFW.
+func resolve(ctx context.Context, st store.Store, providers []artworks.Provider, limiter *ratelimit.Limiter, url string) (*store.Artwork, bool, error) {
+	if url == "" {
+		return nil, false, nil
+	}
+
+	artwork, err := st.Artwork(ctx, 0, url)
+	if err == nil {
+		return artwork, false, nil
+	}
+
+	if !errors.Is(err, store.ErrArtworkNotFound) {
+		return nil, false, err
+	}
+
+	for _, provider := range providers {
+		id, ok := provider.Match(url)
+		if !ok {
+			continue
+		}
+
+		if err := limiter.Wait(ctx, provider.Info().Name); err != nil {
+			return nil, false, err
+		}
+
+		found, err := provider.Find(id)
+		if err != nil || found.Len() == 0 {
+			return nil, false, nil
+		}
+
+		//Stored artworks use canonical URLs, which may differ from the imported one.
+		artwork, err := st.Artwork(ctx, 0, found.URL())
+		if errors.Is(err, store.ErrArtworkNotFound) {
+			artwork, err = st.CreateArtwork(ctx, found.StoreArtwork())
+		}
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		return artwork, artworks.IsNSFW(found), nil
+	}
+
+	return nil, false, nil
+}
@@ -0,0 +1,290 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/artworks"
+	"github.com/VTGare/boe-tea-go/internal/ratelimit"
+	"github.com/VTGare/boe-tea-go/store"
+	"github.com/VTGare/boe-tea-go/store/memory"
+	"github.com/bwmarrin/discordgo"
+)
+
+const artworkPrefix = "https://example.com/artworks/"
+
+type testProvider struct{}
+
+func (testProvider) Match(url string) (string, bool) {
+	id := strings.TrimPrefix(url, artworkPrefix)
+	return id, id != url
+}
+
+func (testProvider) Find(id string) (artworks.Artwork, error) {
+	if id == "missing" {
+		return nil, errors.New("not found")
+	}
+
+	return &testArtwork{id: id}, nil
+}
+
+func (testProvider) Info() artworks.ProviderInfo {
+	return artworks.ProviderInfo{Name: "example", DisplayName: "Example"}
+}
+
+type testArtwork struct {
+	id string
+}
+
+func (a *testArtwork) StoreArtwork() *store.Artwork {
+	return &store.Artwork{Title: "artwork " + a.id, Author: "author", URL: a.URL(), Images: []string{a.URL() + ".png"}}
+}
+
+func (a *testArtwork) MessageSends(string, bool) ([]*discordgo.MessageSend, error) {
+	return nil, nil
+}
+
+func (a *testArtwork) URL() string  { return artworkPrefix + a.id }
+func (a *testArtwork) Len() int     { return 1 }
+func (a *testArtwork) IsNSFW() bool { return strings.HasPrefix(a.id, "nsfw") }
+
+func newTestStore(t *testing.T) store.Store {
+	t.Helper()
+
+	st := memory.New()
+	if err := st.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return st
+}
+
+func TestExport(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t)
+	now := time.Now()
+
+	for i, id := range []string{"1", "2"} {
+		artwork, err := st.CreateArtwork(ctx, (&testArtwork{id: id}).StoreArtwork())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = st.AddBookmark(ctx, &store.Bookmark{
+			UserID: "user", ArtworkID: artwork.ID, NSFW: i == 1, Tags: []string{"maid"}, CreatedAt: now.Add(time.Duration(i) * time.Minute),
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	//Bookmarks of deleted artworks are skipped.
+	if _, err := st.AddBookmark(ctx, &store.Bookmark{UserID: "user", ArtworkID: 100, CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Export(ctx, st, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 2 || entries[0].URL != artworkPrefix+"2" || !entries[0].NSFW || entries[0].Title != "artwork 2" {
+		t.Fatalf("Export() = %+v, want 2 entries, newest first", entries)
+	}
+
+	if len(entries[1].Images) != 1 || entries[1].Tags[0] != "maid" {
+		t.Errorf("Export()[1] = %+v, want images and tags", entries[1])
+	}
+}
+
+func TestEncode(t *testing.T) {
+	file := &File{
+		UserID:     "user",
+		ExportedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		Favourites: []*Entry{
+			{
+				ID: 1, Title: "Title, with comma", Author: "author", URL: artworkPrefix + "1", Images: []string{"a.png", "b.png"},
+				NSFW: true, Collection: "waifus", Tags: []string{"maid", "swimsuit"}, CreatedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Encode(&buf, FormatJSON, file); err != nil {
+			t.Fatal(err)
+		}
+
+		entries, err := Parse(buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(entries) != 1 || entries[0].Collection != "waifus" || len(entries[0].Tags) != 2 || !entries[0].CreatedAt.Equal(file.Favourites[0].CreatedAt) {
+			t.Errorf("Parse(Encode()) = %+v, want the exported entry", entries)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Encode(&buf, FormatCSV, file); err != nil {
+			t.Fatal(err)
+		}
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []string{"1", "Title, with comma", "author", artworkPrefix + "1", "a.png b.png", "true", "waifus", "maid swimsuit", "2022-01-01T00:00:00Z"}
+		if len(records) != 2 || strings.Join(records[1], "|") != strings.Join(want, "|") {
+			t.Errorf("Encode(csv) = %v, want a header and %v", records, want)
+		}
+	})
+
+	t.Run("html", func(t *testing.T) {
+		file := *file
+		file.Favourites = []*Entry{{Title: "<script>", URL: artworkPrefix + "1"}}
+
+		var buf bytes.Buffer
+		if err := Encode(&buf, FormatHTML, &file); err != nil {
+			t.Fatal(err)
+		}
+
+		if html := buf.String(); strings.Contains(html, "<script>") || !strings.Contains(html, artworkPrefix+"1") {
+			t.Errorf("Encode(html) = %v, want an escaped title and a link", html)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		if err := Encode(&bytes.Buffer{}, "xml", file); !errors.Is(err, ErrUnknownFormat) {
+			t.Errorf("Encode(xml) error = %v, want %v", err, ErrUnknownFormat)
+		}
+	})
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "export", data: `{"favourites": [{"url": "https://a"}, {"url": "https://b"}]}`, want: []string{"https://a", "https://b"}},
+		{name: "array", data: ` [{"url": "https://a"}]`, want: []string{"https://a"}},
+		{name: "urls", data: "https://a\nnot a url\r\nhttp://b https://c", want: []string{"https://a", "http://b", "https://c"}},
+		{name: "empty", data: "", want: []string{}},
+		{name: "invalid json", data: `{"favourites": [}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := Parse([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				got = append(got, entry.URL)
+			}
+
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+	st := newTestStore(t)
+
+	existing, err := st.CreateArtwork(ctx, (&testArtwork{id: "1"}).StoreArtwork())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := st.AddBookmark(ctx, &store.Bookmark{UserID: "user", ArtworkID: existing.ID, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	created := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []*Entry{
+		{URL: artworkPrefix + "1"},
+		{URL: artworkPrefix + "2", Collection: "Waifus", Tags: []string{"maid"}, CreatedAt: created},
+		{URL: artworkPrefix + "nsfw"},
+		{URL: artworkPrefix + "missing"},
+		{URL: "https://unknown.com/1"},
+	}
+
+	start := time.Now()
+	res, err := Import(ctx, st, []artworks.Provider{testProvider{}}, ratelimit.New(20*time.Millisecond, nil), "user", entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Added != 2 || res.Existing != 1 || len(res.Failed) != 2 {
+		t.Fatalf("Import() = %+v, want 2 added, 1 existing and 2 failed", res)
+	}
+
+	//Stored artworks and unknown URLs don't wait for the provider.
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("3 provider requests took %v, want at least 40ms", elapsed)
+	}
+
+	bookmarks, err := st.ListBookmarks(ctx, "user", store.BookmarkQuery{Collection: "waifus"}, store.Descending)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bookmarks) != 1 || !bookmarks[0].CreatedAt.Equal(created) || bookmarks[0].Tags[0] != "maid" {
+		t.Errorf("ListBookmarks(waifus) = %+v, want the imported bookmark", bookmarks)
+	}
+
+	collections, err := st.ListCollections(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(collections) != 1 {
+		t.Errorf("ListCollections() = %+v, want a created collection", collections)
+	}
+
+	bookmarks, err = st.ListBookmarks(ctx, "user", store.BookmarkQuery{Filter: store.BookmarkFilterUnsafe}, store.Descending)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bookmarks) != 1 {
+		t.Errorf("ListBookmarks(nsfw) = %+v, want the artwork rated by provider", bookmarks)
+	}
+}
+
+func TestImport_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	st := newTestStore(t)
+
+	limiter := ratelimit.New(time.Hour, nil)
+	if err := limiter.Wait(ctx, "example"); err != nil {
+		t.Fatal(err)
+	}
+
+	//The next request to the provider waits for an hour.
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	res, err := Import(ctx, st, []artworks.Provider{testProvider{}}, limiter, "user", []*Entry{{URL: artworkPrefix + "1"}})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Import() = %v, want %v", err, context.Canceled)
+	}
+
+	if res.Added != 0 {
+		t.Errorf("Import() = %+v, want nothing added", res)
+	}
+}
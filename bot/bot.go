@@ -2,6 +2,7 @@ package bot
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/ReneKroon/ttlcache"
@@ -9,6 +10,7 @@ import (
 	"github.com/VTGare/boe-tea-go/internal/apis/nhentai"
 	"github.com/VTGare/boe-tea-go/internal/cache"
 	"github.com/VTGare/boe-tea-go/internal/config"
+	"github.com/VTGare/boe-tea-go/internal/dgoutils"
 	"github.com/VTGare/boe-tea-go/repost"
 	"github.com/VTGare/boe-tea-go/stats"
 	"github.com/VTGare/boe-tea-go/store"
@@ -32,6 +34,8 @@ type Bot struct {
 	BannedUsers  *ttlcache.Cache
 	EmbedCache   *cache.EmbedCache
 	ArtworkCache *goCache.Cache
+	Interactions *cache.InteractionCache
+	Widgets      *dgoutils.WidgetManager
 
 	// services
 	Sengoku          *sengoku.Sengoku
@@ -41,6 +45,12 @@ type Bot struct {
 
 	ShardManager *shards.Manager
 	Store        store.Store
+
+	//Session replaces sessions of all shards if it's not nil. It's used to run handlers with a fake session in tests.
+	Session dgoutils.Session
+
+	//CommandPermissions are default Discord permissions required to run commands by command name.
+	CommandPermissions map[string]int64
 }
 
 func New(config *config.Config, store store.Store, logger *zap.SugaredLogger, rd repost.Detector) (*Bot, error) {
@@ -69,6 +79,8 @@ func New(config *config.Config, store store.Store, logger *zap.SugaredLogger, rd
 		RepostDetector: rd,
 		BannedUsers:    banned,
 		EmbedCache:     cache.NewEmbedCache(),
+		Interactions:   cache.NewInteractionCache(),
+		Widgets:        dgoutils.NewWidgetManager(2 * time.Minute),
 		ArtworkCache:   goCache.New(60*time.Minute, 90*time.Minute),
 		NHentai:        nh,
 		Sengoku:        sg,
@@ -85,10 +97,73 @@ func (b *Bot) AddProvider(provider artworks.Provider) {
 	b.ArtworkProviders = append(b.ArtworkProviders, provider)
 }
 
+//Provider finds an artwork provider by its name.
+func (b *Bot) Provider(name string) (artworks.Provider, bool) {
+	for _, provider := range b.ArtworkProviders {
+		if provider.Info().Name == name {
+			return provider, true
+		}
+	}
+
+	return nil, false
+}
+
 func (b *Bot) AddHandler(handler interface{}) {
 	b.ShardManager.AddHandler(handler)
 }
 
+//SessionFor returns a session handlers should use instead of shard's session s.
+func (b *Bot) SessionFor(s *discordgo.Session) dgoutils.Session {
+	if b.Session != nil {
+		return b.Session
+	}
+
+	return s
+}
+
+//SessionForGuild returns a session of the shard that serves a guild.
+func (b *Bot) SessionForGuild(guildID string) dgoutils.Session {
+	if b.Session != nil {
+		return b.Session
+	}
+
+	id, _ := strconv.ParseInt(guildID, 10, 64)
+	return b.ShardManager.SessionForGuild(id)
+}
+
+//SessionForDM returns a session that's used to send direct messages.
+func (b *Bot) SessionForDM() dgoutils.Session {
+	if b.Session != nil {
+		return b.Session
+	}
+
+	return b.ShardManager.SessionForDM()
+}
+
+//ReplyComplex sends a message to the channel a command was executed in. Commands executed
+//through an application command are responded to with an interaction response instead.
+func (b *Bot) ReplyComplex(ctx *gumi.Ctx, send *discordgo.MessageSend) (*discordgo.Message, error) {
+	if ctx.Event.Interaction != nil {
+		if i, ok := b.Interactions.Get(ctx.Event.Interaction.ID); ok {
+			return i.Respond(b.SessionFor(ctx.Session), send)
+		}
+	}
+
+	return b.SessionFor(ctx.Session).ChannelMessageSendComplex(ctx.Event.ChannelID, send)
+}
+
+//Reply responds to a command with a text message.
+func (b *Bot) Reply(ctx *gumi.Ctx, text string) error {
+	_, err := b.ReplyComplex(ctx, &discordgo.MessageSend{Content: text})
+	return err
+}
+
+//ReplyEmbed responds to a command with an embed.
+func (b *Bot) ReplyEmbed(ctx *gumi.Ctx, embed *discordgo.MessageEmbed) error {
+	_, err := b.ReplyComplex(ctx, &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}})
+	return err
+}
+
 func (b *Bot) Open() error {
 	b.ShardManager.AddHandler(b.Router.Handler())
 
@@ -105,5 +180,6 @@ func (b *Bot) Open() error {
 }
 
 func (b *Bot) Close() error {
+	b.Widgets.Close()
 	return b.ShardManager.Shutdown()
 }
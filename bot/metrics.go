@@ -0,0 +1,67 @@
+package bot
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/stats"
+)
+
+//MetricsHandler serves bot's metrics in Prometheus text exposition format.
+func (b *Bot) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if b.Stats == nil {
+			http.Error(w, "bot is not started", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", stats.ContentType)
+		if err := b.Stats.WriteMetrics(w, b.gauges()...); err != nil {
+			b.Log.Infof("Failed to write metrics: %v", err)
+		}
+	})
+}
+
+//gauges collects metrics that aren't counted by stats, e.g. guild count and shard latency.
+func (b *Bot) gauges() []stats.Gauge {
+	gauges := []stats.Gauge{
+		{Name: "boetea_uptime_seconds", Help: "Time since the bot was started.", Value: time.Since(b.StartTime).Seconds()},
+	}
+
+	if b.ArtworkCache != nil {
+		gauges = append(gauges, stats.Gauge{
+			Name:  "boetea_artwork_cache_items",
+			Help:  "Artworks and image hashes in the artwork cache.",
+			Value: float64(b.ArtworkCache.ItemCount()),
+		})
+	}
+
+	if b.ShardManager == nil {
+		return gauges
+	}
+
+	gauges = append(gauges, stats.Gauge{
+		Name:  "boetea_guilds",
+		Help:  "Guilds served by all shards.",
+		Value: float64(b.ShardManager.GuildCount()),
+	})
+
+	b.ShardManager.RLock()
+	defer b.ShardManager.RUnlock()
+
+	for id, shard := range b.ShardManager.Shards {
+		if shard.Session == nil {
+			continue
+		}
+
+		gauges = append(gauges, stats.Gauge{
+			Name:   "boetea_shard_latency_seconds",
+			Help:   "Heartbeat latency of a shard.",
+			Labels: map[string]string{"shard": strconv.Itoa(id)},
+			Value:  shard.Session.HeartbeatLatency().Seconds(),
+		})
+	}
+
+	return gauges
+}
@@ -0,0 +1,65 @@
+package bot
+
+import (
+	"context"
+	"errors"
+
+	"github.com/VTGare/boe-tea-go/internal/arrays"
+	"github.com/VTGare/boe-tea-go/internal/dgoutils"
+	"github.com/VTGare/boe-tea-go/store"
+	"github.com/VTGare/gumi"
+	"github.com/bwmarrin/discordgo"
+)
+
+//ManagePermissions are Discord permissions of server managers. Managers can't be denied commands in channels.
+const ManagePermissions = discordgo.PermissionAdministrator | discordgo.PermissionManageServer
+
+//ManagerCommands can only be run by server managers, they can't be granted to roles. Otherwise a role granted
+//bt!perms could grant itself any other command.
+var ManagerCommands = []string{"perms"}
+
+//ManagerOnly reports if a command can only be run by server managers.
+func ManagerOnly(command string) bool {
+	return arrays.Any(ManagerCommands, command)
+}
+
+//TakeCommandPermissions moves default Discord permissions of router's commands to CommandPermissions.
+//gumi rejects commands before they run, but roles granted with bt!perms can run commands without default permissions.
+func (b *Bot) TakeCommandPermissions() {
+	if b.CommandPermissions == nil {
+		b.CommandPermissions = make(map[string]int64)
+	}
+
+	for _, cmd := range b.Router.Commands {
+		if cmd.Permissions != 0 {
+			b.CommandPermissions[cmd.Name] = cmd.Permissions
+			cmd.Permissions = 0
+		}
+	}
+}
+
+//HasPermission reports if command's author has any of Discord permissions or one of their roles is granted the command.
+//Role grants are ignored for manager-only commands.
+func (b *Bot) HasPermission(ctx *gumi.Ctx, permission int64) (bool, error) {
+	s := b.SessionFor(ctx.Session)
+
+	ok, err := dgoutils.MemberHasPermission(s, ctx.Event.GuildID, ctx.Event.Author.ID, permission)
+	if err != nil || ok || ctx.Command == nil || ManagerOnly(ctx.Command.Name) {
+		return ok, err
+	}
+
+	guild, err := b.Store.Guild(context.Background(), ctx.Event.GuildID)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+
+	member, err := s.GuildMember(ctx.Event.GuildID, ctx.Event.Author.ID)
+	if err != nil {
+		return false, err
+	}
+
+	return guild.Permissions.Granted(member.Roles, ctx.Command.Name, ctx.Command.Group), nil
+}
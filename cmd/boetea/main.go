@@ -2,48 +2,162 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"github.com/VTGare/boe-tea-go/artworks/artstation"
+	"github.com/VTGare/boe-tea-go/artworks/bluesky"
+	"github.com/VTGare/boe-tea-go/artworks/booru"
 	"github.com/VTGare/boe-tea-go/artworks/deviant"
+	"github.com/VTGare/boe-tea-go/artworks/fanbox"
 	"github.com/VTGare/boe-tea-go/artworks/pixiv"
 	"github.com/VTGare/boe-tea-go/artworks/twitter"
 	"github.com/VTGare/boe-tea-go/bot"
 	"github.com/VTGare/boe-tea-go/commands"
+	"github.com/VTGare/boe-tea-go/feed"
 	"github.com/VTGare/boe-tea-go/handlers"
 	"github.com/VTGare/boe-tea-go/internal/config"
 	"github.com/VTGare/boe-tea-go/internal/logger"
+	"github.com/VTGare/boe-tea-go/internal/pximg"
+	"github.com/VTGare/boe-tea-go/refresh"
 	"github.com/VTGare/boe-tea-go/repost"
 	"github.com/VTGare/boe-tea-go/store"
+	memorystore "github.com/VTGare/boe-tea-go/store/memory"
 	"github.com/VTGare/boe-tea-go/store/mongo"
+	sqlstore "github.com/VTGare/boe-tea-go/store/sql"
 	"github.com/VTGare/gumi"
 
 	"github.com/getsentry/sentry-go"
+	_ "github.com/jackc/pgx/v4/stdlib"
 	cache "github.com/patrickmn/go-cache"
 	"go.uber.org/zap"
 )
 
-func initStore(mongoURI, database string) (store.Store, error) {
+func initStore(cfg *config.Config) (store.Store, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	mongo, err := mongo.New(ctx, mongoURI, database)
+	var (
+		backend store.Store
+		err     error
+	)
+
+	switch cfg.Store {
+	case "sqlite":
+		backend, err = sqlstore.New(ctx, sqlstore.DriverSQLite, cfg.SQL.DSN)
+	case "postgres":
+		backend, err = sqlstore.New(ctx, sqlstore.DriverPostgres, cfg.SQL.DSN)
+	case "memory":
+		backend = memorystore.New()
+		if cfg.Memory != nil && cfg.Memory.Snapshot != "" {
+			backend = memorystore.NewSnapshot(cfg.Memory.Snapshot)
+		}
+	case "", "mongo":
+		backend, err = mongo.New(ctx, cfg.Mongo.URI, cfg.Mongo.Database)
+	default:
+		return nil, fmt.Errorf("unknown store: %v", cfg.Store)
+	}
+
 	if err != nil {
 		return nil, err
 	}
 
-	if err := mongo.Init(ctx); err != nil {
+	if err := backend.Init(ctx); err != nil {
 		return nil, err
 	}
 
-	store := store.NewStatefulStore(mongo, cache.New(30*time.Minute, 1*time.Hour))
+	store := store.NewStatefulStore(backend, cache.New(30*time.Minute, 1*time.Hour))
 	return store, nil
 }
 
+//initPximg creates a pximg URL signer and a proxy server. The server is nil if the proxy runs elsewhere.
+func initPximg(cfg *config.Pximg) (*pximg.Signer, *http.Server, error) {
+	signer := pximg.NewSigner(cfg.BaseURL, cfg.Secret)
+	if cfg.Address == "" {
+		return signer, nil, nil
+	}
+
+	dir := cfg.CacheDir
+	if dir == "" {
+		dir = "pximg-cache"
+	}
+
+	size := cfg.CacheSize
+	if size == 0 {
+		size = 1024
+	}
+
+	dc, err := pximg.NewDiskCache(dir, size*1024*1024)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return signer, &http.Server{
+		Addr:              cfg.Address,
+		Handler:           pximg.NewProxy(signer, dc),
+		ReadHeaderTimeout: 10 * time.Second,
+	}, nil
+}
+
+//feedOptions converts feed scheduler configuration to scheduler options. Shard count is known only after the bot is started.
+func feedOptions(cfg *config.Feeds, shardCount int) (feed.Options, error) {
+	opts := feed.Options{ShardCount: shardCount}
+	if cfg == nil {
+		return opts, nil
+	}
+
+	var err error
+	if cfg.Interval != "" {
+		if opts.Interval, err = time.ParseDuration(cfg.Interval); err != nil {
+			return opts, fmt.Errorf("invalid feed interval: %w", err)
+		}
+	}
+
+	if cfg.RateLimit != "" {
+		if opts.RateLimit, err = time.ParseDuration(cfg.RateLimit); err != nil {
+			return opts, fmt.Errorf("invalid feed rate limit: %w", err)
+		}
+	}
+
+	opts.Shards = cfg.Shards
+	return opts, nil
+}
+
+//refreshOptions converts artwork refresh job configuration to refresher options.
+func refreshOptions(cfg *config.Refresh) (refresh.Options, error) {
+	opts := refresh.Options{}
+	if cfg == nil {
+		return opts, nil
+	}
+
+	var err error
+	if cfg.Interval != "" {
+		if opts.Interval, err = time.ParseDuration(cfg.Interval); err != nil {
+			return opts, fmt.Errorf("invalid refresh interval: %w", err)
+		}
+	}
+
+	if cfg.MaxAge != "" {
+		if opts.MaxAge, err = time.ParseDuration(cfg.MaxAge); err != nil {
+			return opts, fmt.Errorf("invalid refresh max age: %w", err)
+		}
+	}
+
+	if cfg.RateLimit != "" {
+		if opts.RateLimit, err = time.ParseDuration(cfg.RateLimit); err != nil {
+			return opts, fmt.Errorf("invalid refresh rate limit: %w", err)
+		}
+	}
+
+	opts.BatchSize = cfg.BatchSize
+	return opts, nil
+}
+
 func main() {
 	cfg, err := config.FromFile("config.json")
 	if err != nil {
@@ -70,7 +184,7 @@ func main() {
 
 	log := zapLogger.Sugar()
 
-	store, err := initStore(cfg.Mongo.URI, cfg.Mongo.Database)
+	store, err := initStore(cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -94,11 +208,44 @@ func main() {
 	b.AddProvider(twitter.New())
 	b.AddProvider(deviant.New())
 	b.AddProvider(artstation.New())
-	if pixiv, err := pixiv.New(cfg.Pixiv.AuthToken, cfg.Pixiv.RefreshToken); err == nil {
+	b.AddProvider(bluesky.New())
+	b.AddProvider(fanbox.New())
+	b.AddProvider(booru.New(booru.Danbooru))
+	b.AddProvider(booru.New(booru.Gelbooru))
+	b.AddProvider(booru.New(booru.Safebooru))
+	b.AddProvider(booru.New(booru.Yandere))
+	var (
+		pixivProxy  pixiv.ProxyFunc
+		pximgServer *http.Server
+	)
+
+	if cfg.Pximg != nil && cfg.Pximg.BaseURL != "" && cfg.Pximg.Secret != "" {
+		signer, server, err := initPximg(cfg.Pximg)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		pixivProxy = signer.URL
+		pximgServer = server
+	}
+
+	if pximgServer != nil {
+		go func() {
+			log.Infof("Starting pximg proxy on %v", pximgServer.Addr)
+			if err := pximgServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Errorf("pximg proxy has stopped: %v", err)
+			}
+		}()
+	}
+
+	if pixiv, err := pixiv.New(cfg.Pixiv.AuthToken, cfg.Pixiv.RefreshToken, pixivProxy, log); err == nil {
 		log.Info("Successfully logged into Pixiv.")
 		b.AddProvider(pixiv)
 	}
 
+	//Novels use Pixiv's public web API and don't require a Pixiv login.
+	b.AddProvider(pixiv.NewNovels(pixivProxy))
+
 	b.AddRouter(&gumi.Router{
 		Commands:                make(map[string]*gumi.Command),
 		AuthorID:                cfg.Discord.AuthorID,
@@ -119,10 +266,58 @@ func main() {
 		log.Fatal(err)
 	}
 
+	var metricsServer *http.Server
+	if cfg.Metrics != nil && cfg.Metrics.Address != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", b.MetricsHandler())
+
+		metricsServer = &http.Server{
+			Addr:              cfg.Metrics.Address,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		go func() {
+			log.Infof("Starting metrics server on %v", metricsServer.Addr)
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Errorf("metrics server has stopped: %v", err)
+			}
+		}()
+	}
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	if cfg.Feeds != nil && cfg.Feeds.Enabled {
+		opts, err := feedOptions(cfg.Feeds, b.ShardManager.ShardCount)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		go feed.New(b, opts).Run(jobsCtx)
+	}
+
+	if cfg.Refresh != nil && cfg.Refresh.Enabled {
+		opts, err := refreshOptions(cfg.Refresh)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		go refresh.New(b, opts).Run(jobsCtx)
+	}
+
 	sc := make(chan os.Signal, 1)
 	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
 	<-sc
 
+	stopJobs()
+
+	for _, server := range []*http.Server{pximgServer, metricsServer} {
+		if server != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			server.Shutdown(ctx)
+			cancel()
+		}
+	}
+
 	store.Close(context.Background())
 	repostDetector.Close()
 	b.Close()
@@ -3,11 +3,13 @@ package commands
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/VTGare/boe-tea-go/artworks"
 	"github.com/VTGare/boe-tea-go/bot"
 	"github.com/VTGare/boe-tea-go/commands/flags"
 	"github.com/VTGare/boe-tea-go/internal/arrays"
@@ -19,7 +21,6 @@ import (
 	"github.com/VTGare/embeds"
 	"github.com/VTGare/gumi"
 	"github.com/bwmarrin/discordgo"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func artworksGroup(b *bot.Bot) {
@@ -59,14 +60,19 @@ func artworksGroup(b *bot.Bot) {
 		Name:        "search",
 		Group:       group,
 		Aliases:     []string{},
-		Description: "Search artworks in Boe Tea's database.",
+		Description: "Search artworks in Boe Tea's database. Words without a flag are searched in titles, authors and tags.",
 		Usage:       "bt!search <query> [flags]",
-		Example:     "bt!search hews limit:10 sort:favourites",
+		Example:     "bt!search provider:pixiv tag:初音ミク author:\"Jane Doe\" sort:favourites",
 		Flags: map[string]string{
-			"sort":   "**Options:** `[time, favourites]`. **Default:** time. Changes sort type.",
-			"order":  "**Options:** `[asc, desc]`. **Default:** desc. Changes order of sorted artworks.",
-			"limit":  "**Options:** `any integer number up to 100`. **Default:** 100. Limits the size of a leaderboard.",
-			"during": "**Options:** `[day, week, month]`. **Default:** all time. Filters artworks by time.",
+			"author":   "**Options:** `any text, use quotes for multiple words`. Filters artworks by author.",
+			"title":    "**Options:** `any text, use quotes for multiple words`. Filters artworks by title.",
+			"tag":      "**Options:** `any tag`. Filters artworks by tag. Can be repeated, artworks must have every tag.",
+			"provider": "**Options:** `[pixiv, twitter, deviant, artstation, ...]`. Filters artworks by provider.",
+			"nsfw":     "**Options:** `[true, false]`. Filters artworks by provider's rating.",
+			"sort":     "**Options:** `[time, favourites]`. **Default:** time. Changes sort type.",
+			"order":    "**Options:** `[asc, desc]`. **Default:** desc. Changes order of sorted artworks.",
+			"limit":    "**Options:** `any integer number up to 100`. **Default:** 100. Limits the size of a leaderboard.",
+			"during":   "**Options:** `[day, week, month]`. **Default:** all time. Filters artworks by time.",
 		},
 		RateLimiter: gumi.NewRateLimiter(10 * time.Second),
 		Exec:        search(b),
@@ -124,7 +130,7 @@ func artwork(b *bot.Bot) func(ctx *gumi.Ctx) error {
 		artwork, err := b.Store.Artwork(context.Background(), id, url)
 		if err != nil {
 			switch {
-			case errors.Is(err, mongo.ErrNoDocuments):
+			case errors.Is(err, store.ErrNotFound):
 				return messages.ErrArtworkNotFound(arg)
 			default:
 				return err
@@ -139,7 +145,7 @@ func artwork(b *bot.Bot) func(ctx *gumi.Ctx) error {
 		}
 
 		widget := dgoutils.NewWidget(ctx.Session, ctx.Event.Author.ID, embeds)
-		return widget.Start(ctx.Event.ChannelID)
+		return startWidget(b, ctx, widget)
 	}
 }
 
@@ -438,29 +444,37 @@ func leaderboard(b *bot.Bot) func(ctx *gumi.Ctx) error {
 		}
 
 		wg := dgoutils.NewWidget(ctx.Session, ctx.Event.Author.ID, artworkEmbeds)
-		return wg.Start(ctx.Event.ChannelID)
+		return startWidget(b, ctx, wg)
 	}
 }
 
+//searchFlags are prefixes of search command flags that aren't a part of a search query.
+var searchFlags = []string{"limit:", "sort:", "order:", "during:"}
+
 func search(b *bot.Bot) func(ctx *gumi.Ctx) error {
 	return func(ctx *gumi.Ctx) error {
 		if ctx.Args.Len() < 1 {
 			return messages.ErrIncorrectCmd(ctx.Command)
 		}
 
-		//Remove $'s to sanitize the input
-		query := strings.Replace(ctx.Args.Get(0).Raw, "$", "", -1)
-
 		var (
-			limit  int64 = 100
-			order        = store.Descending
-			sort         = store.ByTime
-			args         = strings.Fields(ctx.Args.Raw)
-			filter       = store.ArtworkFilter{
-				Query: query,
-			}
+			limit int64 = 100
+			order       = store.Descending
+			sort        = store.ByTime
+			args        = strings.Fields(ctx.Args.Raw)
 		)
 
+		terms := arrays.Filter(args, func(arg string) bool {
+			return !arrays.AnyFunc(searchFlags, func(prefix string) bool { return strings.HasPrefix(arg, prefix) })
+		})
+
+		//Remove $'s to sanitize the input
+		query := strings.Replace(strings.Join(terms, " "), "$", "", -1)
+		if query == "" {
+			return messages.ErrIncorrectCmd(ctx.Command)
+		}
+
+		filter := store.ParseSearchQuery(query)
 		flagsMap, err := flags.FromArgs(
 			args,
 			flags.FlagTypeDuring,
@@ -496,35 +510,80 @@ func search(b *bot.Bot) func(ctx *gumi.Ctx) error {
 		}
 
 		artworks, err := b.Store.SearchArtworks(context.Background(), filter, opts)
-
 		if err != nil {
 			return err
 		}
 
 		if len(artworks) == 0 {
-			return messages.ErrArtworkNotFound(query)
+			return messages.ErrSearchArtworksNoResults(query)
 		}
 
-		artworkEmbeds := make([]*discordgo.MessageEmbed, 0, len(artworks))
+		facets, err := b.Store.ArtworkFacets(context.Background(), filter, 5)
+		if err != nil {
+			return err
+		}
 
 		ch, err := ctx.Session.Channel(ctx.Event.ChannelID)
 		if err != nil {
 			return messages.ErrChannelNotFound(err, ctx.Event.ChannelID)
 		}
 
-		if !ch.NSFW {
-			locale := messages.SearchWarningEmbed()
-			eb := embeds.NewBuilder()
-			embed := eb.Title(locale.Title).Description(locale.Description).Finalize()
-
-			artworkEmbeds = append(artworkEmbeds, embed)
-		}
+		artworkEmbeds := make([]*discordgo.MessageEmbed, 0, len(artworks)+1)
+		artworkEmbeds = append(artworkEmbeds, facetsToEmbed(b, query, facets, len(artworks), ch.NSFW))
 
 		for ind, artwork := range artworks {
 			artworkEmbeds = append(artworkEmbeds, artworkToEmbed(artwork, artwork.Images[0], ind, len(artworks)))
 		}
 
 		wg := dgoutils.NewWidget(ctx.Session, ctx.Event.Author.ID, artworkEmbeds)
-		return wg.Start(ctx.Event.ChannelID)
+		return startWidget(b, ctx, wg)
+	}
+}
+
+//facetsToEmbed summarises search results. Artworks database may contain NSFW artworks, so a warning is added outside of NSFW channels.
+func facetsToEmbed(b *bot.Bot, query string, facets *store.ArtworkFacets, shown int, nsfw bool) *discordgo.MessageEmbed {
+	eb := embeds.NewBuilder()
+	eb.InfoTemplate("")
+	eb.Title("🔎 Search results")
+	eb.Description(fmt.Sprintf("Found **%v** artworks matching `%v`, showing %v. Use controls below to browse them.", facets.Total, query, shown))
+
+	eb.AddField("Artworks", strconv.FormatInt(facets.Total, 10), true).
+		AddField("NSFW", strconv.FormatInt(facets.NSFW, 10), true)
+
+	providerName := func(name string) string {
+		provider := arrays.Find(b.ArtworkProviders, func(p artworks.Provider) bool { return p.Info().Name == name })
+		if provider == nil {
+			return name
+		}
+
+		return provider.Info().DisplayName
+	}
+
+	for _, field := range []struct {
+		name   string
+		facets []*store.Facet
+		format func(string) string
+	}{
+		{"Providers", facets.Providers, providerName},
+		{"Top authors", facets.Authors, func(s string) string { return s }},
+		{"Top tags", facets.Tags, func(s string) string { return "`tag:" + s + "`" }},
+	} {
+		if len(field.facets) == 0 {
+			continue
+		}
+
+		lines := make([]string, 0, len(field.facets))
+		for _, facet := range field.facets {
+			lines = append(lines, fmt.Sprintf("%v • %v", field.format(facet.Value), facet.Count))
+		}
+
+		eb.AddField(field.name, strings.Join(lines, "\n"), true)
 	}
+
+	if !nsfw {
+		locale := messages.SearchWarningEmbed()
+		eb.AddField(locale.Title, "Results __may contain not safe for work artworks__. Add `nsfw:false` to the query to hide artworks rated NSFW by their providers.")
+	}
+
+	return eb.Finalize()
 }
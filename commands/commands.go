@@ -1,6 +1,11 @@
 package commands
 
-import "github.com/VTGare/boe-tea-go/bot"
+import (
+	"github.com/VTGare/boe-tea-go/bot"
+	"github.com/VTGare/boe-tea-go/internal/dgoutils"
+	"github.com/VTGare/gumi"
+	"github.com/bwmarrin/discordgo"
+)
 
 func RegisterCommands(b *bot.Bot) {
 	generalGroup(b)
@@ -9,4 +14,17 @@ func RegisterCommands(b *bot.Bot) {
 	artworksGroup(b)
 	ownerGroup(b)
 	sourceGroup(b)
+	slashCommands(b)
+
+	//Default permissions are checked by handlers.OnExecute, it also respects roles granted with bt!perms.
+	b.TakeCommandPermissions()
+}
+
+//startWidget sends an embed widget as a reply to the command.
+func startWidget(b *bot.Bot, ctx *gumi.Ctx, wg *dgoutils.EmbedWidget) error {
+	wg.WithReply(func(send *discordgo.MessageSend) (*discordgo.Message, error) {
+		return b.ReplyComplex(ctx, send)
+	})
+
+	return b.Widgets.Start(wg, ctx.Event.ChannelID)
 }
@@ -17,6 +17,9 @@ const (
 	FlagTypeSort
 	FlagTypeOrder
 	FlagTypeMode
+	FlagTypeCollection
+	FlagTypeTag
+	FlagTypeFormat
 )
 
 func FromArgs(args []string, flags ...FlagType) (map[FlagType]interface{}, error) {
@@ -89,6 +92,26 @@ func FromArgs(args []string, flags ...FlagType) (map[FlagType]interface{}, error
 						m[FlagTypeMode] = store.BookmarkFilterAll
 					}
 				}
+			case FlagTypeCollection:
+				if strings.HasPrefix(arg, "collection:") {
+					m[FlagTypeCollection] = strings.ToLower(strings.TrimPrefix(arg, "collection:"))
+				}
+			case FlagTypeTag:
+				//Tags can be repeated or comma-separated, e.g. tag:maid,swimsuit. Bookmarks must have all of them.
+				if strings.HasPrefix(arg, "tag:") {
+					tags, _ := m[FlagTypeTag].([]string)
+					for _, tag := range strings.Split(strings.TrimPrefix(arg, "tag:"), ",") {
+						if tag != "" {
+							tags = append(tags, strings.ToLower(tag))
+						}
+					}
+
+					m[FlagTypeTag] = tags
+				}
+			case FlagTypeFormat:
+				if strings.HasPrefix(arg, "format:") {
+					m[FlagTypeFormat] = strings.ToLower(strings.TrimPrefix(arg, "format:"))
+				}
 			}
 		}
 	}
@@ -11,17 +11,24 @@ import (
 	"time"
 	"unicode"
 
+	"github.com/VTGare/boe-tea-go/artworks"
 	"github.com/VTGare/boe-tea-go/bot"
+	"github.com/VTGare/boe-tea-go/commands/flags"
 	"github.com/VTGare/boe-tea-go/internal/arrays"
 	"github.com/VTGare/boe-tea-go/internal/dgoutils"
 	"github.com/VTGare/boe-tea-go/messages"
+	"github.com/VTGare/boe-tea-go/repost"
 	"github.com/VTGare/boe-tea-go/store"
 	"github.com/VTGare/embeds"
 	"github.com/VTGare/gumi"
 	"github.com/bwmarrin/discordgo"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
+//guildSettings are setting names accepted by the set command. Artwork providers are toggled by their names.
+var guildSettings = []string{
+	"prefix", "limit", "repost", "repost.expiration", "repost.scope", "repost.images", "repost.distance", "nsfw", "nsfw.mode", "crosspost", "reactions", "tags", "footer",
+}
+
 func generalGroup(b *bot.Bot) {
 	group := "general"
 
@@ -30,8 +37,8 @@ func generalGroup(b *bot.Bot) {
 		Group:       group,
 		Aliases:     []string{"cfg", "config", "settings"},
 		Description: "Shows or edits server settings.",
-		Usage:       "bt!set <setting name> <new setting>",
-		Example:     "bt!set pixiv false",
+		Usage:       "bt!set [#channel] [setting name] [new setting]",
+		Example:     "bt!set #art tags false",
 		Flags:       map[string]string{},
 		GuildOnly:   true,
 		NSFW:        false,
@@ -117,6 +124,35 @@ func generalGroup(b *bot.Bot) {
 		Exec:        addchannel(b),
 	})
 
+	b.Router.RegisterCmd(&gumi.Command{
+		Name:        "reposts",
+		Group:       group,
+		Aliases:     []string{"repostlog"},
+		Description: "Lists detected reposts, optionally by a user.",
+		Usage:       "bt!reposts [user] [flags]",
+		Example:     "bt!reposts @Kira during:week",
+		Flags: map[string]string{
+			"during": "**Options:** `[day, week, month]`. **Default:** all time. Filters reposts by time.",
+		},
+		GuildOnly:   true,
+		Permissions: discordgo.PermissionAdministrator | discordgo.PermissionManageMessages,
+		RateLimiter: gumi.NewRateLimiter(5 * time.Second),
+		Exec:        reposts(b),
+	})
+
+	b.Router.RegisterCmd(&gumi.Command{
+		Name:        "perms",
+		Group:       group,
+		Aliases:     []string{"permissions"},
+		Description: "Shows or edits command permissions. Roles can be granted commands or command groups, commands can be denied in channels.",
+		Usage:       "bt!perms [grant/revoke/deny/allow] [target] [commands...]",
+		Example:     "bt!perms grant @Curator addchannel rmchannel",
+		GuildOnly:   true,
+		Permissions: discordgo.PermissionAdministrator | discordgo.PermissionManageServer,
+		RateLimiter: gumi.NewRateLimiter(5 * time.Second),
+		Exec:        perms(b),
+	})
+
 	b.Router.RegisterCmd(&gumi.Command{
 		Name:        "rmchannel",
 		Group:       group,
@@ -129,6 +165,32 @@ func generalGroup(b *bot.Bot) {
 		RateLimiter: gumi.NewRateLimiter(5 * time.Second),
 		Exec:        removechannel(b),
 	})
+
+	b.Router.RegisterCmd(&gumi.Command{
+		Name:        "follow",
+		Group:       group,
+		Aliases:     []string{"subscribe", "following"},
+		Description: "Lists followed artists or follows an artist. New artworks of followed artists are posted to a channel.",
+		Usage:       "bt!follow [profile url] [#channel]",
+		Example:     "bt!follow https://pixiv.net/users/123 #art",
+		GuildOnly:   true,
+		Permissions: discordgo.PermissionAdministrator | discordgo.PermissionManageServer,
+		RateLimiter: gumi.NewRateLimiter(5 * time.Second),
+		Exec:        follow(b),
+	})
+
+	b.Router.RegisterCmd(&gumi.Command{
+		Name:        "unfollow",
+		Group:       group,
+		Aliases:     []string{"unsubscribe"},
+		Description: "Unfollows an artist in a channel.",
+		Usage:       "bt!unfollow <profile url> [#channel]",
+		Example:     "bt!unfollow https://pixiv.net/users/123 #art",
+		GuildOnly:   true,
+		Permissions: discordgo.PermissionAdministrator | discordgo.PermissionManageServer,
+		RateLimiter: gumi.NewRateLimiter(5 * time.Second),
+		Exec:        unfollow(b),
+	})
 }
 
 func help(b *bot.Bot) func(ctx *gumi.Ctx) error {
@@ -224,7 +286,7 @@ func help(b *bot.Bot) func(ctx *gumi.Ctx) error {
 
 		}
 
-		return ctx.ReplyEmbed(eb.Finalize())
+		return b.ReplyEmbed(ctx, eb.Finalize())
 	}
 }
 
@@ -232,7 +294,8 @@ func ping(b *bot.Bot) func(ctx *gumi.Ctx) error {
 	return func(ctx *gumi.Ctx) error {
 		eb := embeds.NewBuilder()
 
-		return ctx.ReplyEmbed(
+		return b.ReplyEmbed(
+			ctx,
 			eb.Title("🏓 Pong!").AddField(
 				"Heartbeat latency",
 				ctx.Session.HeartbeatLatency().Round(time.Millisecond).String(),
@@ -269,7 +332,7 @@ func about(b *bot.Bot) func(ctx *gumi.Ctx) error {
 			true,
 		)
 
-		ctx.ReplyEmbed(eb.Finalize())
+		b.ReplyEmbed(ctx, eb.Finalize())
 		return nil
 	}
 }
@@ -323,7 +386,7 @@ func feedback(b *bot.Bot) func(ctx *gumi.Ctx) error {
 		}
 
 		eb.Clear()
-		ctx.ReplyEmbed(eb.SuccessTemplate("Feedback message has been sent.").Finalize())
+		b.ReplyEmbed(ctx, eb.SuccessTemplate("Feedback message has been sent.").Finalize())
 		return nil
 	}
 }
@@ -385,7 +448,7 @@ func generalStats(b *bot.Bot, ctx *gumi.Ctx) error {
 		AddField("Uptime", messages.FormatDuration(uptime), true).
 		AddField("RAM used", fmt.Sprintf("%v MB", mem.Alloc/1024/1024), true)
 
-	return ctx.ReplyEmbed(eb.Finalize())
+	return b.ReplyEmbed(ctx, eb.Finalize())
 }
 
 func artworkStats(b *bot.Bot, ctx *gumi.Ctx) error {
@@ -397,7 +460,7 @@ func artworkStats(b *bot.Bot, ctx *gumi.Ctx) error {
 		eb.AddField(item.Name, strconv.FormatInt(item.Count, 10))
 	}
 
-	return ctx.ReplyEmbed(eb.Finalize())
+	return b.ReplyEmbed(ctx, eb.Finalize())
 }
 
 func commandStats(b *bot.Bot, ctx *gumi.Ctx) error {
@@ -409,7 +472,7 @@ func commandStats(b *bot.Bot, ctx *gumi.Ctx) error {
 		eb.AddField(item.Name, strconv.FormatInt(item.Count, 10), true)
 	}
 
-	return ctx.ReplyEmbed(eb.Finalize())
+	return b.ReplyEmbed(ctx, eb.Finalize())
 }
 
 func set(b *bot.Bot) func(ctx *gumi.Ctx) error {
@@ -423,7 +486,7 @@ func set(b *bot.Bot) func(ctx *gumi.Ctx) error {
 			guild, err := b.Store.Guild(context.Background(), gd.ID)
 			if err != nil {
 				switch {
-				case errors.Is(err, mongo.ErrNoDocuments):
+				case errors.Is(err, store.ErrNotFound):
 					return messages.ErrGuildNotFound(err, ctx.Event.GuildID)
 				default:
 					return err
@@ -442,18 +505,23 @@ func set(b *bot.Bot) func(ctx *gumi.Ctx) error {
 			eb.AddField(
 				msg.General.Title,
 				fmt.Sprintf(
-					"**%v**: %v | **%v**: %v",
+					"**%v**: %v | **%v**: %v | **%v**: %v\n**%v**: %v",
 					msg.General.Prefix, guild.Prefix,
 					msg.General.NSFW, messages.FormatBool(guild.NSFW),
+					msg.General.Limit, strconv.Itoa(guild.Limit),
+					msg.General.NSFWMode, guild.CurrentNSFWMode(),
 				),
 			)
 
 			eb.AddField(
 				msg.Features.Title,
 				fmt.Sprintf(
-					"**%v**: %v | **%v**: %v\n**%v**: %v | **%v**: %v\n**%v**: %v | **%v**: %v",
+					"**%v**: %v | **%v**: %v | **%v**: %v\n**%v**: %v | **%v**: %v\n**%v**: %v | **%v**: %v\n**%v**: %v | **%v**: %v",
 					msg.Features.Repost, guild.Repost,
 					msg.Features.RepostExpiration, guild.RepostExpiration,
+					msg.Features.RepostScope, guild.RepostScope,
+					msg.Features.ImageRepost, messages.FormatBool(guild.ImageRepost),
+					msg.Features.RepostDistance, guild.RepostDistance,
 					msg.Features.Crosspost, messages.FormatBool(guild.Crosspost),
 					msg.Features.Reactions, messages.FormatBool(guild.Reactions),
 					msg.Features.Tags, messages.FormatBool(guild.Tags),
@@ -461,38 +529,16 @@ func set(b *bot.Bot) func(ctx *gumi.Ctx) error {
 				),
 			)
 
-			eb.AddField(
-				msg.PixivSettings.Title,
-				fmt.Sprintf(
-					"**%v**: %v | **%v**: %v",
-					msg.PixivSettings.Enabled, messages.FormatBool(guild.Pixiv),
-					msg.PixivSettings.Limit, strconv.Itoa(guild.Limit),
-				),
-			)
-
-			eb.AddField(
-				msg.TwitterSettings.Title,
-				fmt.Sprintf(
-					"**%v**: %v",
-					msg.TwitterSettings.Enabled, messages.FormatBool(guild.Twitter),
-				),
-			)
-
-			eb.AddField(
-				msg.DeviantSettings.Title,
-				fmt.Sprintf(
-					"**%v**: %v",
-					msg.DeviantSettings.Enabled, messages.FormatBool(guild.Deviant),
-				),
-			)
+			providers := make([]string, 0, len(b.ArtworkProviders))
+			for _, provider := range b.ArtworkProviders {
+				info := provider.Info()
+				providers = append(providers, fmt.Sprintf(
+					"**%v** __(%v)__: %v",
+					info.DisplayName, info.Name, messages.FormatBool(artworks.Enabled(provider, guild)),
+				))
+			}
 
-			eb.AddField(
-				msg.ArtstationSettings.Title,
-				fmt.Sprintf(
-					"**%v**: %v",
-					msg.ArtstationSettings.Enabled, messages.FormatBool(guild.Artstation),
-				),
-			)
+			eb.AddField(msg.Providers, strings.Join(providers, "\n"))
 
 			eb.AddField(
 				msg.ArtChannels,
@@ -507,24 +553,26 @@ func set(b *bot.Bot) func(ctx *gumi.Ctx) error {
 				),
 			)
 
-			ctx.ReplyEmbed(eb.Finalize())
+			b.ReplyEmbed(ctx, eb.Finalize())
 			return nil
 		}
 
-		changeSetting := func() error {
-			perms, err := dgoutils.MemberHasPermission(
-				ctx.Session,
-				ctx.Event.GuildID,
-				ctx.Event.Author.ID,
-				discordgo.PermissionAdministrator|discordgo.PermissionManageServer,
-			)
-
+		checkPermissions := func() error {
+			perms, err := b.HasPermission(ctx, bot.ManagePermissions)
 			if err != nil {
 				return err
 			}
 
 			if !perms {
-				return ctx.Router.OnNoPermissionsCallback(ctx)
+				return messages.ErrNoPerms()
+			}
+
+			return nil
+		}
+
+		changeSetting := func() error {
+			if err := checkPermissions(); err != nil {
+				return err
 			}
 
 			guild, err := b.Store.Guild(context.Background(), ctx.Event.GuildID)
@@ -582,6 +630,38 @@ func set(b *bot.Bot) func(ctx *gumi.Ctx) error {
 				oldSettingEmbed = guild.RepostExpiration
 				newSettingEmbed = dur
 				guild.RepostExpiration = dur
+			case "repost.scope":
+				switch repost.Scope(newSetting.Raw) {
+				case repost.ScopeChannel, repost.ScopeCategory, repost.ScopeGuild:
+				default:
+					return messages.ErrUnknownRepostScope(newSetting.Raw)
+				}
+
+				oldSettingEmbed = guild.RepostScope
+				newSettingEmbed = newSetting.Raw
+				guild.RepostScope = newSetting.Raw
+			case "repost.images":
+				new, err := parseBool(newSetting.Raw)
+				if err != nil {
+					return err
+				}
+
+				oldSettingEmbed = guild.ImageRepost
+				newSettingEmbed = new
+				guild.ImageRepost = new
+			case "repost.distance":
+				distance, err := strconv.Atoi(newSetting.Raw)
+				if err != nil {
+					return messages.ErrParseInt(newSetting.Raw)
+				}
+
+				if distance < 0 || distance > 16 {
+					return messages.ErrDistanceOutOfRange(newSetting.Raw)
+				}
+
+				oldSettingEmbed = guild.RepostDistance
+				newSettingEmbed = distance
+				guild.RepostDistance = distance
 			case "nsfw":
 				nsfw, err := parseBool(newSetting.Raw)
 				if err != nil {
@@ -591,6 +671,16 @@ func set(b *bot.Bot) func(ctx *gumi.Ctx) error {
 				oldSettingEmbed = guild.NSFW
 				newSettingEmbed = nsfw
 				guild.NSFW = nsfw
+			case "nsfw.mode":
+				switch newSetting.Raw {
+				case store.NSFWModeAllow, store.NSFWModeSkip, store.NSFWModeSpoiler, store.NSFWModeLink:
+				default:
+					return messages.ErrUnknownNSFWMode(newSetting.Raw)
+				}
+
+				oldSettingEmbed = guild.CurrentNSFWMode()
+				newSettingEmbed = newSetting.Raw
+				guild.NSFWMode = newSetting.Raw
 			case "crosspost":
 				crosspost, err := parseBool(newSetting.Raw)
 				if err != nil {
@@ -609,62 +699,38 @@ func set(b *bot.Bot) func(ctx *gumi.Ctx) error {
 				oldSettingEmbed = guild.Reactions
 				newSettingEmbed = new
 				guild.Reactions = new
-			case "pixiv":
-				new, err := parseBool(newSetting.Raw)
-				if err != nil {
-					return err
-				}
-
-				oldSettingEmbed = guild.Pixiv
-				newSettingEmbed = new
-				guild.Pixiv = new
-			case "twitter":
-				new, err := parseBool(newSetting.Raw)
-				if err != nil {
-					return err
-				}
-
-				oldSettingEmbed = guild.Twitter
-				newSettingEmbed = new
-				guild.Twitter = new
-			case "deviant":
+			case "tags":
 				new, err := parseBool(newSetting.Raw)
 				if err != nil {
 					return err
 				}
 
-				oldSettingEmbed = guild.Deviant
+				oldSettingEmbed = guild.Tags
 				newSettingEmbed = new
-				guild.Deviant = new
-			case "artstation":
+				guild.Tags = new
+			case "footer":
 				new, err := parseBool(newSetting.Raw)
 				if err != nil {
 					return err
 				}
 
-				oldSettingEmbed = guild.Artstation
+				oldSettingEmbed = guild.FlavourText
 				newSettingEmbed = new
-				guild.Artstation = new
-			case "tags":
-				new, err := parseBool(newSetting.Raw)
-				if err != nil {
-					return err
+				guild.FlavourText = new
+			default:
+				provider, ok := b.Provider(settingName.Raw)
+				if !ok {
+					return messages.ErrUnknownSetting(settingName.Raw)
 				}
 
-				oldSettingEmbed = guild.Tags
-				newSettingEmbed = new
-				guild.Tags = new
-			case "footer":
 				new, err := parseBool(newSetting.Raw)
 				if err != nil {
 					return err
 				}
 
-				oldSettingEmbed = guild.FlavourText
+				oldSettingEmbed = artworks.Enabled(provider, guild)
 				newSettingEmbed = new
-				guild.FlavourText = new
-			default:
-				return messages.ErrUnknownSetting(settingName.Raw)
+				guild.SetProvider(provider.Info().Name, new)
 			}
 
 			_, err = b.Store.UpdateGuild(context.Background(), guild)
@@ -678,10 +744,35 @@ func set(b *bot.Bot) func(ctx *gumi.Ctx) error {
 			eb.AddField("Old setting", fmt.Sprintf("%v", oldSettingEmbed), true)
 			eb.AddField("New setting", fmt.Sprintf("%v", newSettingEmbed), true)
 
-			ctx.ReplyEmbed(eb.Finalize())
+			b.ReplyEmbed(ctx, eb.Finalize())
 			return nil
 		}
 
+		//Channel overrides are shown and changed by passing a channel mention as the first argument.
+		if ctx.Args.Len() > 0 && strings.HasPrefix(ctx.Args.Get(0).Raw, "<#") {
+			ch, err := ctx.Session.Channel(strings.Trim(ctx.Args.Get(0).Raw, "<#>"))
+			if err != nil {
+				return messages.ErrChannelNotFound(err, ctx.Args.Get(0).Raw)
+			}
+
+			if ch.GuildID != ctx.Event.GuildID {
+				return messages.ErrForeignChannel(ch.ID)
+			}
+
+			switch {
+			case ctx.Args.Len() == 1:
+				return showChannelSettings(b, ctx, ch)
+			case ctx.Args.Len() >= 3:
+				if err := checkPermissions(); err != nil {
+					return err
+				}
+
+				return changeChannelSetting(b, ctx, ch)
+			default:
+				return messages.ErrIncorrectCmd(ctx.Command)
+			}
+		}
+
 		switch {
 		case ctx.Args.Len() == 0:
 			return showSettings()
@@ -693,6 +784,329 @@ func set(b *bot.Bot) func(ctx *gumi.Ctx) error {
 	}
 }
 
+//showChannelSettings shows effective settings of a channel and whether they're inherited from the guild or overridden.
+func showChannelSettings(b *bot.Bot, ctx *gumi.Ctx, ch *discordgo.Channel) error {
+	guild, err := b.Store.Guild(context.Background(), ch.GuildID)
+	if err != nil {
+		return messages.ErrGuildNotFound(err, ch.GuildID)
+	}
+
+	var (
+		eb        = embeds.NewBuilder()
+		msg       = messages.SetEmbed()
+		eff       = guild.Effective(ch.ID)
+		overrides = guild.Channel(ch.ID)
+	)
+
+	source := func(overridden bool) string {
+		if overridden {
+			return fmt.Sprintf("__(%v)__", msg.Overridden)
+		}
+
+		return fmt.Sprintf("*(%v)*", msg.Inherited)
+	}
+
+	eb.Title(msg.ChannelSettings).Description(fmt.Sprintf("<#%v> | `%v`", ch.ID, ch.ID))
+	eb.AddField(
+		msg.General.Title,
+		fmt.Sprintf(
+			"**%v**: %v %v\n**%v**: %v %v",
+			msg.General.NSFW, messages.FormatBool(eff.NSFW), source(overrides.NSFW != nil),
+			msg.General.Limit, eff.Limit, source(overrides.Limit != nil),
+		),
+	)
+
+	eb.AddField(
+		msg.Features.Title,
+		fmt.Sprintf(
+			"**%v**: %v %v\n**%v**: %v %v\n**%v**: %v %v\n**%v**: %v %v",
+			msg.Features.Repost, eff.Repost, source(overrides.Repost != nil),
+			msg.Features.Reactions, messages.FormatBool(eff.Reactions), source(overrides.Reactions != nil),
+			msg.Features.Tags, messages.FormatBool(eff.Tags), source(overrides.Tags != nil),
+			msg.Features.FlavourText, messages.FormatBool(eff.FlavourText), source(overrides.FlavourText != nil),
+		),
+	)
+
+	providers := make([]string, 0, len(b.ArtworkProviders))
+	for _, provider := range b.ArtworkProviders {
+		info := provider.Info()
+		_, overridden := overrides.Providers[info.Name]
+
+		providers = append(providers, fmt.Sprintf(
+			"**%v** __(%v)__: %v %v",
+			info.DisplayName, info.Name, messages.FormatBool(artworks.Enabled(provider, eff)), source(overridden),
+		))
+	}
+
+	eb.AddField(msg.Providers, strings.Join(providers, "\n"))
+	eb.Footer("Use \"inherit\" as a new setting to remove an override.", "")
+
+	return b.ReplyEmbed(ctx, eb.Finalize())
+}
+
+//changeChannelSetting overrides a guild setting in a channel. New setting "inherit" removes the override.
+func changeChannelSetting(b *bot.Bot, ctx *gumi.Ctx, ch *discordgo.Channel) error {
+	guild, err := b.Store.Guild(context.Background(), ch.GuildID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		settingName     = ctx.Args.Get(1)
+		newSetting      = ctx.Args.Get(2)
+		inherit         = newSetting.Raw == "inherit"
+		eff             = guild.Effective(ch.ID)
+		overrides       = guild.Channel(ch.ID)
+		newSettingEmbed interface{}
+		oldSettingEmbed interface{}
+	)
+
+	setBool := func(override **bool, inherited bool) (interface{}, error) {
+		if inherit {
+			*override = nil
+			return inherited, nil
+		}
+
+		new, err := parseBool(newSetting.Raw)
+		if err != nil {
+			return nil, err
+		}
+
+		*override = &new
+		return new, nil
+	}
+
+	switch settingName.Raw {
+	case "limit":
+		oldSettingEmbed = eff.Limit
+		if inherit {
+			overrides.Limit = nil
+			newSettingEmbed = guild.Limit
+			break
+		}
+
+		limit, err := strconv.Atoi(newSetting.Raw)
+		if err != nil {
+			return messages.ErrParseInt(newSetting.Raw)
+		}
+
+		overrides.Limit = &limit
+		newSettingEmbed = limit
+	case "repost":
+		oldSettingEmbed = eff.Repost
+		if inherit {
+			overrides.Repost = nil
+			newSettingEmbed = guild.Repost
+			break
+		}
+
+		if newSetting.Raw != "enabled" && newSetting.Raw != "disabled" && newSetting.Raw != "strict" {
+			return messages.ErrUnknownRepostOption(newSetting.Raw)
+		}
+
+		option := newSetting.Raw
+		overrides.Repost = &option
+		newSettingEmbed = option
+	case "nsfw":
+		oldSettingEmbed = eff.NSFW
+		newSettingEmbed, err = setBool(&overrides.NSFW, guild.NSFW)
+	case "reactions":
+		oldSettingEmbed = eff.Reactions
+		newSettingEmbed, err = setBool(&overrides.Reactions, guild.Reactions)
+	case "tags":
+		oldSettingEmbed = eff.Tags
+		newSettingEmbed, err = setBool(&overrides.Tags, guild.Tags)
+	case "footer":
+		oldSettingEmbed = eff.FlavourText
+		newSettingEmbed, err = setBool(&overrides.FlavourText, guild.FlavourText)
+	default:
+		provider, ok := b.Provider(settingName.Raw)
+		if !ok {
+			return messages.ErrUnknownChannelSetting(settingName.Raw)
+		}
+
+		name := provider.Info().Name
+		oldSettingEmbed = artworks.Enabled(provider, eff)
+		if inherit {
+			delete(overrides.Providers, name)
+			newSettingEmbed = artworks.Enabled(provider, guild)
+			break
+		}
+
+		new, err := parseBool(newSetting.Raw)
+		if err != nil {
+			return err
+		}
+
+		overrides.Providers[name] = new
+		newSettingEmbed = new
+	}
+
+	if err != nil {
+		return err
+	}
+
+	guild.SetChannel(ch.ID, overrides)
+	if _, err := b.Store.UpdateGuild(context.Background(), guild); err != nil {
+		return err
+	}
+
+	eb := embeds.NewBuilder()
+	eb.InfoTemplate("Successfully changed channel setting.")
+	eb.AddField("Channel", fmt.Sprintf("<#%v>", ch.ID), true)
+	eb.AddField("Setting name", settingName.Raw, true)
+	eb.AddField("Old setting", fmt.Sprintf("%v", oldSettingEmbed), true)
+	eb.AddField("New setting", fmt.Sprintf("%v", newSettingEmbed), true)
+
+	return b.ReplyEmbed(ctx, eb.Finalize())
+}
+
+func perms(b *bot.Bot) func(ctx *gumi.Ctx) error {
+	return func(ctx *gumi.Ctx) error {
+		guild, err := b.Store.Guild(context.Background(), ctx.Event.GuildID)
+		if err != nil {
+			return messages.ErrGuildNotFound(err, ctx.Event.GuildID)
+		}
+
+		if ctx.Args.Len() == 0 {
+			return showPermissions(b, ctx, guild)
+		}
+
+		if ctx.Args.Len() < 3 {
+			return messages.ErrIncorrectCmd(ctx.Command)
+		}
+
+		targets := make([]string, 0, ctx.Args.Len()-2)
+		for _, arg := range ctx.Args.Arguments[2:] {
+			target, err := permissionTarget(b, arg.Raw)
+			if err != nil {
+				return err
+			}
+
+			targets = append(targets, target)
+		}
+
+		var (
+			action  = ctx.Args.Get(0).Raw
+			id      string
+			mention string
+			apply   func(id, target string) bool
+		)
+
+		switch action {
+		case "grant", "revoke":
+			id = strings.Trim(ctx.Args.Get(1).Raw, "<@&>")
+			roles, err := ctx.Session.GuildRoles(guild.ID)
+			if err != nil {
+				return err
+			}
+
+			if !arrays.AnyFunc(roles, func(role *discordgo.Role) bool { return role.ID == id }) {
+				return messages.ErrRoleNotFound(ctx.Args.Get(1).Raw)
+			}
+
+			mention = fmt.Sprintf("<@&%v>", id)
+			apply = guild.Permissions.Grant
+			if action == "revoke" {
+				apply = guild.Permissions.Revoke
+			}
+		case "deny", "allow":
+			ch, err := ctx.Session.Channel(strings.Trim(ctx.Args.Get(1).Raw, "<#>"))
+			if err != nil {
+				return messages.ErrChannelNotFound(err, ctx.Args.Get(1).Raw)
+			}
+
+			if ch.GuildID != guild.ID {
+				return messages.ErrForeignChannel(ch.ID)
+			}
+
+			id = ch.ID
+			mention = fmt.Sprintf("<#%v>", id)
+			apply = guild.Permissions.Deny
+			if action == "allow" {
+				apply = guild.Permissions.Allow
+			}
+		default:
+			return messages.ErrIncorrectCmd(ctx.Command)
+		}
+
+		changed := false
+		for _, target := range targets {
+			if apply(id, target) {
+				changed = true
+			}
+		}
+
+		if !changed {
+			return messages.ErrPermissionsUnchanged(targets)
+		}
+
+		if _, err := b.Store.UpdateGuild(context.Background(), guild); err != nil {
+			return err
+		}
+
+		eb := embeds.NewBuilder()
+		eb.SuccessTemplate(messages.PermissionsChanged(action, mention, targets))
+
+		return b.ReplyEmbed(ctx, eb.Finalize())
+	}
+}
+
+//showPermissions lists roles granted commands, commands denied in channels and commands that require Discord permissions by default.
+func showPermissions(b *bot.Bot, ctx *gumi.Ctx, guild *store.Guild) error {
+	list := func(m map[string][]string, format string) string {
+		lines := make([]string, 0, len(m))
+		for id, targets := range m {
+			lines = append(lines, fmt.Sprintf(format+": `%v`", id, strings.Join(targets, "`, `")))
+		}
+
+		if len(lines) == 0 {
+			return "None"
+		}
+
+		sort.Strings(lines)
+		return strings.Join(lines, "\n")
+	}
+
+	defaults := make([]string, 0, len(b.CommandPermissions))
+	for name := range b.CommandPermissions {
+		defaults = append(defaults, name)
+	}
+	sort.Strings(defaults)
+
+	eb := embeds.NewBuilder()
+	eb.Title("Command permissions").Description(
+		"Roles can use granted commands without Discord permissions. Server managers can use commands denied in channels.",
+	)
+
+	eb.AddField("Granted roles", list(guild.Permissions.Roles, "<@&%v>"))
+	eb.AddField("Denied in channels", list(guild.Permissions.Channels, "<#%v>"))
+	eb.AddField("Require Discord permissions", fmt.Sprintf("`%v`", strings.Join(defaults, "`, `")))
+
+	return b.ReplyEmbed(ctx, eb.Finalize())
+}
+
+//permissionTarget resolves a command name, an alias or a command group to a permission target.
+//Manager-only commands aren't valid targets, granting a group doesn't grant them either.
+func permissionTarget(b *bot.Bot, name string) (string, error) {
+	lower := strings.ToLower(name)
+	if cmd, ok := b.Router.Commands[lower]; ok {
+		if bot.ManagerOnly(cmd.Name) {
+			return "", messages.ErrManagerOnlyCommand(cmd.Name)
+		}
+
+		return cmd.Name, nil
+	}
+
+	for _, cmd := range b.Router.Commands {
+		if strings.ToLower(cmd.Group) == lower {
+			return cmd.Group, nil
+		}
+	}
+
+	return "", messages.ErrUnknownPermissionTarget(name)
+}
+
 func artchannels(b *bot.Bot) func(ctx *gumi.Ctx) error {
 	return func(ctx *gumi.Ctx) error {
 		switch {
@@ -719,7 +1133,7 @@ func artchannels(b *bot.Bot) func(ctx *gumi.Ctx) error {
 			if len(guild.ArtChannels) == 0 {
 				eb.Description("You haven't added any art channels yet. Add your first art channel using `bt!artchannels add <channel mention>` command.")
 
-				return ctx.ReplyEmbed(eb.Finalize())
+				return b.ReplyEmbed(ctx, eb.Finalize())
 			}
 
 			eb.Footer("Total: "+strconv.Itoa(len(guild.ArtChannels)), "")
@@ -749,15 +1163,10 @@ func artchannels(b *bot.Bot) func(ctx *gumi.Ctx) error {
 			}
 
 			wg := dgoutils.NewWidget(ctx.Session, ctx.Event.Author.ID, channelEmbeds)
-			return wg.Start(ctx.Event.ChannelID)
+			return startWidget(b, ctx, wg)
 
 		case ctx.Args.Len() >= 2:
-			perms, err := dgoutils.MemberHasPermission(
-				ctx.Session,
-				ctx.Event.GuildID,
-				ctx.Event.Author.ID,
-				discordgo.PermissionAdministrator|discordgo.PermissionManageServer,
-			)
+			perms, err := b.HasPermission(ctx, bot.ManagePermissions)
 			if err != nil {
 				return err
 			}
@@ -782,7 +1191,7 @@ func artchannels(b *bot.Bot) func(ctx *gumi.Ctx) error {
 
 					eb := embeds.NewBuilder()
 					eb.SuccessTemplate(messages.AddArtChannelSuccess(channels))
-					return ctx.ReplyEmbed(eb.Finalize())
+					return b.ReplyEmbed(ctx, eb.Finalize())
 				}
 
 				filter = func(guild *store.Guild, channelID string) error {
@@ -807,7 +1216,7 @@ func artchannels(b *bot.Bot) func(ctx *gumi.Ctx) error {
 
 					eb := embeds.NewBuilder()
 					eb.SuccessTemplate(messages.RemoveArtChannelSuccess(channels))
-					return ctx.ReplyEmbed(eb.Finalize())
+					return b.ReplyEmbed(ctx, eb.Finalize())
 				}
 
 				filter = func(guild *store.Guild, channelID string) error {
@@ -882,6 +1291,98 @@ func artchannels(b *bot.Bot) func(ctx *gumi.Ctx) error {
 	}
 }
 
+func reposts(b *bot.Bot) func(ctx *gumi.Ctx) error {
+	return func(ctx *gumi.Ctx) error {
+		if !b.Config.Repost.History {
+			return messages.ErrRepostHistoryDisabled()
+		}
+
+		var (
+			args   = strings.Fields(ctx.Args.Raw)
+			filter = store.RepostFilter{Limit: 500}
+		)
+
+		flagsMap, err := flags.FromArgs(args, flags.FlagTypeDuring)
+		if err != nil {
+			return err
+		}
+
+		if during, ok := flagsMap[flags.FlagTypeDuring]; ok {
+			filter.Time = during.(time.Duration)
+		}
+
+		for _, arg := range args {
+			switch {
+			case strings.HasPrefix(arg, "<@&"):
+				return messages.ErrRepostRoleFilter(arg)
+			case strings.HasPrefix(arg, "<@"):
+				filter.UserID = strings.Trim(arg, "<@!>")
+			}
+		}
+
+		reposts, err := b.Store.ListReposts(context.Background(), ctx.Event.GuildID, filter)
+		if err != nil {
+			return err
+		}
+
+		total, err := b.Store.CountReposts(context.Background(), ctx.Event.GuildID, filter)
+		if err != nil {
+			return err
+		}
+
+		footer := "Total: " + strconv.FormatInt(total, 10)
+		if total > int64(len(reposts)) {
+			footer = fmt.Sprintf("Showing the latest %v of %v", len(reposts), total)
+		}
+
+		title := "Reposts"
+		if filter.UserID != "" {
+			if user, err := b.SessionFor(ctx.Session).User(filter.UserID); err == nil {
+				title = "Reposts by " + user.Username
+			}
+		}
+
+		eb := embeds.NewBuilder()
+		eb.Title(title)
+		if len(reposts) == 0 {
+			eb.Description("No reposts found.")
+			return b.ReplyEmbed(ctx, eb.Finalize())
+		}
+
+		var (
+			repostEmbeds = make([]*discordgo.MessageEmbed, 0)
+			sb           = &strings.Builder{}
+		)
+
+		for ind, rep := range reposts {
+			similar := ""
+			if rep.Similar {
+				similar = " | similar image"
+			}
+
+			sb.WriteString(fmt.Sprintf(
+				"%v. <@%v> in <#%v> %v%v\n%v | %v\n",
+				ind+1, rep.UserID, rep.ChannelID, messages.RelativeTimestamp(rep.CreatedAt), similar,
+				messages.NamedLink(rep.ArtworkID, rep.URL),
+				messages.NamedLink("original", fmt.Sprintf("https://discord.com/channels/%v/%v/%v", rep.GuildID, rep.OriginalChannelID, rep.OriginalMessageID)),
+			))
+
+			if (ind+1)%10 == 0 || ind == len(reposts)-1 {
+				eb.Description(sb.String())
+				eb.Footer(footer, "")
+				repostEmbeds = append(repostEmbeds, eb.Finalize())
+
+				eb = embeds.NewBuilder()
+				eb.Title(title)
+				sb.Reset()
+			}
+		}
+
+		wg := dgoutils.NewWidget(b.SessionFor(ctx.Session), ctx.Event.Author.ID, repostEmbeds)
+		return startWidget(b, ctx, wg)
+	}
+}
+
 func addchannel(b *bot.Bot) func(ctx *gumi.Ctx) error {
 	return func(ctx *gumi.Ctx) error {
 		if ctx.Args.Len() == 0 {
@@ -961,7 +1462,7 @@ func addchannel(b *bot.Bot) func(ctx *gumi.Ctx) error {
 
 		eb := embeds.NewBuilder()
 		eb.SuccessTemplate(messages.AddArtChannelSuccess(channels))
-		return ctx.ReplyEmbed(eb.Finalize())
+		return b.ReplyEmbed(ctx, eb.Finalize())
 	}
 }
 
@@ -1017,7 +1518,7 @@ func removechannel(b *bot.Bot) func(ctx *gumi.Ctx) error {
 		)
 
 		if err != nil {
-			if errors.Is(err, mongo.ErrNoDocuments) {
+			if errors.Is(err, store.ErrNotFound) {
 				return messages.RemoveArtChannelFail(channels)
 			}
 
@@ -1026,10 +1527,155 @@ func removechannel(b *bot.Bot) func(ctx *gumi.Ctx) error {
 
 		eb := embeds.NewBuilder()
 		eb.SuccessTemplate(messages.RemoveArtChannelSuccess(channels))
-		return ctx.ReplyEmbed(eb.Finalize())
+		return b.ReplyEmbed(ctx, eb.Finalize())
+	}
+}
+
+//maxSubscriptions is the maximum number of artists a guild can follow.
+const maxSubscriptions = 25
+
+func follow(b *bot.Bot) func(ctx *gumi.Ctx) error {
+	return func(ctx *gumi.Ctx) error {
+		subs, err := b.Store.GuildSubscriptions(context.Background(), ctx.Event.GuildID)
+		if err != nil {
+			return err
+		}
+
+		if ctx.Args.Len() == 0 {
+			return showSubscriptions(b, ctx, subs)
+		}
+
+		if len(subs) >= maxSubscriptions {
+			return messages.ErrTooManySubscriptions(maxSubscriptions)
+		}
+
+		sub, provider, feed, err := subscription(b, ctx)
+		if err != nil {
+			return err
+		}
+
+		//Existing artworks are marked as seen, only artworks published after following are posted.
+		urls, err := feed.RecentWorks(sub.AuthorID)
+		if err != nil {
+			return err
+		}
+
+		for i := len(urls) - 1; i >= 0; i-- {
+			if id, ok := provider.Match(urls[i]); ok {
+				sub.MarkSeen(id)
+			}
+		}
+
+		added, err := b.Store.AddSubscription(context.Background(), sub)
+		if err != nil {
+			return err
+		}
+
+		if !added {
+			return messages.ErrAlreadyFollowing(sub.URL, sub.ChannelID)
+		}
+
+		eb := embeds.NewBuilder()
+		eb.SuccessTemplate(messages.FollowSuccess(sub.URL, sub.ChannelID))
+		return b.ReplyEmbed(ctx, eb.Finalize())
 	}
 }
 
+func unfollow(b *bot.Bot) func(ctx *gumi.Ctx) error {
+	return func(ctx *gumi.Ctx) error {
+		if ctx.Args.Len() == 0 {
+			return messages.ErrIncorrectCmd(ctx.Command)
+		}
+
+		sub, _, _, err := subscription(b, ctx)
+		if err != nil {
+			return err
+		}
+
+		deleted, err := b.Store.DeleteSubscription(context.Background(), sub)
+		if err != nil {
+			return err
+		}
+
+		if !deleted {
+			return messages.ErrNotFollowing(sub.URL, sub.ChannelID)
+		}
+
+		eb := embeds.NewBuilder()
+		eb.SuccessTemplate(messages.UnfollowSuccess(sub.URL, sub.ChannelID))
+		return b.ReplyEmbed(ctx, eb.Finalize())
+	}
+}
+
+//subscription parses a profile URL and an optional channel from command arguments. The current channel is used by default.
+func subscription(b *bot.Bot, ctx *gumi.Ctx) (*store.Subscription, artworks.Provider, artworks.Feed, error) {
+	url := strings.Trim(ctx.Args.Get(0).Raw, "<>")
+
+	var (
+		provider artworks.Provider
+		feed     artworks.Feed
+		authorID string
+	)
+
+	for _, p := range b.ArtworkProviders {
+		if f, ok := p.(artworks.Feed); ok {
+			if id, ok := f.MatchAuthor(url); ok {
+				provider, feed, authorID = p, f, id
+				break
+			}
+		}
+	}
+
+	if feed == nil {
+		return nil, nil, nil, messages.ErrUnknownFeed(url)
+	}
+
+	channelID := ctx.Event.ChannelID
+	if ctx.Args.Len() > 1 {
+		ch, err := ctx.Session.Channel(strings.Trim(ctx.Args.Get(1).Raw, "<#>"))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		if ch.GuildID != ctx.Event.GuildID {
+			return nil, nil, nil, messages.ErrForeignChannel(ch.ID)
+		}
+
+		channelID = ch.ID
+	}
+
+	now := time.Now()
+	return &store.Subscription{
+		GuildID:   ctx.Event.GuildID,
+		ChannelID: channelID,
+		Provider:  provider.Info().Name,
+		AuthorID:  authorID,
+		URL:       url,
+		ShardKey:  store.ShardKey(ctx.Event.GuildID),
+		CheckedAt: now,
+		CreatedAt: now,
+	}, provider, feed, nil
+}
+
+func showSubscriptions(b *bot.Bot, ctx *gumi.Ctx, subs []*store.Subscription) error {
+	lines := make([]string, 0, len(subs))
+	for ind, sub := range subs {
+		lines = append(lines, fmt.Sprintf("%v. %v → <#%v>", ind+1, sub.URL, sub.ChannelID))
+	}
+
+	if len(lines) == 0 {
+		lines = append(lines, "None")
+	}
+
+	eb := embeds.NewBuilder()
+	eb.Title("Followed artists").Description(
+		fmt.Sprintf("New artworks of followed artists are posted automatically. Use `bt!follow <profile url> [#channel]` to follow an artist.\n\n%v", strings.Join(lines, "\n")),
+	)
+
+	eb.Footer(fmt.Sprintf("%v / %v artists", len(subs), maxSubscriptions), "")
+	return b.ReplyEmbed(ctx, eb.Finalize())
+}
+
 func parseBool(s string) (bool, error) {
 	s = strings.ToLower(s)
 	if s == "true" || s == "enabled" || s == "on" {
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/bot"
+	"github.com/VTGare/boe-tea-go/internal/config"
+	"github.com/VTGare/boe-tea-go/internal/dgoutils/dgofake"
+	"github.com/VTGare/boe-tea-go/messages"
+	"github.com/VTGare/boe-tea-go/store"
+	"github.com/VTGare/boe-tea-go/store/memory"
+	"github.com/VTGare/gumi"
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+)
+
+func TestReposts_Mentions(t *testing.T) {
+	st := memory.New()
+	for _, userID := range []string{"user", "role"} {
+		err := st.AddRepost(context.Background(), &store.Repost{GuildID: "guild", UserID: userID, CreatedAt: time.Now()})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := dgofake.New("bot")
+	s.AddChannel(&discordgo.Channel{ID: "channel", GuildID: "guild"})
+
+	b := &bot.Bot{
+		Log:     zap.NewNop().Sugar(),
+		Config:  &config.Config{Repost: &config.Repost{History: true}},
+		Store:   st,
+		Session: s,
+	}
+
+	run := func(args string) error {
+		return reposts(b)(&gumi.Ctx{
+			Event: &discordgo.MessageCreate{Message: &discordgo.Message{
+				ID: "command", ChannelID: "channel", GuildID: "guild", Author: &discordgo.User{ID: "author"},
+			}},
+			Args: gumi.ParseArguments(args),
+		})
+	}
+
+	var usrErr *messages.UserErr
+	if err := run("<@&role>"); !errors.As(err, &usrErr) {
+		t.Fatalf("reposts(<@&role>) error = %v, want a user error", err)
+	}
+
+	if err := run("<@!user>"); err != nil {
+		t.Fatalf("reposts(<@!user>) error = %v", err)
+	}
+
+	sent := s.Sent("channel")
+	if len(sent) != 1 || len(sent[0].Send.Embeds) == 0 {
+		t.Fatalf("Sent() = %v, want reposts of the user", sent)
+	}
+
+	if desc := sent[0].Send.Embeds[0].Description; !strings.Contains(desc, "<@user>") || strings.Contains(desc, "<@role>") {
+		t.Errorf("reposts = %q, want only reposts of the user", desc)
+	}
+}
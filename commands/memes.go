@@ -105,7 +105,8 @@ func memesGroup(b *bot.Bot) {
 
 func brainpower(b *bot.Bot) func(ctx *gumi.Ctx) error {
 	return func(ctx *gumi.Ctx) error {
-		return ctx.Reply(
+		return b.Reply(
+			ctx,
 			"O-oooooooooo AAAAE-A-A-I-A-U- JO-oooooooooooo AAE-O-A-A-U-U-A- E-eee-ee-eee AAAAE-A-E-I-E-A-JO-ooo-oo-oo-oo EEEEO-A-AAA-AAAA",
 		)
 	}
@@ -121,7 +122,7 @@ func borgar(b *bot.Bot) func(ctx *gumi.Ctx) error {
 		).Image(
 			"https://images-ext-2.discordapp.net/external/gRgdT4gZIPbY26qK9iM0edWQA4hYPZF5RvxVdSeXhRQ/https/i.kym-cdn.com/photos/images/original/001/568/282/ef2.gif",
 		)
-		return ctx.ReplyEmbed(eb.Finalize())
+		return b.ReplyEmbed(ctx, eb.Finalize())
 	}
 }
 
@@ -139,7 +140,7 @@ func nuggets(b *bot.Bot) func(ctx *gumi.Ctx) error {
 		buf := new(bytes.Buffer)
 		nuggetsTemplatePart1.Execute(buf, n)
 
-		ctx.Reply(buf.String())
+		b.Reply(ctx, buf.String())
 		return nil
 	}
 }
@@ -165,11 +166,11 @@ func nuggets2(b *bot.Bot) func(ctx *gumi.Ctx) error {
 		part3 := buf.String()
 
 		reply := func() error {
-			if err := ctx.Reply(part2); err != nil {
+			if err := b.Reply(ctx, part2); err != nil {
 				return err
 			}
 
-			if err := ctx.Reply(part3); err != nil {
+			if err := b.Reply(ctx, part3); err != nil {
 				return err
 			}
 
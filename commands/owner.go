@@ -62,7 +62,7 @@ func reply(b *bot.Bot) func(ctx *gumi.Ctx) error {
 		}
 
 		eb.Clear()
-		ctx.ReplyEmbed(eb.SuccessTemplate("Reply has been sent.").Finalize())
+		b.ReplyEmbed(ctx, eb.SuccessTemplate("Reply has been sent.").Finalize())
 		return nil
 	}
 }
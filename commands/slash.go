@@ -0,0 +1,537 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/VTGare/boe-tea-go/bot"
+	"github.com/VTGare/boe-tea-go/internal/dgoutils"
+	"github.com/VTGare/boe-tea-go/messages"
+	"github.com/VTGare/embeds"
+	"github.com/VTGare/gumi"
+	"github.com/bwmarrin/discordgo"
+)
+
+var (
+	usageArgRegex    = regexp.MustCompile(`[<\[]([^>\]]+)[>\]]`)
+	parenthesesRegex = regexp.MustCompile(`\([^)]*\)`)
+	flagOptionsRegex = regexp.MustCompile("`\\[([^\\]]+)\\]`")
+	nonAlphanumRegex = regexp.MustCompile(`[^a-z0-9]+`)
+	//commandNameRegex is Discord's pattern of command and option names, names are also required to be lowercase.
+	commandNameRegex = regexp.MustCompile(`^[-_\p{L}\p{N}]{1,32}$`)
+)
+
+//Discord's limits of application commands.
+const (
+	maxDescription = 100
+	maxOptions     = 25
+	maxChoices     = 25
+)
+
+//usageArgument is a positional command argument parsed from command's usage string.
+//Required arguments are wrapped in <angle brackets>, optional ones in [square brackets].
+type usageArgument struct {
+	Name        string
+	Description string
+	Required    bool
+	Variadic    bool
+	Choices     []string
+}
+
+//slashCommands exposes every router command as a Discord application command.
+func slashCommands(b *bot.Bot) {
+	b.AddHandler(func(s *discordgo.Session, r *discordgo.Ready) {
+		//Application commands are global, only the first shard has to register them.
+		if s.ShardID != 0 {
+			return
+		}
+
+		commands, errs := applicationCommands(b.Router)
+		for _, err := range errs {
+			b.Log.With("error", err).Warn("skipped an invalid application command")
+		}
+
+		if _, err := s.ApplicationCommandBulkOverwrite(r.User.ID, "", commands); err != nil {
+			b.Log.With("error", err).Error("failed to register application commands")
+		}
+	})
+
+	b.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		var err error
+		switch i.Type {
+		case discordgo.InteractionApplicationCommand:
+			err = executeSlashCommand(b, s, i)
+		case discordgo.InteractionApplicationCommandAutocomplete:
+			err = autocompleteSlashCommand(b, s, i)
+		default:
+			return
+		}
+
+		if err != nil {
+			b.Log.With(
+				"guild", i.GuildID,
+				"channel", i.ChannelID,
+				"interaction", i.ID,
+				"error", err,
+			).Error("failed to handle an interaction")
+		}
+	})
+}
+
+//applicationCommands converts router commands to application commands. Commands that break Discord's limits are
+//skipped, otherwise the whole bulk overwrite is rejected.
+func applicationCommands(router *gumi.Router) ([]*discordgo.ApplicationCommand, []error) {
+	var (
+		added    = make(map[string]struct{})
+		commands = make([]*discordgo.ApplicationCommand, 0, len(router.Commands))
+		errs     = make([]error, 0)
+	)
+
+	for _, cmd := range router.Commands {
+		if _, ok := added[cmd.Name]; ok {
+			continue
+		}
+
+		//Owner commands stay prefix-only.
+		if cmd.AuthorOnly {
+			continue
+		}
+
+		added[cmd.Name] = struct{}{}
+		command := applicationCommand(cmd)
+		if err := validateCommand(command); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		commands = append(commands, command)
+	}
+
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+
+	return commands, errs
+}
+
+func applicationCommand(cmd *gumi.Command) *discordgo.ApplicationCommand {
+	var (
+		options = make([]*discordgo.ApplicationCommandOption, 0)
+		names   = make(map[string]struct{})
+	)
+
+	for _, arg := range parseUsage(cmd.Usage) {
+		opt := &discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        arg.Name,
+			Description: truncate(arg.Description, maxDescription),
+			Required:    arg.Required,
+		}
+
+		switch {
+		case len(arg.Choices) > 0:
+			opt.Choices = optionChoices(arg.Choices)
+		case hasAutocomplete(arg.Name):
+			opt.Autocomplete = true
+		case !arg.Variadic && strings.Contains(arg.Name, "channel"):
+			opt.Type = discordgo.ApplicationCommandOptionChannel
+			opt.ChannelTypes = []discordgo.ChannelType{discordgo.ChannelTypeGuildText}
+		}
+
+		names[opt.Name] = struct{}{}
+		options = append(options, opt)
+	}
+
+	for _, name := range flagNames(cmd) {
+		if _, ok := names[name]; ok {
+			continue
+		}
+
+		desc := strings.NewReplacer("**", "", "`", "").Replace(cmd.Flags[name])
+		opt := &discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        name,
+			Description: truncate(desc, maxDescription),
+		}
+
+		switch {
+		case strings.Contains(cmd.Flags[name], "integer"):
+			opt.Type = discordgo.ApplicationCommandOptionInteger
+		default:
+			if match := flagOptionsRegex.FindStringSubmatch(cmd.Flags[name]); match != nil {
+				opt.Choices = optionChoices(strings.Split(match[1], ", "))
+			}
+		}
+
+		options = append(options, opt)
+	}
+
+	//Discord requires required options to go first. Arguments are matched by name, so their order doesn't matter.
+	sort.SliceStable(options, func(i, j int) bool {
+		return options[i].Required && !options[j].Required
+	})
+
+	return &discordgo.ApplicationCommand{
+		Type:        discordgo.ChatApplicationCommand,
+		Name:        strings.ToLower(cmd.Name),
+		Description: truncate(cmd.Description, maxDescription),
+		Options:     options,
+	}
+}
+
+//validateCommand checks an application command against Discord's limits.
+func validateCommand(cmd *discordgo.ApplicationCommand) error {
+	if err := validateName(cmd.Name, cmd.Description); err != nil {
+		return fmt.Errorf("command %v: %w", cmd.Name, err)
+	}
+
+	if len(cmd.Options) > maxOptions {
+		return fmt.Errorf("command %v: %v options, at most %v are allowed", cmd.Name, len(cmd.Options), maxOptions)
+	}
+
+	optional := false
+	for _, opt := range cmd.Options {
+		if err := validateName(opt.Name, opt.Description); err != nil {
+			return fmt.Errorf("command %v: option %v: %w", cmd.Name, opt.Name, err)
+		}
+
+		if opt.Required && optional {
+			return fmt.Errorf("command %v: required option %v follows an optional one", cmd.Name, opt.Name)
+		}
+		optional = !opt.Required
+
+		if len(opt.Choices) > maxChoices {
+			return fmt.Errorf("command %v: option %v has %v choices, at most %v are allowed", cmd.Name, opt.Name, len(opt.Choices), maxChoices)
+		}
+
+		for _, choice := range opt.Choices {
+			if n := utf8.RuneCountInString(choice.Name); n == 0 || n > maxDescription {
+				return fmt.Errorf("command %v: option %v has an invalid choice %q", cmd.Name, opt.Name, choice.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateName(name, description string) error {
+	if !commandNameRegex.MatchString(name) || strings.ToLower(name) != name {
+		return fmt.Errorf("invalid name %q", name)
+	}
+
+	if n := utf8.RuneCountInString(description); n == 0 || n > maxDescription {
+		return fmt.Errorf("description must be 1-%v characters long", maxDescription)
+	}
+
+	return nil
+}
+
+//parseUsage converts command's usage string, e.g. bt!set <setting name> <new setting>, to a list of arguments.
+func parseUsage(usage string) []*usageArgument {
+	args := make([]*usageArgument, 0)
+	for _, match := range usageArgRegex.FindAllStringSubmatch(usage, -1) {
+		raw := strings.TrimSpace(parenthesesRegex.ReplaceAllString(match[1], ""))
+		if raw == "flags" {
+			continue
+		}
+
+		arg := &usageArgument{
+			Name:        optionName(raw),
+			Description: raw,
+			Required:    strings.HasPrefix(match[0], "<"),
+			Variadic:    strings.Contains(raw, "...") || strings.Contains(raw, "ids") || strings.Contains(raw, "channels"),
+		}
+
+		if !strings.Contains(raw, " ") {
+			if choices := strings.FieldsFunc(raw, isChoiceSeparator); len(choices) > 1 {
+				arg.Choices = choices
+			}
+		}
+
+		args = append(args, arg)
+	}
+
+	return args
+}
+
+//isChoiceSeparator reports if a rune separates choices of an argument, e.g. <add/remove> or [export|import].
+func isChoiceSeparator(r rune) bool {
+	return r == '/' || r == '|'
+}
+
+//optionalByValue reports if commands tell an optional argument apart from the next ones by its value,
+//e.g. a channel mention or one of the choices. Such arguments can be omitted in the middle.
+func (arg *usageArgument) optionalByValue() bool {
+	return len(arg.Choices) > 0 || (!arg.Variadic && strings.Contains(arg.Name, "channel"))
+}
+
+//flagNames returns names of command's flags in a stable order. Commands without [flags] in their usage
+//use the flags map to document settings instead.
+func flagNames(cmd *gumi.Command) []string {
+	if !strings.Contains(cmd.Usage, "[flags]") {
+		return nil
+	}
+
+	names := make([]string, 0, len(cmd.Flags))
+	for name := range cmd.Flags {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+//slashArguments converts application command options back to a raw argument string understood by gumi commands.
+//Gumi splits arguments by whitespace and can't escape them, so options that would be read differently are rejected.
+func slashArguments(cmd *gumi.Command, options []*discordgo.ApplicationCommandInteractionDataOption) (string, error) {
+	values := make(map[string]string)
+	for _, opt := range options {
+		values[opt.Name] = optionValue(opt)
+	}
+
+	var (
+		usage   = parseUsage(cmd.Usage)
+		flags   = flagNames(cmd)
+		args    = make([]string, 0, len(values))
+		omitted *usageArgument
+		last    = -1
+	)
+
+	for ind, arg := range usage {
+		if _, ok := values[arg.Name]; ok {
+			last = ind
+		}
+	}
+
+	for ind, arg := range usage[:last+1] {
+		val, ok := values[arg.Name]
+		if !ok {
+			if !arg.optionalByValue() {
+				omitted = arg
+			}
+
+			continue
+		}
+
+		//Commands read arguments by position, a skipped argument would shift the next ones.
+		if omitted != nil {
+			return "", messages.ErrSlashOptionMissing(omitted.Name, arg.Name)
+		}
+
+		if ind != last && strings.ContainsAny(val, " \t\n") {
+			return "", messages.ErrSlashOptionSpaces(arg.Name)
+		}
+
+		for _, field := range strings.Fields(val) {
+			for _, flag := range flags {
+				if strings.HasPrefix(field, flag+":") {
+					return "", messages.ErrSlashOptionFlag(arg.Name, field)
+				}
+			}
+		}
+
+		args = append(args, val)
+	}
+
+	for _, name := range flags {
+		val, ok := values[name]
+		if !ok {
+			continue
+		}
+
+		if strings.ContainsAny(val, " \t\n") {
+			return "", messages.ErrSlashOptionSpaces(name)
+		}
+
+		args = append(args, name+":"+val)
+	}
+
+	return strings.Join(args, " "), nil
+}
+
+func executeSlashCommand(b *bot.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	data := i.ApplicationCommandData()
+
+	cmd, ok := b.Router.Commands[data.Name]
+	if !ok {
+		return nil
+	}
+
+	interaction := dgoutils.NewInteraction(i.Interaction)
+	if err := interaction.Defer(s); err != nil {
+		return fmt.Errorf("failed to defer an interaction: %w", err)
+	}
+
+	args, err := slashArguments(cmd, data.Options)
+	if err != nil {
+		eb := embeds.NewBuilder()
+		eb.FailureTemplate(err.Error())
+		if _, err := interaction.Respond(s, &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{eb.Finalize()}}); err != nil {
+			return fmt.Errorf("failed to respond to an interaction: %w", err)
+		}
+
+		return nil
+	}
+
+	b.Interactions.Set(interaction)
+	defer b.Interactions.Remove(interaction.ID)
+
+	user := interactionUser(i.Interaction)
+	content := fmt.Sprintf("<@%v> %v %v", s.State.User.ID, cmd.Name, args)
+
+	//Application commands are routed through the same router as prefix commands
+	//to keep permission checks, rate limits and error handling in one place.
+	b.Router.Handler()(s, &discordgo.MessageCreate{
+		Message: &discordgo.Message{
+			ID:        i.ID,
+			ChannelID: i.ChannelID,
+			GuildID:   i.GuildID,
+			Author:    user,
+			Member:    i.Member,
+			Content:   strings.TrimSpace(content),
+			Interaction: &discordgo.MessageInteraction{
+				ID:   i.ID,
+				Type: i.Type,
+				Name: data.Name,
+				User: user,
+			},
+		},
+	})
+
+	return interaction.Finish(s)
+}
+
+func autocompleteSlashCommand(b *bot.Bot, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	data := i.ApplicationCommandData()
+
+	cmd, ok := b.Router.Commands[data.Name]
+	if !ok {
+		return nil
+	}
+
+	var focused *discordgo.ApplicationCommandInteractionDataOption
+	for _, opt := range data.Options {
+		if opt.Focused {
+			focused = opt
+		}
+	}
+
+	if focused == nil {
+		return nil
+	}
+
+	suggestions, err := autocomplete(b, cmd, focused.Name, interactionUser(i.Interaction).ID)
+	if err != nil {
+		return err
+	}
+
+	value := strings.ToLower(focused.StringValue())
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		if len(choices) == 25 {
+			break
+		}
+
+		if strings.Contains(strings.ToLower(suggestion), value) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: suggestion, Value: suggestion})
+		}
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+}
+
+func hasAutocomplete(option string) bool {
+	switch option {
+	case "group_name", "from", "setting_name":
+		return true
+	}
+
+	return false
+}
+
+func autocomplete(b *bot.Bot, cmd *gumi.Command, option, userID string) ([]string, error) {
+	switch option {
+	case "group_name", "from":
+		user, err := b.Store.User(context.Background(), userID)
+		if err != nil {
+			return nil, err
+		}
+
+		names := make([]string, 0, len(user.Groups))
+		for _, group := range user.Groups {
+			names = append(names, group.Name)
+		}
+
+		return names, nil
+	case "setting_name":
+		if cmd.Name == "userset" {
+			return userSettings, nil
+		}
+
+		settings := make([]string, 0, len(guildSettings)+len(b.ArtworkProviders))
+		settings = append(settings, guildSettings...)
+		for _, provider := range b.ArtworkProviders {
+			settings = append(settings, provider.Info().Name)
+		}
+
+		return settings, nil
+	}
+
+	return nil, nil
+}
+
+func interactionUser(i *discordgo.Interaction) *discordgo.User {
+	if i.Member != nil {
+		return i.Member.User
+	}
+
+	return i.User
+}
+
+func optionValue(opt *discordgo.ApplicationCommandInteractionDataOption) string {
+	switch opt.Type {
+	case discordgo.ApplicationCommandOptionInteger:
+		return strconv.FormatInt(opt.IntValue(), 10)
+	case discordgo.ApplicationCommandOptionBoolean:
+		return strconv.FormatBool(opt.BoolValue())
+	case discordgo.ApplicationCommandOptionChannel:
+		return fmt.Sprintf("<#%v>", opt.Value)
+	default:
+		return fmt.Sprintf("%v", opt.Value)
+	}
+}
+
+func optionChoices(values []string) []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(values))
+	for _, val := range values {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: val, Value: val})
+	}
+
+	return choices
+}
+
+//optionName converts a usage argument to a valid application command option name.
+func optionName(s string) string {
+	name := nonAlphanumRegex.ReplaceAllString(strings.ToLower(s), "_")
+	name = strings.Trim(name, "_")
+
+	return truncate(name, 32)
+}
+
+//truncate cuts a string to length runes. Discord counts characters, not bytes.
+func truncate(s string, length int) string {
+	runes := []rune(s)
+	if len(runes) <= length {
+		return s
+	}
+
+	return strings.TrimSpace(string(runes[:length]))
+}
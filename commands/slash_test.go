@@ -0,0 +1,290 @@
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/VTGare/gumi"
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestParseUsage(t *testing.T) {
+	tests := []struct {
+		name  string
+		usage string
+		want  []*usageArgument
+	}{
+		{name: "no arguments", usage: "bt!ping", want: []*usageArgument{}},
+		{
+			name:  "required and optional",
+			usage: "bt!share <artwork url> [indices to exclude]",
+			want: []*usageArgument{
+				{Name: "artwork_url", Description: "artwork url", Required: true},
+				{Name: "indices_to_exclude", Description: "indices to exclude"},
+			},
+		},
+		{
+			name:  "choices",
+			usage: "bt!artchannels <add/remove> [channel ids/category id...]",
+			want: []*usageArgument{
+				{Name: "add_remove", Description: "add/remove", Required: true, Choices: []string{"add", "remove"}},
+				{Name: "channel_ids_category_id", Description: "channel ids/category id...", Variadic: true},
+			},
+		},
+		{
+			name:  "parentheses and flags",
+			usage: "bt!crosspost <artwork url> [excluded channels (by default all)] [flags]",
+			want: []*usageArgument{
+				{Name: "artwork_url", Description: "artwork url", Required: true},
+				{Name: "excluded_channels", Description: "excluded channels", Variadic: true},
+			},
+		},
+		{
+			name:  "long name",
+			usage: "bt!groupfilter <provider|nsfw|tags|exclude|likes|clear|everything>",
+			want: []*usageArgument{
+				{
+					Name:        "provider_nsfw_tags_exclude_likes",
+					Description: "provider|nsfw|tags|exclude|likes|clear|everything",
+					Required:    true,
+					Choices:     []string{"provider", "nsfw", "tags", "exclude", "likes", "clear", "everything"},
+				},
+			},
+		},
+		{
+			name:  "optional choices",
+			usage: "bt!favourites [export|import] [flags]",
+			want: []*usageArgument{
+				{Name: "export_import", Description: "export|import", Choices: []string{"export", "import"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseUsage(tt.usage); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("parseUsage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplicationCommand(t *testing.T) {
+	cmd := applicationCommand(&gumi.Command{
+		Name:        "groupfilter",
+		Description: "Sets crosspost filters of a group.",
+		Usage:       "bt!groupfilter <group name> [channel] <provider|nsfw|tags|exclude|likes|clear> [values]",
+	})
+
+	if err := validateCommand(cmd); err != nil {
+		t.Fatalf("validateCommand() = %v", err)
+	}
+
+	//Required options are moved before optional ones.
+	want := []string{"group_name", "provider_nsfw_tags_exclude_likes", "channel", "values"}
+	got := make([]string, 0, len(cmd.Options))
+	for _, opt := range cmd.Options {
+		got = append(got, opt.Name)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("options = %v, want %v", got, want)
+	}
+
+	if cmd.Options[2].Type != discordgo.ApplicationCommandOptionChannel {
+		t.Fatalf("channel option type = %v, want %v", cmd.Options[2].Type, discordgo.ApplicationCommandOptionChannel)
+	}
+}
+
+func TestSlashArguments(t *testing.T) {
+	str := func(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+		return &discordgo.ApplicationCommandInteractionDataOption{
+			Name:  name,
+			Type:  discordgo.ApplicationCommandOptionString,
+			Value: value,
+		}
+	}
+
+	groupfilter := &gumi.Command{
+		Usage: "bt!groupfilter <group name> [channel] <provider|nsfw|tags|exclude|likes|clear> [values]",
+	}
+
+	tests := []struct {
+		name    string
+		cmd     *gumi.Command
+		options []*discordgo.ApplicationCommandInteractionDataOption
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "usage order",
+			cmd:  groupfilter,
+			options: []*discordgo.ApplicationCommandInteractionDataOption{
+				str("provider_nsfw_tags_exclude_likes", "tags"),
+				str("values", "gawr_gura"),
+				str("group_name", "hololive"),
+				{Name: "channel", Type: discordgo.ApplicationCommandOptionChannel, Value: "123"},
+			},
+			want: "hololive <#123> tags gawr_gura",
+		},
+		{
+			name: "omitted channel",
+			cmd:  groupfilter,
+			options: []*discordgo.ApplicationCommandInteractionDataOption{
+				str("group_name", "hololive"),
+				str("provider_nsfw_tags_exclude_likes", "tags"),
+				str("values", "gawr_gura ina"),
+			},
+			want: "hololive tags gawr_gura ina",
+		},
+		{
+			name: "omitted argument before a set one",
+			cmd:  &gumi.Command{Usage: "bt!unfollow [profile url] [note] [#channel]"},
+			options: []*discordgo.ApplicationCommandInteractionDataOption{
+				str("profile_url", "https://pixiv.net/users/1"),
+				{Name: "channel", Type: discordgo.ApplicationCommandOptionChannel, Value: "123"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "spaces in a middle argument",
+			cmd:  groupfilter,
+			options: []*discordgo.ApplicationCommandInteractionDataOption{
+				str("group_name", "holo live"),
+				str("provider_nsfw_tags_exclude_likes", "tags"),
+			},
+			wantErr: true,
+		},
+		{
+			name:    "spaces in the last argument",
+			cmd:     &gumi.Command{Usage: "bt!feedback <your wall of text here>"},
+			options: []*discordgo.ApplicationCommandInteractionDataOption{str("your_wall_of_text_here", "nice bot")},
+			want:    "nice bot",
+		},
+		{
+			name:    "missing optional argument",
+			cmd:     &gumi.Command{Usage: "bt!share <artwork url> [indices to exclude]"},
+			options: []*discordgo.ApplicationCommandInteractionDataOption{str("artwork_url", "https://pixiv.net/en/artworks/1")},
+			want:    "https://pixiv.net/en/artworks/1",
+		},
+		{
+			name: "flags",
+			cmd: &gumi.Command{
+				Usage: "bt!leaderboard [flags]",
+				Flags: map[string]string{"limit": "integer", "during": "`[day, week, month]`"},
+			},
+			options: []*discordgo.ApplicationCommandInteractionDataOption{
+				{Name: "limit", Type: discordgo.ApplicationCommandOptionInteger, Value: float64(10)},
+				str("during", "week"),
+			},
+			want: "during:week limit:10",
+		},
+		{
+			name: "flag in an argument",
+			cmd: &gumi.Command{
+				Usage: "bt!search <query> [flags]",
+				Flags: map[string]string{"limit": "integer"},
+			},
+			options: []*discordgo.ApplicationCommandInteractionDataOption{str("query", "gura limit:100")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := slashArguments(tt.cmd, tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("slashArguments() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Fatalf("slashArguments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCommand(t *testing.T) {
+	option := func(name string, required bool) *discordgo.ApplicationCommandOption {
+		return &discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        name,
+			Description: name,
+			Required:    required,
+		}
+	}
+
+	tooMany := make([]*discordgo.ApplicationCommandOption, 0, maxOptions+1)
+	for i := 0; i <= maxOptions; i++ {
+		tooMany = append(tooMany, option("option"+strings.Repeat("a", i), false))
+	}
+
+	tests := []struct {
+		name    string
+		cmd     *discordgo.ApplicationCommand
+		wantErr bool
+	}{
+		{name: "valid", cmd: &discordgo.ApplicationCommand{
+			Name:        "share",
+			Description: "Shares an artwork.",
+			Options:     []*discordgo.ApplicationCommandOption{option("url", true), option("indices", false)},
+		}},
+		{name: "empty description", cmd: &discordgo.ApplicationCommand{Name: "share"}, wantErr: true},
+		{name: "uppercase name", cmd: &discordgo.ApplicationCommand{Name: "Share", Description: "Shares."}, wantErr: true},
+		{name: "space in name", cmd: &discordgo.ApplicationCommand{Name: "sh are", Description: "Shares."}, wantErr: true},
+		{name: "long name", cmd: &discordgo.ApplicationCommand{Name: strings.Repeat("a", 33), Description: "Shares."}, wantErr: true},
+		{
+			name:    "too many options",
+			cmd:     &discordgo.ApplicationCommand{Name: "share", Description: "Shares.", Options: tooMany},
+			wantErr: true,
+		},
+		{
+			name: "required after optional",
+			cmd: &discordgo.ApplicationCommand{
+				Name:        "share",
+				Description: "Shares.",
+				Options:     []*discordgo.ApplicationCommandOption{option("indices", false), option("url", true)},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid option name",
+			cmd: &discordgo.ApplicationCommand{
+				Name:        "share",
+				Description: "Shares.",
+				Options:     []*discordgo.ApplicationCommandOption{option("", true)},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateCommand(tt.cmd); (err != nil) != tt.wantErr {
+				t.Fatalf("validateCommand() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name   string
+		s      string
+		length int
+		want   string
+	}{
+		{name: "short", s: "artwork", length: 10, want: "artwork"},
+		{name: "ascii", s: "artwork url", length: 8, want: "artwork"},
+		{name: "multibyte", s: "アートワーク", length: 3, want: "アート"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncate(tt.s, tt.length); got != tt.want {
+				t.Fatalf("truncate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -146,7 +146,7 @@ func nhentai(b *bot.Bot) func(ctx *gumi.Ctx) error {
 			)
 		}
 
-		return ctx.ReplyEmbed(eb.Finalize())
+		return b.ReplyEmbed(ctx, eb.Finalize())
 	}
 }
 
@@ -185,7 +185,7 @@ func sauce(b *bot.Bot) func(ctx *gumi.Ctx) error {
 
 		sauceEmbeds := sauceNAOEmbeds(filtered)
 		widget := dgoutils.NewWidget(ctx.Session, ctx.Event.Author.ID, sauceEmbeds)
-		return widget.Start(ctx.Event.ChannelID)
+		return startWidget(b, ctx, widget)
 	}
 }
 
@@ -1,25 +1,53 @@
 package commands
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/VTGare/boe-tea-go/backup"
 	"github.com/VTGare/boe-tea-go/bot"
 	"github.com/VTGare/boe-tea-go/commands/flags"
 	"github.com/VTGare/boe-tea-go/internal/arrays"
 	"github.com/VTGare/boe-tea-go/internal/dgoutils"
+	"github.com/VTGare/boe-tea-go/internal/ratelimit"
 	"github.com/VTGare/boe-tea-go/messages"
 	"github.com/VTGare/boe-tea-go/store"
 	"github.com/VTGare/embeds"
 	"github.com/VTGare/gumi"
 	"github.com/bwmarrin/discordgo"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
+//userSettings are setting names accepted by the userset command.
+var userSettings = []string{"dm", "crosspost", "ignore"}
+
+const (
+	//maxCollections is the maximum number of user's bookmark collections.
+	maxCollections = 25
+	//maxTags is the maximum number of tags on a bookmark.
+	maxTags = 10
+	//maxImport is the maximum number of favourites imported at once. Unknown artworks are fetched from providers one by one.
+	maxImport = 1000
+	//maxImportSize is the maximum size of an imported file in bytes.
+	maxImportSize = 4 << 20
+	//maxExportSize is the maximum size of an exported file. It's Discord's attachment limit.
+	maxExportSize = 8 << 20
+)
+
+//importLimiter spaces out provider requests of all imports, so large imports don't get the bot rate limited.
+var importLimiter = ratelimit.New(time.Second, nil)
+
+//nameRegex matches valid collection names and tags. They're used in favourites flags, so they can't contain spaces.
+var nameRegex = regexp.MustCompile(`^[\p{L}\p{N}_\-]{1,32}$`)
+
 func userGroup(b *bot.Bot) {
 	group := "user"
 	b.Router.RegisterCmd(&gumi.Command{
@@ -87,18 +115,41 @@ func userGroup(b *bot.Bot) {
 		Exec:        copygroup(b),
 	})
 
+	b.Router.RegisterCmd(&gumi.Command{
+		Name:        "groupfilter",
+		Group:       group,
+		Aliases:     []string{"filter"},
+		Description: "Filters artworks crossposted to a whole group or to one of its channels.",
+		Usage:       "bt!groupfilter <group name> [channel] <provider|nsfw|tags|exclude|likes|clear> [values]",
+		Example:     "bt!groupfilter lewds #sfw-art nsfw false",
+		Flags: map[string]string{
+			"provider": "`bt!groupfilter <group> [channel] provider [providers]`. Crossposts only artworks from the providers. Clears the filter if used without providers.",
+			"nsfw":     "`bt!groupfilter <group> [channel] nsfw [true|false|any]`. Crossposts only NSFW or only safe artworks.",
+			"tags":     "`bt!groupfilter <group> [channel] tags [tags]`. Crossposts only artworks with at least one of the tags.",
+			"exclude":  "`bt!groupfilter <group> [channel] exclude [tags]`. Doesn't crosspost artworks with any of the tags.",
+			"likes":    "`bt!groupfilter <group> [channel] likes [number]`. Crossposts only artworks with at least this many likes.",
+			"clear":    "`bt!groupfilter <group> [channel] clear`. Removes all filters.",
+		},
+		RateLimiter: gumi.NewRateLimiter(5 * time.Second),
+		Exec:        groupfilter(b),
+	})
+
 	b.Router.RegisterCmd(&gumi.Command{
 		Name:        "favourites",
 		Group:       group,
 		Aliases:     []string{"favorites", "favs"},
-		Description: "Shows your favourites. Use help command to learn more about filtering and sorting.",
-		Usage:       "bt!favourites [flags]",
-		Example:     "bt!favourites during:month sort:time order:asc",
+		Description: "Shows, exports or imports your favourites. Use help command to learn more about filtering and sorting.",
+		Usage:       "bt!favourites [export|import] [flags]",
+		Example:     "bt!favourites collection:waifus tag:maid order:asc",
 		Flags: map[string]string{
-			"sort":   "**Options:** `[time, favourites]`. **Default:** time. Changes sort type.",
-			"order":  "**Options:** `[asc, desc]`. **Default:** desc. Changes order of sorted artworks.",
-			"mode":   "**Options:** `[all, sfw, nsfw]`. **Default:** all in nsfw channels and DMs, sfw otherwise.",
-			"during": "**Options:** `[day, week, month]`. **Default:** none. Filters artworks by time.",
+			"sort":       "**Options:** `[time, favourites]`. **Default:** time. Changes sort type.",
+			"order":      "**Options:** `[asc, desc]`. **Default:** desc. Changes order of sorted artworks.",
+			"mode":       "**Options:** `[all, sfw, nsfw]`. **Default:** all in nsfw channels and DMs, sfw otherwise.",
+			"during":     "**Options:** `[day, week, month]`. **Default:** none. Filters artworks by time.",
+			"collection": "**Options:** `[collection name]`. **Default:** none. Shows favourites from a collection.",
+			"tag":        "**Options:** `[tag, tag,tag]`. **Default:** none. Shows favourites that have all of the tags.",
+			"export":     "`bt!favourites export [format:json|csv|html]`. Sends all your favourites to direct messages as a file.",
+			"import":     "`bt!favourites import [URLs]`. Imports a JSON export or a list of artwork URLs from an attached file or the message.",
 		},
 		RateLimiter: gumi.NewRateLimiter(10 * time.Second),
 		Exec:        favourites(b),
@@ -115,6 +166,43 @@ func userGroup(b *bot.Bot) {
 		Exec:        unfav(b),
 	})
 
+	b.Router.RegisterCmd(&gumi.Command{
+		Name:        "collection",
+		Group:       group,
+		Aliases:     []string{"collections", "folder"},
+		Description: "Organizes favourites into collections. Shows your collections if used without arguments.",
+		Usage:       "bt!collection [create|delete|add|remove] <collection name> [artwork IDs or URLs]",
+		Example:     "bt!collection add waifus 69 420",
+		Flags: map[string]string{
+			"create": "`bt!collection create <name>`. Creates a new collection.",
+			"delete": "`bt!collection delete <name>`. Deletes a collection, its favourites are kept.",
+			"add":    "`bt!collection add <name> [artworks]`. Adds or moves favourites to a collection.",
+			"remove": "`bt!collection remove [artworks]`. Removes favourites from their collection.",
+		},
+		RateLimiter: gumi.NewRateLimiter(5 * time.Second),
+		Exec:        collection(b),
+	})
+
+	b.Router.RegisterCmd(&gumi.Command{
+		Name:        "tag",
+		Group:       group,
+		Description: "Adds tags to a favourite. Shows its tags if used without tags.",
+		Usage:       "bt!tag <artwork ID or URL> [tags]",
+		Example:     "bt!tag 69 maid swimsuit",
+		RateLimiter: gumi.NewRateLimiter(5 * time.Second),
+		Exec:        tag(b),
+	})
+
+	b.Router.RegisterCmd(&gumi.Command{
+		Name:        "untag",
+		Group:       group,
+		Description: "Removes tags from a favourite. Removes all tags if used without tags.",
+		Usage:       "bt!untag <artwork ID or URL> [tags]",
+		Example:     "bt!untag 69 swimsuit",
+		RateLimiter: gumi.NewRateLimiter(5 * time.Second),
+		Exec:        untag(b),
+	})
+
 	b.Router.RegisterCmd(&gumi.Command{
 		Name:        "userset",
 		Aliases:     []string{"profile"},
@@ -145,25 +233,32 @@ func groups(b *bot.Bot) func(ctx *gumi.Ctx) error {
 		eb.Description(locale.Description)
 
 		for _, group := range user.Groups {
-			eb.AddField(
-				locale.Group+" "+group.Name,
-				fmt.Sprintf(
-					"**%v:** %v\n **%v:**\n%v",
-					locale.Parent, fmt.Sprintf(
-						"<#%v> | `%v`",
-						group.Parent, group.Parent,
-					),
-					locale.Children, strings.Join(arrays.Map(
-						group.Children,
-						func(s string) string {
-							return fmt.Sprintf("<#%v> | `%v`", s, s)
-						},
-					), "\n"),
+			value := fmt.Sprintf(
+				"**%v:** %v\n **%v:**\n%v",
+				locale.Parent, fmt.Sprintf(
+					"<#%v> | `%v`",
+					group.Parent, group.Parent,
 				),
+				locale.Children, strings.Join(arrays.Map(
+					group.Children,
+					func(s string) string {
+						if f := group.ChildFilters[s]; !f.IsZero() {
+							return fmt.Sprintf("<#%v> | `%v` • %v", s, s, formatCrosspostFilter(f))
+						}
+
+						return fmt.Sprintf("<#%v> | `%v`", s, s)
+					},
+				), "\n"),
 			)
+
+			if !group.Filter.IsZero() {
+				value += fmt.Sprintf("\n **%v:** %v", locale.Filter, formatCrosspostFilter(group.Filter))
+			}
+
+			eb.AddField(locale.Group+" "+group.Name, value)
 		}
 
-		ctx.ReplyEmbed(eb.Finalize())
+		b.ReplyEmbed(ctx, eb.Finalize())
 		return nil
 	}
 }
@@ -193,7 +288,7 @@ func newgroup(b *bot.Bot) func(ctx *gumi.Ctx) error {
 
 		if err != nil {
 			switch {
-			case errors.Is(err, mongo.ErrNoDocuments):
+			case errors.Is(err, store.ErrNotFound):
 				return messages.ErrInsertGroup(name, parent)
 			default:
 				return err
@@ -206,7 +301,7 @@ func newgroup(b *bot.Bot) func(ctx *gumi.Ctx) error {
 			"Created a group `%v` with parent channel <#%v> | `%v`", name, parent, parent,
 		))
 
-		ctx.ReplyEmbed(eb.Finalize())
+		b.ReplyEmbed(ctx, eb.Finalize())
 		return nil
 	}
 }
@@ -227,7 +322,7 @@ func delgroup(b *bot.Bot) func(ctx *gumi.Ctx) error {
 
 		if err != nil {
 			switch {
-			case errors.Is(err, mongo.ErrNoDocuments):
+			case errors.Is(err, store.ErrNotFound):
 				return messages.ErrDeleteGroup(name)
 			default:
 				return err
@@ -240,7 +335,7 @@ func delgroup(b *bot.Bot) func(ctx *gumi.Ctx) error {
 			"Removed a group named `%v`", name,
 		))
 
-		ctx.ReplyEmbed(eb.Finalize())
+		b.ReplyEmbed(ctx, eb.Finalize())
 		return nil
 	}
 }
@@ -294,7 +389,7 @@ func push(b *bot.Bot) func(ctx *gumi.Ctx) error {
 
 			if err != nil {
 				switch {
-				case errors.Is(err, mongo.ErrNoDocuments):
+				case errors.Is(err, store.ErrNotFound):
 					continue
 				default:
 					return err
@@ -307,7 +402,7 @@ func push(b *bot.Bot) func(ctx *gumi.Ctx) error {
 		if len(inserted) > 0 {
 			eb := embeds.NewBuilder()
 			eb.SuccessTemplate(messages.UserPushSuccess(name, inserted))
-			ctx.ReplyEmbed(eb.Finalize())
+			b.ReplyEmbed(ctx, eb.Finalize())
 		} else {
 			return messages.ErrUserPushFail(name)
 		}
@@ -352,7 +447,7 @@ func remove(b *bot.Bot) func(ctx *gumi.Ctx) error {
 
 			if err != nil {
 				switch {
-				case errors.Is(err, mongo.ErrNoDocuments):
+				case errors.Is(err, store.ErrNotFound):
 					continue
 				default:
 					return err
@@ -365,7 +460,7 @@ func remove(b *bot.Bot) func(ctx *gumi.Ctx) error {
 		if len(removed) > 0 {
 			eb := embeds.NewBuilder()
 			eb.SuccessTemplate(messages.UserRemoveSuccess(name, removed))
-			ctx.ReplyEmbed(eb.Finalize())
+			b.ReplyEmbed(ctx, eb.Finalize())
 		} else {
 			return messages.ErrUserRemoveFail(name)
 		}
@@ -400,6 +495,13 @@ func copygroup(b *bot.Bot) func(ctx *gumi.Ctx) error {
 					Children: arrays.Filter(group.Children, func(s string) bool {
 						return s != parent
 					}),
+					Filter: group.Filter,
+				}
+
+				for _, child := range newGroup.Children {
+					if f, ok := group.ChildFilters[child]; ok {
+						newGroup.SetFilter(child, f)
+					}
 				}
 
 				_, err := b.Store.CreateCrosspostGroup(context.Background(), user.ID, newGroup)
@@ -412,7 +514,7 @@ func copygroup(b *bot.Bot) func(ctx *gumi.Ctx) error {
 					messages.UserCopyGroupSuccess(src, dest, newGroup.Children),
 				)
 
-				return ctx.ReplyEmbed(eb.Finalize())
+				return b.ReplyEmbed(ctx, eb.Finalize())
 			}
 		}
 
@@ -420,8 +522,176 @@ func copygroup(b *bot.Bot) func(ctx *gumi.Ctx) error {
 	}
 }
 
+func groupfilter(b *bot.Bot) func(ctx *gumi.Ctx) error {
+	return func(ctx *gumi.Ctx) error {
+		if ctx.Args.Len() < 2 {
+			return messages.ErrIncorrectCmd(ctx.Command)
+		}
+
+		user, err := b.Store.User(context.Background(), ctx.Event.Author.ID)
+		if err != nil {
+			return err
+		}
+
+		name := ctx.Args.Get(0).Raw
+		group, ok := user.FindGroupByName(name)
+		if !ok {
+			return messages.ErrUserGroupNotFound(name)
+		}
+
+		var (
+			child  string
+			filter = group.Filter
+			args   = argsFrom(ctx, 1)
+		)
+
+		if channelID := strings.Trim(args[0], "<#>"); arrays.Any(group.Children, channelID) {
+			child = channelID
+			filter = group.ChildFilters[child]
+			args = args[1:]
+		}
+
+		if len(args) == 0 {
+			return messages.ErrIncorrectCmd(ctx.Command)
+		}
+
+		var f store.CrosspostFilter
+		if filter != nil {
+			f = *filter
+		}
+
+		action, values := strings.ToLower(args[0]), args[1:]
+		switch action {
+		case "provider", "providers":
+			providers := make([]string, 0, len(values))
+			for _, value := range values {
+				value = strings.ToLower(value)
+				if _, ok := b.Provider(value); !ok {
+					return messages.ErrUnknownProvider(value)
+				}
+
+				if !arrays.Any(providers, value) {
+					providers = append(providers, value)
+				}
+			}
+
+			f.Providers = providers
+		case "nsfw":
+			f.NSFW = nil
+			if len(values) > 0 && !strings.EqualFold(values[0], "any") {
+				nsfw, err := parseBool(values[0])
+				if err != nil {
+					return err
+				}
+
+				f.NSFW = &nsfw
+			}
+		case "tags", "tag":
+			f.Tags, err = filterTags(values)
+		case "exclude":
+			f.ExcludeTags, err = filterTags(values)
+		case "likes":
+			f.MinLikes = 0
+			if len(values) > 0 {
+				likes, err := strconv.Atoi(values[0])
+				if err != nil || likes < 0 {
+					return messages.ErrParseInt(values[0])
+				}
+
+				f.MinLikes = likes
+			}
+		case "clear", "reset":
+			f = store.CrosspostFilter{}
+		default:
+			return messages.ErrIncorrectCmd(ctx.Command)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		_, err = b.Store.SetCrosspostFilter(context.Background(), user.ID, name, child, &f)
+		if err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				return messages.ErrUserGroupNotFound(name)
+			default:
+				return err
+			}
+		}
+
+		eb := embeds.NewBuilder()
+		eb.SuccessTemplate(messages.UserGroupFilterSuccess(name, child, formatCrosspostFilter(&f)))
+		return b.ReplyEmbed(ctx, eb.Finalize())
+	}
+}
+
+//filterTags returns lowercase tags of a crosspost filter without duplicates.
+func filterTags(values []string) ([]string, error) {
+	tags := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.ToLower(value)
+		if !arrays.Any(tags, value) {
+			tags = append(tags, value)
+		}
+	}
+
+	if len(tags) > maxTags {
+		return nil, messages.ErrTooManyTags(maxTags)
+	}
+
+	return tags, nil
+}
+
+//formatCrosspostFilter describes a crosspost filter in one line.
+func formatCrosspostFilter(f *store.CrosspostFilter) string {
+	if f.IsZero() {
+		return "none"
+	}
+
+	codes := func(items []string) string {
+		return strings.Join(arrays.Map(items, func(s string) string { return "`" + s + "`" }), ", ")
+	}
+
+	parts := make([]string, 0)
+	if len(f.Providers) > 0 {
+		parts = append(parts, "providers: "+codes(f.Providers))
+	}
+
+	if f.NSFW != nil {
+		if *f.NSFW {
+			parts = append(parts, "NSFW only")
+		} else {
+			parts = append(parts, "SFW only")
+		}
+	}
+
+	if len(f.Tags) > 0 {
+		parts = append(parts, "tags: "+codes(f.Tags))
+	}
+
+	if len(f.ExcludeTags) > 0 {
+		parts = append(parts, "excluded tags: "+codes(f.ExcludeTags))
+	}
+
+	if f.MinLikes > 0 {
+		parts = append(parts, fmt.Sprintf("%v+ likes", f.MinLikes))
+	}
+
+	return strings.Join(parts, " • ")
+}
+
 func favourites(b *bot.Bot) func(ctx *gumi.Ctx) error {
 	return func(ctx *gumi.Ctx) error {
+		if ctx.Args.Len() > 0 {
+			switch strings.ToLower(ctx.Args.Get(0).Raw) {
+			case "export":
+				return exportFavourites(b, ctx)
+			case "import":
+				return importFavourites(b, ctx)
+			}
+		}
+
 		tctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
 		defer cancel()
 
@@ -430,20 +700,20 @@ func favourites(b *bot.Bot) func(ctx *gumi.Ctx) error {
 			order        = store.Descending
 			sortBy       = store.ByTime
 			args         = strings.Fields(ctx.Args.Raw)
-			mode         = store.BookmarkFilterSafe
+			query        = store.BookmarkQuery{Filter: store.BookmarkFilterSafe}
 			filter       = store.ArtworkFilter{}
 		)
 
-		ch, err := ctx.Session.Channel(ctx.Event.ChannelID)
+		ch, err := b.SessionFor(ctx.Session).Channel(ctx.Event.ChannelID)
 		if err != nil {
 			return err
 		}
 
 		if ch.NSFW || ch.Type == discordgo.ChannelTypeDM {
-			mode = store.BookmarkFilterAll
+			query.Filter = store.BookmarkFilterAll
 		}
 
-		flagsMap, err := flags.FromArgs(args, flags.FlagTypeOrder, flags.FlagTypeMode)
+		flagsMap, err := flags.FromArgs(args, flags.FlagTypeOrder, flags.FlagTypeMode, flags.FlagTypeCollection, flags.FlagTypeTag)
 		if err != nil {
 			return err
 		}
@@ -453,16 +723,24 @@ func favourites(b *bot.Bot) func(ctx *gumi.Ctx) error {
 			case flags.FlagTypeOrder:
 				order = val.(store.Order)
 			case flags.FlagTypeMode:
-				mode = val.(store.BookmarkFilter)
+				query.Filter = val.(store.BookmarkFilter)
+			case flags.FlagTypeCollection:
+				query.Collection = val.(string)
+			case flags.FlagTypeTag:
+				query.Tags = val.([]string)
 			}
 		}
 
-		bookmarks, err := b.Store.ListBookmarks(tctx, ctx.Event.Author.ID, mode, order)
+		bookmarks, err := b.Store.ListBookmarks(tctx, ctx.Event.Author.ID, query, order)
 		if err != nil {
 			return err
 		}
 
 		if len(bookmarks) == 0 {
+			if query.Collection != "" || len(query.Tags) != 0 {
+				return messages.ErrNoMatchingFavourites()
+			}
+
 			return messages.ErrUserNoFavourites(ctx.Event.Author.ID)
 		}
 
@@ -493,22 +771,19 @@ func favourites(b *bot.Bot) func(ctx *gumi.Ctx) error {
 				break
 			}
 
-			page := artworkToEmbed(artwork, artwork.Images[0], ind, len(bookmarks))
-			page.Fields = append(page.Fields, &discordgo.MessageEmbedField{
-				Name:   "NSFW",
-				Value:  strconv.FormatBool(bookmark.NSFW),
-				Inline: true,
-			})
-
-			pages[ind] = page
+			pages[ind] = bookmarkToEmbed(artwork, bookmark, ind, len(bookmarks))
 		}
 
-		wg := dgoutils.NewWidget(ctx.Session, ctx.Event.Author.ID, pages)
+		wg := dgoutils.NewWidget(b.SessionFor(ctx.Session), ctx.Event.Author.ID, pages)
 		wg.WithCallback(func(wa dgoutils.WidgetAction, i int) error {
 			if wg.Pages[i] != nil {
 				return nil
 			}
 
+			//The widget outlives the command, every page gets its own context.
+			tctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
 			artwork, err := b.Store.Artwork(tctx, bookmarks[i].ArtworkID, "")
 			if errors.Is(err, store.ErrArtworkNotFound) {
 				eb := embeds.NewBuilder()
@@ -529,18 +804,441 @@ func favourites(b *bot.Bot) func(ctx *gumi.Ctx) error {
 				return err
 			}
 
-			page := artworkToEmbed(artwork, artwork.Images[0], i, len(bookmarks))
-			page.Fields = append(page.Fields, &discordgo.MessageEmbedField{
-				Name:   "NSFW",
-				Value:  strconv.FormatBool(bookmarks[i].NSFW),
-				Inline: true,
-			})
-
-			wg.Pages[i] = page
+			wg.Pages[i] = bookmarkToEmbed(artwork, bookmarks[i], i, len(bookmarks))
 			return nil
 		})
-		return wg.Start(ctx.Event.ChannelID)
+		return startWidget(b, ctx, wg)
+	}
+}
+
+func exportFavourites(b *bot.Bot, ctx *gumi.Ctx) error {
+	tctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	format := backup.FormatJSON
+	flagsMap, err := flags.FromArgs(argsFrom(ctx, 1), flags.FlagTypeFormat)
+	if err != nil {
+		return err
+	}
+
+	if val, ok := flagsMap[flags.FlagTypeFormat]; ok {
+		format = val.(string)
+	}
+
+	entries, err := backup.Export(tctx, b.Store, ctx.Event.Author.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		return messages.ErrUserNoFavourites(ctx.Event.Author.ID)
+	}
+
+	var buf bytes.Buffer
+	err = backup.Encode(&buf, format, &backup.File{
+		UserID:     ctx.Event.Author.ID,
+		ExportedAt: time.Now(),
+		Favourites: entries,
+	})
+
+	if errors.Is(err, backup.ErrUnknownFormat) {
+		return messages.ErrUnknownExportFormat(format)
+	}
+
+	if err != nil {
+		return err
 	}
+
+	if buf.Len() > maxExportSize {
+		return messages.ErrExportTooLarge()
+	}
+
+	dmSession := b.SessionForDM()
+	ch, err := dmSession.UserChannelCreate(ctx.Event.Author.ID)
+	if err != nil {
+		return messages.ErrExportDM(err)
+	}
+
+	_, err = dmSession.ChannelMessageSendComplex(ch.ID, &discordgo.MessageSend{
+		Content: fmt.Sprintf("Your Boe Tea favourites, %v in total.", len(entries)),
+		Files: []*discordgo.File{{
+			Name:        "favourites." + format,
+			ContentType: backup.ContentType(format),
+			Reader:      &buf,
+		}},
+	})
+
+	if err != nil {
+		return messages.ErrExportDM(err)
+	}
+
+	eb := embeds.NewBuilder()
+	eb.SuccessTemplate(messages.ExportSuccess(len(entries)))
+	return b.ReplyEmbed(ctx, eb.Finalize())
+}
+
+//importFavourites imports favourites from an attached file. If there's no attachment, artwork URLs are taken from arguments.
+func importFavourites(b *bot.Bot, ctx *gumi.Ctx) error {
+	tctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	data := []byte(strings.Join(argsFrom(ctx, 1), "\n"))
+	if len(ctx.Event.Attachments) > 0 {
+		var err error
+		data, err = downloadAttachment(tctx, ctx.Event.Attachments[0])
+		if err != nil {
+			return messages.ErrImportFile(err)
+		}
+	}
+
+	entries, err := backup.Parse(data)
+	if err != nil {
+		return messages.ErrImportFile(err)
+	}
+
+	if len(entries) == 0 {
+		return messages.ErrImportEmpty()
+	}
+
+	if len(entries) > maxImport {
+		return messages.ErrImportTooLarge(maxImport)
+	}
+
+	eb := embeds.NewBuilder()
+	eb.InfoTemplate(messages.ImportStarted(len(entries)))
+	b.ReplyEmbed(ctx, eb.Finalize())
+
+	res, err := backup.Import(tctx, b.Store, b.ArtworkProviders, importLimiter, ctx.Event.Author.ID, entries)
+	if err != nil {
+		return err
+	}
+
+	eb = embeds.NewBuilder()
+	eb.SuccessTemplate(messages.ImportSuccess(res.Added, res.Existing, len(res.Failed)))
+	if len(res.Failed) > 0 {
+		failed := res.Failed
+		if len(failed) > 10 {
+			failed = failed[:10]
+		}
+
+		eb.AddField("Not found", strings.Join(failed, "\n"))
+	}
+
+	return b.ReplyEmbed(ctx, eb.Finalize())
+}
+
+func downloadAttachment(ctx context.Context, attachment *discordgo.MessageAttachment) ([]byte, error) {
+	if attachment.Size > maxImportSize {
+		return nil, fmt.Errorf("file is larger than %v MB", maxImportSize>>20)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, attachment.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("attachment request returned %v", resp.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxImportSize))
+}
+
+//bookmarkToEmbed creates a favourites page with bookmark's collection and tags.
+func bookmarkToEmbed(artwork *store.Artwork, bookmark *store.Bookmark, ind, length int) *discordgo.MessageEmbed {
+	page := artworkToEmbed(artwork, artwork.Images[0], ind, length)
+	page.Fields = append(page.Fields, &discordgo.MessageEmbedField{
+		Name:   "NSFW",
+		Value:  strconv.FormatBool(bookmark.NSFW),
+		Inline: true,
+	})
+
+	if bookmark.Collection != "" {
+		page.Fields = append(page.Fields, &discordgo.MessageEmbedField{
+			Name:   "Collection",
+			Value:  bookmark.Collection,
+			Inline: true,
+		})
+	}
+
+	if len(bookmark.Tags) != 0 {
+		page.Fields = append(page.Fields, &discordgo.MessageEmbedField{
+			Name:   "Tags",
+			Value:  strings.Join(bookmark.Tags, " • "),
+			Inline: true,
+		})
+	}
+
+	return page
+}
+
+func collection(b *bot.Bot) func(ctx *gumi.Ctx) error {
+	return func(ctx *gumi.Ctx) error {
+		if ctx.Args.Len() == 0 {
+			return showCollections(b, ctx)
+		}
+
+		if ctx.Args.Len() < 2 {
+			return messages.ErrIncorrectCmd(ctx.Command)
+		}
+
+		var (
+			action = strings.ToLower(ctx.Args.Get(0).Raw)
+			name   = strings.ToLower(ctx.Args.Get(1).Raw)
+		)
+
+		switch action {
+		case "create", "new":
+			return createCollection(b, ctx, name)
+		case "delete", "del":
+			return deleteCollection(b, ctx, name)
+		case "add", "move":
+			if ctx.Args.Len() < 3 {
+				return messages.ErrIncorrectCmd(ctx.Command)
+			}
+
+			collections, err := b.Store.ListCollections(context.Background(), ctx.Event.Author.ID)
+			if err != nil {
+				return err
+			}
+
+			if !arrays.AnyFunc(collections, func(c *store.Collection) bool { return c.Name == name }) {
+				return messages.ErrCollectionNotFound(name)
+			}
+
+			return moveBookmarks(b, ctx, name, argsFrom(ctx, 2))
+		case "remove", "rm":
+			return moveBookmarks(b, ctx, "", argsFrom(ctx, 1))
+		default:
+			return messages.ErrIncorrectCmd(ctx.Command)
+		}
+	}
+}
+
+func showCollections(b *bot.Bot, ctx *gumi.Ctx) error {
+	collections, err := b.Store.ListCollections(context.Background(), ctx.Event.Author.ID)
+	if err != nil {
+		return err
+	}
+
+	eb := embeds.NewBuilder()
+	eb.InfoTemplate("")
+	eb.Title(fmt.Sprintf("%v's collections", ctx.Event.Author.Username))
+	if len(collections) == 0 {
+		eb.Description("You don't have any collections. Use `bt!collection create <name>` to create one.")
+		return b.ReplyEmbed(ctx, eb.Finalize())
+	}
+
+	bookmarks, err := b.Store.ListBookmarks(context.Background(), ctx.Event.Author.ID, store.BookmarkQuery{}, store.Descending)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	for _, bookmark := range bookmarks {
+		counts[bookmark.Collection]++
+	}
+
+	lines := make([]string, 0, len(collections))
+	for _, col := range collections {
+		lines = append(lines, fmt.Sprintf("`%v` • %v favourites", col.Name, counts[col.Name]))
+	}
+
+	eb.Description(strings.Join(lines, "\n"))
+	eb.Footer("Use bt!favourites collection:<name> to view a collection.", "")
+	return b.ReplyEmbed(ctx, eb.Finalize())
+}
+
+func createCollection(b *bot.Bot, ctx *gumi.Ctx, name string) error {
+	if !nameRegex.MatchString(name) {
+		return messages.ErrInvalidName(name)
+	}
+
+	collections, err := b.Store.ListCollections(context.Background(), ctx.Event.Author.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(collections) >= maxCollections {
+		return messages.ErrTooManyCollections(maxCollections)
+	}
+
+	created, err := b.Store.CreateCollection(context.Background(), &store.Collection{
+		UserID:    ctx.Event.Author.ID,
+		Name:      name,
+		CreatedAt: time.Now(),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if !created {
+		return messages.ErrCollectionExists(name)
+	}
+
+	eb := embeds.NewBuilder()
+	eb.SuccessTemplate(messages.CollectionCreated(name))
+	return b.ReplyEmbed(ctx, eb.Finalize())
+}
+
+func deleteCollection(b *bot.Bot, ctx *gumi.Ctx, name string) error {
+	deleted, err := b.Store.DeleteCollection(context.Background(), ctx.Event.Author.ID, name)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return messages.ErrCollectionNotFound(name)
+	}
+
+	eb := embeds.NewBuilder()
+	eb.SuccessTemplate(messages.CollectionDeleted(name))
+	return b.ReplyEmbed(ctx, eb.Finalize())
+}
+
+//moveBookmarks moves bookmarks to a collection. If name is empty, bookmarks are moved out of their collections.
+func moveBookmarks(b *bot.Bot, ctx *gumi.Ctx, name string, queries []string) error {
+	if len(queries) == 0 {
+		return messages.ErrIncorrectCmd(ctx.Command)
+	}
+
+	bookmarks := make([]*store.Bookmark, 0, len(queries))
+	for _, query := range queries {
+		bookmark, err := findBookmark(b, ctx.Event.Author.ID, query)
+		if err != nil {
+			return err
+		}
+
+		bookmarks = append(bookmarks, bookmark)
+	}
+
+	for _, bookmark := range bookmarks {
+		bookmark.Collection = name
+		if err := b.Store.UpdateBookmark(context.Background(), bookmark); err != nil {
+			return err
+		}
+	}
+
+	eb := embeds.NewBuilder()
+	eb.SuccessTemplate(messages.CollectionMoved(name, queries))
+	return b.ReplyEmbed(ctx, eb.Finalize())
+}
+
+func tag(b *bot.Bot) func(ctx *gumi.Ctx) error {
+	return func(ctx *gumi.Ctx) error {
+		if ctx.Args.Len() == 0 {
+			return messages.ErrIncorrectCmd(ctx.Command)
+		}
+
+		query := ctx.Args.Get(0).Raw
+		bookmark, err := findBookmark(b, ctx.Event.Author.ID, query)
+		if err != nil {
+			return err
+		}
+
+		tags := argsFrom(ctx, 1)
+		if len(tags) == 0 {
+			eb := embeds.NewBuilder()
+			eb.InfoTemplate(messages.BookmarkTagged(query, bookmark.Tags))
+			return b.ReplyEmbed(ctx, eb.Finalize())
+		}
+
+		for _, t := range tags {
+			t = strings.ToLower(t)
+			if !nameRegex.MatchString(t) {
+				return messages.ErrInvalidName(t)
+			}
+
+			if !arrays.Any(bookmark.Tags, t) {
+				bookmark.Tags = append(bookmark.Tags, t)
+			}
+		}
+
+		if len(bookmark.Tags) > maxTags {
+			return messages.ErrTooManyTags(maxTags)
+		}
+
+		return saveTags(b, ctx, query, bookmark)
+	}
+}
+
+func untag(b *bot.Bot) func(ctx *gumi.Ctx) error {
+	return func(ctx *gumi.Ctx) error {
+		if ctx.Args.Len() == 0 {
+			return messages.ErrIncorrectCmd(ctx.Command)
+		}
+
+		query := ctx.Args.Get(0).Raw
+		bookmark, err := findBookmark(b, ctx.Event.Author.ID, query)
+		if err != nil {
+			return err
+		}
+
+		remove := argsFrom(ctx, 1)
+		tags := make([]string, 0, len(bookmark.Tags))
+		if len(remove) != 0 {
+			for _, t := range bookmark.Tags {
+				if !arrays.AnyFunc(remove, func(s string) bool { return strings.EqualFold(s, t) }) {
+					tags = append(tags, t)
+				}
+			}
+		}
+
+		bookmark.Tags = tags
+		return saveTags(b, ctx, query, bookmark)
+	}
+}
+
+func saveTags(b *bot.Bot, ctx *gumi.Ctx, query string, bookmark *store.Bookmark) error {
+	sort.Strings(bookmark.Tags)
+	if err := b.Store.UpdateBookmark(context.Background(), bookmark); err != nil {
+		return err
+	}
+
+	eb := embeds.NewBuilder()
+	eb.SuccessTemplate(messages.BookmarkTagged(query, bookmark.Tags))
+	return b.ReplyEmbed(ctx, eb.Finalize())
+}
+
+//findBookmark finds user's bookmark by artwork ID or URL.
+func findBookmark(b *bot.Bot, userID, query string) (*store.Bookmark, error) {
+	id, err := strconv.Atoi(query)
+	if err != nil {
+		artwork, err := b.Store.Artwork(context.Background(), 0, query)
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, messages.ErrArtworkNotFound(query)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		id = artwork.ID
+	}
+
+	bookmark, err := b.Store.Bookmark(context.Background(), userID, id)
+	if errors.Is(err, store.ErrBookmarkNotFound) {
+		return nil, messages.ErrBookmarkNotFound(query)
+	}
+
+	return bookmark, err
+}
+
+//argsFrom returns raw arguments starting from an index.
+func argsFrom(ctx *gumi.Ctx, start int) []string {
+	args := make([]string, 0)
+	for i := start; i < ctx.Args.Len(); i++ {
+		args = append(args, ctx.Args.Get(i).Raw)
+	}
+
+	return args
 }
 
 func userset(b *bot.Bot) func(ctx *gumi.Ctx) error {
@@ -590,7 +1288,7 @@ func showUserProfile(b *bot.Bot, ctx *gumi.Ctx) error {
 		),
 	)
 
-	ctx.ReplyEmbed(eb.Finalize())
+	b.ReplyEmbed(ctx, eb.Finalize())
 	return nil
 }
 
@@ -651,7 +1349,7 @@ func changeUserSettings(b *bot.Bot, ctx *gumi.Ctx) error {
 	eb.AddField("Old setting", fmt.Sprintf("%v", oldSettingEmbed), true)
 	eb.AddField("New setting", fmt.Sprintf("%v", newSettingEmbed), true)
 
-	ctx.ReplyEmbed(eb.Finalize())
+	b.ReplyEmbed(ctx, eb.Finalize())
 	return nil
 }
 
@@ -704,7 +1402,7 @@ func unfav(b *bot.Bot) func(ctx *gumi.Ctx) error {
 			eb.Thumbnail(artwork.Images[0])
 		}
 
-		return ctx.ReplyEmbed(eb.Finalize())
+		return b.ReplyEmbed(ctx, eb.Finalize())
 	}
 }
 
@@ -726,7 +1424,12 @@ func artworkToEmbed(artwork *store.Artwork, image string, ind, length int) *disc
 
 	eb := embeds.NewBuilder()
 	eb.Title(title).URL(artwork.URL)
-	if len(artwork.Images) > 0 {
+
+	//Images of removed artworks are most likely gone too.
+	switch {
+	case artwork.Removed:
+		eb.Description("⚠ This artwork has been removed from the website. Saved details are shown below.")
+	case len(artwork.Images) > 0:
 		eb.Image(image)
 	}
 
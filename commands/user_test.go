@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/bot"
+	"github.com/VTGare/boe-tea-go/internal/dgoutils"
+	"github.com/VTGare/boe-tea-go/internal/dgoutils/dgofake"
+	"github.com/VTGare/boe-tea-go/store"
+	"github.com/VTGare/boe-tea-go/store/memory"
+	"github.com/VTGare/gumi"
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+)
+
+//contextStore fails like a network store when it's used with a cancelled context.
+type contextStore struct {
+	store.Store
+}
+
+func (s contextStore) Artwork(ctx context.Context, id int, url string) (*store.Artwork, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return s.Store.Artwork(ctx, id, url)
+}
+
+func TestFavourites_Pages(t *testing.T) {
+	ctx := context.Background()
+	st := memory.New()
+	if _, err := st.User(ctx, "author"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, title := range []string{"first", "second"} {
+		artwork, err := st.CreateArtwork(ctx, &store.Artwork{
+			Title:  title,
+			URL:    "https://example.com/artworks/" + title,
+			Images: []string{"https://example.com/" + title + ".png"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := st.AddBookmark(ctx, &store.Bookmark{
+			UserID:    "author",
+			ArtworkID: artwork.ID,
+			CreatedAt: time.Now().Add(time.Duration(i) * time.Minute),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := dgofake.New("bot")
+	s.AddChannel(&discordgo.Channel{ID: "dm", Type: discordgo.ChannelTypeDM})
+
+	b := &bot.Bot{
+		Log:     zap.NewNop().Sugar(),
+		Store:   contextStore{st},
+		Session: s,
+		Widgets: dgoutils.NewWidgetManager(time.Minute),
+	}
+	t.Cleanup(b.Widgets.Close)
+
+	err := favourites(b)(&gumi.Ctx{
+		Event: &discordgo.MessageCreate{Message: &discordgo.Message{
+			ID: "command", ChannelID: "dm", Author: &discordgo.User{ID: "author"},
+		}},
+		Args: gumi.ParseArguments(""),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := s.Sent("dm")
+	if len(sent) != 1 {
+		t.Fatalf("Sent() = %v, want the first page", sent)
+	}
+
+	msg, err := s.ChannelMessage("dm", sent[0].MessageID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//The second page is loaded after the command has returned.
+	err = b.Widgets.Handle(s, &discordgo.Interaction{
+		Type:    discordgo.InteractionMessageComponent,
+		Message: msg,
+		User:    &discordgo.User{ID: "author"},
+		Data: discordgo.MessageComponentInteractionData{
+			CustomID:      "widget:next",
+			ComponentType: discordgo.ButtonComponent,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	responses := s.Responses()
+	if len(responses) != 1 {
+		t.Fatalf("Responses() = %v, want the second page", responses)
+	}
+
+	resp := responses[0].Data.(*discordgo.InteractionResponse)
+	if resp.Type != discordgo.InteractionResponseUpdateMessage || resp.Data.Embeds[0].Title != "[2/2] first" {
+		t.Errorf("next page response = %+v, want the first favourite", resp.Data)
+	}
+}
@@ -0,0 +1,257 @@
+package feed
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/artworks"
+	"github.com/VTGare/boe-tea-go/bot"
+	"github.com/VTGare/boe-tea-go/internal/ratelimit"
+	"github.com/VTGare/boe-tea-go/post"
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+//maxPosts is the maximum number of artworks posted from a feed per check. Older unseen artworks are skipped,
+//e.g. after a long downtime, to avoid flooding a channel.
+const maxPosts = 5
+
+//maxAttempts is the number of checks an artwork is retried on. After that it's skipped, so it doesn't block newer artworks.
+const maxAttempts = 3
+
+//Options configures a Scheduler. Zero values are replaced with defaults.
+type Options struct {
+	//Interval is the time between checks of a subscription. Defaults to 15 minutes.
+	Interval time.Duration
+	//Jitter spreads subscription checks over time, every subscription is checked up to Jitter earlier. Defaults to Interval / 10.
+	Jitter time.Duration
+	//Tick is the time between polls for due subscriptions. Defaults to a minute or Interval, whichever is shorter.
+	Tick time.Duration
+	//RateLimit is the minimum time between requests to the same provider. Defaults to 5 seconds.
+	RateLimit time.Duration
+	//RateLimits override RateLimit by provider name.
+	RateLimits map[string]time.Duration
+	//Shards are IDs of shards the instance polls subscriptions for. Defaults to all shards.
+	Shards []int
+	//ShardCount is the total number of shards. Defaults to 1.
+	ShardCount int
+}
+
+//Scheduler polls followed artists' feeds and posts new artworks to subscribed channels.
+//Subscriptions are loaded from the store on every poll, so they survive restarts, and only subscriptions of guilds
+//served by the instance's shards are polled.
+type Scheduler struct {
+	bot  *bot.Bot
+	opts Options
+	now  func() time.Time
+
+	limiter *ratelimit.Limiter
+
+	mu sync.Mutex
+	//failures counts failed attempts to post an artwork by subscription and artwork ID.
+	failures map[string]int
+}
+
+func New(b *bot.Bot, opts Options) *Scheduler {
+	if opts.Interval <= 0 {
+		opts.Interval = 15 * time.Minute
+	}
+
+	if opts.Jitter < 0 {
+		opts.Jitter = 0
+	} else if opts.Jitter == 0 {
+		opts.Jitter = opts.Interval / 10
+	}
+
+	if opts.Tick <= 0 {
+		opts.Tick = time.Minute
+		if opts.Interval < opts.Tick {
+			opts.Tick = opts.Interval
+		}
+	}
+
+	if opts.RateLimit <= 0 {
+		opts.RateLimit = 5 * time.Second
+	}
+
+	if opts.ShardCount < 1 {
+		opts.ShardCount = 1
+	}
+
+	if len(opts.Shards) == 0 {
+		opts.Shards = make([]int, 0, opts.ShardCount)
+		for id := 0; id < opts.ShardCount; id++ {
+			opts.Shards = append(opts.Shards, id)
+		}
+	}
+
+	return &Scheduler{
+		bot:      b,
+		opts:     opts,
+		now:      time.Now,
+		limiter:  ratelimit.New(opts.RateLimit, opts.RateLimits),
+		failures: make(map[string]int),
+	}
+}
+
+//Run polls subscriptions every tick until the context is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.Tick)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Poll(ctx); err != nil {
+			s.bot.Log.Errorf("Failed to poll artist feeds: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+//Poll checks all due subscriptions once. Providers are polled concurrently, requests to the same provider are rate limited.
+func (s *Scheduler) Poll(ctx context.Context) error {
+	byProvider := make(map[string][]*store.Subscription)
+	for _, shardID := range s.opts.Shards {
+		subs, err := s.bot.Store.ShardSubscriptions(ctx, shardID, s.opts.ShardCount)
+		if err != nil {
+			return err
+		}
+
+		for _, sub := range subs {
+			if s.due(sub) {
+				byProvider[sub.Provider] = append(byProvider[sub.Provider], sub)
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	for name, subs := range byProvider {
+		provider, ok := s.bot.Provider(name)
+		if !ok {
+			continue
+		}
+
+		feed, ok := provider.(artworks.Feed)
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(provider artworks.Provider, feed artworks.Feed, subs []*store.Subscription) {
+			defer wg.Done()
+
+			for _, sub := range subs {
+				if err := s.wait(ctx, provider.Info().Name); err != nil {
+					return
+				}
+
+				if err := s.check(ctx, provider, feed, sub); err != nil {
+					s.bot.Log.With(
+						"guildID", sub.GuildID,
+						"channelID", sub.ChannelID,
+						"provider", sub.Provider,
+						"authorID", sub.AuthorID,
+					).Infof("Failed to check an artist feed: %v", err)
+				}
+			}
+		}(provider, feed, subs)
+	}
+
+	wg.Wait()
+	return nil
+}
+
+//due reports if a subscription should be checked. Every subscription has a stable offset within the jitter
+//so subscriptions created at the same time don't hit providers at the same time.
+func (s *Scheduler) due(sub *store.Subscription) bool {
+	next := sub.CheckedAt.Add(s.opts.Interval)
+	if s.opts.Jitter > 0 {
+		h := fnv.New64a()
+		h.Write([]byte(sub.ChannelID + ":" + sub.Provider + ":" + sub.AuthorID))
+		next = next.Add(-time.Duration(h.Sum64() % uint64(s.opts.Jitter)))
+	}
+
+	return !s.now().Before(next)
+}
+
+//wait blocks until a request to the provider is allowed by its rate limit.
+func (s *Scheduler) wait(ctx context.Context, provider string) error {
+	return s.limiter.Wait(ctx, provider)
+}
+
+//check posts unseen artworks of a subscription, oldest first, and saves them as seen.
+func (s *Scheduler) check(ctx context.Context, provider artworks.Provider, feed artworks.Feed, sub *store.Subscription) error {
+	urls, err := feed.RecentWorks(sub.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		fresh = make([]string, 0)
+		ids   = make([]string, 0)
+	)
+
+	for i := len(urls) - 1; i >= 0; i-- {
+		id, ok := provider.Match(urls[i])
+		if !ok || sub.HasSeen(id) {
+			continue
+		}
+
+		fresh = append(fresh, urls[i])
+		ids = append(ids, id)
+	}
+
+	if skipped := len(fresh) - maxPosts; skipped > 0 {
+		sub.MarkSeen(ids[:skipped]...)
+		fresh, ids = fresh[skipped:], ids[skipped:]
+	}
+
+	//Artworks are posted one by one to keep their order. If sending fails, the rest is retried on the next check.
+	var sendErr error
+	for i, url := range fresh {
+		_, err := post.NewFeed(s.bot, sub.GuildID, sub.ChannelID, url).SendFeed()
+		if err != nil && !s.giveUp(sub, ids[i]) {
+			sendErr = err
+			break
+		}
+
+		if err != nil {
+			s.bot.Log.With(
+				"guildID", sub.GuildID,
+				"channelID", sub.ChannelID,
+				"provider", sub.Provider,
+				"authorID", sub.AuthorID,
+			).Infof("Skipping an artwork %v after %v failed attempts: %v", url, maxAttempts, err)
+		}
+
+		sub.MarkSeen(ids[i])
+	}
+
+	sub.CheckedAt = s.now()
+	if err := s.bot.Store.UpdateSubscription(ctx, sub); err != nil {
+		return err
+	}
+
+	return sendErr
+}
+
+//giveUp counts a failed attempt to post an artwork and reports if the artwork should be skipped.
+//Attempts are counted in memory, they start over after a restart.
+func (s *Scheduler) giveUp(sub *store.Subscription, id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := sub.ChannelID + ":" + sub.Provider + ":" + sub.AuthorID + ":" + id
+	s.failures[key]++
+	if s.failures[key] < maxAttempts {
+		return false
+	}
+
+	delete(s.failures, key)
+	return true
+}
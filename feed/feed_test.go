@@ -0,0 +1,344 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/artworks"
+	"github.com/VTGare/boe-tea-go/bot"
+	"github.com/VTGare/boe-tea-go/internal/cache"
+	"github.com/VTGare/boe-tea-go/internal/config"
+	"github.com/VTGare/boe-tea-go/internal/dgoutils/dgofake"
+	"github.com/VTGare/boe-tea-go/repost"
+	"github.com/VTGare/boe-tea-go/stats"
+	"github.com/VTGare/boe-tea-go/store"
+	"github.com/VTGare/boe-tea-go/store/memory"
+	"github.com/VTGare/gumi"
+	"github.com/bwmarrin/discordgo"
+	goCache "github.com/patrickmn/go-cache"
+	"go.uber.org/zap"
+)
+
+const artworkPrefix = "https://example.com/artworks/"
+
+type testProvider struct {
+	mu    sync.Mutex
+	works []string
+	calls int
+}
+
+func (*testProvider) Match(url string) (string, bool) {
+	id := strings.TrimPrefix(url, artworkPrefix)
+	return id, id != url
+}
+
+//Find fails for artwork "broken", e.g. a deleted artwork that's still in the feed.
+func (*testProvider) Find(id string) (artworks.Artwork, error) {
+	if id == "broken" {
+		return nil, errors.New("artwork is unavailable")
+	}
+
+	return &testArtwork{url: artworkPrefix + id}, nil
+}
+
+func (*testProvider) Info() artworks.ProviderInfo {
+	return artworks.ProviderInfo{Name: "example", DisplayName: "Example"}
+}
+
+func (*testProvider) MatchAuthor(url string) (string, bool) {
+	id := strings.TrimPrefix(url, "https://example.com/users/")
+	return id, id != url
+}
+
+func (p *testProvider) RecentWorks(string) ([]string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.calls++
+	return p.works, nil
+}
+
+//publish adds artworks to the feed, newest first.
+func (p *testProvider) publish(ids ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, id := range ids {
+		p.works = append([]string{artworkPrefix + id}, p.works...)
+	}
+}
+
+type testArtwork struct {
+	url string
+}
+
+func (a *testArtwork) StoreArtwork() *store.Artwork {
+	return &store.Artwork{Title: "artwork", URL: a.url}
+}
+
+func (a *testArtwork) MessageSends(string, bool) ([]*discordgo.MessageSend, error) {
+	return []*discordgo.MessageSend{
+		{Embeds: []*discordgo.MessageEmbed{{Title: "artwork", URL: a.url}}},
+	}, nil
+}
+
+func (a *testArtwork) URL() string { return a.url }
+func (a *testArtwork) Len() int    { return 1 }
+
+//newTestScheduler creates a scheduler with a fake session and an in-memory store. The provider is disabled in guild "guild",
+//feeds must ignore provider toggles.
+func newTestScheduler(t *testing.T, opts Options) (*Scheduler, *testProvider, *dgofake.Session) {
+	t.Helper()
+
+	s := dgofake.New("bot")
+	s.AddGuild(&discordgo.Guild{ID: "guild", Members: []*discordgo.Member{{User: &discordgo.User{ID: "bot", Bot: true}}}})
+	s.AddChannel(&discordgo.Channel{ID: "art", GuildID: "guild"})
+
+	st := memory.New()
+	guild, err := st.CreateGuild(context.Background(), "guild")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	guild.Reactions = false
+	if _, err := st.UpdateGuild(context.Background(), guild); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := &testProvider{}
+	providers := []artworks.Provider{provider}
+	rd := repost.NewMemory()
+	t.Cleanup(func() { rd.Close() })
+
+	b := &bot.Bot{
+		Log:              zap.NewNop().Sugar(),
+		Config:           &config.Config{Repost: &config.Repost{}},
+		Stats:            stats.New(&gumi.Router{}, providers),
+		EmbedCache:       cache.NewEmbedCache(),
+		ArtworkCache:     goCache.New(0, 0),
+		ArtworkProviders: providers,
+		RepostDetector:   rd,
+		Store:            st,
+		Session:          s,
+	}
+
+	if opts.RateLimit == 0 {
+		opts.RateLimit = time.Millisecond
+	}
+
+	if opts.Jitter == 0 {
+		opts.Jitter = -1
+	}
+
+	return New(b, opts), provider, s
+}
+
+func subscribe(t *testing.T, s *Scheduler, sub *store.Subscription) {
+	t.Helper()
+
+	if _, err := s.bot.Store.AddSubscription(context.Background(), sub); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sentURLs(s *dgofake.Session, channelID string) []string {
+	urls := make([]string, 0)
+	for _, msg := range s.Sent(channelID) {
+		urls = append(urls, msg.Send.Embeds[0].URL)
+	}
+
+	return urls
+}
+
+func TestSchedulerPoll(t *testing.T) {
+	sched, provider, s := newTestScheduler(t, Options{Interval: time.Hour})
+
+	now := time.Now()
+	sched.now = func() time.Time { return now }
+
+	provider.publish("1")
+	subscribe(t, sched, &store.Subscription{
+		GuildID: "guild", ChannelID: "art", Provider: "example", AuthorID: "author", Seen: []string{"1"},
+	})
+
+	provider.publish("2", "3")
+	if err := sched.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{artworkPrefix + "2", artworkPrefix + "3"}
+	if got := sentURLs(s, "art"); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("Sent() = %v, want %v", got, want)
+	}
+
+	if send := s.Sent("art")[0].Send; send.Reference != nil {
+		t.Errorf("Sent()[0].Reference = %+v, want nil", send.Reference)
+	}
+
+	//Subscription was checked just now, it's not due.
+	provider.publish("4")
+	if err := sched.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if provider.calls != 1 {
+		t.Errorf("RecentWorks() called %v times, want 1", provider.calls)
+	}
+
+	now = now.Add(time.Hour)
+	if err := sched.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := sentURLs(s, "art"); len(got) != 3 || got[2] != artworkPrefix+"4" {
+		t.Errorf("Sent() = %v, want the 4th artwork posted", got)
+	}
+
+	subs, err := sched.bot.Store.GuildSubscriptions(context.Background(), "guild")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := subs[0]; strings.Join(got.Seen, " ") != "1 2 3 4" || !got.CheckedAt.Equal(now) {
+		t.Errorf("Subscription = %+v, want all artworks seen and checked now", got)
+	}
+}
+
+func TestSchedulerPoll_MaxPosts(t *testing.T) {
+	sched, provider, s := newTestScheduler(t, Options{})
+	subscribe(t, sched, &store.Subscription{GuildID: "guild", ChannelID: "art", Provider: "example", AuthorID: "author"})
+
+	provider.publish("1", "2", "3", "4", "5", "6", "7")
+	if err := sched.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := sentURLs(s, "art")
+	if len(got) != maxPosts || got[0] != artworkPrefix+"3" {
+		t.Fatalf("Sent() = %v, want the last %v artworks", got, maxPosts)
+	}
+
+	subs, err := sched.bot.Store.GuildSubscriptions(context.Background(), "guild")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !subs[0].HasSeen("1") || !subs[0].HasSeen("7") {
+		t.Errorf("Subscription.Seen = %v, want skipped artworks seen", subs[0].Seen)
+	}
+}
+
+func TestSchedulerPoll_FailedArtwork(t *testing.T) {
+	sched, provider, s := newTestScheduler(t, Options{Interval: time.Hour})
+
+	now := time.Now()
+	sched.now = func() time.Time { return now }
+
+	subscribe(t, sched, &store.Subscription{GuildID: "guild", ChannelID: "art", Provider: "example", AuthorID: "author"})
+	provider.publish("1", "broken", "3")
+
+	//The broken artwork blocks newer ones until it's out of attempts.
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err := sched.Poll(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		want := []string{artworkPrefix + "1"}
+		if attempt == maxAttempts {
+			want = append(want, artworkPrefix+"3")
+		}
+
+		if got := sentURLs(s, "art"); strings.Join(got, " ") != strings.Join(want, " ") {
+			t.Fatalf("attempt %v: Sent() = %v, want %v", attempt, got, want)
+		}
+
+		now = now.Add(time.Hour)
+	}
+
+	subs, err := sched.bot.Store.GuildSubscriptions(context.Background(), "guild")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := subs[0]; strings.Join(got.Seen, " ") != "1 broken 3" {
+		t.Errorf("Subscription.Seen = %v, want the broken artwork seen", got.Seen)
+	}
+}
+
+func TestSchedulerPoll_Shards(t *testing.T) {
+	sched, provider, s := newTestScheduler(t, Options{Shards: []int{1}, ShardCount: 2})
+	s.AddGuild(&discordgo.Guild{ID: "other"})
+	s.AddChannel(&discordgo.Channel{ID: "other-art", GuildID: "other"})
+	if _, err := sched.bot.Store.CreateGuild(context.Background(), "other"); err != nil {
+		t.Fatal(err)
+	}
+
+	subscribe(t, sched, &store.Subscription{GuildID: "guild", ChannelID: "art", Provider: "example", AuthorID: "author", ShardKey: 2})
+	subscribe(t, sched, &store.Subscription{GuildID: "other", ChannelID: "other-art", Provider: "example", AuthorID: "author", ShardKey: 3})
+
+	provider.publish("1")
+	if err := sched.Poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := sentURLs(s, "art"); len(got) != 0 {
+		t.Errorf("Sent(art) = %v, want nothing from another shard", got)
+	}
+
+	if got := sentURLs(s, "other-art"); len(got) != 1 {
+		t.Errorf("Sent(other-art) = %v, want 1 artwork", got)
+	}
+}
+
+func TestSchedulerDue(t *testing.T) {
+	sched, _, _ := newTestScheduler(t, Options{Interval: time.Hour, Jitter: 10 * time.Minute})
+
+	now := time.Now()
+	sched.now = func() time.Time { return now }
+
+	tests := []struct {
+		name      string
+		checkedAt time.Time
+		want      bool
+	}{
+		{name: "never checked", want: true},
+		{name: "checked just now", checkedAt: now, want: false},
+		{name: "checked within jitter", checkedAt: now.Add(-49 * time.Minute), want: false},
+		{name: "checked an interval ago", checkedAt: now.Add(-time.Hour), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &store.Subscription{ChannelID: "art", Provider: "example", AuthorID: "author", CheckedAt: tt.checkedAt}
+			if got := sched.due(sub); got != tt.want {
+				t.Errorf("due() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedulerWait(t *testing.T) {
+	sched, _, _ := newTestScheduler(t, Options{RateLimit: 20 * time.Millisecond})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := sched.wait(context.Background(), "example"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("3 requests took %v, want at least 40ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := sched.wait(ctx, "example"); err == nil {
+		t.Errorf("wait() with cancelled context = nil, want an error")
+	}
+}
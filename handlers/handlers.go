@@ -5,19 +5,20 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/VTGare/boe-tea-go/artworks"
 	"github.com/VTGare/boe-tea-go/bot"
 	"github.com/VTGare/boe-tea-go/internal/arrays"
 	"github.com/VTGare/boe-tea-go/internal/cache"
+	"github.com/VTGare/boe-tea-go/internal/dgoutils"
 	"github.com/VTGare/boe-tea-go/messages"
 	"github.com/VTGare/boe-tea-go/post"
 	"github.com/VTGare/boe-tea-go/store"
 	"github.com/VTGare/embeds"
 	"github.com/VTGare/gumi"
 	"github.com/bwmarrin/discordgo"
-	"go.mongodb.org/mongo-driver/mongo"
 	"mvdan.cc/xurls/v2"
 )
 
@@ -59,6 +60,16 @@ func NotCommand(b *bot.Bot) func(*gumi.Ctx) error {
 			urls := rx.FindAllString(ctx.Event.Content, -1)
 
 			if len(urls) == 0 {
+				//Messages without URLs are only checked for image reposts.
+				effective := guild.Effective(ctx.Event.ChannelID)
+				if effective.Repost == "disabled" || !effective.ImageRepost || !hasImages(ctx.Event.Attachments) {
+					return nil
+				}
+
+				if _, err := post.New(b, ctx).Send(); err != nil {
+					return err
+				}
+
 				return nil
 			}
 
@@ -72,7 +83,7 @@ func NotCommand(b *bot.Bot) func(*gumi.Ctx) error {
 			allSent = append(allSent, sent...)
 			user, err := b.Store.User(context.Background(), ctx.Event.Author.ID)
 			if err != nil {
-				if !errors.Is(err, mongo.ErrNoDocuments) {
+				if !errors.Is(err, store.ErrNotFound) {
 					return err
 				}
 			} else {
@@ -117,6 +128,21 @@ func RegisterHandlers(b *bot.Bot) {
 	b.AddHandler(OnReactionAdd(b))
 	b.AddHandler(OnReactionRemove(b))
 	b.AddHandler(OnMessageRemove(b))
+	b.AddHandler(OnInteractionCreate(b))
+}
+
+//OnInteractionCreate routes message component interactions to embed widgets.
+func OnInteractionCreate(b *bot.Bot) func(*discordgo.Session, *discordgo.InteractionCreate) {
+	return func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+		if err := b.Widgets.Handle(b.SessionFor(s), i.Interaction); err != nil {
+			b.Log.With(
+				"guild", i.GuildID,
+				"channel", i.ChannelID,
+				"interaction", i.ID,
+				"error", err,
+			).Error("failed to handle a widget interaction")
+		}
+	}
 }
 
 //OnReady logs that bot's up.
@@ -130,7 +156,7 @@ func OnReady(b *bot.Bot) func(*discordgo.Session, *discordgo.Ready) {
 func OnGuildCreate(b *bot.Bot) func(*discordgo.Session, *discordgo.GuildCreate) {
 	return func(s *discordgo.Session, g *discordgo.GuildCreate) {
 		_, err := b.Store.Guild(context.Background(), g.ID)
-		if errors.Is(err, mongo.ErrNoDocuments) {
+		if errors.Is(err, store.ErrNotFound) {
 			b.Log.Infof("Joined a guild. Name: %v. ID: %v", g.Name, g.ID)
 			_, err := b.Store.CreateGuild(context.Background(), g.ID)
 			if err != nil {
@@ -161,6 +187,7 @@ func OnGuildBanAdd(b *bot.Bot) func(*discordgo.Session, *discordgo.GuildBanAdd)
 
 func OnMessageRemove(b *bot.Bot) func(*discordgo.Session, *discordgo.MessageDelete) {
 	return func(s *discordgo.Session, m *discordgo.MessageDelete) {
+		sess := b.SessionFor(s)
 		msg, ok := b.EmbedCache.Get(
 			m.ChannelID,
 			m.ID,
@@ -195,7 +222,7 @@ func OnMessageRemove(b *bot.Bot) func(*discordgo.Session, *discordgo.MessageDele
 					child.ChannelID, child.MessageID,
 				)
 
-				err := s.ChannelMessageDelete(child.ChannelID, child.MessageID)
+				err := sess.ChannelMessageDelete(child.ChannelID, child.MessageID)
 				if err != nil {
 					b.Log.Warn("OnMessageRemove -> s.ChannelMessageDelete: ", err)
 				}
@@ -206,8 +233,10 @@ func OnMessageRemove(b *bot.Bot) func(*discordgo.Session, *discordgo.MessageDele
 
 func OnReactionAdd(b *bot.Bot) func(*discordgo.Session, *discordgo.MessageReactionAdd) {
 	return func(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+		sess := b.SessionFor(s)
+
 		//Do nothing for bot's own reactions
-		if r.UserID == s.State.User.ID {
+		if r.UserID == dgoutils.BotUserID(sess) {
 			return
 		}
 
@@ -237,7 +266,7 @@ func OnReactionAdd(b *bot.Bot) func(*discordgo.Session, *discordgo.MessageReacti
 			log.Infof("deleting a message from reaction event")
 			b.EmbedCache.Remove(r.ChannelID, r.MessageID)
 
-			err := s.ChannelMessageDelete(r.ChannelID, r.MessageID)
+			err := sess.ChannelMessageDelete(r.ChannelID, r.MessageID)
 			if err != nil {
 				return err
 			}
@@ -266,7 +295,7 @@ func OnReactionAdd(b *bot.Bot) func(*discordgo.Session, *discordgo.MessageReacti
 			}
 
 			for channelID, messageIDs := range childrenIDs {
-				if err := s.ChannelMessagesBulkDelete(channelID, messageIDs); err != nil {
+				if err := sess.ChannelMessagesBulkDelete(channelID, messageIDs); err != nil {
 					log.With("error", err).Warn("failed to delete children messages")
 				}
 			}
@@ -275,12 +304,12 @@ func OnReactionAdd(b *bot.Bot) func(*discordgo.Session, *discordgo.MessageReacti
 		}
 
 		crosspost := func() error {
-			msg, err := s.ChannelMessage(r.ChannelID, r.MessageID)
+			msg, err := sess.ChannelMessage(r.ChannelID, r.MessageID)
 			if err != nil {
 				return err
 			}
 
-			dgUser, err := s.User(r.UserID)
+			dgUser, err := sess.User(r.UserID)
 			if err != nil {
 				return err
 			}
@@ -336,12 +365,12 @@ func OnReactionAdd(b *bot.Bot) func(*discordgo.Session, *discordgo.MessageReacti
 		}
 
 		addFavourite := func() error {
-			msg, err := s.ChannelMessage(r.ChannelID, r.MessageID)
+			msg, err := sess.ChannelMessage(r.ChannelID, r.MessageID)
 			if err != nil {
 				return fmt.Errorf("failed to get a discord message: %w", err)
 			}
 
-			dgUser, err := s.User(r.UserID)
+			dgUser, err := sess.User(r.UserID)
 			if err != nil {
 				return fmt.Errorf("failed to get a discord user: %w", err)
 			}
@@ -430,7 +459,7 @@ func OnReactionAdd(b *bot.Bot) func(*discordgo.Session, *discordgo.MessageReacti
 				return nil
 			}
 
-			dmSession := b.ShardManager.SessionForDM()
+			dmSession := b.SessionForDM()
 			ch, err := dmSession.UserChannelCreate(user.ID)
 			if err != nil {
 				return fmt.Errorf("failed to create private channel: %w", err)
@@ -471,8 +500,10 @@ func OnReactionAdd(b *bot.Bot) func(*discordgo.Session, *discordgo.MessageReacti
 
 func OnReactionRemove(b *bot.Bot) func(*discordgo.Session, *discordgo.MessageReactionRemove) {
 	return func(s *discordgo.Session, r *discordgo.MessageReactionRemove) {
+		sess := b.SessionFor(s)
+
 		//Do nothing for bot's own reactions
-		if r.UserID == s.State.User.ID {
+		if r.UserID == dgoutils.BotUserID(sess) {
 			return
 		}
 
@@ -496,13 +527,13 @@ func OnReactionRemove(b *bot.Bot) func(*discordgo.Session, *discordgo.MessageRea
 			return
 		}
 
-		msg, err := s.ChannelMessage(r.ChannelID, r.MessageID)
+		msg, err := sess.ChannelMessage(r.ChannelID, r.MessageID)
 		if err != nil {
 			log.With("error", err).Error("failed to get discord message")
 			return
 		}
 
-		dgUser, err := s.User(r.UserID)
+		dgUser, err := sess.User(r.UserID)
 		if err != nil {
 			log.With("error", err).Error("failed to get discord user")
 			return
@@ -548,7 +579,7 @@ func OnReactionRemove(b *bot.Bot) func(*discordgo.Session, *discordgo.MessageRea
 
 		artworkDB, err := b.Store.Artwork(ctx, 0, artwork.URL())
 		if err != nil {
-			if !errors.Is(err, mongo.ErrNoDocuments) {
+			if !errors.Is(err, store.ErrNotFound) {
 				log.With("error", err).Error("failed to find an artwork")
 			}
 
@@ -576,7 +607,7 @@ func OnReactionRemove(b *bot.Bot) func(*discordgo.Session, *discordgo.MessageRea
 			return
 		}
 
-		dmSession := b.ShardManager.SessionForDM()
+		dmSession := b.SessionForDM()
 		ch, err := dmSession.UserChannelCreate(user.ID)
 		if err != nil {
 			log.With("error", err).Error("failed to create private channel")
@@ -625,6 +656,7 @@ func OnError(b *bot.Bot) func(*gumi.Ctx, error) {
 		default:
 			if ctx.Command != nil {
 				b.Log.Errorf("An error occured. Command: %v. Arguments: [%v]. Error: %v", ctx.Command.Name, ctx.Args.Raw, err)
+				b.Stats.IncrementCommandError(ctx.Command.Name)
 			} else {
 				b.Log.Errorf("An error occured. Error: %v", err)
 			}
@@ -633,7 +665,7 @@ func OnError(b *bot.Bot) func(*gumi.Ctx, error) {
 				"If error persists, please let the developer know about it with `bt!feedback` command.")
 		}
 
-		ctx.ReplyEmbed(eb.Finalize())
+		b.ReplyEmbed(ctx, eb.Finalize())
 	}
 }
 
@@ -648,7 +680,7 @@ func OnRateLimit(b *bot.Bot) func(*gumi.Ctx) error {
 		eb := embeds.NewBuilder()
 		eb.FailureTemplate(messages.RateLimit(duration))
 
-		return ctx.ReplyEmbed(eb.Finalize())
+		return b.ReplyEmbed(ctx, eb.Finalize())
 	}
 }
 
@@ -658,7 +690,7 @@ func OnNoPerms(b *bot.Bot) func(ctx *gumi.Ctx) error {
 		eb := embeds.NewBuilder()
 		eb.FailureTemplate(messages.NoPerms())
 
-		return ctx.ReplyEmbed(eb.Finalize())
+		return b.ReplyEmbed(ctx, eb.Finalize())
 	}
 }
 
@@ -669,16 +701,65 @@ func OnNSFW(b *bot.Bot) func(ctx *gumi.Ctx) error {
 
 		eb.FailureTemplate(messages.NSFWCommand(ctx.Command.Name))
 
-		return ctx.ReplyEmbed(eb.Finalize())
+		return b.ReplyEmbed(ctx, eb.Finalize())
 	}
 }
 
-//OnExecute logs every executed command.
+//OnExecute enforces command permissions and logs every executed command.
 func OnExecute(b *bot.Bot) func(ctx *gumi.Ctx) error {
 	return func(ctx *gumi.Ctx) error {
+		if ctx.Event.GuildID != "" {
+			if err := checkPermissions(b, ctx); err != nil {
+				return err
+			}
+		}
+
 		b.Log.Infof("Executing command [%v]. Arguments: [%v]. Guild ID: %v, channel ID: %v", ctx.Command.Name, ctx.Args.Raw, ctx.Event.GuildID, ctx.Event.ChannelID)
 
 		b.Stats.IncrementCommand(ctx.Command.Name)
 		return nil
 	}
 }
+
+//checkPermissions checks if a command is denied in the channel and if command's author has default permissions of the command
+//or a role granted with bt!perms. Server managers can run denied commands, so they can't lock themselves out of bt!perms.
+func checkPermissions(b *bot.Bot, ctx *gumi.Ctx) error {
+	guild, err := b.Store.Guild(context.Background(), ctx.Event.GuildID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+
+	if guild != nil && guild.Permissions.Denied(ctx.Event.ChannelID, ctx.Command.Name, ctx.Command.Group) {
+		manager, err := dgoutils.MemberHasPermission(b.SessionFor(ctx.Session), ctx.Event.GuildID, ctx.Event.Author.ID, bot.ManagePermissions)
+		if err != nil {
+			return err
+		}
+
+		if !manager {
+			return messages.ErrCommandDenied(ctx.Command.Name, ctx.Event.ChannelID)
+		}
+	}
+
+	permission, ok := b.CommandPermissions[ctx.Command.Name]
+	if !ok {
+		return nil
+	}
+
+	allowed, err := b.HasPermission(ctx, permission)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return messages.ErrNoPerms()
+	}
+
+	return nil
+}
+
+//hasImages reports if any of message's attachments is an image.
+func hasImages(attachments []*discordgo.MessageAttachment) bool {
+	return arrays.AnyFunc(attachments, func(a *discordgo.MessageAttachment) bool {
+		return strings.HasPrefix(a.ContentType, "image/")
+	})
+}
@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/VTGare/boe-tea-go/artworks"
+	"github.com/VTGare/boe-tea-go/bot"
+	"github.com/VTGare/boe-tea-go/internal/cache"
+	"github.com/VTGare/boe-tea-go/internal/config"
+	"github.com/VTGare/boe-tea-go/internal/dgoutils/dgofake"
+	"github.com/VTGare/boe-tea-go/messages"
+	"github.com/VTGare/boe-tea-go/repost"
+	"github.com/VTGare/boe-tea-go/stats"
+	"github.com/VTGare/boe-tea-go/store"
+	"github.com/VTGare/boe-tea-go/store/memory"
+	"github.com/VTGare/gumi"
+	"github.com/bwmarrin/discordgo"
+	goCache "github.com/patrickmn/go-cache"
+	"go.uber.org/zap"
+)
+
+const artworkURL = "https://example.com/artworks/1"
+
+var author = &discordgo.User{ID: "author", Username: "author"}
+
+type testProvider struct{}
+
+func (testProvider) Match(url string) (string, bool) {
+	id := strings.TrimPrefix(url, "https://example.com/artworks/")
+	return id, id != url
+}
+
+func (testProvider) Find(id string) (artworks.Artwork, error) {
+	return &testArtwork{url: "https://example.com/artworks/" + id}, nil
+}
+
+func (testProvider) Info() artworks.ProviderInfo {
+	return artworks.ProviderInfo{Name: "example", DisplayName: "Example", DefaultEnabled: true}
+}
+
+type testArtwork struct {
+	url string
+}
+
+func (a *testArtwork) StoreArtwork() *store.Artwork {
+	return &store.Artwork{Title: "artwork", URL: a.url, Images: []string{a.url + ".png"}}
+}
+
+func (a *testArtwork) MessageSends(footer string, tags bool) ([]*discordgo.MessageSend, error) {
+	return []*discordgo.MessageSend{
+		{Embeds: []*discordgo.MessageEmbed{{Title: "artwork", URL: a.url}}},
+	}, nil
+}
+
+func (a *testArtwork) URL() string { return a.url }
+func (a *testArtwork) Len() int    { return 1 }
+
+//newTestBot creates a bot with a fake session and an in-memory store. Guild "guild" has an art channel "art", the bot can manage messages there.
+//Guild "other" has channel "crosspost", the author is a member of both guilds.
+func newTestBot(t *testing.T) (*bot.Bot, *dgofake.Session) {
+	t.Helper()
+
+	s := dgofake.New("bot")
+	s.AddGuild(&discordgo.Guild{
+		ID:      "guild",
+		OwnerID: "owner",
+		Roles:   []*discordgo.Role{{ID: "mod", Permissions: discordgo.PermissionManageMessages}},
+		Members: []*discordgo.Member{
+			{User: &discordgo.User{ID: "bot", Bot: true}, Roles: []string{"mod"}},
+			{User: author},
+		},
+	})
+
+	s.AddGuild(&discordgo.Guild{ID: "other", Members: []*discordgo.Member{{User: author}}})
+	s.AddChannel(&discordgo.Channel{ID: "art", GuildID: "guild"})
+	s.AddChannel(&discordgo.Channel{ID: "crosspost", GuildID: "other"})
+
+	st := memory.New()
+	for _, id := range []string{"guild", "other"} {
+		if _, err := st.CreateGuild(context.Background(), id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	providers := []artworks.Provider{testProvider{}}
+	rd := repost.NewMemory()
+	t.Cleanup(func() { rd.Close() })
+
+	return &bot.Bot{
+		Log:              zap.NewNop().Sugar(),
+		Config:           &config.Config{Repost: &config.Repost{}},
+		Stats:            stats.New(&gumi.Router{}, providers),
+		EmbedCache:       cache.NewEmbedCache(),
+		ArtworkCache:     goCache.New(0, 0),
+		ArtworkProviders: providers,
+		RepostDetector:   rd,
+		Store:            st,
+		Session:          s,
+	}, s
+}
+
+//postMessage adds a message to the fake session and runs NotCommand handler on it.
+func postMessage(t *testing.T, b *bot.Bot, s *dgofake.Session, id, content string) {
+	t.Helper()
+
+	msg := &discordgo.Message{ID: id, ChannelID: "art", GuildID: "guild", Content: content, Author: author}
+	s.AddMessage(msg)
+
+	if err := NotCommand(b)(&gumi.Ctx{Event: &discordgo.MessageCreate{Message: msg}}); err != nil {
+		t.Fatalf("NotCommand() error = %v", err)
+	}
+}
+
+func addCrosspostGroup(t *testing.T, b *bot.Bot) {
+	t.Helper()
+
+	ctx := context.Background()
+	if _, err := b.Store.User(ctx, author.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := b.Store.CreateCrosspostGroup(ctx, author.ID, &store.Group{Name: "group", Parent: "art", Children: []string{"crosspost"}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNotCommand(t *testing.T) {
+	b, s := newTestBot(t)
+	postMessage(t, b, s, "message", "look at this "+artworkURL)
+
+	sent := s.Sent("art")
+	if len(sent) != 1 {
+		t.Fatalf("Sent() = %v messages, want 1", len(sent))
+	}
+
+	send := sent[0].Send
+	if send.Embeds[0].URL != artworkURL || send.Reference == nil || send.Reference.MessageID != "message" {
+		t.Errorf("sent message = %+v, want an artwork embed replying to the message", send)
+	}
+
+	if reactions := s.Reactions(); len(reactions) != 2 || reactions[0].MessageID != sent[0].MessageID {
+		t.Errorf("Reactions() = %+v, want 2 reactions on the sent message", reactions)
+	}
+
+	post, ok := b.EmbedCache.Get("art", "message")
+	if !ok || !post.Parent || post.AuthorID != author.ID || len(post.Children) != 1 {
+		t.Fatalf("EmbedCache.Get() = %+v, want a parent post with 1 child", post)
+	}
+
+	if _, ok := b.EmbedCache.Get("art", sent[0].MessageID); !ok {
+		t.Errorf("EmbedCache.Get() didn't find the sent message")
+	}
+}
+
+func TestNotCommand_ChannelOverrides(t *testing.T) {
+	b, s := newTestBot(t)
+
+	guild, err := b.Store.Guild(context.Background(), "guild")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reactions := false
+	guild.SetChannel("art", &store.ChannelSettings{Reactions: &reactions})
+	if _, err := b.Store.UpdateGuild(context.Background(), guild); err != nil {
+		t.Fatal(err)
+	}
+
+	postMessage(t, b, s, "message", artworkURL)
+	if sent := s.Sent("art"); len(sent) != 1 {
+		t.Fatalf("Sent() = %v messages, want 1", len(sent))
+	}
+
+	if reactions := s.Reactions(); len(reactions) != 0 {
+		t.Errorf("Reactions() = %+v, want no reactions in a channel with disabled reactions", reactions)
+	}
+
+	guild.SetChannel("art", &store.ChannelSettings{Providers: map[string]bool{"example": false}})
+	if _, err := b.Store.UpdateGuild(context.Background(), guild); err != nil {
+		t.Fatal(err)
+	}
+
+	postMessage(t, b, s, "message2", "https://example.com/artworks/2")
+	if sent := s.Sent("art"); len(sent) != 1 {
+		t.Errorf("Sent() = %v messages, want no new messages in a channel with disabled provider", len(sent))
+	}
+}
+
+func TestNotCommand_Crosspost(t *testing.T) {
+	b, s := newTestBot(t)
+	addCrosspostGroup(t, b)
+
+	postMessage(t, b, s, "message", artworkURL)
+
+	sent := s.Sent("crosspost")
+	if len(sent) != 1 {
+		t.Fatalf("Sent(crosspost) = %v messages, want 1", len(sent))
+	}
+
+	send := sent[0].Send
+	if !strings.HasPrefix(send.Content, artworkURL) || send.Embeds[0].Author == nil {
+		t.Errorf("crossposted message = %+v, want artwork URL and crosspost author", send)
+	}
+
+	if post, ok := b.EmbedCache.Get("art", "message"); !ok || len(post.Children) != 2 {
+		t.Fatalf("EmbedCache.Get() = %+v, want a parent post with 2 children", post)
+	}
+}
+
+func TestNotCommand_StrictRepost(t *testing.T) {
+	b, s := newTestBot(t)
+
+	guild, _ := b.Store.Guild(context.Background(), "guild")
+	guild.Repost = "strict"
+	if _, err := b.Store.UpdateGuild(context.Background(), guild); err != nil {
+		t.Fatal(err)
+	}
+
+	postMessage(t, b, s, "first", artworkURL)
+	postMessage(t, b, s, "second", artworkURL)
+
+	deleted := s.Deleted()
+	if len(deleted) != 1 || deleted[0].MessageID != "second" {
+		t.Fatalf("Deleted() = %+v, want the repost to be deleted", deleted)
+	}
+
+	sent := s.Sent("art")
+	if len(sent) != 2 || sent[1].Send.Embeds[0].URL != "" {
+		t.Fatalf("Sent() = %+v, want an artwork and a repost notice", sent)
+	}
+}
+
+func TestOnMessageRemove(t *testing.T) {
+	b, s := newTestBot(t)
+	addCrosspostGroup(t, b)
+
+	postMessage(t, b, s, "message", artworkURL)
+	OnMessageRemove(b)(nil, &discordgo.MessageDelete{Message: &discordgo.Message{ID: "message", ChannelID: "art"}})
+
+	deleted := s.Deleted()
+	if len(deleted) != 2 {
+		t.Fatalf("Deleted() = %+v, want 2 children deleted", deleted)
+	}
+
+	for _, msg := range s.Sent("") {
+		if _, ok := b.EmbedCache.Get(msg.ChannelID, msg.MessageID); ok {
+			t.Errorf("EmbedCache still has a child %v", msg.MessageID)
+		}
+	}
+}
+
+func TestOnReactionAdd_Delete(t *testing.T) {
+	react := func(b *bot.Bot, userID, channelID, messageID string) {
+		OnReactionAdd(b)(nil, &discordgo.MessageReactionAdd{
+			MessageReaction: &discordgo.MessageReaction{
+				UserID:    userID,
+				ChannelID: channelID,
+				MessageID: messageID,
+				GuildID:   "guild",
+				Emoji:     discordgo.Emoji{Name: "❌"},
+			},
+		})
+	}
+
+	t.Run("child", func(t *testing.T) {
+		b, s := newTestBot(t)
+		postMessage(t, b, s, "message", artworkURL)
+		child := s.Sent("art")[0]
+
+		//Only the author can delete an embed.
+		react(b, "stranger", "art", child.MessageID)
+		if deleted := s.Deleted(); len(deleted) != 0 {
+			t.Fatalf("Deleted() = %+v, want nothing deleted by a stranger", deleted)
+		}
+
+		react(b, author.ID, "art", child.MessageID)
+		if deleted := s.Deleted(); len(deleted) != 1 || deleted[0].MessageID != child.MessageID {
+			t.Fatalf("Deleted() = %+v, want the embed deleted", deleted)
+		}
+	})
+
+	t.Run("parent", func(t *testing.T) {
+		b, s := newTestBot(t)
+		addCrosspostGroup(t, b)
+		postMessage(t, b, s, "message", artworkURL)
+
+		react(b, author.ID, "art", "message")
+		if deleted := s.Deleted(); len(deleted) != 3 {
+			t.Fatalf("Deleted() = %+v, want the message and 2 children deleted", deleted)
+		}
+	})
+}
+
+func TestOnExecute_Permissions(t *testing.T) {
+	b, s := newTestBot(t)
+	b.CommandPermissions = map[string]int64{"addchannel": bot.ManagePermissions}
+
+	curator := &discordgo.User{ID: "curator"}
+	s.AddMember("guild", &discordgo.Member{User: curator, Roles: []string{"curator"}})
+
+	execute := func(user *discordgo.User, command string) error {
+		return OnExecute(b)(&gumi.Ctx{
+			Event: &discordgo.MessageCreate{Message: &discordgo.Message{
+				ID: "command", ChannelID: "art", GuildID: "guild", Author: user,
+			}},
+			Args:    gumi.ParseArguments(""),
+			Command: &gumi.Command{Name: command, Group: "general"},
+		})
+	}
+
+	updateGuild := func(fn func(guild *store.Guild)) {
+		guild, err := b.Store.Guild(context.Background(), "guild")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fn(guild)
+		if _, err := b.Store.UpdateGuild(context.Background(), guild); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var usrErr *messages.UserErr
+	if err := execute(curator, "addchannel"); !errors.As(err, &usrErr) {
+		t.Errorf("OnExecute(addchannel) error = %v, want no permissions", err)
+	}
+
+	if err := execute(curator, "about"); err != nil {
+		t.Errorf("OnExecute(about) error = %v, want nil", err)
+	}
+
+	updateGuild(func(guild *store.Guild) { guild.Permissions.Grant("curator", "addchannel") })
+	if err := execute(curator, "addchannel"); err != nil {
+		t.Errorf("OnExecute(addchannel) error = %v, want granted command", err)
+	}
+
+	//Granting a group grants its commands except manager-only ones.
+	b.CommandPermissions["perms"] = bot.ManagePermissions
+	b.CommandPermissions["rmchannel"] = bot.ManagePermissions
+	updateGuild(func(guild *store.Guild) { guild.Permissions.Grant("curator", "general") })
+	if err := execute(curator, "rmchannel"); err != nil {
+		t.Errorf("OnExecute(rmchannel) error = %v, want command granted by group", err)
+	}
+
+	if err := execute(curator, "perms"); err == nil || err.Error() != messages.ErrNoPerms().Error() {
+		t.Errorf("OnExecute(perms) error = %v, want no permissions", err)
+	}
+
+	updateGuild(func(guild *store.Guild) { guild.Permissions.Grant("curator", "perms") })
+	if err := execute(curator, "perms"); err == nil || err.Error() != messages.ErrNoPerms().Error() {
+		t.Errorf("OnExecute(perms) error = %v, want no permissions despite a direct grant", err)
+	}
+
+	updateGuild(func(guild *store.Guild) { guild.Permissions.Deny("art", "general") })
+	if err := execute(curator, "about"); !errors.As(err, &usrErr) {
+		t.Errorf("OnExecute(about) error = %v, want denied command", err)
+	}
+
+	//Guild owner is a server manager and isn't affected by denied commands.
+	owner := &discordgo.User{ID: "owner"}
+	s.AddMember("guild", &discordgo.Member{User: owner})
+	if err := execute(owner, "about"); err != nil {
+		t.Errorf("OnExecute(about) by owner error = %v, want nil", err)
+	}
+}
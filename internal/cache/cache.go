@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/ReneKroon/ttlcache"
+	"github.com/VTGare/boe-tea-go/internal/dgoutils"
 )
 
 //Cache represents a thread-safe map
@@ -131,3 +132,34 @@ func NewEmbedCache() *EmbedCache {
 
 	return &EmbedCache{cache}
 }
+
+//InteractionCache stores deferred application command interactions until they're responded to.
+type InteractionCache struct {
+	cache *ttlcache.Cache
+}
+
+func (ic *InteractionCache) Get(interactionID string) (*dgoutils.Interaction, bool) {
+	if i, ok := ic.cache.Get(interactionID); ok {
+		if i, ok := i.(*dgoutils.Interaction); ok {
+			return i, true
+		}
+	}
+
+	return nil, false
+}
+
+func (ic *InteractionCache) Set(i *dgoutils.Interaction) {
+	ic.cache.Set(i.ID, i)
+}
+
+func (ic *InteractionCache) Remove(interactionID string) bool {
+	return ic.cache.Remove(interactionID)
+}
+
+//NewInteractionCache creates a new interaction cache. Interaction tokens are only valid for 15 minutes.
+func NewInteractionCache() *InteractionCache {
+	cache := ttlcache.NewCache()
+	cache.SetTTL(15 * time.Minute)
+
+	return &InteractionCache{cache}
+}
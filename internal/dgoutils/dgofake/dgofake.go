@@ -0,0 +1,376 @@
+//Package dgofake implements a fake Discord session that records sent messages, reactions, deletions and interaction responses.
+package dgofake
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/VTGare/boe-tea-go/internal/dgoutils"
+	"github.com/bwmarrin/discordgo"
+)
+
+//ErrNotFound is returned when a requested guild, channel, member, user or message wasn't added to the fake.
+var ErrNotFound = errors.New("not found")
+
+var _ dgoutils.Session = (*Session)(nil)
+
+//Session is a fake dgoutils.Session. It's safe for concurrent use.
+type Session struct {
+	mu sync.Mutex
+
+	me       *discordgo.User
+	lastID   int
+	guilds   map[string]*discordgo.Guild
+	channels map[string]*discordgo.Channel
+	members  map[string]*discordgo.Member
+	users    map[string]*discordgo.User
+	messages map[string]*discordgo.Message
+
+	sent      []*Message
+	reactions []*Reaction
+	deleted   []*Deleted
+	responses []*Response
+}
+
+//Message is a message sent through the fake.
+type Message struct {
+	ChannelID string
+	MessageID string
+	Send      *discordgo.MessageSend
+}
+
+//Reaction is a reaction added by the bot.
+type Reaction struct {
+	ChannelID string
+	MessageID string
+	Emoji     string
+}
+
+//Deleted is a deleted message.
+type Deleted struct {
+	ChannelID string
+	MessageID string
+}
+
+//Response is an interaction response. Type is "respond", "edit", "followup" or "delete".
+type Response struct {
+	Type        string
+	Interaction *discordgo.Interaction
+	Data        interface{}
+}
+
+//New creates a fake session of a bot user with ID me.
+func New(me string) *Session {
+	s := &Session{
+		me:       &discordgo.User{ID: me, Username: "Boe Tea", Bot: true},
+		guilds:   make(map[string]*discordgo.Guild),
+		channels: make(map[string]*discordgo.Channel),
+		members:  make(map[string]*discordgo.Member),
+		users:    make(map[string]*discordgo.User),
+		messages: make(map[string]*discordgo.Message),
+	}
+
+	s.users[me] = s.me
+	return s
+}
+
+//AddGuild adds a guild. Roles and members of the guild are added too.
+func (s *Session) AddGuild(guild *discordgo.Guild) {
+	s.mu.Lock()
+	s.guilds[guild.ID] = guild
+	s.mu.Unlock()
+
+	for _, member := range guild.Members {
+		s.AddMember(guild.ID, member)
+	}
+}
+
+//AddChannel adds a guild channel.
+func (s *Session) AddChannel(channel *discordgo.Channel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.channels[channel.ID] = channel
+}
+
+//AddMember adds a guild member and its user.
+func (s *Session) AddMember(guildID string, member *discordgo.Member) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	member.GuildID = guildID
+	s.members[guildID+":"+member.User.ID] = member
+	s.users[member.User.ID] = member.User
+}
+
+//AddUser adds a user that isn't a member of any guild.
+func (s *Session) AddUser(user *discordgo.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users[user.ID] = user
+}
+
+//AddMessage adds a message, e.g. a message that triggered a handler.
+func (s *Session) AddMessage(msg *discordgo.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages[msg.ChannelID+":"+msg.ID] = msg
+}
+
+//Sent returns messages sent to a channel. If channelID is empty, messages sent to all channels are returned.
+func (s *Session) Sent(channelID string) []*Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sent := make([]*Message, 0)
+	for _, msg := range s.sent {
+		if channelID == "" || msg.ChannelID == channelID {
+			sent = append(sent, msg)
+		}
+	}
+
+	return sent
+}
+
+//Reactions returns reactions added by the bot.
+func (s *Session) Reactions() []*Reaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*Reaction{}, s.reactions...)
+}
+
+//Deleted returns deleted messages.
+func (s *Session) Deleted() []*Deleted {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*Deleted{}, s.deleted...)
+}
+
+//Responses returns interaction responses.
+func (s *Session) Responses() []*Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*Response{}, s.responses...)
+}
+
+func (s *Session) Channel(channelID string) (*discordgo.Channel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ch, ok := s.channels[channelID]; ok {
+		return ch, nil
+	}
+
+	return nil, fmt.Errorf("channel %v %w", channelID, ErrNotFound)
+}
+
+func (s *Session) ChannelMessage(channelID, messageID string) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if msg, ok := s.messages[channelID+":"+messageID]; ok {
+		return msg, nil
+	}
+
+	return nil, fmt.Errorf("message %v %w", messageID, ErrNotFound)
+}
+
+func (s *Session) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.send(channelID, data), nil
+}
+
+func (s *Session) ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed) (*discordgo.Message, error) {
+	return s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}})
+}
+
+func (s *Session) ChannelMessageEditComplex(m *discordgo.MessageEdit) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg, ok := s.messages[m.Channel+":"+m.ID]
+	if !ok {
+		return nil, fmt.Errorf("message %v %w", m.ID, ErrNotFound)
+	}
+
+	if m.Content != nil {
+		msg.Content = *m.Content
+	}
+
+	if m.Embeds != nil {
+		msg.Embeds = m.Embeds
+	}
+
+	if m.Components != nil {
+		msg.Components = m.Components
+	}
+
+	return msg, nil
+}
+
+func (s *Session) ChannelMessageDelete(channelID, messageID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.delete(channelID, messageID)
+}
+
+func (s *Session) ChannelMessagesBulkDelete(channelID string, messages []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, messageID := range messages {
+		if err := s.delete(channelID, messageID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Session) MessageReactionAdd(channelID, messageID, emojiID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reactions = append(s.reactions, &Reaction{ChannelID: channelID, MessageID: messageID, Emoji: emojiID})
+	return nil
+}
+
+func (s *Session) Guild(guildID string) (*discordgo.Guild, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if guild, ok := s.guilds[guildID]; ok {
+		return guild, nil
+	}
+
+	return nil, fmt.Errorf("guild %v %w", guildID, ErrNotFound)
+}
+
+func (s *Session) GuildMember(guildID, userID string) (*discordgo.Member, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if member, ok := s.members[guildID+":"+userID]; ok {
+		return member, nil
+	}
+
+	return nil, fmt.Errorf("member %v %w", userID, ErrNotFound)
+}
+
+func (s *Session) GuildRoles(guildID string) ([]*discordgo.Role, error) {
+	guild, err := s.Guild(guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	return guild.Roles, nil
+}
+
+//User returns a user. "@me" is the bot user.
+func (s *Session) User(userID string) (*discordgo.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if userID == "@me" {
+		return s.me, nil
+	}
+
+	if user, ok := s.users[userID]; ok {
+		return user, nil
+	}
+
+	return nil, fmt.Errorf("user %v %w", userID, ErrNotFound)
+}
+
+//UserChannelCreate creates a DM channel. DM channel IDs are "dm:" followed by the recipient ID.
+func (s *Session) UserChannelCreate(recipientID string) (*discordgo.Channel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := "dm:" + recipientID
+	ch, ok := s.channels[id]
+	if !ok {
+		ch = &discordgo.Channel{ID: id, Type: discordgo.ChannelTypeDM}
+		s.channels[id] = ch
+	}
+
+	return ch, nil
+}
+
+func (s *Session) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses = append(s.responses, &Response{Type: "respond", Interaction: interaction, Data: resp})
+	return nil
+}
+
+func (s *Session) InteractionResponseEdit(_ string, interaction *discordgo.Interaction, newresp *discordgo.WebhookEdit) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses = append(s.responses, &Response{Type: "edit", Interaction: interaction, Data: newresp})
+	return s.send(interaction.ChannelID, &discordgo.MessageSend{Content: newresp.Content, Embeds: newresp.Embeds, Components: newresp.Components}), nil
+}
+
+func (s *Session) InteractionResponseDelete(_ string, interaction *discordgo.Interaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses = append(s.responses, &Response{Type: "delete", Interaction: interaction})
+	return nil
+}
+
+func (s *Session) FollowupMessageCreate(_ string, interaction *discordgo.Interaction, _ bool, data *discordgo.WebhookParams) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses = append(s.responses, &Response{Type: "followup", Interaction: interaction, Data: data})
+	return s.send(interaction.ChannelID, &discordgo.MessageSend{Content: data.Content, Embeds: data.Embeds, Components: data.Components}), nil
+}
+
+//send records a message and stores it, so it can be fetched and deleted later. It must be called with the lock held.
+func (s *Session) send(channelID string, data *discordgo.MessageSend) *discordgo.Message {
+	s.lastID++
+
+	msg := &discordgo.Message{
+		ID:         strconv.Itoa(s.lastID),
+		ChannelID:  channelID,
+		Content:    data.Content,
+		Embeds:     data.Embeds,
+		Components: data.Components,
+		Author:     s.me,
+	}
+
+	if ch, ok := s.channels[channelID]; ok {
+		msg.GuildID = ch.GuildID
+	}
+
+	s.messages[channelID+":"+msg.ID] = msg
+	s.sent = append(s.sent, &Message{ChannelID: channelID, MessageID: msg.ID, Send: data})
+
+	return msg
+}
+
+//delete records a deletion. Deleting a message that doesn't exist fails like it does on Discord. It must be called with the lock held.
+func (s *Session) delete(channelID, messageID string) error {
+	key := channelID + ":" + messageID
+	if _, ok := s.messages[key]; !ok {
+		return fmt.Errorf("message %v %w", messageID, ErrNotFound)
+	}
+
+	delete(s.messages, key)
+	s.deleted = append(s.deleted, &Deleted{ChannelID: channelID, MessageID: messageID})
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package dgoutils
+
+import (
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+//Interaction is a deferred application command interaction. The first response edits the deferred
+// "thinking" message, every consecutive response is sent as a followup message.
+type Interaction struct {
+	*discordgo.Interaction
+
+	mu        sync.Mutex
+	responded bool
+}
+
+func NewInteraction(i *discordgo.Interaction) *Interaction {
+	return &Interaction{Interaction: i}
+}
+
+//Defer acknowledges the interaction. Discord requires an acknowledgement within 3 seconds.
+func (i *Interaction) Defer(s Session) error {
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+}
+
+//Respond sends a message as an interaction response.
+func (i *Interaction) Respond(s Session, send *discordgo.MessageSend) (*discordgo.Message, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if !i.responded {
+		components := send.Components
+		if components == nil {
+			components = []discordgo.MessageComponent{}
+		}
+
+		msg, err := s.InteractionResponseEdit(BotUserID(s), i.Interaction, &discordgo.WebhookEdit{
+			Content:         send.Content,
+			Embeds:          send.Embeds,
+			Components:      components,
+			Files:           send.Files,
+			AllowedMentions: send.AllowedMentions,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		i.responded = true
+		return msg, nil
+	}
+
+	return s.FollowupMessageCreate(BotUserID(s), i.Interaction, true, &discordgo.WebhookParams{
+		Content:         send.Content,
+		Embeds:          send.Embeds,
+		Components:      send.Components,
+		Files:           send.Files,
+		AllowedMentions: send.AllowedMentions,
+	})
+}
+
+//Finish removes the deferred response if the command never responded to the interaction,
+//e.g. when it only posted artworks to the channel.
+func (i *Interaction) Finish(s Session) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.responded {
+		return nil
+	}
+
+	i.responded = true
+	return s.InteractionResponseDelete(BotUserID(s), i.Interaction)
+}
@@ -0,0 +1,51 @@
+package dgoutils
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+//Session is a subset of *discordgo.Session methods used by the bot. Handlers and posts depend on it instead of *discordgo.Session,
+//so they can be tested offline with a fake session from dgofake package.
+type Session interface {
+	Channel(channelID string) (*discordgo.Channel, error)
+	ChannelMessage(channelID, messageID string) (*discordgo.Message, error)
+	ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend) (*discordgo.Message, error)
+	ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed) (*discordgo.Message, error)
+	ChannelMessageEditComplex(m *discordgo.MessageEdit) (*discordgo.Message, error)
+	ChannelMessageDelete(channelID, messageID string) error
+	ChannelMessagesBulkDelete(channelID string, messages []string) error
+	MessageReactionAdd(channelID, messageID, emojiID string) error
+
+	Guild(guildID string) (*discordgo.Guild, error)
+	GuildMember(guildID, userID string) (*discordgo.Member, error)
+	GuildRoles(guildID string) ([]*discordgo.Role, error)
+	User(userID string) (*discordgo.User, error)
+	UserChannelCreate(recipientID string) (*discordgo.Channel, error)
+
+	InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse) error
+	InteractionResponseEdit(appID string, interaction *discordgo.Interaction, newresp *discordgo.WebhookEdit) (*discordgo.Message, error)
+	InteractionResponseDelete(appID string, interaction *discordgo.Interaction) error
+	FollowupMessageCreate(appID string, interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams) (*discordgo.Message, error)
+}
+
+//state returns session's state cache. Sessions other than *discordgo.Session don't have one.
+func state(s Session) *discordgo.State {
+	if ds, ok := s.(*discordgo.Session); ok && ds != nil {
+		return ds.State
+	}
+
+	return nil
+}
+
+//BotUserID returns ID of the bot user. It's taken from the state if the session has one, otherwise the user is requested as "@me".
+func BotUserID(s Session) string {
+	if st := state(s); st != nil && st.User != nil {
+		return st.User.ID
+	}
+
+	if user, err := s.User("@me"); err == nil {
+		return user.ID
+	}
+
+	return ""
+}
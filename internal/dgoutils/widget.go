@@ -0,0 +1,508 @@
+package dgoutils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	widgetPrefix    = "widget:"
+	widgetModal     = widgetPrefix + "modal:"
+	widgetPageInput = widgetPrefix + "page"
+
+	//maxSelectOptions is the maximum number of options Discord allows in a select menu.
+	//Widgets with more pages use a modal to jump to a page.
+	maxSelectOptions = 25
+)
+
+var ErrInvalidPage = errors.New("invalid page")
+
+//EmbedWidget is an interactive paginated embed controlled with message components.
+type EmbedWidget struct {
+	s           Session
+	m           *discordgo.Message
+	author      string
+	currentPage int
+	Pages       []*discordgo.MessageEmbed
+
+	callback func(action WidgetAction, page int) error
+	reply    func(*discordgo.MessageSend) (*discordgo.Message, error)
+
+	mu    sync.Mutex
+	timer *time.Timer
+	//expiresAt is moved forward on every interaction. Timer can fire while an interaction holds the lock, such timers are ignored.
+	expiresAt time.Time
+	//stopped is set once the widget is stopped, expired or closed. Interactions that were already waiting for the lock are ignored.
+	stopped bool
+}
+
+type WidgetAction int
+
+const (
+	WidgetActionFirstPage WidgetAction = iota
+	WidgetActionPreviousPage
+	WidgetActionStop
+	WidgetActionNextPage
+	WidgetActionLastPage
+	WidgetActionJump
+)
+
+var actionMap = map[string]WidgetAction{
+	"first":    WidgetActionFirstPage,
+	"previous": WidgetActionPreviousPage,
+	"stop":     WidgetActionStop,
+	"next":     WidgetActionNextPage,
+	"last":     WidgetActionLastPage,
+	"jump":     WidgetActionJump,
+}
+
+func (a WidgetAction) String() string {
+	return []string{"first", "previous", "stop", "next", "last", "jump"}[a]
+}
+
+func (a WidgetAction) customID() string {
+	return widgetPrefix + a.String()
+}
+
+func NewWidget(s Session, author string, embeds []*discordgo.MessageEmbed) *EmbedWidget {
+	return &EmbedWidget{s: s, author: author, Pages: embeds}
+}
+
+//WithCallback sets a function that's called before a page is shown. It's used to lazy-load pages,
+//a callback is expected to fill w.Pages[page] if it's nil.
+func (w *EmbedWidget) WithCallback(fn func(WidgetAction, int) error) {
+	w.callback = fn
+}
+
+//WithReply overrides how the first page is sent, e.g. as an interaction response.
+func (w *EmbedWidget) WithReply(fn func(*discordgo.MessageSend) (*discordgo.Message, error)) {
+	w.reply = fn
+}
+
+//components returns message components for the current page.
+func (w *EmbedWidget) components() []discordgo.MessageComponent {
+	if w.len() <= 1 {
+		return []discordgo.MessageComponent{}
+	}
+
+	var (
+		first = w.currentPage == 0
+		last  = w.currentPage == w.len()-1
+	)
+
+	buttons := discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.Button{Label: "⏮", Style: discordgo.SecondaryButton, CustomID: WidgetActionFirstPage.customID(), Disabled: first},
+			discordgo.Button{Label: "◀", Style: discordgo.SecondaryButton, CustomID: WidgetActionPreviousPage.customID(), Disabled: first},
+			discordgo.Button{Label: "⏹", Style: discordgo.DangerButton, CustomID: WidgetActionStop.customID()},
+			discordgo.Button{Label: "▶", Style: discordgo.SecondaryButton, CustomID: WidgetActionNextPage.customID(), Disabled: last},
+			discordgo.Button{Label: "⏭", Style: discordgo.SecondaryButton, CustomID: WidgetActionLastPage.customID(), Disabled: last},
+		},
+	}
+
+	if w.len() > maxSelectOptions {
+		jump := discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    fmt.Sprintf("Page %v of %v. Jump to page...", w.currentPage+1, w.len()),
+					Style:    discordgo.PrimaryButton,
+					CustomID: WidgetActionJump.customID(),
+				},
+			},
+		}
+
+		return []discordgo.MessageComponent{buttons, jump}
+	}
+
+	options := make([]discordgo.SelectMenuOption, 0, w.len())
+	for ind := range w.Pages {
+		options = append(options, discordgo.SelectMenuOption{
+			Label:   fmt.Sprintf("Page %v", ind+1),
+			Value:   strconv.Itoa(ind),
+			Default: ind == w.currentPage,
+		})
+	}
+
+	jump := discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				CustomID:    WidgetActionJump.customID(),
+				Placeholder: "Jump to page",
+				Options:     options,
+			},
+		},
+	}
+
+	return []discordgo.MessageComponent{buttons, jump}
+}
+
+//modal returns a modal with a page number input for widgets with too many pages for a select menu.
+func (w *EmbedWidget) modal() *discordgo.InteractionResponseData {
+	return &discordgo.InteractionResponseData{
+		CustomID: widgetModal + w.m.ID,
+		Title:    "Jump to page",
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.TextInput{
+						CustomID:    widgetPageInput,
+						Label:       "Page",
+						Style:       discordgo.TextInputShort,
+						Placeholder: fmt.Sprintf("1-%v", w.len()),
+						Required:    true,
+						MaxLength:   len(strconv.Itoa(w.len())),
+					},
+				},
+			},
+		},
+	}
+}
+
+//navigate changes the current page. Page is only used by WidgetActionJump. The page isn't changed if the callback fails.
+func (w *EmbedWidget) navigate(action WidgetAction, page int) error {
+	previous := w.currentPage
+	switch action {
+	case WidgetActionFirstPage:
+		w.currentPage = 0
+	case WidgetActionPreviousPage:
+		if w.currentPage > 0 {
+			w.currentPage--
+		}
+	case WidgetActionNextPage:
+		if w.currentPage < w.len()-1 {
+			w.currentPage++
+		}
+	case WidgetActionLastPage:
+		w.currentPage = w.len() - 1
+	case WidgetActionJump:
+		if page < 0 || page >= w.len() {
+			return ErrInvalidPage
+		}
+
+		w.currentPage = page
+	}
+
+	if w.callback != nil {
+		if err := w.callback(action, w.currentPage); err != nil {
+			w.currentPage = previous
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *EmbedWidget) len() int {
+	return len(w.Pages)
+}
+
+//WidgetManager keeps track of active embed widgets and routes component interactions to them by message ID.
+//Widgets expire after a period of inactivity, their components are removed.
+type WidgetManager struct {
+	mu      sync.Mutex
+	widgets map[string]*EmbedWidget
+	ttl     time.Duration
+}
+
+func NewWidgetManager(ttl time.Duration) *WidgetManager {
+	return &WidgetManager{
+		widgets: make(map[string]*EmbedWidget),
+		ttl:     ttl,
+	}
+}
+
+//Start sends the first page of a widget and registers the widget if it has more than one page.
+func (wm *WidgetManager) Start(w *EmbedWidget, channelID string) error {
+	if w.len() == 0 {
+		return nil
+	}
+
+	if w.Pages[0] == nil && w.callback != nil {
+		if err := w.callback(WidgetActionFirstPage, 0); err != nil {
+			return err
+		}
+	}
+
+	send := &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{w.Pages[0]},
+		Components: w.components(),
+	}
+
+	var (
+		m   *discordgo.Message
+		err error
+	)
+
+	if w.reply != nil {
+		m, err = w.reply(send)
+	} else {
+		m, err = w.s.ChannelMessageSendComplex(channelID, send)
+	}
+
+	if err != nil {
+		return err
+	}
+	w.m = m
+
+	if w.len() == 1 {
+		return nil
+	}
+
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	wm.widgets[m.ID] = w
+	w.expiresAt = time.Now().Add(wm.ttl)
+	w.timer = time.AfterFunc(wm.ttl, func() {
+		wm.expire(m.ID)
+	})
+
+	return nil
+}
+
+//Len returns the number of active widgets.
+func (wm *WidgetManager) Len() int {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	return len(wm.widgets)
+}
+
+//Close stops all active widgets without editing their messages.
+func (wm *WidgetManager) Close() {
+	wm.mu.Lock()
+	widgets := make([]*EmbedWidget, 0, len(wm.widgets))
+	for id, w := range wm.widgets {
+		widgets = append(widgets, w)
+		delete(wm.widgets, id)
+	}
+	wm.mu.Unlock()
+
+	//Widget locks are taken without holding the manager lock, Handle takes them in the opposite order.
+	for _, w := range widgets {
+		w.mu.Lock()
+		w.stop()
+		w.mu.Unlock()
+	}
+}
+
+//Handle processes a component or modal submit interaction. Interactions that don't belong to widgets are ignored.
+func (wm *WidgetManager) Handle(s Session, i *discordgo.Interaction) error {
+	var (
+		messageID string
+		customID  string
+	)
+
+	switch i.Type {
+	case discordgo.InteractionMessageComponent:
+		customID = i.MessageComponentData().CustomID
+		if !strings.HasPrefix(customID, widgetPrefix) {
+			return nil
+		}
+
+		messageID = i.Message.ID
+	case discordgo.InteractionModalSubmit:
+		customID = i.ModalSubmitData().CustomID
+		if !strings.HasPrefix(customID, widgetModal) {
+			return nil
+		}
+
+		messageID = strings.TrimPrefix(customID, widgetModal)
+		customID = WidgetActionJump.customID()
+	default:
+		return nil
+	}
+
+	w, ok := wm.get(messageID)
+	if !ok {
+		//The bot might have been restarted, remove stale components.
+		return removeComponents(s, i)
+	}
+
+	if user := interactionUser(i); user == nil || user.ID != w.author {
+		return respondEphemeral(s, i, "Only the author of the command can use this widget.")
+	}
+
+	action, ok := actionMap[strings.TrimPrefix(customID, widgetPrefix)]
+	if !ok {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	//The widget has expired while the interaction was waiting for the lock.
+	if w.stopped {
+		return removeComponents(s, i)
+	}
+
+	if action == WidgetActionStop {
+		w.stop()
+		wm.remove(messageID)
+
+		return s.InteractionRespond(i, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Embeds:     []*discordgo.MessageEmbed{w.Pages[w.currentPage]},
+				Components: []discordgo.MessageComponent{},
+			},
+		})
+	}
+
+	page := w.currentPage
+	if action == WidgetActionJump {
+		var err error
+
+		switch i.Type {
+		case discordgo.InteractionModalSubmit:
+			page, err = modalPage(i.ModalSubmitData())
+		default:
+			data := i.MessageComponentData()
+			if data.ComponentType == discordgo.ButtonComponent {
+				return s.InteractionRespond(i, &discordgo.InteractionResponse{
+					Type: discordgo.InteractionResponseModal,
+					Data: w.modal(),
+				})
+			}
+
+			if len(data.Values) == 0 {
+				return nil
+			}
+
+			page, err = strconv.Atoi(data.Values[0])
+		}
+
+		if err != nil {
+			return respondEphemeral(s, i, "Page number is not an integer.")
+		}
+	}
+
+	if err := w.navigate(action, page); err != nil {
+		if errors.Is(err, ErrInvalidPage) {
+			return respondEphemeral(s, i, fmt.Sprintf("Page should be between 1 and %v.", w.len()))
+		}
+
+		if rerr := respondEphemeral(s, i, "Failed to load the page. Please try again later."); rerr != nil {
+			return rerr
+		}
+
+		return err
+	}
+
+	w.expiresAt = time.Now().Add(wm.ttl)
+	w.timer.Reset(wm.ttl)
+	return s.InteractionRespond(i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{w.Pages[w.currentPage]},
+			Components: w.components(),
+		},
+	})
+}
+
+func (wm *WidgetManager) get(messageID string) (*EmbedWidget, bool) {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	w, ok := wm.widgets[messageID]
+	return w, ok
+}
+
+func (wm *WidgetManager) remove(messageID string) (*EmbedWidget, bool) {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+
+	w, ok := wm.widgets[messageID]
+	if !ok {
+		return nil, false
+	}
+
+	delete(wm.widgets, messageID)
+	return w, true
+}
+
+//expire removes widget's components once it's inactive for too long.
+func (wm *WidgetManager) expire(messageID string) {
+	w, ok := wm.get(messageID)
+	if !ok {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stopped || time.Now().Before(w.expiresAt) {
+		return
+	}
+
+	w.stop()
+	w.s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:         w.m.ID,
+		Channel:    w.m.ChannelID,
+		Components: []discordgo.MessageComponent{},
+	})
+
+	wm.remove(messageID)
+}
+
+//stop stops widget's timer. It must be called with widget's lock held.
+func (w *EmbedWidget) stop() {
+	w.stopped = true
+	w.timer.Stop()
+}
+
+//modalPage reads a 1-based page number from the jump modal and converts it to a page index.
+func modalPage(data discordgo.ModalSubmitInteractionData) (int, error) {
+	for _, row := range data.Components {
+		row, ok := row.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+
+		for _, c := range row.Components {
+			if input, ok := c.(*discordgo.TextInput); ok && input.CustomID == widgetPageInput {
+				page, err := strconv.Atoi(strings.TrimSpace(input.Value))
+				if err != nil {
+					return 0, err
+				}
+
+				return page - 1, nil
+			}
+		}
+	}
+
+	return 0, ErrInvalidPage
+}
+
+func interactionUser(i *discordgo.Interaction) *discordgo.User {
+	if i.Member != nil {
+		return i.Member.User
+	}
+
+	return i.User
+}
+
+func removeComponents(s Session, i *discordgo.Interaction) error {
+	return s.InteractionRespond(i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+}
+
+func respondEphemeral(s Session, i *discordgo.Interaction, content string) error {
+	return s.InteractionRespond(i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   uint64(discordgo.MessageFlagsEphemeral),
+		},
+	})
+}
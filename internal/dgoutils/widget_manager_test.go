@@ -0,0 +1,180 @@
+package dgoutils_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/internal/dgoutils"
+	"github.com/VTGare/boe-tea-go/internal/dgoutils/dgofake"
+	"github.com/bwmarrin/discordgo"
+)
+
+func startWidget(t *testing.T, ttl time.Duration, callback func(dgoutils.WidgetAction, int) error) (*dgofake.Session, *dgoutils.WidgetManager, *discordgo.Message) {
+	t.Helper()
+
+	s := dgofake.New("bot")
+	s.AddChannel(&discordgo.Channel{ID: "channel", GuildID: "guild"})
+
+	wm := dgoutils.NewWidgetManager(ttl)
+	t.Cleanup(wm.Close)
+
+	w := dgoutils.NewWidget(s, "author", []*discordgo.MessageEmbed{{Title: "1"}, {Title: "2"}})
+	if callback != nil {
+		w.WithCallback(callback)
+	}
+
+	if err := wm.Start(w, "channel"); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := s.Sent("channel")
+	if len(sent) != 1 {
+		t.Fatalf("Sent() = %v, want the first page", sent)
+	}
+
+	msg, err := s.ChannelMessage("channel", sent[0].MessageID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s, wm, msg
+}
+
+func click(msg *discordgo.Message, userID string, action string) *discordgo.Interaction {
+	return &discordgo.Interaction{
+		Type:    discordgo.InteractionMessageComponent,
+		Message: msg,
+		User:    &discordgo.User{ID: userID},
+		Data: discordgo.MessageComponentInteractionData{
+			CustomID:      "widget:" + action,
+			ComponentType: discordgo.ButtonComponent,
+		},
+	}
+}
+
+//lastResponse returns data of the last interaction response.
+func lastResponse(t *testing.T, s *dgofake.Session) *discordgo.InteractionResponse {
+	t.Helper()
+
+	responses := s.Responses()
+	if len(responses) == 0 {
+		t.Fatal("no interaction responses")
+	}
+
+	return responses[len(responses)-1].Data.(*discordgo.InteractionResponse)
+}
+
+func waitExpired(t *testing.T, wm *dgoutils.WidgetManager) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for wm.Len() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("widget hasn't expired")
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestWidgetManager_Handle(t *testing.T) {
+	s, wm, msg := startWidget(t, time.Minute, nil)
+
+	if err := wm.Handle(s, click(msg, "author", "next")); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := lastResponse(t, s)
+	if resp.Type != discordgo.InteractionResponseUpdateMessage || resp.Data.Embeds[0].Title != "2" {
+		t.Fatalf("next page response = %+v, want the second page", resp.Data)
+	}
+
+	if err := wm.Handle(s, click(msg, "someone", "previous")); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp := lastResponse(t, s); resp.Data.Flags != uint64(discordgo.MessageFlagsEphemeral) {
+		t.Fatalf("response to another user = %+v, want an ephemeral message", resp.Data)
+	}
+
+	if err := wm.Handle(s, click(msg, "author", "stop")); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp := lastResponse(t, s); len(resp.Data.Components) != 0 || resp.Data.Embeds[0].Title != "2" {
+		t.Fatalf("stop response = %+v, want the current page without components", resp.Data)
+	}
+
+	if wm.Len() != 0 {
+		t.Errorf("Len() = %v, want a stopped widget to be removed", wm.Len())
+	}
+}
+
+func TestWidgetManager_CallbackError(t *testing.T) {
+	s, wm, msg := startWidget(t, time.Minute, func(_ dgoutils.WidgetAction, _ int) error {
+		return errors.New("store is unavailable")
+	})
+
+	if err := wm.Handle(s, click(msg, "author", "next")); err == nil {
+		t.Fatal("Handle() error = nil, want the callback error")
+	}
+
+	if resp := lastResponse(t, s); resp.Data.Flags != uint64(discordgo.MessageFlagsEphemeral) {
+		t.Fatalf("response to a failed callback = %+v, want an ephemeral message", resp.Data)
+	}
+
+	//The page isn't changed, so stopping the widget shows the first page.
+	if err := wm.Handle(s, click(msg, "author", "stop")); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp := lastResponse(t, s); resp.Data.Embeds[0].Title != "1" {
+		t.Errorf("stop response = %+v, want the first page", resp.Data)
+	}
+}
+
+func TestWidgetManager_Expire(t *testing.T) {
+	s, wm, msg := startWidget(t, 20*time.Millisecond, nil)
+	waitExpired(t, wm)
+
+	expired, err := s.ChannelMessage("channel", msg.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(expired.Components) != 0 {
+		t.Errorf("components = %v, want them removed on expiry", expired.Components)
+	}
+
+	//Interactions with an expired widget remove components instead of changing the page.
+	if err := wm.Handle(s, click(msg, "author", "next")); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := lastResponse(t, s)
+	if resp.Type != discordgo.InteractionResponseUpdateMessage || len(resp.Data.Components) != 0 || len(resp.Data.Embeds) != 0 {
+		t.Errorf("response after expiry = %+v, want components removed", resp.Data)
+	}
+}
+
+func TestWidgetManager_Close(t *testing.T) {
+	s, wm, msg := startWidget(t, 20*time.Millisecond, nil)
+	wm.Close()
+
+	if wm.Len() != 0 {
+		t.Fatalf("Len() = %v, want 0", wm.Len())
+	}
+
+	//Closed widgets don't expire, their messages aren't edited.
+	time.Sleep(50 * time.Millisecond)
+
+	closed, err := s.ChannelMessage("channel", msg.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(closed.Components) == 0 {
+		t.Errorf("components were removed from a closed widget")
+	}
+}
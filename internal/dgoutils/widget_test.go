@@ -0,0 +1,61 @@
+package dgoutils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+var errCallback = errors.New("callback failed")
+
+func TestEmbedWidget_navigate(t *testing.T) {
+	tests := []struct {
+		name    string
+		current int
+		action  WidgetAction
+		page    int
+		want    int
+		fail    bool
+		err     error
+	}{
+		{name: "next page", current: 0, action: WidgetActionNextPage, want: 1},
+		{name: "next page on last page", current: 2, action: WidgetActionNextPage, want: 2},
+		{name: "previous page on first page", current: 0, action: WidgetActionPreviousPage, want: 0},
+		{name: "last page", current: 0, action: WidgetActionLastPage, want: 2},
+		{name: "first page", current: 2, action: WidgetActionFirstPage, want: 0},
+		{name: "jump", current: 0, action: WidgetActionJump, page: 1, want: 1},
+		{name: "jump out of range", current: 0, action: WidgetActionJump, page: 3, want: 0, err: ErrInvalidPage},
+		{name: "callback error", current: 0, action: WidgetActionNextPage, want: 0, fail: true, err: errCallback},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var loaded int
+			w := NewWidget(nil, "", make([]*discordgo.MessageEmbed, 3))
+			w.currentPage = tt.current
+			w.WithCallback(func(_ WidgetAction, page int) error {
+				if tt.fail {
+					return errCallback
+				}
+
+				loaded = page
+				w.Pages[page] = &discordgo.MessageEmbed{}
+				return nil
+			})
+
+			err := w.navigate(tt.action, tt.page)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("navigate() error = %v, want %v", err, tt.err)
+			}
+
+			if w.currentPage != tt.want {
+				t.Errorf("currentPage = %v, want %v", w.currentPage, tt.want)
+			}
+
+			if tt.err == nil && (loaded != tt.want || w.Pages[tt.want] == nil) {
+				t.Errorf("callback wasn't called for page %v", tt.want)
+			}
+		})
+	}
+}
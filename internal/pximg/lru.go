@@ -0,0 +1,169 @@
+package pximg
+
+import (
+	"container/list"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//DiskCache is a least recently used file cache. Files are evicted once the total size exceeds the limit.
+type DiskCache struct {
+	dir   string
+	limit int64
+
+	mu    sync.Mutex
+	size  int64
+	order *list.List
+	items map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key  string
+	size int64
+}
+
+//NewDiskCache creates a disk cache in dir. Files left from previous runs are indexed by their modification time.
+func NewDiskCache(dir string, limit int64) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	dc := &DiskCache{
+		dir:   dir,
+		limit: limit,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]fs.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		//Unfinished downloads from previous runs.
+		if strings.HasPrefix(entry.Name(), ".") {
+			os.Remove(filepath.Join(dir, entry.Name()))
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		files = append(files, info)
+	}
+
+	//Oldest files go to the back of the list and are evicted first.
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().After(files[j].ModTime())
+	})
+
+	for _, file := range files {
+		dc.items[file.Name()] = dc.order.PushBack(&cacheEntry{file.Name(), file.Size()})
+		dc.size += file.Size()
+	}
+
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.evict()
+
+	return dc, nil
+}
+
+//Open opens a cached file and marks it as recently used.
+func (dc *DiskCache) Open(key string) (*os.File, bool) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	el, ok := dc.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	f, err := os.Open(dc.path(key))
+	if err != nil {
+		dc.remove(el)
+		return nil, false
+	}
+
+	dc.order.MoveToFront(el)
+	return f, true
+}
+
+//TempFile creates a file in cache's directory that can be added to the cache with Put.
+func (dc *DiskCache) TempFile() (*os.File, error) {
+	return os.CreateTemp(dc.dir, ".download-*")
+}
+
+//Put moves a temporary file to the cache. Files larger than the cache limit are removed.
+func (dc *DiskCache) Put(key, tmp string) error {
+	info, err := os.Stat(tmp)
+	if err != nil {
+		return err
+	}
+
+	if info.Size() > dc.limit {
+		return os.Remove(tmp)
+	}
+
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	if err := os.Rename(tmp, dc.path(key)); err != nil {
+		return err
+	}
+
+	if el, ok := dc.items[key]; ok {
+		dc.size -= el.Value.(*cacheEntry).size
+		dc.order.Remove(el)
+	}
+
+	dc.items[key] = dc.order.PushFront(&cacheEntry{key, info.Size()})
+	dc.size += info.Size()
+	dc.evict()
+
+	return nil
+}
+
+//Size returns the total size of cached files.
+func (dc *DiskCache) Size() int64 {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+
+	return dc.size
+}
+
+func (dc *DiskCache) evict() {
+	for dc.size > dc.limit {
+		el := dc.order.Back()
+		if el == nil {
+			return
+		}
+
+		dc.remove(el)
+	}
+}
+
+func (dc *DiskCache) remove(el *list.Element) {
+	entry := el.Value.(*cacheEntry)
+
+	os.Remove(dc.path(entry.key))
+	dc.order.Remove(el)
+	delete(dc.items, entry.key)
+	dc.size -= entry.size
+}
+
+func (dc *DiskCache) path(key string) string {
+	return filepath.Join(dc.dir, key)
+}
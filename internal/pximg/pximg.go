@@ -0,0 +1,232 @@
+package pximg
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Upstream is Pixiv's image server. It rejects requests without a Pixiv referer.
+const Upstream = "https://i.pximg.net"
+
+//Signer signs proxied image URLs so the proxy can't be used to fetch arbitrary pximg files.
+type Signer struct {
+	baseURL string
+	secret  []byte
+}
+
+func NewSigner(baseURL, secret string) *Signer {
+	return &Signer{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		secret:  []byte(secret),
+	}
+}
+
+//URL converts an i.pximg.net URL to a signed proxy URL. Other URLs are returned unchanged.
+func (s *Signer) URL(pximgURL string) string {
+	if !strings.HasPrefix(pximgURL, Upstream+"/") {
+		return pximgURL
+	}
+
+	p := strings.TrimPrefix(pximgURL, Upstream)
+	return s.baseURL + p + "?sig=" + s.sign(p)
+}
+
+//Verify reports if a signature matches the path.
+func (s *Signer) Verify(path, sig string) bool {
+	return hmac.Equal([]byte(s.sign(path)), []byte(sig))
+}
+
+func (s *Signer) sign(path string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(path))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+//Proxy is an HTTP handler that serves pximg files with a correct referer. Files are cached on disk.
+//Range requests are supported.
+type Proxy struct {
+	signer   *Signer
+	cache    *DiskCache
+	upstream string
+	client   *http.Client
+
+	mu        sync.Mutex
+	downloads map[string]*download
+}
+
+//download is a file being fetched from upstream. Requests for the same file wait for one download and share its
+//descriptor, so the file is served even if it's evicted or too large to be cached.
+type download struct {
+	done chan struct{}
+	file *os.File
+	err  error
+	refs int
+}
+
+func NewProxy(signer *Signer, cache *DiskCache) *Proxy {
+	return NewProxyWithUpstream(signer, cache, Upstream)
+}
+
+//NewProxyWithUpstream creates a proxy for a custom upstream server.
+func NewProxyWithUpstream(signer *Signer, cache *DiskCache, upstream string) *Proxy {
+	return &Proxy{
+		signer:    signer,
+		cache:     cache,
+		upstream:  strings.TrimSuffix(upstream, "/"),
+		client:    &http.Client{Timeout: time.Minute},
+		downloads: make(map[string]*download),
+	}
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	filePath := path.Clean(r.URL.Path)
+	if filePath == "/" || !p.signer.Verify(filePath, r.URL.Query().Get("sig")) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	f, release, err := p.open(cacheKey(filePath), filePath)
+	if err != nil {
+		var upstreamErr *upstreamError
+		if errors.As(err, &upstreamErr) {
+			http.Error(w, http.StatusText(upstreamErr.status), upstreamErr.status)
+			return
+		}
+
+		http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		return
+	}
+	defer release()
+
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	//pximg files never change, their URLs contain upload timestamps.
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	//Shared descriptors are read with ReadAt, their offset isn't moved.
+	http.ServeContent(w, r, path.Base(filePath), info.ModTime(), io.NewSectionReader(f, 0, info.Size()))
+}
+
+//open opens a cached file or waits for its download. Release must be called once the file is served.
+func (p *Proxy) open(key, filePath string) (*os.File, func(), error) {
+	if f, ok := p.cache.Open(key); ok {
+		return f, func() { f.Close() }, nil
+	}
+
+	p.mu.Lock()
+	d, downloading := p.downloads[key]
+	if !downloading {
+		d = &download{done: make(chan struct{})}
+		p.downloads[key] = d
+	}
+	d.refs++
+	p.mu.Unlock()
+
+	if downloading {
+		<-d.done
+	} else {
+		d.file, d.err = p.fetch(key, filePath)
+
+		p.mu.Lock()
+		delete(p.downloads, key)
+		p.mu.Unlock()
+		close(d.done)
+	}
+
+	release := func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		d.refs--
+		if d.refs == 0 && d.file != nil {
+			d.file.Close()
+		}
+	}
+
+	if d.err != nil {
+		release()
+		return nil, nil, d.err
+	}
+
+	return d.file, release, nil
+}
+
+//fetch downloads a file to the cache and returns its open descriptor.
+func (p *Proxy) fetch(key, filePath string) (*os.File, error) {
+	//The file could've been cached by a download that has just finished.
+	if f, ok := p.cache.Open(key); ok {
+		return f, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, p.upstream+(&url.URL{Path: filePath}).EscapedPath(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Referer", "https://www.pixiv.net/")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &upstreamError{resp.StatusCode}
+	}
+
+	tmp, err := p.cache.TempFile()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+
+	//The descriptor stays valid after the file is renamed or removed by the cache.
+	if err := p.cache.Put(key, tmp.Name()); err != nil {
+		os.Remove(tmp.Name())
+	}
+
+	return tmp, nil
+}
+
+type upstreamError struct {
+	status int
+}
+
+func (e *upstreamError) Error() string {
+	return fmt.Sprintf("upstream returned %v", e.status)
+}
+
+//cacheKey converts a file path to a file name, keeping the extension for content type detection.
+func cacheKey(filePath string) string {
+	sum := sha256.Sum256([]byte(filePath))
+	return hex.EncodeToString(sum[:]) + path.Ext(filePath)
+}
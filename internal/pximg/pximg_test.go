@@ -0,0 +1,227 @@
+package pximg
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+const testFile = "/img-original/img/2021/01/01/00/00/00/12345_p0.png"
+
+func newTestProxy(t *testing.T, body string, limit int64) (*httptest.Server, *Signer, *int32) {
+	t.Helper()
+
+	var hits int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+
+		if r.Header.Get("Referer") != "https://www.pixiv.net/" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		if r.URL.Path != testFile {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(upstream.Close)
+
+	dc, err := NewDiskCache(t.TempDir(), limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(nil)
+	t.Cleanup(server.Close)
+
+	signer := NewSigner(server.URL, "secret")
+	server.Config.Handler = NewProxyWithUpstream(signer, dc, upstream.URL)
+
+	return server, signer, &hits
+}
+
+func get(t *testing.T, url string, header http.Header) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return resp.StatusCode, string(body)
+}
+
+func TestSigner_URL(t *testing.T) {
+	signer := NewSigner("https://pximg.example.com/", "secret")
+
+	signed := signer.URL(Upstream + testFile)
+	if !strings.HasPrefix(signed, "https://pximg.example.com"+testFile+"?sig=") {
+		t.Fatalf("URL() = %v, want a signed proxy URL", signed)
+	}
+
+	if !signer.Verify(testFile, signed[strings.Index(signed, "sig=")+4:]) {
+		t.Fatalf("Verify() = false for a signed URL")
+	}
+
+	if NewSigner("https://pximg.example.com", "other").Verify(testFile, signed[strings.Index(signed, "sig=")+4:]) {
+		t.Fatalf("Verify() = true for a different secret")
+	}
+
+	if got := signer.URL("https://example.com/image.png"); got != "https://example.com/image.png" {
+		t.Fatalf("URL() = %v, want non-pximg URLs unchanged", got)
+	}
+}
+
+func TestProxy_ServeHTTP(t *testing.T) {
+	server, signer, hits := newTestProxy(t, "0123456789", 1024)
+	signed := signer.URL(Upstream + testFile)
+
+	tests := []struct {
+		name   string
+		url    string
+		header http.Header
+		status int
+		body   string
+	}{
+		{name: "missing signature", url: server.URL + testFile, status: http.StatusForbidden},
+		{name: "invalid signature", url: server.URL + testFile + "?sig=invalid", status: http.StatusForbidden},
+		{name: "signature of another file", url: server.URL + "/other.png?" + strings.SplitN(signed, "?", 2)[1], status: http.StatusForbidden},
+		{name: "full file", url: signed, status: http.StatusOK, body: "0123456789"},
+		{name: "cached file", url: signed, status: http.StatusOK, body: "0123456789"},
+		{name: "range", url: signed, header: http.Header{"Range": {"bytes=2-5"}}, status: http.StatusPartialContent, body: "2345"},
+		{name: "upstream not found", url: signer.URL(Upstream + "/img-original/missing.png"), status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := get(t, tt.url, tt.header)
+			if status != tt.status {
+				t.Fatalf("status = %v, want %v", status, tt.status)
+			}
+
+			if tt.body != "" && body != tt.body {
+				t.Fatalf("body = %q, want %q", body, tt.body)
+			}
+		})
+	}
+
+	//The file is fetched once, the missing file once.
+	if got := atomic.LoadInt32(hits); got != 2 {
+		t.Fatalf("upstream hits = %v, want 2", got)
+	}
+}
+
+func TestProxy_ServeHTTP_Uncached(t *testing.T) {
+	//The file is larger than the cache and is removed as soon as it's downloaded.
+	_, signer, hits := newTestProxy(t, "0123456789", 4)
+	signed := signer.URL(Upstream + testFile)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			status, body := get(t, signed, nil)
+			if status != http.StatusOK || body != "0123456789" {
+				t.Errorf("got %v %q, want 200 %q", status, body, "0123456789")
+			}
+		}()
+	}
+	wg.Wait()
+
+	status, body := get(t, signed, http.Header{"Range": {"bytes=2-5"}})
+	if status != http.StatusPartialContent || body != "2345" {
+		t.Fatalf("got %v %q, want 206 %q", status, body, "2345")
+	}
+
+	//Concurrent requests may share a download, but the file is fetched again after all of them are served.
+	if got := atomic.LoadInt32(hits); got < 2 {
+		t.Fatalf("upstream hits = %v, want at least 2", got)
+	}
+}
+
+func TestDiskCache_evict(t *testing.T) {
+	dir := t.TempDir()
+	dc, err := NewDiskCache(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	put := func(key, content string) {
+		tmp, err := dc.TempFile()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tmp.WriteString(content)
+		tmp.Close()
+
+		if err := dc.Put(key, tmp.Name()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	put("a", "1234")
+	put("b", "1234")
+
+	//a becomes the most recently used file, b is evicted.
+	f, ok := dc.Open("a")
+	if !ok {
+		t.Fatal("a is not cached")
+	}
+	f.Close()
+
+	put("c", "1234")
+
+	if _, ok := dc.Open("b"); ok {
+		t.Fatal("b wasn't evicted")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "b")); !os.IsNotExist(err) {
+		t.Fatalf("b wasn't removed from disk: %v", err)
+	}
+
+	if dc.Size() != 8 {
+		t.Fatalf("Size() = %v, want 8", dc.Size())
+	}
+
+	put("large", "12345678901")
+	if _, ok := dc.Open("large"); ok {
+		t.Fatal("file larger than the limit was cached")
+	}
+
+	//Files are indexed on restart.
+	dc, err = NewDiskCache(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if dc.Size() != 8 {
+		t.Fatalf("Size() after restart = %v, want 8", dc.Size())
+	}
+}
@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+//Limiter spaces out requests with the same key, e.g. requests to the same artwork provider.
+type Limiter struct {
+	limit  time.Duration
+	limits map[string]time.Duration
+
+	mu       sync.Mutex
+	requests map[string]time.Time
+}
+
+//New creates a limiter that allows one request per limit. Limits override the default limit by key.
+func New(limit time.Duration, limits map[string]time.Duration) *Limiter {
+	return &Limiter{
+		limit:    limit,
+		limits:   limits,
+		requests: make(map[string]time.Time),
+	}
+}
+
+//Wait blocks until a request with the key is allowed or the context is cancelled.
+func (l *Limiter) Wait(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	limit, ok := l.limits[key]
+	if !ok {
+		limit = l.limit
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	next := l.requests[key].Add(limit)
+	if next.Before(now) {
+		next = now
+	}
+	l.requests[key] = next
+	l.mu.Unlock()
+
+	timer := time.NewTimer(next.Sub(now))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
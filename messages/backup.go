@@ -0,0 +1,39 @@
+package messages
+
+import "fmt"
+
+func ErrUnknownExportFormat(format string) error {
+	return newUserError(fmt.Sprintf("`%v` isn't a supported format. Favourites can be exported as json, csv or html.", format))
+}
+
+func ErrExportTooLarge() error {
+	return newUserError("Your favourites are too large to be sent as a file. Please try the csv format.")
+}
+
+func ErrExportDM(err error) error {
+	return newUserError("Couldn't send you a direct message. Please allow direct messages from server members and try again.", err)
+}
+
+func ErrImportEmpty() error {
+	return newUserError("Nothing to import. Attach a JSON export or a text file with artwork URLs, or list URLs after the command.")
+}
+
+func ErrImportTooLarge(limit int) error {
+	return newUserError(fmt.Sprintf("Up to %v favourites can be imported at once. Please split the file.", limit))
+}
+
+func ErrImportFile(err error) error {
+	return newUserError(fmt.Sprintf("Couldn't read the file: %v", err), err)
+}
+
+func ExportSuccess(count int) string {
+	return fmt.Sprintf("Sent %v favourites to your direct messages.", count)
+}
+
+func ImportStarted(count int) string {
+	return fmt.Sprintf("Importing %v favourites. It may take a while, artworks that aren't known to Boe Tea are fetched from their websites.", count)
+}
+
+func ImportSuccess(added, existing, failed int) string {
+	return fmt.Sprintf("Imported %v new favourites, %v were already in your favourites. %v artworks couldn't be found.", added, existing, failed)
+}
@@ -0,0 +1,68 @@
+package messages
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/VTGare/boe-tea-go/internal/arrays"
+)
+
+func ErrInvalidName(name string) error {
+	return newUserError(fmt.Sprintf(
+		"`%v` isn't a valid name. Names are up to 32 letters, digits, dashes and underscores.", name,
+	))
+}
+
+func ErrCollectionExists(name string) error {
+	return newUserError(fmt.Sprintf("You already have a collection named `%v`.", name))
+}
+
+func ErrCollectionNotFound(name string) error {
+	return newUserError(fmt.Sprintf(
+		"Collection `%v` doesn't exist. Use `bt!collection create %v` to create it.", name, name,
+	))
+}
+
+func ErrTooManyCollections(limit int) error {
+	return newUserError(fmt.Sprintf("You can have up to %v collections. Please delete one first.", limit))
+}
+
+func ErrBookmarkNotFound(query string) error {
+	return newUserError(fmt.Sprintf("Artwork `%v` isn't in your favourites.", query))
+}
+
+func ErrTooManyTags(limit int) error {
+	return newUserError(fmt.Sprintf("Favourites can have up to %v tags.", limit))
+}
+
+func ErrNoMatchingFavourites() error {
+	return newUserError("None of your favourites match the collection and tags.")
+}
+
+func CollectionCreated(name string) string {
+	return fmt.Sprintf("Created collection `%v`. Use `bt!collection add %v <artwork ID>` to add favourites.", name, name)
+}
+
+func CollectionDeleted(name string) string {
+	return fmt.Sprintf("Deleted collection `%v`. Its favourites were moved out of the collection.", name)
+}
+
+func CollectionMoved(name string, ids []string) string {
+	if name == "" {
+		return fmt.Sprintf("Removed favourites from their collections: %v", listCodes(ids))
+	}
+
+	return fmt.Sprintf("Moved favourites to collection `%v`: %v", name, listCodes(ids))
+}
+
+func BookmarkTagged(query string, tags []string) string {
+	if len(tags) == 0 {
+		return fmt.Sprintf("Favourite `%v` has no tags.", query)
+	}
+
+	return fmt.Sprintf("Favourite `%v` is tagged %v.", query, listCodes(tags))
+}
+
+func listCodes(items []string) string {
+	return strings.Join(arrays.Map(items, func(s string) string { return "`" + s + "`" }), " • ")
+}
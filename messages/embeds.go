@@ -36,6 +36,7 @@ type Repost struct {
 	Title           string
 	OriginalMessage string
 	Expires         string
+	Similar         string
 }
 
 type About struct {
@@ -55,36 +56,31 @@ type Sauce struct {
 }
 
 type SetCommand struct {
-	CurrentSettings    string
-	General            *General
-	Features           *Features
-	PixivSettings      *PixivSettings
-	TwitterSettings    *ProviderSettings
-	DeviantSettings    *ProviderSettings
-	ArtstationSettings *ProviderSettings
-	ArtChannels        string
+	CurrentSettings string
+	ChannelSettings string
+	Inherited       string
+	Overridden      string
+	General         *General
+	Features        *Features
+	Providers       string
+	ArtChannels     string
 }
 
 type General struct {
-	Title  string
-	Prefix string
-	NSFW   string
-}
-
-type ProviderSettings struct {
-	Title   string
-	Enabled string
-}
-
-type PixivSettings struct {
-	ProviderSettings
-	Limit string
+	Title    string
+	Prefix   string
+	NSFW     string
+	NSFWMode string
+	Limit    string
 }
 
 type Features struct {
 	Title            string
 	Repost           string
 	RepostExpiration string
+	RepostScope      string
+	ImageRepost      string
+	RepostDistance   string
 	Crosspost        string
 	Reactions        string
 	Tags             string
@@ -107,6 +103,7 @@ type UserGroups struct {
 	Group       string
 	Parent      string
 	Children    string
+	Filter      string
 }
 
 var embeds = map[Language]map[EmbedType]interface{}{
@@ -120,6 +117,7 @@ var embeds = map[Language]map[EmbedType]interface{}{
 			Title:           "Repost detected",
 			OriginalMessage: "Jump to original message.",
 			Expires:         "Expires",
+			Similar:         "Matched by image similarity.",
 		},
 
 		about: &About{
@@ -145,35 +143,25 @@ var embeds = map[Language]map[EmbedType]interface{}{
 
 		set: &SetCommand{
 			CurrentSettings: "Current settings",
+			ChannelSettings: "Channel settings",
+			Inherited:       "inherited",
+			Overridden:      "overridden",
 			ArtChannels:     "Art channels",
+			Providers:       "Artwork providers",
 			General: &General{
-				Title:  "General",
-				Prefix: "Prefix",
-				NSFW:   "NSFW",
-			},
-			TwitterSettings: &ProviderSettings{
-				Title:   "Twitter settings",
-				Enabled: "Status __(twitter)__",
-			},
-			DeviantSettings: &ProviderSettings{
-				Title:   "DeviantArt settings",
-				Enabled: "Status __(deviant)__",
-			},
-			ArtstationSettings: &ProviderSettings{
-				Title:   "ArtStation settings",
-				Enabled: "Status __(artstation)__",
-			},
-			PixivSettings: &PixivSettings{
-				ProviderSettings: ProviderSettings{
-					Title:   "Pixiv settings",
-					Enabled: "Status __(pixiv)__",
-				},
-				Limit: "Limit",
+				Title:    "General",
+				Prefix:   "Prefix",
+				NSFW:     "NSFW",
+				NSFWMode: "NSFW in SFW channels __(nsfw.mode)__",
+				Limit:    "Limit",
 			},
 			Features: &Features{
 				Title:            "Features",
 				Repost:           "Repost",
 				RepostExpiration: "Expiration __(repost.expiration)__",
+				RepostScope:      "Scope __(repost.scope)__",
+				ImageRepost:      "Image similarity __(repost.images)__",
+				RepostDistance:   "Distance __(repost.distance)__",
 				Crosspost:        "Crosspost",
 				Reactions:        "Reactions",
 				Tags:             "Tags",
@@ -104,3 +104,15 @@ func ErrSkipIndexSyntax(str string) error {
 		),
 	)
 }
+
+func ErrSlashOptionMissing(option, next string) error {
+	return newUserError(fmt.Sprintf("Option `%v` is required when `%v` is set.", option, next))
+}
+
+func ErrSlashOptionSpaces(option string) error {
+	return newUserError(fmt.Sprintf("Option `%v` can't contain spaces.", option))
+}
+
+func ErrSlashOptionFlag(option, flag string) error {
+	return newUserError(fmt.Sprintf("Option `%v` can't contain flag `%v`, please use the flag's own option.", option, flag))
+}
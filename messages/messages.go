@@ -53,6 +53,14 @@ func NSFWCommand(cmd string) string {
 	return fmt.Sprintf("Bonk! You're trying to execute a NSFW command `%v` in a SFW channel.", cmd)
 }
 
+func NSFWArtwork() string {
+	return "🔞 NSFW artwork"
+}
+
+func NSFWArtworkNotice(url string) string {
+	return fmt.Sprintf("This artwork is NSFW and the channel isn't age-restricted. %v to open it.", ClickHere(url))
+}
+
 func ListChannels(channels []string) string {
 	return strings.Join(
 		arrays.Map(
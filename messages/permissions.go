@@ -0,0 +1,33 @@
+package messages
+
+import "fmt"
+
+func ErrNoPerms() error {
+	return newUserError(NoPerms())
+}
+
+func ErrCommandDenied(command, channelID string) error {
+	return newUserError(fmt.Sprintf("Command `%v` is disabled in <#%v>.", command, channelID))
+}
+
+func ErrUnknownPermissionTarget(target string) error {
+	return newUserError(
+		fmt.Sprintf("`%v` isn't a command or a command group. Please use `bt!help` to view existing commands", target),
+	)
+}
+
+func ErrManagerOnlyCommand(command string) error {
+	return newUserError(fmt.Sprintf("Command `%v` can only be used by server managers, its permissions can't be changed.", command))
+}
+
+func ErrRoleNotFound(role string) error {
+	return newUserError(fmt.Sprintf("Couldn't find role `%v` on this server.", role))
+}
+
+func ErrPermissionsUnchanged(targets []string) error {
+	return newUserError(fmt.Sprintf("Permissions of `%v` are already up to date.", targets))
+}
+
+func PermissionsChanged(action, mention string, targets []string) string {
+	return fmt.Sprintf("Successfully changed permissions of %v. Action: `%v`. Commands: `%v`", mention, action, targets)
+}
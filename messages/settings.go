@@ -10,6 +10,12 @@ func ErrUnknownSetting(setting string) error {
 	return newUserError(fmt.Sprintf("Setting `%v` doesn't exist. Please use `bt!set` to view existing settings", setting))
 }
 
+func ErrUnknownChannelSetting(setting string) error {
+	return newUserError(
+		fmt.Sprintf("Setting `%v` can't be overridden in a channel. Available settings are `[limit, repost, nsfw, reactions, tags, footer]` and artwork providers", setting),
+	)
+}
+
 func ErrParseBool(value string) error {
 	return newUserError(fmt.Sprintf("Failed to parse %v to boolean", value))
 }
@@ -33,10 +39,32 @@ func ErrExpirationOutOfRange(value string) error {
 	)
 }
 
+func ErrDistanceOutOfRange(value string) error {
+	return newUserError(
+		fmt.Sprintf("Distance `%v` is out of range. Image hashes are compared bit by bit, allowed distance is from `0` (identical) to `16`.", value),
+	)
+}
+
 func ErrUnknownRepostOption(option string) error {
 	return newUserError(fmt.Sprintf("Repost option `%v` doesn't exist. Available options are `[enabled, disabled, strict]`", option))
 }
 
+func ErrUnknownRepostScope(scope string) error {
+	return newUserError(fmt.Sprintf("Repost scope `%v` doesn't exist. Available scopes are `[channel, category, guild]`", scope))
+}
+
+func ErrUnknownNSFWMode(mode string) error {
+	return newUserError(fmt.Sprintf("NSFW mode `%v` doesn't exist. Available modes are `[allow, skip, spoiler, link]`", mode))
+}
+
+func ErrRepostHistoryDisabled() error {
+	return newUserError("Repost history is disabled on this instance of Boe Tea.")
+}
+
+func ErrRepostRoleFilter(mention string) error {
+	return newUserError(fmt.Sprintf("Reposts can't be filtered by role %v. Please mention a user instead.", mention))
+}
+
 func ErrForeignChannel(id string) error {
 	return newUserError(
 		fmt.Sprintf("Cannot get channel <#%v>. It's from a foreign server.", id),
@@ -0,0 +1,29 @@
+package messages
+
+import "fmt"
+
+func ErrUnknownFeed(url string) error {
+	return newUserError(
+		fmt.Sprintf("`%v` isn't a supported artist profile. Pixiv, Twitter, ArtStation and DeviantArt profiles can be followed.", url),
+	)
+}
+
+func ErrAlreadyFollowing(url, channelID string) error {
+	return newUserError(fmt.Sprintf("<#%v> already follows %v.", channelID, url))
+}
+
+func ErrNotFollowing(url, channelID string) error {
+	return newUserError(fmt.Sprintf("<#%v> doesn't follow %v.", channelID, url))
+}
+
+func ErrTooManySubscriptions(limit int) error {
+	return newUserError(fmt.Sprintf("Servers can follow up to %v artists. Please unfollow someone first.", limit))
+}
+
+func FollowSuccess(url, channelID string) string {
+	return fmt.Sprintf("Successfully followed %v. New artworks will be posted to <#%v>.", url, channelID)
+}
+
+func UnfollowSuccess(url, channelID string) string {
+	return fmt.Sprintf("Successfully unfollowed %v in <#%v>.", url, channelID)
+}
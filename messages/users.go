@@ -11,6 +11,7 @@ func UserGroupsEmbed(username string) *UserGroups {
 		Group:       "Group",
 		Parent:      "Parent",
 		Children:    "Children",
+		Filter:      "Filter",
 	}
 }
 
@@ -100,3 +101,23 @@ func ErrUserUnfavouriteFail(query interface{}, err error) error {
 		err,
 	)
 }
+
+func ErrUserGroupNotFound(name string) error {
+	return newUserError(fmt.Sprintf(
+		"Group `%v` doesn't exist. Use `bt!groups` to see your groups.", name,
+	))
+}
+
+func ErrUnknownProvider(name string) error {
+	return newUserError(fmt.Sprintf(
+		"Artwork provider `%v` doesn't exist. Please use `bt!set` to see available providers.", name,
+	))
+}
+
+func UserGroupFilterSuccess(name, child, filter string) string {
+	if child == "" {
+		return fmt.Sprintf("Changed a filter of group `%v`:\n%v", name, filter)
+	}
+
+	return fmt.Sprintf("Changed a filter of <#%v> | `%v` in group `%v`:\n%v", child, child, name, filter)
+}
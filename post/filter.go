@@ -0,0 +1,100 @@
+package post
+
+import (
+	"strings"
+
+	"github.com/VTGare/boe-tea-go/artworks"
+	"github.com/VTGare/boe-tea-go/internal/arrays"
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+//crosspostArtwork is an artwork matched by one of post's URLs. Artwork is nil if the provider failed to find it.
+type crosspostArtwork struct {
+	url      string
+	provider artworks.Provider
+	artwork  artworks.Artwork
+	tags     []string
+}
+
+//resolveArtworks finds artworks of post's URLs to evaluate crosspost filters. URLs that aren't matched by any provider are skipped.
+func (p *Post) resolveArtworks() []*crosspostArtwork {
+	resolved := make([]*crosspostArtwork, 0, len(p.urls))
+	for _, url := range p.urls {
+		for _, provider := range p.bot.ArtworkProviders {
+			id, ok := provider.Match(url)
+			if !ok {
+				continue
+			}
+
+			ca := &crosspostArtwork{url: url, provider: provider}
+			artwork, err := p.find(provider, id)
+			if err != nil {
+				p.bot.Log.Infof("Failed to find an artwork %v for crosspost filters: %v", url, err)
+			} else {
+				ca.artwork = artwork
+				ca.tags = arrays.Map(artwork.StoreArtwork().Tags, strings.ToLower)
+			}
+
+			resolved = append(resolved, ca)
+			break
+		}
+	}
+
+	return resolved
+}
+
+//filterURLs returns URLs of artworks that pass both group's filter and child channel's filter.
+func filterURLs(resolved []*crosspostArtwork, group, child *store.CrosspostFilter) []string {
+	urls := make([]string, 0, len(resolved))
+	for _, ca := range resolved {
+		if matchFilter(group, ca) && matchFilter(child, ca) {
+			urls = append(urls, ca.url)
+		}
+	}
+
+	return urls
+}
+
+//matchFilter reports if an artwork passes a crosspost filter. Artworks that couldn't be found only pass filters by provider.
+func matchFilter(f *store.CrosspostFilter, ca *crosspostArtwork) bool {
+	if f.IsZero() {
+		return true
+	}
+
+	if len(f.Providers) > 0 && !arrays.Any(f.Providers, ca.provider.Info().Name) {
+		return false
+	}
+
+	if f.NSFW == nil && len(f.Tags) == 0 && len(f.ExcludeTags) == 0 && f.MinLikes == 0 {
+		return true
+	}
+
+	if ca.artwork == nil {
+		return false
+	}
+
+	if f.NSFW != nil && artworks.IsNSFW(ca.artwork) != *f.NSFW {
+		return false
+	}
+
+	hasTag := func(tag string) bool {
+		return arrays.Any(ca.tags, strings.ToLower(tag))
+	}
+
+	if len(f.Tags) > 0 && !arrays.AnyFunc(f.Tags, hasTag) {
+		return false
+	}
+
+	if arrays.AnyFunc(f.ExcludeTags, hasTag) {
+		return false
+	}
+
+	if f.MinLikes > 0 {
+		likes, ok := artworks.LikeCount(ca.artwork)
+		if !ok || likes < f.MinLikes {
+			return false
+		}
+	}
+
+	return true
+}
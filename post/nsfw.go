@@ -0,0 +1,169 @@
+package post
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/artworks"
+	"github.com/VTGare/boe-tea-go/messages"
+	"github.com/VTGare/boe-tea-go/store"
+	"github.com/VTGare/embeds"
+	"github.com/bwmarrin/discordgo"
+)
+
+//maxSpoilerSize is the maximum size of a spoiler attachment. Discord rejects larger files on servers without boosts.
+const maxSpoilerSize = 8 << 20
+
+//spoilerPrefix marks attachments as spoilers. Discord blurs attachments with the prefix in their names.
+const spoilerPrefix = "SPOILER_"
+
+//spoilerClient downloads images that are sent as spoiler attachments.
+var spoilerClient = &http.Client{Timeout: 15 * time.Second}
+
+//ageRestricted reports if a channel is age-restricted. Threads inherit age restriction from their parent channel.
+func (p *Post) ageRestricted(channelID string) bool {
+	ch, err := p.session.Channel(channelID)
+	if err != nil {
+		p.bot.Log.Infof("Failed to get a channel %v: %v", channelID, err)
+		return false
+	}
+
+	if ch.IsThread() && ch.ParentID != "" {
+		return p.ageRestricted(ch.ParentID)
+	}
+
+	return ch.NSFW
+}
+
+//filterNSFW applies guild's NSFW mode to sends of an NSFW artwork if the channel isn't age-restricted.
+func (p *Post) filterNSFW(guild *store.Guild, channelID string, artwork artworks.Artwork, sends []*discordgo.MessageSend) []*discordgo.MessageSend {
+	mode := guild.CurrentNSFWMode()
+	if !artworks.IsNSFW(artwork) || mode == store.NSFWModeAllow || p.ageRestricted(channelID) {
+		return sends
+	}
+
+	return p.applyNSFWMode(mode, artwork, sends)
+}
+
+//applyNSFWMode changes message sends of an NSFW artwork that's posted to a channel that isn't age-restricted.
+func (p *Post) applyNSFWMode(mode string, artwork artworks.Artwork, sends []*discordgo.MessageSend) []*discordgo.MessageSend {
+	switch mode {
+	case store.NSFWModeAllow:
+		return sends
+	case store.NSFWModeSkip:
+		return nil
+	case store.NSFWModeLink:
+		return []*discordgo.MessageSend{nsfwNotice(artwork.URL())}
+	default:
+		spoilers := make([]*discordgo.MessageSend, 0, len(sends))
+		for _, send := range sends {
+			spoiler, err := spoilerSend(send)
+			if err != nil {
+				p.bot.Log.Infof("Failed to send an artwork %v as a spoiler: %v", artwork.URL(), err)
+				return []*discordgo.MessageSend{nsfwNotice(artwork.URL())}
+			}
+
+			spoilers = append(spoilers, spoiler)
+		}
+
+		return spoilers
+	}
+}
+
+//nsfwNotice creates a link-only message. It keeps artwork's URL in the embed, so reactions and crossposts work as usual.
+func nsfwNotice(url string) *discordgo.MessageSend {
+	eb := embeds.NewBuilder()
+	eb.Title(messages.NSFWArtwork()).URL(url).Description(messages.NSFWArtworkNotice(url))
+
+	return &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{eb.Finalize()}}
+}
+
+//spoilerSend returns a copy of the send with embed images replaced by spoiler attachments. Files that are already attached, like videos and ugoira, are renamed to spoilers.
+//The send itself isn't changed, it may be shared with other channels.
+func spoilerSend(send *discordgo.MessageSend) (*discordgo.MessageSend, error) {
+	spoiler := *send
+	spoiler.Embed = nil
+	spoiler.Embeds = make([]*discordgo.MessageEmbed, 0, len(send.Embeds)+1)
+	spoiler.Files = make([]*discordgo.File, 0, len(send.Files))
+
+	embeds := send.Embeds
+	if send.Embed != nil {
+		embeds = append(embeds[:len(embeds):len(embeds)], send.Embed)
+	}
+
+	for _, file := range send.Files {
+		copied := *file
+		if !strings.HasPrefix(copied.Name, spoilerPrefix) {
+			copied.Name = spoilerPrefix + copied.Name
+		}
+
+		spoiler.Files = append(spoiler.Files, &copied)
+	}
+
+	for _, embed := range embeds {
+		copied := *embed
+		spoiler.Embeds = append(spoiler.Embeds, &copied)
+		if copied.Image == nil || copied.Image.URL == "" {
+			continue
+		}
+
+		//Embeds show attached images without a blur, the attachment itself is a spoiler now.
+		if strings.HasPrefix(copied.Image.URL, "attachment://") {
+			copied.Image = nil
+			continue
+		}
+
+		file, err := downloadSpoiler(copied.Image.URL, len(spoiler.Files))
+		if err != nil {
+			return nil, err
+		}
+
+		copied.Image = nil
+		spoiler.Files = append(spoiler.Files, file)
+	}
+
+	return &spoiler, nil
+}
+
+//downloadSpoiler downloads an image as a spoiler attachment.
+func downloadSpoiler(imageURL string, index int) (*discordgo.File, error) {
+	resp, err := spoilerClient.Get(imageURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %v", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSpoilerSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxSpoilerSize {
+		return nil, fmt.Errorf("image is larger than %v bytes", maxSpoilerSize)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	ext := ".png"
+	if u, err := url.Parse(imageURL); err == nil && path.Ext(u.Path) != "" {
+		ext = path.Ext(u.Path)
+	} else if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+		ext = exts[0]
+	}
+
+	return &discordgo.File{
+		Name:        fmt.Sprintf("%v%v%v", spoilerPrefix, index+1, ext),
+		ContentType: contentType,
+		Reader:      bytes.NewReader(data),
+	}, nil
+}
@@ -3,8 +3,8 @@ package post
 import (
 	"context"
 	"fmt"
-	"reflect"
-	"strconv"
+	"net/http"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -24,6 +24,9 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+//hashClient downloads images for repost detection.
+var hashClient = &http.Client{Timeout: 15 * time.Second}
+
 //SkipMode is an enum that configures what indices are skipped from the send function
 type SkipMode int
 
@@ -37,10 +40,13 @@ const (
 type Post struct {
 	bot       *bot.Bot
 	ctx       *gumi.Ctx
+	session   dgoutils.Session
 	urls      []string
 	indices   map[int]struct{}
 	skipMode  SkipMode
 	crosspost bool
+	//feed is true if artworks are posted from a followed artist's feed and there's no message to reply to.
+	feed bool
 }
 
 type fetchResult struct {
@@ -53,17 +59,57 @@ func New(bot *bot.Bot, ctx *gumi.Ctx, urls ...string) *Post {
 	return &Post{
 		bot:      bot,
 		ctx:      ctx,
+		session:  bot.SessionFor(ctx.Session),
+		urls:     urls,
+		indices:  make(map[int]struct{}),
+		skipMode: SkipModeNone,
+	}
+}
+
+//NewFeed creates a post of new artworks from a followed artist's feed in a guild's channel.
+func NewFeed(bot *bot.Bot, guildID, channelID string, urls ...string) *Post {
+	ctx := &gumi.Ctx{
+		Event: &discordgo.MessageCreate{
+			Message: &discordgo.Message{GuildID: guildID, ChannelID: channelID, Author: &discordgo.User{}},
+		},
+	}
+
+	return &Post{
+		bot:      bot,
+		ctx:      ctx,
+		session:  bot.SessionForGuild(guildID),
 		urls:     urls,
 		indices:  make(map[int]struct{}),
 		skipMode: SkipModeNone,
+		feed:     true,
 	}
 }
 
+//SendFeed sends artworks from a followed artist's feed. Feed artworks are deduplicated by the scheduler,
+//so repost detection is skipped. Provider toggles are ignored, following an artist is an explicit opt-in.
+func (p *Post) SendFeed() ([]*cache.MessageInfo, error) {
+	guild, err := p.bot.Store.Guild(context.Background(), p.ctx.Event.GuildID)
+	if err != nil {
+		return nil, err
+	}
+
+	effective := *guild.Effective(p.ctx.Event.ChannelID)
+	effective.Repost = "disabled"
+
+	res, err := p.fetch(&effective, p.ctx.Event.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.send(&effective, p.ctx.Event.ChannelID, res.Artworks)
+}
+
 func (p *Post) Send() ([]*cache.MessageInfo, error) {
 	guild, err := p.bot.Store.Guild(context.Background(), p.ctx.Event.GuildID)
 	if err != nil {
 		return nil, err
 	}
+	guild = guild.Effective(p.ctx.Event.ChannelID)
 
 	user, err := p.bot.Store.User(context.Background(), p.ctx.Event.Author.ID)
 	if err != nil {
@@ -80,19 +126,24 @@ func (p *Post) Send() ([]*cache.MessageInfo, error) {
 	}
 
 	if len(res.Reposts) > 0 {
+		p.bot.Stats.AddReposts(len(res.Reposts))
 		if guild.Repost == "strict" {
 			perm, _ := dgoutils.MemberHasPermission(
-				p.ctx.Session,
+				p.session,
 				guild.ID,
-				p.ctx.Session.State.User.ID,
+				dgoutils.BotUserID(p.session),
 				discordgo.PermissionAdministrator|discordgo.PermissionManageMessages,
 			)
 
 			if perm && res.Matched == len(res.Reposts) {
-				p.ctx.Session.ChannelMessageDelete(p.ctx.Event.ChannelID, p.ctx.Event.ID)
+				p.session.ChannelMessageDelete(p.ctx.Event.ChannelID, p.ctx.Event.ID)
 			}
 		}
 
+		if p.bot.Config.Repost.History {
+			go p.logReposts(guild, p.ctx.Event.ChannelID, res.Reposts)
+		}
+
 		p.sendReposts(guild, res.Reposts, 15*time.Second)
 	}
 
@@ -109,6 +160,13 @@ func (p *Post) Crosspost(userID, group string, channels []string) ([]*cache.Mess
 		return []*cache.MessageInfo{}, nil
 	}
 
+	//Artworks are only resolved before fetching if the group has filters. Fetch gets them from the artwork cache later.
+	var resolved []*crosspostArtwork
+	g, ok := user.FindGroupByName(group)
+	if ok && (!g.Filter.IsZero() || len(g.ChildFilters) > 0) {
+		resolved = p.resolveArtworks()
+	}
+
 	var (
 		wg      = sync.WaitGroup{}
 		msgChan = make(chan []*cache.MessageInfo, len(channels))
@@ -124,13 +182,13 @@ func (p *Post) Crosspost(userID, group string, channels []string) ([]*cache.Mess
 
 		go func(channelID string) {
 			defer wg.Done()
-			ch, err := p.ctx.Session.Channel(channelID)
+			ch, err := p.session.Channel(channelID)
 			if err != nil {
 				log.Infow("Couldn't crosspost. Error: %v", err)
 				return
 			}
 
-			if _, err := p.ctx.Session.GuildMember(ch.GuildID, userID); err != nil {
+			if _, err := p.session.GuildMember(ch.GuildID, userID); err != nil {
 				log.Infof("Removing a channel from user's group. User left the server.")
 				if _, err := p.bot.Store.DeleteCrosspostChannel(context.Background(), userID, group, channelID); err != nil {
 					log.Errorf("Failed to remove a channel from user's group. Error: %v", err)
@@ -147,14 +205,37 @@ func (p *Post) Crosspost(userID, group string, channels []string) ([]*cache.Mess
 
 			if guild.Crosspost {
 				if len(guild.ArtChannels) == 0 || arrays.Any(guild.ArtChannels, ch.ID) {
-					p.crosspost = true
-					res, err := p.fetch(guild, channelID)
+					guild := guild.Effective(channelID)
+
+					//Every channel gets its own copy, the original post isn't a crosspost.
+					copied := *p
+					copied.crosspost = true
+					post := &copied
+					if resolved != nil {
+						urls := filterURLs(resolved, g.Filter, g.ChildFilters[channelID])
+						if len(urls) == 0 {
+							log.Debugf("Skipping a channel. No artworks match crosspost filters.")
+							return
+						}
+
+						post.urls = urls
+					}
+
+					res, err := post.fetch(guild, channelID)
 					if err != nil {
 						log.Infof("Couldn't crosspost. Fetch error: %v", err)
 						return
 					}
 
-					sent, err := p.send(guild, channelID, res.Artworks)
+					//Crossposted reposts are skipped silently, they're only counted and logged.
+					if len(res.Reposts) > 0 {
+						p.bot.Stats.AddReposts(len(res.Reposts))
+						if p.bot.Config.Repost.History {
+							go post.logReposts(guild, channelID, res.Reposts)
+						}
+					}
+
+					sent, err := post.send(guild, channelID, res.Artworks)
 					if err != nil {
 						log.Infof("Couldn't crosspost. Send error: %v", err)
 						return
@@ -187,21 +268,41 @@ func (p *Post) fetch(guild *store.Guild, channelID string) (*fetchResult, error)
 	var (
 		wg, _        = errgroup.WithContext(context.Background())
 		matched      int64
-		artworksChan = make(chan interface{}, len(p.urls)*2)
+		artworksChan = make(chan interface{}, len(p.urls)*2+len(p.ctx.Event.Attachments))
+		scopeID      = p.repostScope(guild, channelID)
 	)
 
+	//Attachments don't have artwork IDs, they're only checked by image similarity.
+	if guild.Repost != "disabled" && guild.ImageRepost && !p.crosspost {
+		for _, attachment := range p.ctx.Event.Attachments {
+			if !strings.HasPrefix(attachment.ContentType, "image/") {
+				continue
+			}
+
+			attachment := attachment
+			atomic.AddInt64(&matched, 1)
+			wg.Go(func() error {
+				if rep := p.attachmentRepost(guild, channelID, scopeID, attachment); rep != nil {
+					artworksChan <- rep
+				}
+
+				return nil
+			})
+		}
+	}
+
 	for _, url := range p.urls {
 		url := url //shadowing loop variables to pass them to wg.Go. It's required otherwise variables will stay the same every loop.
 
 		wg.Go(func() error {
 			for _, provider := range p.bot.ArtworkProviders {
 				if id, ok := provider.Match(url); ok {
-					p.bot.Log.Infof("Matched a URL: %v. Provider: %v", url, reflect.TypeOf(provider))
+					p.bot.Log.Infof("Matched a URL: %v. Provider: %v", url, provider.Info().Name)
 					atomic.AddInt64(&matched, 1)
 
 					var isRepost bool
 					if guild.Repost != "disabled" {
-						rep, _ := p.bot.RepostDetector.Find(channelID, id)
+						rep, _ := p.bot.RepostDetector.Find(scopeID, id)
 						if rep != nil {
 							artworksChan <- rep
 
@@ -214,47 +315,76 @@ func (p *Post) fetch(guild *store.Guild, channelID string) (*fetchResult, error)
 						}
 					}
 
-					if guild.Repost != "disabled" && !isRepost {
-						err := p.bot.RepostDetector.Create(
-							&repost.Repost{
-								ID:        id,
-								URL:       url,
-								GuildID:   guild.ID,
-								ChannelID: channelID,
-								MessageID: p.ctx.Event.ID,
-							},
-							guild.RepostExpiration,
-						)
+					_, isTwitter := provider.(*twitter.Twitter)
+					// Only post the picture if the provider is enabled
+					// or the function is called from a command
+					// or we're crossposting a twitter artwork.
+					enabled := artworks.Enabled(provider, guild) || p.ctx.Command != nil || p.feed || (p.crosspost && isTwitter)
 
+					var artwork artworks.Artwork
+					if enabled {
+						var err error
+						artwork, err = p.find(provider, id)
 						if err != nil {
-							p.bot.Log.Errorf("error creating a repost: %v", err)
+							return err
 						}
 					}
 
-					_, isTwitter := provider.(*twitter.Twitter)
-					// Only post the picture if the provider is enabled
-					// or the function is called from a command
-					// or we're crossposting a twitter artwork.
-					if provider.Enabled(guild) || p.ctx.Command != nil || (p.crosspost && isTwitter) {
+					if guild.Repost != "disabled" && !isRepost {
 						var (
-							artwork artworks.Artwork
-							key     = fmt.Sprintf("%T:%v", provider, id)
+							hash      uint64
+							hashKey   = fmt.Sprintf("%v:%v:hash", provider.Info().Name, id)
+							hashed    bool
+							needsHash bool
 						)
 
-						if i, ok := p.bot.ArtworkCache.Get(key); ok {
-							artwork = i.(artworks.Artwork)
-						} else {
-							var err error
-							artwork, err = provider.Find(id)
-							if err != nil {
-								return err
+						//Only cached hashes are compared while posting, new artworks are hashed in the background.
+						if guild.ImageRepost && artwork != nil && artwork.Len() > 0 {
+							if h, ok := p.bot.ArtworkCache.Get(hashKey); ok {
+								hash, hashed = h.(uint64), true
+							} else {
+								needsHash = true
+							}
+						}
+
+						if hashed {
+							rep, _ := p.bot.RepostDetector.FindSimilar(scopeID, hash, guild.RepostDistance)
+							if rep != nil {
+								artworksChan <- similarTo(rep, id, url)
+
+								if p.crosspost || guild.Repost == "strict" {
+									return nil
+								}
+
+								isRepost = true
+							}
+						}
+
+						if !isRepost {
+							rep := &repost.Repost{
+								ID:        id,
+								URL:       url,
+								GuildID:   guild.ID,
+								ChannelID: channelID,
+								MessageID: p.ctx.Event.ID,
+								ScopeID:   scopeID,
+								Hash:      hash,
+								Hashed:    hashed,
+							}
+
+							if err := p.bot.RepostDetector.Create(rep, guild.RepostExpiration); err != nil {
+								p.bot.Log.Errorf("error creating a repost: %v", err)
 							}
 
-							p.bot.ArtworkCache.Set(key, artwork, 0)
+							if needsHash {
+								go p.hashRepost(guild, rep, hashKey, artwork)
+							}
 						}
+					}
 
+					if enabled {
 						// Only add reactions to the original message for Twitter links.
-						if guild.Reactions && p.ctx.Command == nil && isTwitter && artwork != nil && artwork.Len() > 0 && !p.crosspost {
+						if guild.Reactions && p.ctx.Command == nil && isTwitter && artwork != nil && artwork.Len() > 0 && !p.crosspost && !p.feed {
 							p.addReactions(p.ctx.Event.Message)
 						}
 
@@ -297,29 +427,196 @@ func (p *Post) fetch(guild *store.Guild, channelID string) (*fetchResult, error)
 	return res, nil
 }
 
+//find returns an artwork from the artwork cache or fetches it from the provider and caches it.
+func (p *Post) find(provider artworks.Provider, id string) (artworks.Artwork, error) {
+	key := fmt.Sprintf("%v:%v", provider.Info().Name, id)
+	i, ok := p.bot.ArtworkCache.Get(key)
+	p.bot.Stats.IncrementCache(ok)
+	if ok {
+		return i.(artworks.Artwork), nil
+	}
+
+	start := time.Now()
+	artwork, err := provider.Find(id)
+	p.bot.Stats.ObserveFetch(provider, time.Since(start), err)
+	if err != nil {
+		return nil, err
+	}
+
+	p.bot.ArtworkCache.Set(key, artwork, 0)
+	return artwork, nil
+}
+
+//repostScope returns an ID of a channel, a category or a guild where reposts are looked up.
+//Threads belong to the category of their parent channel. Channels without a category fall back to channel scope.
+func (p *Post) repostScope(guild *store.Guild, channelID string) string {
+	switch repost.Scope(guild.RepostScope) {
+	case repost.ScopeGuild:
+		return guild.ID
+	case repost.ScopeCategory:
+		ch, err := p.session.Channel(channelID)
+		if err != nil {
+			return channelID
+		}
+
+		if ch.IsThread() {
+			ch, err = p.session.Channel(ch.ParentID)
+			if err != nil {
+				return channelID
+			}
+		}
+
+		if ch.ParentID == "" {
+			return ch.ID
+		}
+
+		return ch.ParentID
+	}
+
+	return channelID
+}
+
+//hashRepost hashes a preview of artwork's first image and saves the repost again with the hash. It runs after the artwork
+//is posted, so a similar repost found by the hash is only reported.
+func (p *Post) hashRepost(guild *store.Guild, rep *repost.Repost, hashKey string, artwork artworks.Artwork) {
+	preview := artworks.PreviewURL(artwork)
+	if preview == "" {
+		return
+	}
+
+	hash, err := repost.HashURL(hashClient, preview)
+	if err != nil {
+		p.bot.Log.Infof("Failed to hash an image %v: %v", preview, err)
+		return
+	}
+
+	p.bot.ArtworkCache.Set(hashKey, hash, 0)
+
+	similar, _ := p.bot.RepostDetector.FindSimilar(rep.ScopeID, hash, guild.RepostDistance)
+	if similar != nil && similar.ID != rep.ID {
+		if p.feed {
+			return
+		}
+
+		p.bot.Stats.AddReposts(1)
+		if p.bot.Config.Repost.History {
+			p.logReposts(guild, rep.ChannelID, []*repost.Repost{similarTo(similar, rep.ID, rep.URL)})
+		}
+
+		if !p.crosspost {
+			p.sendReposts(guild, []*repost.Repost{similar}, 15*time.Second)
+		}
+
+		return
+	}
+
+	rep.Hash, rep.Hashed = hash, true
+	if err := p.bot.RepostDetector.Create(rep, guild.RepostExpiration); err != nil {
+		p.bot.Log.Errorf("error creating a repost: %v", err)
+	}
+}
+
+//attachmentRepost hashes an image attachment and finds a similar repost. If there's none, the attachment is stored as a repost.
+func (p *Post) attachmentRepost(guild *store.Guild, channelID, scopeID string, attachment *discordgo.MessageAttachment) *repost.Repost {
+	hash, err := repost.HashURL(hashClient, attachment.URL)
+	if err != nil {
+		p.bot.Log.Infof("Failed to hash an attachment %v: %v", attachment.URL, err)
+		return nil
+	}
+
+	if rep, _ := p.bot.RepostDetector.FindSimilar(scopeID, hash, guild.RepostDistance); rep != nil {
+		return similarTo(rep, attachment.ID, attachment.URL)
+	}
+
+	err = p.bot.RepostDetector.Create(
+		&repost.Repost{
+			ID:        attachment.ID,
+			URL:       attachment.URL,
+			GuildID:   guild.ID,
+			ChannelID: channelID,
+			MessageID: p.ctx.Event.ID,
+			ScopeID:   scopeID,
+			Hash:      hash,
+			Hashed:    true,
+		},
+		guild.RepostExpiration,
+	)
+
+	if err != nil {
+		p.bot.Log.Errorf("error creating a repost: %v", err)
+	}
+
+	return nil
+}
+
+//similarTo returns a copy of a repost found by image similarity with the new artwork or attachment that's similar to it.
+func similarTo(rep *repost.Repost, id, url string) *repost.Repost {
+	copied := *rep
+	copied.SimilarID, copied.SimilarURL = id, url
+	return &copied
+}
+
+//logReposts saves reposts in a channel to the durable repost log. Crossposted reposts aren't sent, they don't have a message.
+func (p *Post) logReposts(guild *store.Guild, channelID string, reposts []*repost.Repost) {
+	messageID := p.ctx.Event.ID
+	if p.crosspost {
+		messageID = ""
+	}
+
+	for _, rep := range reposts {
+		//Similar reposts are logged with the artwork that was posted, the original one is linked by its message.
+		artworkID, url := rep.ID, rep.URL
+		if rep.Similar && rep.SimilarID != "" {
+			artworkID, url = rep.SimilarID, rep.SimilarURL
+		}
+
+		err := p.bot.Store.AddRepost(context.Background(), &store.Repost{
+			GuildID:           guild.ID,
+			ChannelID:         channelID,
+			MessageID:         messageID,
+			UserID:            p.ctx.Event.Author.ID,
+			ArtworkID:         artworkID,
+			URL:               url,
+			Similar:           rep.Similar,
+			OriginalChannelID: rep.ChannelID,
+			OriginalMessageID: rep.MessageID,
+			CreatedAt:         time.Now(),
+		})
+
+		if err != nil {
+			p.bot.Log.Errorf("error logging a repost: %v", err)
+		}
+	}
+}
+
 func (p *Post) sendReposts(guild *store.Guild, reposts []*repost.Repost, timeout time.Duration) {
 	locale := messages.RepostEmbed()
 
 	eb := embeds.NewBuilder()
 	eb.Title(locale.Title)
 	for ind, rep := range reposts {
+		var similar string
+		if rep.Similar {
+			similar = "\n*" + locale.Similar + "*"
+		}
+
 		eb.AddField(
 			fmt.Sprintf("#%v | %v", ind+1, rep.ID),
 			fmt.Sprintf(
-				"**%v %v**\n**URL:** %v\n\n%v",
+				"**%v %v**\n**URL:** %v%v\n\n%v",
 				locale.Expires, messages.RelativeTimestamp(rep.ExpiresAt),
-				rep.URL,
+				rep.URL, similar,
 				messages.NamedLink(locale.OriginalMessage, fmt.Sprintf("https://discord.com/channels/%v/%v/%v", rep.GuildID, rep.ChannelID, rep.MessageID)),
 			),
 		)
 	}
 
-	msg, _ := p.ctx.Session.ChannelMessageSendEmbed(p.ctx.Event.ChannelID, eb.Finalize())
+	msg, _ := p.session.ChannelMessageSendEmbed(p.ctx.Event.ChannelID, eb.Finalize())
 	if msg != nil {
 		go func() {
 			time.Sleep(timeout)
 
-			p.ctx.Session.ChannelMessageDelete(msg.ChannelID, msg.ID)
+			p.session.ChannelMessageDelete(msg.ChannelID, msg.ID)
 		}()
 	}
 }
@@ -342,12 +639,9 @@ func (p *Post) send(guild *store.Guild, channelID string, artworks []artworks.Ar
 	// It only happens from commands so only first artwork should be affected.
 	allMessages[0] = p.skipArtworks(allMessages[0])
 	sendMessage := func(send *discordgo.MessageSend) {
-		var s *discordgo.Session
+		s := p.session
 		if p.crosspost {
-			guildID, _ := strconv.ParseInt(guild.ID, 10, 64)
-			s = p.bot.ShardManager.SessionForGuild(guildID)
-		} else {
-			s = p.ctx.Session
+			s = p.bot.SessionForGuild(guild.ID)
 		}
 
 		msg, _ := s.ChannelMessageSendComplex(channelID, send)
@@ -399,6 +693,8 @@ func (p *Post) generateMessages(guild *store.Guild, artworks []artworks.Artwork,
 				sends = sends[1:]
 			}
 
+			sends = p.filterNSFW(guild, channelID, artwork, sends)
+
 			for _, msg := range sends {
 				if len(msg.Embeds) == 0 {
 					continue
@@ -411,10 +707,14 @@ func (p *Post) generateMessages(guild *store.Guild, artworks []artworks.Artwork,
 					}
 				} else {
 					msg.AllowedMentions = &discordgo.MessageAllowedMentions{} // disable reference ping.
-					msg.Reference = &discordgo.MessageReference{
-						GuildID:   p.ctx.Event.GuildID,
-						ChannelID: p.ctx.Event.ChannelID,
-						MessageID: p.ctx.Event.ID,
+
+					// Application commands and feeds don't have a message to reply to.
+					if p.ctx.Event.Interaction == nil && !p.feed {
+						msg.Reference = &discordgo.MessageReference{
+							GuildID:   p.ctx.Event.GuildID,
+							ChannelID: p.ctx.Event.ChannelID,
+							MessageID: p.ctx.Event.ID,
+						}
 					}
 				}
 			}
@@ -429,11 +729,11 @@ func (p *Post) generateMessages(guild *store.Guild, artworks []artworks.Artwork,
 }
 
 func (p *Post) addReactions(msg *discordgo.Message) {
-	p.ctx.Session.MessageReactionAdd(
+	p.session.MessageReactionAdd(
 		msg.ChannelID, msg.ID, "💖",
 	)
 
-	p.ctx.Session.MessageReactionAdd(
+	p.session.MessageReactionAdd(
 		msg.ChannelID, msg.ID, "🤤",
 	)
 }
@@ -461,7 +761,7 @@ func (p *Post) skipArtworks(embeds []*discordgo.MessageSend) []*discordgo.Messag
 }
 
 func (p *Post) skipFirst(a artworks.Artwork) bool {
-	if p.ctx.Command != nil {
+	if p.ctx.Command != nil || p.feed {
 		return false
 	}
 
@@ -1,15 +1,27 @@
 package post
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/VTGare/boe-tea-go/artworks"
+	"github.com/VTGare/boe-tea-go/artworks/deviant"
 	"github.com/VTGare/boe-tea-go/artworks/pixiv"
 	"github.com/VTGare/boe-tea-go/artworks/twitter"
+	"github.com/VTGare/boe-tea-go/bot"
+	"github.com/VTGare/boe-tea-go/internal/dgoutils/dgofake"
+	"github.com/VTGare/boe-tea-go/messages"
+	"github.com/VTGare/boe-tea-go/repost"
+	"github.com/VTGare/boe-tea-go/store"
+	"github.com/VTGare/boe-tea-go/store/memory"
 	"github.com/VTGare/gumi"
 	"github.com/bwmarrin/discordgo"
 	twitterscraper "github.com/n0madic/twitter-scraper"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"go.uber.org/zap"
 )
 
 func TestPost(t *testing.T) {
@@ -190,3 +202,320 @@ var _ = Describe("Skip Pages Tests", func() {
 		Expect(result).Should(HaveLen(0))
 	})
 })
+
+var _ = Describe("Repost Scope Tests", func() {
+	var (
+		post  Post
+		guild *store.Guild
+	)
+
+	BeforeEach(func() {
+		s := dgofake.New("bot")
+		s.AddChannel(&discordgo.Channel{ID: "channel", GuildID: "guild", ParentID: "category"})
+		s.AddChannel(&discordgo.Channel{ID: "uncategorized", GuildID: "guild"})
+		s.AddChannel(&discordgo.Channel{ID: "thread", GuildID: "guild", ParentID: "channel", Type: discordgo.ChannelTypeGuildPublicThread})
+		s.AddChannel(&discordgo.Channel{
+			ID:       "uncategorized-thread",
+			GuildID:  "guild",
+			ParentID: "uncategorized",
+			Type:     discordgo.ChannelTypeGuildPublicThread,
+		})
+
+		post = Post{ctx: &gumi.Ctx{}, session: s}
+		guild = store.DefaultGuild("guild")
+	})
+
+	It("should use channel scope by default", func() {
+		Expect(post.repostScope(guild, "channel")).To(Equal("channel"))
+	})
+
+	It("should use guild scope", func() {
+		guild.RepostScope = "guild"
+		Expect(post.repostScope(guild, "channel")).To(Equal("guild"))
+	})
+
+	It("should use channel's category", func() {
+		guild.RepostScope = "category"
+		Expect(post.repostScope(guild, "channel")).To(Equal("category"))
+	})
+
+	It("should use thread parent's category", func() {
+		guild.RepostScope = "category"
+		Expect(post.repostScope(guild, "thread")).To(Equal("category"))
+	})
+
+	It("should fall back to channel scope without a category", func() {
+		guild.RepostScope = "category"
+		Expect(post.repostScope(guild, "uncategorized")).To(Equal("uncategorized"))
+	})
+
+	It("should use thread's own scope by default", func() {
+		Expect(post.repostScope(guild, "thread")).To(Equal("thread"))
+	})
+
+	It("should use thread parent without a category", func() {
+		guild.RepostScope = "category"
+		Expect(post.repostScope(guild, "uncategorized-thread")).To(Equal("uncategorized"))
+	})
+
+	It("should fall back to channel scope if a channel is unknown", func() {
+		guild.RepostScope = "category"
+		Expect(post.repostScope(guild, "unknown")).To(Equal("unknown"))
+	})
+})
+
+var _ = Describe("Repost Log Tests", func() {
+	var (
+		post  Post
+		guild *store.Guild
+		st    store.Store
+	)
+
+	BeforeEach(func() {
+		st = memory.New()
+		post = Post{
+			bot: &bot.Bot{Log: zap.NewNop().Sugar(), Store: st},
+			ctx: &gumi.Ctx{Event: &discordgo.MessageCreate{Message: &discordgo.Message{
+				ID:        "message",
+				ChannelID: "source",
+				Author:    &discordgo.User{ID: "user"},
+			}}},
+		}
+		guild = store.DefaultGuild("guild")
+	})
+
+	reposts := []*repost.Repost{{ID: "1", URL: "https://pixiv.net/en/artworks/1", ChannelID: "original", MessageID: "first"}}
+
+	It("should log reposts with the message", func() {
+		post.logReposts(guild, "source", reposts)
+
+		logged, err := st.ListReposts(context.Background(), "guild", store.RepostFilter{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(logged).To(HaveLen(1))
+		Expect(logged[0].ChannelID).To(Equal("source"))
+		Expect(logged[0].MessageID).To(Equal("message"))
+		Expect(logged[0].OriginalMessageID).To(Equal("first"))
+	})
+
+	It("should log crossposted reposts in the target channel", func() {
+		post.crosspost = true
+		post.logReposts(guild, "target", reposts)
+
+		logged, err := st.ListReposts(context.Background(), "guild", store.RepostFilter{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(logged).To(HaveLen(1))
+		Expect(logged[0].ChannelID).To(Equal("target"))
+		Expect(logged[0].MessageID).To(BeEmpty())
+		Expect(logged[0].UserID).To(Equal("user"))
+	})
+
+	It("should log similar reposts with the reposted artwork", func() {
+		similar := similarTo(&repost.Repost{
+			ID:        "1",
+			URL:       "https://pixiv.net/en/artworks/1",
+			ChannelID: "original",
+			MessageID: "first",
+			Similar:   true,
+		}, "2", "https://twitter.com/i/status/2")
+
+		post.logReposts(guild, "source", []*repost.Repost{similar})
+
+		logged, err := st.ListReposts(context.Background(), "guild", store.RepostFilter{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(logged).To(HaveLen(1))
+		Expect(logged[0].ArtworkID).To(Equal("2"))
+		Expect(logged[0].URL).To(Equal("https://twitter.com/i/status/2"))
+		Expect(logged[0].OriginalChannelID).To(Equal("original"))
+		Expect(logged[0].OriginalMessageID).To(Equal("first"))
+	})
+})
+
+var _ = Describe("NSFW Tests", func() {
+	var (
+		post    Post
+		guild   *store.Guild
+		server  *httptest.Server
+		artwork *deviant.Artwork
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/image.png" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("image"))
+		}))
+
+		s := dgofake.New("bot")
+		s.AddChannel(&discordgo.Channel{ID: "sfw", GuildID: "guild"})
+		s.AddChannel(&discordgo.Channel{ID: "nsfw", GuildID: "guild", NSFW: true})
+		s.AddChannel(&discordgo.Channel{ID: "thread", GuildID: "guild", ParentID: "nsfw", Type: discordgo.ChannelTypeGuildPublicThread})
+
+		post = Post{bot: &bot.Bot{Log: zap.NewNop().Sugar()}, ctx: &gumi.Ctx{}, session: s}
+		guild = store.DefaultGuild("guild")
+		artwork = &deviant.Artwork{
+			Title:    "artwork",
+			Author:   &deviant.Author{Name: "author"},
+			ImageURL: server.URL + "/image.png",
+			NSFW:     true,
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	sends := func(channelID string) []*discordgo.MessageSend {
+		sends, err := artwork.MessageSends("", false)
+		Expect(err).ToNot(HaveOccurred())
+
+		return post.filterNSFW(guild, channelID, artwork, sends)
+	}
+
+	It("should post NSFW artworks to age-restricted channels and threads", func() {
+		Expect(sends("nsfw")[0].Embeds[0].Image).ToNot(BeNil())
+		Expect(sends("thread")[0].Embeds[0].Image).ToNot(BeNil())
+	})
+
+	It("should post safe artworks as usual", func() {
+		artwork.NSFW = false
+		Expect(sends("sfw")[0].Embeds[0].Image).ToNot(BeNil())
+	})
+
+	It("should send images as spoilers by default", func() {
+		result := sends("sfw")
+		Expect(result).To(HaveLen(1))
+		Expect(result[0].Embeds[0].Image).To(BeNil())
+		Expect(result[0].Files).To(HaveLen(1))
+		Expect(result[0].Files[0].Name).To(Equal("SPOILER_1.png"))
+	})
+
+	It("should send a link-only notice if an image can't be downloaded", func() {
+		artwork.ImageURL = server.URL + "/missing.png"
+		result := sends("sfw")
+		Expect(result).To(HaveLen(1))
+		Expect(result[0].Files).To(BeEmpty())
+		Expect(result[0].Embeds[0].URL).To(Equal(artwork.URL()))
+	})
+
+	It("should mark attached files as spoilers", func() {
+		send := &discordgo.MessageSend{
+			Embed: &discordgo.MessageEmbed{Title: "tweet"},
+			Files: []*discordgo.File{{Name: "video.mp4"}},
+		}
+
+		result := post.applyNSFWMode(store.NSFWModeSpoiler, artwork, []*discordgo.MessageSend{send})
+		Expect(result).To(HaveLen(1))
+		Expect(result[0].Embed).To(BeNil())
+		Expect(result[0].Embeds).To(HaveLen(1))
+		Expect(result[0].Files).To(HaveLen(1))
+		Expect(result[0].Files[0].Name).To(Equal("SPOILER_video.mp4"))
+
+		//The original send may be shared with other channels.
+		Expect(send.Embed).ToNot(BeNil())
+		Expect(send.Files[0].Name).To(Equal("video.mp4"))
+	})
+
+	It("should send attached images as spoilers without downloading them", func() {
+		send := &discordgo.MessageSend{
+			Embeds: []*discordgo.MessageEmbed{{Image: &discordgo.MessageEmbedImage{URL: "attachment://1.gif"}}},
+			Files:  []*discordgo.File{{Name: "1.gif"}},
+		}
+
+		result := post.applyNSFWMode(store.NSFWModeSpoiler, artwork, []*discordgo.MessageSend{send})
+		Expect(result).To(HaveLen(1))
+		Expect(result[0].Embeds[0].Image).To(BeNil())
+		Expect(result[0].Files).To(HaveLen(1))
+		Expect(result[0].Files[0].Name).To(Equal("SPOILER_1.gif"))
+	})
+
+	It("should skip NSFW artworks", func() {
+		guild.NSFWMode = store.NSFWModeSkip
+		Expect(sends("sfw")).To(BeEmpty())
+	})
+
+	It("should send a link-only notice", func() {
+		guild.NSFWMode = store.NSFWModeLink
+		result := sends("sfw")
+		Expect(result).To(HaveLen(1))
+		Expect(result[0].Embeds[0].Image).To(BeNil())
+		Expect(result[0].Embeds[0].Title).To(Equal(messages.NSFWArtwork()))
+	})
+
+	It("should post NSFW artworks as usual in guilds without NSFW mode", func() {
+		guild.NSFWMode = ""
+		Expect(sends("sfw")[0].Embeds[0].Image).ToNot(BeNil())
+	})
+
+	It("should allow NSFW artworks everywhere", func() {
+		guild.NSFWMode = store.NSFWModeAllow
+		Expect(sends("sfw")[0].Embeds[0].Image).ToNot(BeNil())
+	})
+})
+
+type filterProvider string
+
+func (filterProvider) Match(string) (string, bool)           { return "", false }
+func (filterProvider) Find(string) (artworks.Artwork, error) { return nil, nil }
+func (p filterProvider) Info() artworks.ProviderInfo         { return artworks.ProviderInfo{Name: string(p)} }
+
+var _ = Describe("Crosspost Filter Tests", func() {
+	var (
+		sfw, nsfw = false, true
+		artwork   *crosspostArtwork
+	)
+
+	BeforeEach(func() {
+		artwork = &crosspostArtwork{
+			url:      "https://deviantart.com/art/1",
+			provider: filterProvider("deviant"),
+			artwork:  &deviant.Artwork{Favourites: 50, NSFW: true},
+			tags:     []string{"maid", "original"},
+		}
+	})
+
+	It("should pass empty filters", func() {
+		Expect(matchFilter(nil, artwork)).To(BeTrue())
+		Expect(matchFilter(&store.CrosspostFilter{}, artwork)).To(BeTrue())
+	})
+
+	It("should filter by provider", func() {
+		Expect(matchFilter(&store.CrosspostFilter{Providers: []string{"pixiv", "deviant"}}, artwork)).To(BeTrue())
+		Expect(matchFilter(&store.CrosspostFilter{Providers: []string{"pixiv"}}, artwork)).To(BeFalse())
+	})
+
+	It("should filter by NSFW", func() {
+		Expect(matchFilter(&store.CrosspostFilter{NSFW: &nsfw}, artwork)).To(BeTrue())
+		Expect(matchFilter(&store.CrosspostFilter{NSFW: &sfw}, artwork)).To(BeFalse())
+	})
+
+	It("should filter by tags ignoring case", func() {
+		Expect(matchFilter(&store.CrosspostFilter{Tags: []string{"swimsuit", "Maid"}}, artwork)).To(BeTrue())
+		Expect(matchFilter(&store.CrosspostFilter{Tags: []string{"swimsuit"}}, artwork)).To(BeFalse())
+		Expect(matchFilter(&store.CrosspostFilter{ExcludeTags: []string{"gore"}}, artwork)).To(BeTrue())
+		Expect(matchFilter(&store.CrosspostFilter{ExcludeTags: []string{"ORIGINAL"}}, artwork)).To(BeFalse())
+	})
+
+	It("should filter by likes", func() {
+		Expect(matchFilter(&store.CrosspostFilter{MinLikes: 50}, artwork)).To(BeTrue())
+		Expect(matchFilter(&store.CrosspostFilter{MinLikes: 51}, artwork)).To(BeFalse())
+	})
+
+	It("should only filter artworks that weren't found by provider", func() {
+		artwork.artwork = nil
+		Expect(matchFilter(&store.CrosspostFilter{Providers: []string{"deviant"}}, artwork)).To(BeTrue())
+		Expect(matchFilter(&store.CrosspostFilter{NSFW: &sfw}, artwork)).To(BeFalse())
+	})
+
+	It("should apply group's and channel's filters", func() {
+		safe := &crosspostArtwork{url: "https://pixiv.net/artworks/1", provider: filterProvider("pixiv"), artwork: &pixiv.Artwork{Likes: 10}}
+		resolved := []*crosspostArtwork{artwork, safe}
+
+		Expect(filterURLs(resolved, nil, nil)).To(HaveLen(2))
+		Expect(filterURLs(resolved, &store.CrosspostFilter{NSFW: &sfw}, nil)).To(Equal([]string{safe.url}))
+		Expect(filterURLs(resolved, &store.CrosspostFilter{NSFW: &sfw}, &store.CrosspostFilter{MinLikes: 100})).To(BeEmpty())
+	})
+})
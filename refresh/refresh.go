@@ -0,0 +1,170 @@
+//Package refresh re-fetches stored artworks from their providers to keep their metadata up to date and to find dead links.
+package refresh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/artworks"
+	"github.com/VTGare/boe-tea-go/bot"
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+//Options configures a Refresher. Zero values are replaced with defaults.
+type Options struct {
+	//Interval is the time between batches. Defaults to 10 minutes.
+	Interval time.Duration
+	//MaxAge is the time since the last update after which an artwork is refreshed. Defaults to a week.
+	MaxAge time.Duration
+	//BatchSize is the maximum number of artworks refreshed per batch. Defaults to 50.
+	BatchSize int64
+	//RateLimit is the minimum time between requests to providers. Defaults to 2 seconds.
+	RateLimit time.Duration
+}
+
+//Refresher periodically re-fetches stale artworks through their providers, updates their metadata and marks artworks
+//deleted from provider's website as removed.
+type Refresher struct {
+	bot  *bot.Bot
+	opts Options
+	now  func() time.Time
+}
+
+func New(b *bot.Bot, opts Options) *Refresher {
+	if opts.Interval <= 0 {
+		opts.Interval = 10 * time.Minute
+	}
+
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = 7 * 24 * time.Hour
+	}
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 50
+	}
+
+	if opts.RateLimit < 0 {
+		opts.RateLimit = 0
+	} else if opts.RateLimit == 0 {
+		opts.RateLimit = 2 * time.Second
+	}
+
+	return &Refresher{
+		bot:  b,
+		opts: opts,
+		now:  time.Now,
+	}
+}
+
+//Run refreshes a batch of stale artworks every interval until the context is cancelled.
+func (r *Refresher) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Refresh(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			r.bot.Log.Errorf("Failed to refresh artworks: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+//Refresh refreshes one batch of stale artworks, least recently updated first. Artworks that can't be fetched
+//because of a provider error are retried after MaxAge, they're not marked as removed.
+func (r *Refresher) Refresh(ctx context.Context) error {
+	stale, err := r.bot.Store.StaleArtworks(ctx, r.now().Add(-r.opts.MaxAge), r.opts.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for ind, artwork := range stale {
+		if ind > 0 {
+			if err := r.wait(ctx); err != nil {
+				return err
+			}
+		}
+
+		if err := r.refresh(ctx, artwork); err != nil {
+			r.bot.Log.With(
+				"artworkID", artwork.ID,
+				"url", artwork.URL,
+			).Infof("Failed to refresh an artwork: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Refresher) wait(ctx context.Context) error {
+	timer := time.NewTimer(r.opts.RateLimit)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+//refresh fetches an artwork from its provider and saves it. Artworks without a provider are saved as is,
+//so they aren't picked up again until MaxAge passes.
+func (r *Refresher) refresh(ctx context.Context, artwork *store.Artwork) error {
+	provider, id, ok := r.match(artwork)
+	if !ok {
+		_, err := r.bot.Store.UpdateArtwork(ctx, artwork)
+		return err
+	}
+
+	found, err := provider.Find(id)
+	switch {
+	case errors.Is(err, artworks.ErrNotFound):
+		artwork.Removed = true
+	case err != nil:
+		if _, err := r.bot.Store.UpdateArtwork(ctx, artwork); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("failed to fetch an artwork from %v: %w", provider.Info().Name, err)
+	case found.Len() == 0:
+		//Some providers return deleted artworks without images instead of an error.
+		artwork.Removed = true
+	default:
+		fresh := found.StoreArtwork()
+
+		artwork.Title = fresh.Title
+		artwork.Author = fresh.Author
+		artwork.Images = fresh.Images
+		artwork.Provider = provider.Info().Name
+		artwork.SourceID = fresh.SourceID
+		artwork.Tags = fresh.Tags
+		artwork.NSFW = fresh.NSFW || artworks.IsNSFW(found)
+		artwork.Removed = false
+	}
+
+	_, err = r.bot.Store.UpdateArtwork(ctx, artwork)
+	return err
+}
+
+//match finds artwork's provider and its ID on provider's website. Artworks saved before providers were stored are matched by URL.
+func (r *Refresher) match(artwork *store.Artwork) (artworks.Provider, string, bool) {
+	if provider, ok := r.bot.Provider(artwork.Provider); ok {
+		id, ok := provider.Match(artwork.URL)
+		return provider, id, ok
+	}
+
+	for _, provider := range r.bot.ArtworkProviders {
+		if id, ok := provider.Match(artwork.URL); ok {
+			return provider, id, true
+		}
+	}
+
+	return nil, "", false
+}
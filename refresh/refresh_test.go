@@ -0,0 +1,242 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/artworks"
+	"github.com/VTGare/boe-tea-go/bot"
+	"github.com/VTGare/boe-tea-go/store"
+	"github.com/VTGare/boe-tea-go/store/memory"
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+)
+
+const artworkPrefix = "https://example.com/artworks/"
+
+//testProvider finds artworks by ID: "deleted" doesn't exist, "empty" has no images and "broken" fails with a server error.
+type testProvider struct {
+	calls []string
+}
+
+func (*testProvider) Match(url string) (string, bool) {
+	id := strings.TrimPrefix(url, artworkPrefix)
+	return id, id != url
+}
+
+func (p *testProvider) Find(id string) (artworks.Artwork, error) {
+	p.calls = append(p.calls, id)
+
+	switch id {
+	case "deleted":
+		return nil, fmt.Errorf("example post %w", artworks.ErrNotFound)
+	case "broken":
+		return nil, errors.New("example returned 500 Internal Server Error")
+	}
+
+	return &testArtwork{id: id}, nil
+}
+
+func (*testProvider) Info() artworks.ProviderInfo {
+	return artworks.ProviderInfo{Name: "example", DisplayName: "Example"}
+}
+
+type testArtwork struct {
+	id string
+}
+
+func (a *testArtwork) StoreArtwork() *store.Artwork {
+	return &store.Artwork{
+		Title:    "refreshed " + a.id,
+		Author:   "author",
+		URL:      a.URL(),
+		Images:   []string{a.URL() + "/new.png"},
+		Provider: "example",
+		SourceID: a.id,
+		Tags:     []string{"maid"},
+	}
+}
+
+func (a *testArtwork) MessageSends(string, bool) ([]*discordgo.MessageSend, error) {
+	return nil, nil
+}
+
+func (a *testArtwork) URL() string { return artworkPrefix + a.id }
+
+func (a *testArtwork) Len() int {
+	if a.id == "empty" {
+		return 0
+	}
+
+	return 1
+}
+
+//newTestRefresher creates a refresher with an in-memory store. Every artwork is stale for the refresher.
+func newTestRefresher(t *testing.T, opts Options) (*Refresher, *testProvider) {
+	t.Helper()
+
+	st := memory.New()
+	if err := st.Init(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := &testProvider{}
+	b := &bot.Bot{
+		Log:              zap.NewNop().Sugar(),
+		Store:            st,
+		ArtworkProviders: []artworks.Provider{provider},
+	}
+
+	if opts.RateLimit == 0 {
+		opts.RateLimit = -1
+	}
+
+	r := New(b, opts)
+	r.now = func() time.Time { return time.Now().Add(r.opts.MaxAge + time.Minute) }
+	return r, provider
+}
+
+func createArtwork(t *testing.T, r *Refresher, url string) *store.Artwork {
+	t.Helper()
+
+	artwork, err := r.bot.Store.CreateArtwork(context.Background(), &store.Artwork{
+		Title:  "original",
+		Author: "author",
+		URL:    url,
+		Images: []string{url + "/old.png"},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return artwork
+}
+
+func TestRefresh(t *testing.T) {
+	r, provider := newTestRefresher(t, Options{})
+
+	ids := make(map[string]int)
+	for _, url := range []string{artworkPrefix + "1", artworkPrefix + "deleted", artworkPrefix + "empty", artworkPrefix + "broken", "https://unknown.com/1"} {
+		ids[url] = createArtwork(t, r, url).ID
+	}
+
+	if err := r.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(provider.calls) != 4 {
+		t.Errorf("Find() called with %v, want 4 calls", provider.calls)
+	}
+
+	tests := []struct {
+		url     string
+		title   string
+		removed bool
+	}{
+		{url: artworkPrefix + "1", title: "refreshed 1"},
+		{url: artworkPrefix + "deleted", title: "original", removed: true},
+		{url: artworkPrefix + "empty", title: "original", removed: true},
+		{url: artworkPrefix + "broken", title: "original"},
+		{url: "https://unknown.com/1", title: "original"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			artwork, err := r.bot.Store.Artwork(context.Background(), ids[tt.url], "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if artwork.Title != tt.title || artwork.Removed != tt.removed {
+				t.Errorf("Artwork() = %+v, want title %q and removed %v", artwork, tt.title, tt.removed)
+			}
+
+			if !artwork.UpdatedAt.After(artwork.CreatedAt) {
+				t.Errorf("Artwork().UpdatedAt = %v, want the artwork updated", artwork.UpdatedAt)
+			}
+		})
+	}
+
+	artwork, err := r.bot.Store.Artwork(context.Background(), ids[artworkPrefix+"1"], "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if artwork.Provider != "example" || artwork.SourceID != "1" || artwork.Images[0] != artworkPrefix+"1/new.png" || len(artwork.Tags) != 1 {
+		t.Errorf("Artwork() = %+v, want metadata from the provider", artwork)
+	}
+
+	//Every artwork was updated just now, the next batch is empty.
+	r.now = time.Now
+	provider.calls = nil
+	if err := r.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(provider.calls) != 0 {
+		t.Errorf("Find() called with %v, want no calls", provider.calls)
+	}
+}
+
+func TestRefresh_BatchSize(t *testing.T) {
+	r, provider := newTestRefresher(t, Options{BatchSize: 2})
+
+	for _, id := range []string{"1", "2", "3"} {
+		createArtwork(t, r, artworkPrefix+id)
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := r.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(provider.calls, " ") != "1 2" {
+		t.Errorf("Find() called with %v, want the 2 least recently updated artworks", provider.calls)
+	}
+}
+
+func TestRefresh_Cancelled(t *testing.T) {
+	r, provider := newTestRefresher(t, Options{RateLimit: time.Hour})
+
+	createArtwork(t, r, artworkPrefix+"1")
+	createArtwork(t, r, artworkPrefix+"2")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := r.Refresh(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Refresh() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if len(provider.calls) != 1 {
+		t.Errorf("Find() called with %v, want 1 call before the rate limit", provider.calls)
+	}
+}
+
+func TestRefresh_Restored(t *testing.T) {
+	r, _ := newTestRefresher(t, Options{})
+
+	artwork := createArtwork(t, r, artworkPrefix+"1")
+	artwork.Removed = true
+	if _, err := r.bot.Store.UpdateArtwork(context.Background(), artwork); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	artwork, err := r.bot.Store.Artwork(context.Background(), artwork.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if artwork.Removed || artwork.Title != "refreshed 1" {
+		t.Errorf("Artwork() = %+v, want a restored artwork", artwork)
+	}
+}
@@ -0,0 +1,128 @@
+package repost
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"math/bits"
+	"net/http"
+
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+//hashWidth and hashHeight are dimensions of a downscaled image. Each row produces 8 bits of a 64-bit hash.
+const (
+	hashWidth  = 9
+	hashHeight = 8
+)
+
+//Limits of images that are hashed. Decoded images take 4 bytes per pixel, larger ones aren't worth the memory.
+const (
+	maxImageSize   = 20 << 20
+	maxImagePixels = 40_000_000
+)
+
+//ErrImageTooLarge is returned by HashURL if an image exceeds size or dimension limits.
+var ErrImageTooLarge = errors.New("image is too large to hash")
+
+//Hash computes a difference hash (dHash) of an image. The image is downscaled to 9x8 grayscale pixels,
+//every bit is set if a pixel is brighter than its right neighbour. Resized and recompressed copies of an image have close hashes.
+func Hash(img image.Image) uint64 {
+	var (
+		bounds = img.Bounds()
+		pixels [hashHeight][hashWidth]float64
+	)
+
+	for y := 0; y < hashHeight; y++ {
+		for x := 0; x < hashWidth; x++ {
+			cell := image.Rect(
+				bounds.Min.X+x*bounds.Dx()/hashWidth,
+				bounds.Min.Y+y*bounds.Dy()/hashHeight,
+				bounds.Min.X+(x+1)*bounds.Dx()/hashWidth,
+				bounds.Min.Y+(y+1)*bounds.Dy()/hashHeight,
+			)
+
+			pixels[y][x] = luminance(img, cell)
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < hashHeight; y++ {
+		for x := 0; x < hashWidth-1; x++ {
+			hash <<= 1
+			if pixels[y][x] > pixels[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+
+	return hash
+}
+
+//Distance returns the Hamming distance between two hashes, the number of different bits.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+//HashURL downloads an image and computes its hash. Supported formats are JPEG, PNG and GIF.
+func HashURL(client *http.Client, url string) (uint64, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to download an image: %v", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return 0, err
+	}
+
+	if len(data) > maxImageSize {
+		return 0, ErrImageTooLarge
+	}
+
+	//Dimensions are checked before decoding, a small file may declare a huge image.
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > maxImagePixels {
+		return 0, ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+
+	return Hash(img), nil
+}
+
+//luminance returns average luminance of a rectangle. Large rectangles are sampled with a stride.
+func luminance(img image.Image, rect image.Rectangle) float64 {
+	if rect.Empty() {
+		rect = image.Rect(rect.Min.X, rect.Min.Y, rect.Min.X+1, rect.Min.Y+1)
+	}
+
+	stepX, stepY := rect.Dx()/32+1, rect.Dy()/32+1
+
+	var sum, count float64
+	for y := rect.Min.Y; y < rect.Max.Y; y += stepY {
+		for x := rect.Min.X; x < rect.Max.X; x += stepX {
+			r, g, b, _ := img.At(x, y).RGBA()
+			sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+			count++
+		}
+	}
+
+	return sum / count
+}
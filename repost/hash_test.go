@@ -0,0 +1,151 @@
+package repost
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+//gradient creates a horizontal gradient image. Reversed gradients have the opposite hash.
+func gradient(width, height int, reversed bool) image.Image {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		v := uint8(x * 255 / width)
+		if reversed {
+			v = 255 - v
+		}
+
+		for y := 0; y < height; y++ {
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+
+	return img
+}
+
+func TestHash(t *testing.T) {
+	var (
+		original = Hash(gradient(900, 800, true))
+		resized  = Hash(gradient(90, 80, true))
+		reversed = Hash(gradient(900, 800, false))
+	)
+
+	if d := Distance(original, resized); d > 2 {
+		t.Errorf("Distance(original, resized) = %v, want at most 2", d)
+	}
+
+	if d := Distance(original, reversed); d < 32 {
+		t.Errorf("Distance(original, reversed) = %v, want at least 32", d)
+	}
+}
+
+func TestInMemory_FindSimilar(t *testing.T) {
+	rd := NewMemory()
+	defer rd.Close()
+
+	rep := &Repost{ID: "1", ChannelID: "channel", ScopeID: "channel", Hash: 0b1111, Hashed: true}
+	for _, r := range []*Repost{
+		rep,
+		{ID: "1", ChannelID: "zero", ScopeID: "zero", Hashed: true},
+		{ID: "1", ChannelID: "unhashed", ScopeID: "unhashed"},
+	} {
+		if err := rd.Create(r, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		scope    string
+		hash     uint64
+		distance int
+		err      error
+	}{
+		{name: "identical", scope: "channel", hash: 0b1111, distance: 0},
+		{name: "within distance", scope: "channel", hash: 0b0011, distance: 2},
+		{name: "too far", scope: "channel", hash: 0b0001, distance: 2, err: ErrNotFound},
+		{name: "other scope", scope: "other", hash: 0b1111, distance: 2, err: ErrNotFound},
+		{name: "zero hash", scope: "zero", hash: 0, distance: 0},
+		{name: "unhashed", scope: "unhashed", hash: 0, distance: 0, err: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := rd.FindSimilar(tt.scope, tt.hash, tt.distance)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("FindSimilar() error = %v, want %v", err, tt.err)
+			}
+
+			if tt.err == nil && (found.ID != rep.ID || !found.Similar) {
+				t.Fatalf("FindSimilar() = %+v, want a similar repost %v", found, rep.ID)
+			}
+		})
+	}
+}
+
+//hugePNG encodes a 1x1 PNG and changes its header to declare a huge image.
+func hugePNG(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, gradient(1, 1, false)); err != nil {
+		t.Fatal(err)
+	}
+
+	//IHDR chunk follows the 8-byte signature: length, type, width, height, ..., CRC of type and data.
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data[16:], 100000)
+	binary.BigEndian.PutUint32(data[20:], 100000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+
+	return data
+}
+
+func TestHashURL(t *testing.T) {
+	var small bytes.Buffer
+	if err := png.Encode(&small, gradient(90, 80, true)); err != nil {
+		t.Fatal(err)
+	}
+
+	huge := hugePNG(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/small.png":
+			w.Write(small.Bytes())
+		case "/huge.png":
+			w.Write(huge)
+		case "/large.png":
+			w.Write(make([]byte, maxImageSize+1))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	hash, err := HashURL(server.Client(), server.URL+"/small.png")
+	if err != nil {
+		t.Fatalf("HashURL(small) error = %v", err)
+	}
+
+	if want := Hash(gradient(90, 80, true)); hash != want {
+		t.Errorf("HashURL(small) = %b, want %b", hash, want)
+	}
+
+	for _, path := range []string{"/huge.png", "/large.png"} {
+		if _, err := HashURL(server.Client(), server.URL+path); !errors.Is(err, ErrImageTooLarge) {
+			t.Errorf("HashURL(%v) error = %v, want %v", path, err, ErrImageTooLarge)
+		}
+	}
+
+	if _, err := HashURL(server.Client(), server.URL+"/missing.png"); err == nil {
+		t.Error("HashURL(missing) error = nil, want an error")
+	}
+}
@@ -2,6 +2,7 @@ package repost
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/ReneKroon/ttlcache"
@@ -9,21 +10,57 @@ import (
 
 type inMemory struct {
 	cache *ttlcache.Cache
+
+	//hashes indexes image hashes of reposts by scope ID and cache key.
+	mu     *sync.Mutex
+	hashes map[string]map[string]uint64
 }
 
 func NewMemory() Detector {
-	return &inMemory{cache: ttlcache.NewCache()}
+	rd := &inMemory{
+		cache:  ttlcache.NewCache(),
+		mu:     &sync.Mutex{},
+		hashes: make(map[string]map[string]uint64),
+	}
+
+	rd.cache.SetExpirationCallback(func(key string, value interface{}) {
+		rd.mu.Lock()
+		defer rd.mu.Unlock()
+
+		rep := value.(*Repost)
+		if scope, ok := rd.hashes[rep.ScopeID]; ok {
+			delete(scope, key)
+			if len(scope) == 0 {
+				delete(rd.hashes, rep.ScopeID)
+			}
+		}
+	})
+
+	return rd
 }
 
 func (rd inMemory) Create(rep *Repost, ttl time.Duration) error {
 	rep.ExpiresAt = time.Now().Add(ttl)
 	rd.cache.SetWithTTL(rd.key(rep), rep, ttl)
 
+	if rep.Hashed {
+		rd.mu.Lock()
+		defer rd.mu.Unlock()
+
+		scope, ok := rd.hashes[rep.ScopeID]
+		if !ok {
+			scope = make(map[string]uint64)
+			rd.hashes[rep.ScopeID] = scope
+		}
+
+		scope[rd.key(rep)] = rep.Hash
+	}
+
 	return nil
 }
 
-func (rd inMemory) Find(channelID, artworkID string) (*Repost, error) {
-	rep, ok := rd.cache.Get(fmt.Sprintf("%v:%v", channelID, artworkID))
+func (rd inMemory) Find(scopeID, artworkID string) (*Repost, error) {
+	rep, ok := rd.cache.Get(fmt.Sprintf("%v:%v", scopeID, artworkID))
 	if !ok {
 		return nil, ErrNotFound
 	}
@@ -31,8 +68,37 @@ func (rd inMemory) Find(channelID, artworkID string) (*Repost, error) {
 	return rep.(*Repost), nil
 }
 
+func (rd inMemory) FindSimilar(scopeID string, hash uint64, distance int) (*Repost, error) {
+	rd.mu.Lock()
+	var (
+		closest string
+		best    = distance + 1
+	)
+
+	for key, h := range rd.hashes[scopeID] {
+		if d := Distance(hash, h); d < best {
+			closest, best = key, d
+		}
+	}
+	rd.mu.Unlock()
+
+	if closest == "" {
+		return nil, ErrNotFound
+	}
+
+	rep, ok := rd.cache.Get(closest)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	similar := *rep.(*Repost)
+	similar.Similar = true
+
+	return &similar, nil
+}
+
 func (rd inMemory) key(rep *Repost) string {
-	return fmt.Sprintf("%v:%v", rep.ChannelID, rep.ID)
+	return fmt.Sprintf("%v:%v", rep.ScopeID, rep.ID)
 }
 
 func (rd inMemory) Close() error {
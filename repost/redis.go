@@ -3,6 +3,8 @@ package repost
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -26,13 +28,13 @@ func NewRedis(addr string) (Detector, error) {
 	return &redisDetector{client}, nil
 }
 
-func (rd redisDetector) Find(channelID, artworkID string) (*Repost, error) {
+func (rd redisDetector) Find(scopeID, artworkID string) (*Repost, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	var (
 		rep Repost
-		key = fmt.Sprintf("channel:%v:artwork:%v", channelID, artworkID)
+		key = rd.key(scopeID, artworkID)
 		ttl time.Duration
 	)
 
@@ -41,6 +43,15 @@ func (rd redisDetector) Find(channelID, artworkID string) (*Repost, error) {
 		return nil, err
 	}
 
+	//Reposts created before scopes were introduced are keyed by channel, channel scopes still match them until they expire.
+	if exists == 0 {
+		key = rd.legacyKey(scopeID, artworkID)
+		exists, err = rd.client.Exists(ctx, key).Result()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if exists == 0 {
 		return nil, ErrNotFound
 	}
@@ -63,6 +74,10 @@ func (rd redisDetector) Find(channelID, artworkID string) (*Repost, error) {
 		return nil, err
 	}
 
+	if rep.ScopeID == "" {
+		rep.ScopeID = scopeID
+	}
+
 	rep.ExpiresAt = time.Now().Add(ttl)
 	return &rep, nil
 }
@@ -71,7 +86,7 @@ func (rd redisDetector) Create(repost *Repost, duration time.Duration) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	key := fmt.Sprintf("channel:%v:artwork:%v", repost.ChannelID, repost.ID)
+	key := rd.key(repost.ScopeID, repost.ID)
 	_, err := rd.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
 		if _, err := rd.client.HSet(ctx, key, map[string]interface{}{
 			"id":         repost.ID,
@@ -79,6 +94,9 @@ func (rd redisDetector) Create(repost *Repost, duration time.Duration) error {
 			"guild_id":   repost.GuildID,
 			"channel_id": repost.ChannelID,
 			"message_id": repost.MessageID,
+			"scope_id":   repost.ScopeID,
+			"hash":       repost.Hash,
+			"hashed":     repost.Hashed,
 		}).Result(); err != nil {
 			return err
 		}
@@ -87,6 +105,23 @@ func (rd redisDetector) Create(repost *Repost, duration time.Duration) error {
 			return err
 		}
 
+		if !repost.Hashed {
+			return nil
+		}
+
+		//Hashes are stored in a sorted set scored by expiration time, expired members are removed on lookup.
+		hashes := rd.hashesKey(repost.ScopeID)
+		if _, err := rd.client.ZAdd(ctx, hashes, &redis.Z{
+			Score:  float64(time.Now().Add(duration).Unix()),
+			Member: fmt.Sprintf("%v:%v", repost.Hash, repost.ID),
+		}).Result(); err != nil {
+			return err
+		}
+
+		if _, err := rd.client.ExpireAt(ctx, hashes, time.Now().Add(duration)).Result(); err != nil {
+			return err
+		}
+
 		return nil
 	})
 
@@ -97,6 +132,67 @@ func (rd redisDetector) Create(repost *Repost, duration time.Duration) error {
 	return nil
 }
 
+func (rd redisDetector) FindSimilar(scopeID string, hash uint64, distance int) (*Repost, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	key := rd.hashesKey(scopeID)
+	if _, err := rd.client.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(time.Now().Unix(), 10)).Result(); err != nil {
+		return nil, err
+	}
+
+	members, err := rd.client.ZRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		closest string
+		best    = distance + 1
+	)
+
+	for _, member := range members {
+		h, id, ok := strings.Cut(member, ":")
+		if !ok {
+			continue
+		}
+
+		parsed, err := strconv.ParseUint(h, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		if d := Distance(hash, parsed); d < best {
+			closest, best = id, d
+		}
+	}
+
+	if closest == "" {
+		return nil, ErrNotFound
+	}
+
+	rep, err := rd.Find(scopeID, closest)
+	if err != nil {
+		return nil, err
+	}
+
+	rep.Similar = true
+	return rep, nil
+}
+
+func (rd redisDetector) key(scopeID, artworkID string) string {
+	return fmt.Sprintf("scope:%v:artwork:%v", scopeID, artworkID)
+}
+
+//legacyKey is a key of reposts created before scopes were introduced.
+func (rd redisDetector) legacyKey(channelID, artworkID string) string {
+	return fmt.Sprintf("channel:%v:artwork:%v", channelID, artworkID)
+}
+
+func (rd redisDetector) hashesKey(scopeID string) string {
+	return fmt.Sprintf("scope:%v:hashes", scopeID)
+}
+
 func (rd redisDetector) Close() error {
 	return rd.client.Close()
 }
@@ -0,0 +1,73 @@
+package repost
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+//TestRedis_LegacyKeys runs against a Redis server from BOETEA_TEST_REDIS environment variable, e.g. "localhost:6379".
+func TestRedis_LegacyKeys(t *testing.T) {
+	addr := os.Getenv("BOETEA_TEST_REDIS")
+	if addr == "" {
+		t.Skip("BOETEA_TEST_REDIS is not set")
+	}
+
+	detector, err := NewRedis(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer detector.Close()
+
+	rd := detector.(*redisDetector)
+	ctx := context.Background()
+
+	//Keys are unique per run, so the test doesn't depend on the state of the database.
+	channelID := "channel-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	key := rd.legacyKey(channelID, "1")
+	if err := rd.client.HSet(ctx, key, map[string]interface{}{
+		"id":         "1",
+		"url":        "https://pixiv.net/en/artworks/1",
+		"guild_id":   "guild",
+		"channel_id": channelID,
+		"message_id": "message",
+	}).Err(); err != nil {
+		t.Fatal(err)
+	}
+	defer rd.client.Del(ctx, key)
+
+	if err := rd.client.Expire(ctx, key, time.Minute).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	rep, err := rd.Find(channelID, "1")
+	if err != nil {
+		t.Fatalf("Find() = %v, want a legacy repost", err)
+	}
+
+	if rep.ChannelID != channelID || rep.ScopeID != channelID || rep.ExpiresAt.Before(time.Now()) {
+		t.Errorf("Find() = %+v, want a legacy repost in channel scope", rep)
+	}
+
+	if _, err := rd.Find("guild", "1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Find(guild) = %v, want %v", err, ErrNotFound)
+	}
+
+	//New reposts take precedence over legacy ones.
+	if err := rd.Create(&Repost{ID: "1", ChannelID: channelID, MessageID: "new", ScopeID: channelID}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	defer rd.client.Del(ctx, rd.key(channelID, "1"))
+
+	rep, err = rd.Find(channelID, "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rep.MessageID != "new" {
+		t.Errorf("Find() = %+v, want the new repost", rep)
+	}
+}
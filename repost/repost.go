@@ -9,8 +9,21 @@ var (
 	ErrNotFound = errors.New("repost not found")
 )
 
+//Scope is a guild setting that configures where reposts are looked up.
+type Scope string
+
+//Scope enum
+const (
+	ScopeChannel  Scope = "channel"
+	ScopeCategory Scope = "category"
+	ScopeGuild    Scope = "guild"
+)
+
+//Detector stores reposts by scope ID, an ID of a channel, a category or a guild depending on guild's scope.
 type Detector interface {
-	Find(channelID string, artworkID string) (*Repost, error)
+	Find(scopeID string, artworkID string) (*Repost, error)
+	//FindSimilar finds a repost with the closest image hash within the maximum Hamming distance.
+	FindSimilar(scopeID string, hash uint64, distance int) (*Repost, error)
 	Create(*Repost, time.Duration) error
 	Close() error
 }
@@ -21,5 +34,15 @@ type Repost struct {
 	GuildID   string `redis:"guild_id"`
 	ChannelID string `redis:"channel_id"`
 	MessageID string `redis:"message_id"`
+	//ScopeID is where the repost is detected. It's a channel ID, a category ID or a guild ID.
+	ScopeID string `redis:"scope_id"`
+	//Hash is a perceptual hash of the first image. It's only valid if Hashed is true, zero is a valid hash.
+	Hash      uint64 `redis:"hash"`
+	Hashed    bool   `redis:"hashed"`
 	ExpiresAt time.Time
+	//Similar is true if the repost was found by image similarity rather than artwork ID.
+	Similar bool
+	//SimilarID and SimilarURL identify the new artwork or attachment that's similar to the repost. They're set by callers of FindSimilar.
+	SimilarID  string
+	SimilarURL string
 }
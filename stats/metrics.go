@@ -0,0 +1,228 @@
+package stats
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/artworks"
+	"go.uber.org/atomic"
+)
+
+//ContentType is the content type of Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+//latencyBuckets are upper bounds of provider fetch latency histogram buckets in seconds.
+var latencyBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+//Provider stores artwork fetch metrics of a provider.
+type Provider struct {
+	Fetches  *atomic.Int64
+	Failures *atomic.Int64
+	Latency  *Histogram
+}
+
+//Histogram counts observations in cumulative buckets like Prometheus histograms.
+type Histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []int64
+	count   int64
+	sum     float64
+}
+
+//Gauge is a metric that's collected when metrics are written, e.g. guild count.
+type Gauge struct {
+	Name   string
+	Help   string
+	Labels map[string]string
+	Value  float64
+}
+
+func newProvider() *Provider {
+	return &Provider{
+		Fetches:  atomic.NewInt64(0),
+		Failures: atomic.NewInt64(0),
+		Latency:  NewHistogram(latencyBuckets),
+	}
+}
+
+//NewHistogram creates a histogram with sorted bucket upper bounds.
+func NewHistogram(buckets []float64) *Histogram {
+	return &Histogram{
+		buckets: buckets,
+		counts:  make([]int64, len(buckets)),
+	}
+}
+
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ind, bound := range h.buckets {
+		if v <= bound {
+			h.counts[ind]++
+		}
+	}
+
+	h.count++
+	h.sum += v
+}
+
+//IncrementCommandError counts a command that failed with an unexpected error.
+func (m *Stats) IncrementCommandError(cmd string) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	count, ok := m.CommandErrors[cmd]
+	if !ok {
+		count = atomic.NewInt64(0)
+		m.CommandErrors[cmd] = count
+	}
+
+	count.Add(1)
+}
+
+//ObserveFetch records an artwork request to a provider, its latency and if it failed.
+func (m *Stats) ObserveFetch(provider artworks.Provider, latency time.Duration, err error) {
+	m.mut.Lock()
+	name := provider.Info().DisplayName
+	p, ok := m.Providers[name]
+	if !ok {
+		p = newProvider()
+		m.Providers[name] = p
+	}
+	m.mut.Unlock()
+
+	p.Fetches.Add(1)
+	if err != nil {
+		p.Failures.Add(1)
+	}
+
+	p.Latency.Observe(latency.Seconds())
+}
+
+//AddReposts counts detected reposts.
+func (m *Stats) AddReposts(n int) {
+	m.Reposts.Add(int64(n))
+}
+
+//IncrementCache counts a lookup in the artwork cache.
+func (m *Stats) IncrementCache(hit bool) {
+	if hit {
+		m.CacheHits.Add(1)
+	} else {
+		m.CacheMisses.Add(1)
+	}
+}
+
+//WriteMetrics writes all metrics and additional gauges in Prometheus text exposition format.
+func (m *Stats) WriteMetrics(w io.Writer, gauges ...Gauge) error {
+	bw := bufio.NewWriter(w)
+
+	m.mut.RLock()
+	writeCounters(bw, "boetea_commands_total", "Executed commands.", "command", m.Commands)
+	writeCounters(bw, "boetea_command_errors_total", "Commands that failed with an unexpected error.", "command", m.CommandErrors)
+	writeCounters(bw, "boetea_artworks_total", "Artworks posted by provider.", "provider", m.Artworks)
+
+	providers := make(map[string]*Provider, len(m.Providers))
+	for name, p := range m.Providers {
+		providers[name] = p
+	}
+	m.mut.RUnlock()
+
+	fetches := make(map[string]*atomic.Int64, len(providers))
+	failures := make(map[string]*atomic.Int64, len(providers))
+	for name, p := range providers {
+		fetches[name] = p.Fetches
+		failures[name] = p.Failures
+	}
+
+	writeCounters(bw, "boetea_provider_fetches_total", "Artwork requests to providers.", "provider", fetches)
+	writeCounters(bw, "boetea_provider_failures_total", "Failed artwork requests to providers.", "provider", failures)
+	writeHistograms(bw, "boetea_provider_fetch_duration_seconds", "Latency of artwork requests to providers.", "provider", providers)
+
+	writeHeader(bw, "boetea_reposts_total", "Detected reposts.", "counter")
+	fmt.Fprintf(bw, "boetea_reposts_total %v\n", m.Reposts.Load())
+
+	writeHeader(bw, "boetea_artwork_cache_requests_total", "Artwork cache lookups by result.", "counter")
+	fmt.Fprintf(bw, "boetea_artwork_cache_requests_total{result=\"hit\"} %v\n", m.CacheHits.Load())
+	fmt.Fprintf(bw, "boetea_artwork_cache_requests_total{result=\"miss\"} %v\n", m.CacheMisses.Load())
+
+	written := make(map[string]bool)
+	for _, g := range gauges {
+		if !written[g.Name] {
+			writeHeader(bw, g.Name, g.Help, "gauge")
+			written[g.Name] = true
+		}
+
+		fmt.Fprintf(bw, "%v%v %v\n", g.Name, labels(g.Labels), formatFloat(g.Value))
+	}
+
+	return bw.Flush()
+}
+
+func writeHeader(w io.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v %v\n", name, help, name, kind)
+}
+
+func writeCounters(w io.Writer, name, help, label string, counters map[string]*atomic.Int64) {
+	writeHeader(w, name, help, "counter")
+	for _, key := range sortedKeys(counters) {
+		fmt.Fprintf(w, "%v%v %v\n", name, labels(map[string]string{label: key}), counters[key].Load())
+	}
+}
+
+func writeHistograms(w io.Writer, name, help, label string, providers map[string]*Provider) {
+	writeHeader(w, name, help, "histogram")
+	for _, key := range sortedKeys(providers) {
+		h := providers[key].Latency
+
+		h.mu.Lock()
+		for ind, bound := range h.buckets {
+			fmt.Fprintf(w, "%v_bucket%v %v\n", name, labels(map[string]string{label: key, "le": formatFloat(bound)}), h.counts[ind])
+		}
+
+		fmt.Fprintf(w, "%v_bucket%v %v\n", name, labels(map[string]string{label: key, "le": "+Inf"}), h.count)
+		fmt.Fprintf(w, "%v_sum%v %v\n", name, labels(map[string]string{label: key}), formatFloat(h.sum))
+		fmt.Fprintf(w, "%v_count%v %v\n", name, labels(map[string]string{label: key}), h.count)
+		h.mu.Unlock()
+	}
+}
+
+//labels formats a label set sorted by label name. Empty string is returned for an empty set.
+func labels(m map[string]string) string {
+	if len(m) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(m))
+	for _, name := range sortedKeys(m) {
+		pairs = append(pairs, fmt.Sprintf("%v=\"%v\"", name, escapeLabel(m[name])))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,91 @@
+package stats
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/artworks"
+	"github.com/VTGare/gumi"
+)
+
+type testProvider struct {
+	artworks.Provider
+}
+
+func (testProvider) Info() artworks.ProviderInfo {
+	return artworks.ProviderInfo{Name: "example", DisplayName: "Example"}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	s := New(&gumi.Router{Commands: map[string]*gumi.Command{"set": {Name: "set"}}}, []artworks.Provider{testProvider{}})
+
+	s.IncrementCommand("set")
+	s.IncrementCommand("set")
+	s.IncrementCommandError("set")
+	s.IncrementArtwork(testProvider{})
+	s.ObserveFetch(testProvider{}, 300*time.Millisecond, nil)
+	s.ObserveFetch(testProvider{}, 20*time.Second, errors.New("timeout"))
+	s.AddReposts(3)
+	s.IncrementCache(true)
+	s.IncrementCache(false)
+	s.IncrementCache(false)
+
+	var buf bytes.Buffer
+	err := s.WriteMetrics(&buf,
+		Gauge{Name: "boetea_shard_latency_seconds", Help: "Heartbeat latency of a shard.", Labels: map[string]string{"shard": "0"}, Value: 0.05},
+		Gauge{Name: "boetea_shard_latency_seconds", Help: "Heartbeat latency of a shard.", Labels: map[string]string{"shard": "1"}, Value: 0.1},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"# TYPE boetea_commands_total counter\nboetea_commands_total{command=\"set\"} 2\n",
+		"boetea_command_errors_total{command=\"set\"} 1\n",
+		"boetea_artworks_total{provider=\"Example\"} 1\n",
+		"boetea_provider_fetches_total{provider=\"Example\"} 2\n",
+		"boetea_provider_failures_total{provider=\"Example\"} 1\n",
+		"# TYPE boetea_provider_fetch_duration_seconds histogram\n",
+		"boetea_provider_fetch_duration_seconds_bucket{le=\"0.25\",provider=\"Example\"} 0\n",
+		"boetea_provider_fetch_duration_seconds_bucket{le=\"0.5\",provider=\"Example\"} 1\n",
+		"boetea_provider_fetch_duration_seconds_bucket{le=\"30\",provider=\"Example\"} 2\n",
+		"boetea_provider_fetch_duration_seconds_bucket{le=\"+Inf\",provider=\"Example\"} 2\n",
+		"boetea_provider_fetch_duration_seconds_count{provider=\"Example\"} 2\n",
+		"boetea_reposts_total 3\n",
+		"boetea_artwork_cache_requests_total{result=\"hit\"} 1\n",
+		"boetea_artwork_cache_requests_total{result=\"miss\"} 2\n",
+		"boetea_shard_latency_seconds{shard=\"0\"} 0.05\nboetea_shard_latency_seconds{shard=\"1\"} 0.1\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("WriteMetrics() output doesn't contain %q\n%v", want, out)
+		}
+	}
+
+	if n := strings.Count(out, "# TYPE boetea_shard_latency_seconds gauge"); n != 1 {
+		t.Errorf("WriteMetrics() wrote gauge header %v times, want 1", n)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   string
+	}{
+		{name: "empty", want: ""},
+		{name: "sorted", labels: map[string]string{"b": "2", "a": "1"}, want: `{a="1",b="2"}`},
+		{name: "escaped", labels: map[string]string{"a": "say \"hi\"\n\\"}, want: `{a="say \"hi\"\n\\"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := labels(tt.labels); got != tt.want {
+				t.Errorf("labels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
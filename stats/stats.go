@@ -1,9 +1,7 @@
 package stats
 
 import (
-	"reflect"
 	"sort"
-	"strings"
 	"sync"
 
 	"github.com/VTGare/boe-tea-go/artworks"
@@ -15,6 +13,14 @@ type Stats struct {
 	Commands map[string]*atomic.Int64
 	Artworks map[string]*atomic.Int64
 
+	//CommandErrors count commands that failed with an unexpected error by command name.
+	CommandErrors map[string]*atomic.Int64
+	//Providers store artwork fetch metrics by provider's display name.
+	Providers   map[string]*Provider
+	Reposts     *atomic.Int64
+	CacheHits   *atomic.Int64
+	CacheMisses *atomic.Int64
+
 	mut sync.RWMutex
 }
 
@@ -25,8 +31,13 @@ type Item struct {
 
 func New(router *gumi.Router, providers []artworks.Provider) *Stats {
 	stats := &Stats{
-		Commands: map[string]*atomic.Int64{},
-		Artworks: map[string]*atomic.Int64{},
+		Commands:      map[string]*atomic.Int64{},
+		Artworks:      map[string]*atomic.Int64{},
+		CommandErrors: map[string]*atomic.Int64{},
+		Providers:     map[string]*Provider{},
+		Reposts:       atomic.NewInt64(0),
+		CacheHits:     atomic.NewInt64(0),
+		CacheMisses:   atomic.NewInt64(0),
 	}
 
 	for _, cmd := range router.Commands {
@@ -34,9 +45,8 @@ func New(router *gumi.Router, providers []artworks.Provider) *Stats {
 	}
 
 	for _, provider := range providers {
-		t := reflect.TypeOf(provider).String()
-		t = strings.Split(t, ".")[1]
-		stats.Artworks[t] = atomic.NewInt64(0)
+		stats.Artworks[provider.Info().DisplayName] = atomic.NewInt64(0)
+		stats.Providers[provider.Info().DisplayName] = newProvider()
 	}
 
 	return stats
@@ -59,13 +69,12 @@ func (m *Stats) IncrementArtwork(provider artworks.Provider) {
 	m.mut.Lock()
 	defer m.mut.Unlock()
 
-	t := reflect.TypeOf(provider).String()
-	t = strings.Split(t, ".")[1]
+	name := provider.Info().DisplayName
 
-	count, ok := m.Artworks[t]
+	count, ok := m.Artworks[name]
 	if !ok {
 		count = atomic.NewInt64(0)
-		m.Commands[t] = count
+		m.Artworks[name] = count
 	}
 
 	count.Add(1)
@@ -9,8 +9,18 @@ type ArtworkStore interface {
 	Artwork(ctx context.Context, id int, url string) (*Artwork, error)
 	CreateArtwork(context.Context, *Artwork) (*Artwork, error)
 	SearchArtworks(context.Context, ArtworkFilter, ...ArtworkSearchOptions) ([]*Artwork, error)
+	//ArtworkFacets counts artworks matching a filter. Every facet has up to limit most common values.
+	ArtworkFacets(ctx context.Context, filter ArtworkFilter, limit int) (*ArtworkFacets, error)
+	//UpdateArtwork saves metadata refreshed from artwork's provider and sets UpdatedAt. Favourites count isn't changed.
+	UpdateArtwork(ctx context.Context, artwork *Artwork) (*Artwork, error)
+	//StaleArtworks returns up to limit artworks that weren't updated since before, least recently updated first.
+	//Removed artworks are returned too, so artworks that were restored on provider's website are found again.
+	StaleArtworks(ctx context.Context, before time.Time, limit int64) ([]*Artwork, error)
 }
 
+//Artwork is an artwork posted with Boe Tea. Provider is a name of the artwork provider, e.g. pixiv, and SourceID is an artwork ID
+//on provider's website. They're empty for artworks saved before they were stored. Removed is true if the artwork was deleted
+//from provider's website, its images are likely broken.
 type Artwork struct {
 	ID         int       `json:"id" bson:"artwork_id"`
 	Title      string    `json:"title" bson:"title"`
@@ -18,6 +28,11 @@ type Artwork struct {
 	URL        string    `json:"url" bson:"url"`
 	Images     []string  `json:"images" bson:"images"`
 	Favourites int       `json:"favourites" bson:"favourites"`
+	Provider   string    `json:"provider,omitempty" bson:"provider,omitempty"`
+	SourceID   string    `json:"source_id,omitempty" bson:"source_id,omitempty"`
+	Tags       []string  `json:"tags,omitempty" bson:"tags,omitempty"`
+	NSFW       bool      `json:"nsfw,omitempty" bson:"nsfw,omitempty"`
+	Removed    bool      `json:"removed,omitempty" bson:"removed,omitempty"`
 	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at" bson:"updated_at"`
 }
@@ -51,13 +66,36 @@ type ArtworkSearchOptions struct {
 	Sort  ArtworkSort
 }
 
+//ArtworkFilter filters artworks. IDs take precedence over URL, URL takes precedence over all other fields.
 type ArtworkFilter struct {
 	IDs    []int  `query:"id"`
 	Title  string `query:"title"`
 	Author string `query:"author"`
-	Query  string `query:"query"`
-	URL    string `query:"url"`
-	Time   time.Duration
+	//Query is a free text search in titles, authors and tags. Every word must be a case-insensitive substring of the author,
+	//the title or one of the tags, e.g. "swim" matches a swimsuit tag.
+	Query    string   `query:"query"`
+	URL      string   `query:"url"`
+	Provider string   `query:"provider"`
+	Tags     []string `query:"tag"`
+	//NSFW filters artworks by rating if it's not nil. Artworks without a rating are considered safe.
+	NSFW *bool `query:"nsfw"`
+	Time time.Duration
+}
+
+//ArtworkFacets are counts of artworks matching a filter.
+type ArtworkFacets struct {
+	Total int64
+	NSFW  int64
+	//Providers, Authors and Tags are the most common values sorted by count. Empty values aren't counted.
+	Providers []*Facet
+	Authors   []*Facet
+	Tags      []*Facet
+}
+
+//Facet is a number of artworks with a field value.
+type Facet struct {
+	Value string `bson:"_id"`
+	Count int64  `bson:"count"`
 }
 
 func DefaultSearchOptions() ArtworkSearchOptions {
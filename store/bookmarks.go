@@ -2,23 +2,50 @@ package store
 
 import (
 	"context"
+	"fmt"
 	"time"
+
+	"github.com/VTGare/boe-tea-go/internal/arrays"
 )
 
+var ErrBookmarkNotFound = fmt.Errorf("bookmark %w", ErrNotFound)
+
 type BookmarkStore interface {
-	ListBookmarks(ctx context.Context, userID string, filter BookmarkFilter, order Order) ([]*Bookmark, error)
+	ListBookmarks(ctx context.Context, userID string, query BookmarkQuery, order Order) ([]*Bookmark, error)
 	CountBookmarks(ctx context.Context, userID string) (int64, error)
+	//Bookmark returns a bookmark of an artwork. ErrBookmarkNotFound is returned if the user didn't bookmark it.
+	Bookmark(ctx context.Context, userID string, artworkID int) (*Bookmark, error)
 	AddBookmark(ctx context.Context, fav *Bookmark) (bool, error)
+	//UpdateBookmark saves collection and tags of a bookmark.
+	UpdateBookmark(ctx context.Context, fav *Bookmark) error
 	DeleteBookmark(ctx context.Context, fav *Bookmark) (bool, error)
+
+	//CreateCollection creates a bookmark collection. It returns false if the user already has a collection with the same name.
+	CreateCollection(ctx context.Context, col *Collection) (bool, error)
+	//DeleteCollection deletes a collection. Its bookmarks aren't deleted, they're moved out of the collection.
+	DeleteCollection(ctx context.Context, userID, name string) (bool, error)
+	//ListCollections returns collections of a user sorted by name.
+	ListCollections(ctx context.Context, userID string) ([]*Collection, error)
 }
 
 type Bookmark struct {
-	UserID    string    `json:"user_id,omitempty" bson:"user_id"`
-	ArtworkID int       `json:"artwork_id,omitempty" bson:"artwork_id"`
-	NSFW      bool      `json:"nsfw,omitempty" bson:"nsfw"`
+	UserID    string `json:"user_id,omitempty" bson:"user_id"`
+	ArtworkID int    `json:"artwork_id,omitempty" bson:"artwork_id"`
+	NSFW      bool   `json:"nsfw,omitempty" bson:"nsfw"`
+	//Collection is a name of user's collection. Empty if the bookmark isn't in a collection.
+	Collection string `json:"collection,omitempty" bson:"collection,omitempty"`
+	//Tags are user-defined lowercase tags.
+	Tags      []string  `json:"tags,omitempty" bson:"tags,omitempty"`
 	CreatedAt time.Time `json:"created_at,omitempty" bson:"created_at"`
 }
 
+//Collection is a named folder of user's bookmarks.
+type Collection struct {
+	UserID    string    `json:"user_id" bson:"user_id"`
+	Name      string    `json:"name" bson:"name"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
 type BookmarkFilter int
 
 const (
@@ -26,3 +53,31 @@ const (
 	BookmarkFilterSafe
 	BookmarkFilterUnsafe
 )
+
+//BookmarkQuery filters listed bookmarks. Zero value matches all bookmarks.
+type BookmarkQuery struct {
+	Filter BookmarkFilter
+	//Collection matches bookmarks in a collection.
+	Collection string
+	//Tags matches bookmarks that have all of the tags.
+	Tags []string
+}
+
+//Match reports if a bookmark matches the query.
+func (q BookmarkQuery) Match(bookmark *Bookmark) bool {
+	if q.Filter != BookmarkFilterAll && bookmark.NSFW != (q.Filter == BookmarkFilterUnsafe) {
+		return false
+	}
+
+	if q.Collection != "" && bookmark.Collection != q.Collection {
+		return false
+	}
+
+	for _, tag := range q.Tags {
+		if !arrays.Any(bookmark.Tags, tag) {
+			return false
+		}
+	}
+
+	return true
+}
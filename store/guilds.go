@@ -5,6 +5,17 @@ import (
 	"time"
 )
 
+//DefaultRepostDistance is the default maximum Hamming distance between image hashes of a repost.
+const DefaultRepostDistance = 6
+
+//NSFW modes define how NSFW artworks are posted to channels that aren't age-restricted.
+const (
+	NSFWModeAllow   = "allow"
+	NSFWModeSkip    = "skip"
+	NSFWModeSpoiler = "spoiler"
+	NSFWModeLink    = "link"
+)
+
 type GuildStore interface {
 	Guild(ctx context.Context, guildID string) (*Guild, error)
 	CreateGuild(ctx context.Context, guildID string) (*Guild, error)
@@ -17,10 +28,8 @@ type Guild struct {
 	ID     string `json:"id" bson:"guild_id" validate:"required"`
 	Prefix string `json:"prefix" bson:"prefix" validate:"required,max=5"`
 
-	Pixiv      bool `json:"pixiv" bson:"pixiv"`
-	Twitter    bool `json:"twitter" bson:"twitter"`
-	Deviant    bool `json:"deviant" bson:"deviant"`
-	Artstation bool `json:"artstation" bson:"artstation"`
+	//Providers stores artwork provider toggles by provider name. Providers without a toggle use their default.
+	Providers map[string]bool `json:"providers" bson:"providers"`
 
 	Tags        bool `json:"tags" bson:"tags"`
 	FlavourText bool `json:"flavour_text" bson:"flavour_text"`
@@ -30,9 +39,22 @@ type Guild struct {
 
 	Repost           string        `json:"repost" bson:"repost" validate:"required"`
 	RepostExpiration time.Duration `json:"repost_expiration" bson:"repost_expiration"`
+	//RepostScope is where reposts are looked up: "channel", "category" or "guild".
+	RepostScope string `json:"repost_scope" bson:"repost_scope"`
+	//ImageRepost enables repost detection by perceptual image hashes within RepostDistance bits.
+	ImageRepost    bool `json:"image_repost" bson:"image_repost"`
+	RepostDistance int  `json:"repost_distance" bson:"repost_distance"`
 
 	ArtChannels []string `json:"art_channels" bson:"art_channels" validate:"required"`
 	NSFW        bool     `json:"nsfw" bson:"nsfw"`
+	//NSFWMode is one of NSFW modes. Use CurrentNSFWMode to read it, guilds created before NSFW modes don't have one.
+	NSFWMode string `json:"nsfw_mode" bson:"nsfw_mode"`
+
+	//Channels stores per-channel setting overrides by channel ID.
+	Channels map[string]*ChannelSettings `json:"channels,omitempty" bson:"channels,omitempty"`
+
+	//Permissions grant commands to roles and deny commands in channels.
+	Permissions Permissions `json:"permissions" bson:"permissions"`
 
 	CreatedAt time.Time `json:"created_at" bson:"created_at" validate:"required"`
 	UpdatedAt time.Time `json:"updated_at" bson:"updated_at"`
@@ -44,13 +66,14 @@ func DefaultGuild(id string) *Guild {
 		Prefix:           "bt!",
 		Limit:            10,
 		NSFW:             true,
-		Pixiv:            true,
-		Twitter:          true,
-		Deviant:          true,
+		NSFWMode:         NSFWModeSpoiler,
+		Providers:        make(map[string]bool),
 		Tags:             true,
 		FlavourText:      true,
 		Repost:           "enabled",
 		RepostExpiration: 24 * time.Hour,
+		RepostScope:      "channel",
+		RepostDistance:   DefaultRepostDistance,
 		Crosspost:        true,
 		Reactions:        true,
 		ArtChannels:      make([]string, 0),
@@ -65,14 +88,132 @@ func UserGuild() *Guild {
 		Prefix:           "bt!",
 		Limit:            100,
 		NSFW:             true,
-		Pixiv:            true,
-		Twitter:          true,
-		Deviant:          true,
+		NSFWMode:         NSFWModeAllow,
+		Providers:        make(map[string]bool),
 		Tags:             true,
 		FlavourText:      true,
 		Repost:           "disabled",
 		RepostExpiration: 24 * time.Hour,
+		RepostScope:      "channel",
+		RepostDistance:   DefaultRepostDistance,
 		Crosspost:        true,
 		Reactions:        true,
 	}
 }
+
+//CurrentNSFWMode returns guild's NSFW mode. Guilds without a mode keep posting NSFW artworks as before, like NSFWModeAllow.
+func (g *Guild) CurrentNSFWMode() string {
+	if g.NSFWMode == "" {
+		return NSFWModeAllow
+	}
+
+	return g.NSFWMode
+}
+
+//ProviderEnabled reports if an artwork provider is enabled. If guild doesn't have a toggle for the provider, def is returned.
+func (g *Guild) ProviderEnabled(name string, def bool) bool {
+	if enabled, ok := g.Providers[name]; ok {
+		return enabled
+	}
+
+	return def
+}
+
+//SetProvider enables or disables an artwork provider.
+func (g *Guild) SetProvider(name string, enabled bool) {
+	if g.Providers == nil {
+		g.Providers = make(map[string]bool)
+	}
+
+	g.Providers[name] = enabled
+}
+
+//ChannelSettings overrides guild settings in a single channel. Nil fields and missing provider toggles fall through to the guild.
+type ChannelSettings struct {
+	Limit       *int            `json:"limit,omitempty" bson:"limit,omitempty"`
+	Tags        *bool           `json:"tags,omitempty" bson:"tags,omitempty"`
+	FlavourText *bool           `json:"flavour_text,omitempty" bson:"flavour_text,omitempty"`
+	Reactions   *bool           `json:"reactions,omitempty" bson:"reactions,omitempty"`
+	NSFW        *bool           `json:"nsfw,omitempty" bson:"nsfw,omitempty"`
+	Repost      *string         `json:"repost,omitempty" bson:"repost,omitempty"`
+	Providers   map[string]bool `json:"providers,omitempty" bson:"providers,omitempty"`
+}
+
+//Empty reports if channel settings don't override anything.
+func (c *ChannelSettings) Empty() bool {
+	return c == nil || (c.Limit == nil && c.Tags == nil && c.FlavourText == nil &&
+		c.Reactions == nil && c.NSFW == nil && c.Repost == nil && len(c.Providers) == 0)
+}
+
+//Channel returns setting overrides of a channel. The result is never nil, modifications aren't saved until SetChannel is called.
+func (g *Guild) Channel(channelID string) *ChannelSettings {
+	if ch, ok := g.Channels[channelID]; ok && ch != nil {
+		copied := *ch
+		copied.Providers = make(map[string]bool, len(ch.Providers))
+		for name, enabled := range ch.Providers {
+			copied.Providers[name] = enabled
+		}
+
+		return &copied
+	}
+
+	return &ChannelSettings{Providers: make(map[string]bool)}
+}
+
+//SetChannel replaces setting overrides of a channel. Empty overrides are removed.
+func (g *Guild) SetChannel(channelID string, settings *ChannelSettings) {
+	if settings.Empty() {
+		delete(g.Channels, channelID)
+		return
+	}
+
+	if g.Channels == nil {
+		g.Channels = make(map[string]*ChannelSettings)
+	}
+
+	g.Channels[channelID] = settings
+}
+
+//Effective returns a copy of guild settings with channel overrides applied. If the channel has no overrides, the guild itself is returned.
+func (g *Guild) Effective(channelID string) *Guild {
+	ch, ok := g.Channels[channelID]
+	if !ok || ch.Empty() {
+		return g
+	}
+
+	eff := *g
+	if ch.Limit != nil {
+		eff.Limit = *ch.Limit
+	}
+
+	if ch.Tags != nil {
+		eff.Tags = *ch.Tags
+	}
+
+	if ch.FlavourText != nil {
+		eff.FlavourText = *ch.FlavourText
+	}
+
+	if ch.Reactions != nil {
+		eff.Reactions = *ch.Reactions
+	}
+
+	if ch.NSFW != nil {
+		eff.NSFW = *ch.NSFW
+	}
+
+	if ch.Repost != nil {
+		eff.Repost = *ch.Repost
+	}
+
+	eff.Providers = make(map[string]bool, len(g.Providers)+len(ch.Providers))
+	for name, enabled := range g.Providers {
+		eff.Providers[name] = enabled
+	}
+
+	for name, enabled := range ch.Providers {
+		eff.Providers[name] = enabled
+	}
+
+	return &eff
+}
@@ -0,0 +1,97 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+func TestGuildEffective(t *testing.T) {
+	guild := store.DefaultGuild("guild")
+	guild.SetProvider("pixiv", false)
+
+	limit, repost := 1, "strict"
+	guild.SetChannel("art", &store.ChannelSettings{
+		Limit:     &limit,
+		Repost:    &repost,
+		Providers: map[string]bool{"pixiv": true, "twitter": false},
+	})
+
+	eff := guild.Effective("art")
+	if eff.Limit != 1 || eff.Repost != "strict" {
+		t.Errorf("Effective() limit = %v, repost = %v, want 1 and strict", eff.Limit, eff.Repost)
+	}
+
+	if !eff.ProviderEnabled("pixiv", false) || eff.ProviderEnabled("twitter", true) {
+		t.Errorf("Effective() providers = %v, want pixiv enabled and twitter disabled", eff.Providers)
+	}
+
+	if eff.Tags != guild.Tags || eff.NSFW != guild.NSFW || eff.ID != guild.ID {
+		t.Errorf("Effective() = %+v, want inherited settings", eff)
+	}
+
+	if guild.Limit != 10 || guild.ProviderEnabled("pixiv", true) {
+		t.Errorf("Effective() modified guild settings: %+v", guild)
+	}
+
+	if got := guild.Effective("other"); got != guild {
+		t.Errorf("Effective(other) = %+v, want guild itself", got)
+	}
+}
+
+func TestGuildChannel(t *testing.T) {
+	guild := store.DefaultGuild("guild")
+
+	ch := guild.Channel("art")
+	if !ch.Empty() || ch.Providers == nil {
+		t.Fatalf("Channel() = %+v, want empty overrides", ch)
+	}
+
+	ch.Providers["twitter"] = false
+	if len(guild.Channels) != 0 {
+		t.Errorf("Channel() modification was saved without SetChannel")
+	}
+
+	guild.SetChannel("art", ch)
+	guild.Channel("art").Providers["twitter"] = true
+	if guild.Effective("art").ProviderEnabled("twitter", true) {
+		t.Errorf("Channel() returned overrides that share providers with the guild")
+	}
+
+	guild.SetChannel("art", &store.ChannelSettings{})
+	if _, ok := guild.Channels["art"]; ok {
+		t.Errorf("SetChannel(empty) didn't remove overrides")
+	}
+}
+
+func TestGuildProviderEnabled(t *testing.T) {
+	guild := store.DefaultGuild("guild")
+	guild.SetProvider("pixiv", false)
+	guild.SetProvider("twitter", true)
+
+	tests := []struct {
+		name     string
+		provider string
+		def      bool
+		want     bool
+	}{
+		{name: "disabled", provider: "pixiv", def: true, want: false},
+		{name: "enabled", provider: "twitter", def: false, want: true},
+		{name: "default enabled", provider: "deviant", def: true, want: true},
+		{name: "default disabled", provider: "danbooru", def: false, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := guild.ProviderEnabled(tt.provider, tt.def); got != tt.want {
+				t.Errorf("ProviderEnabled(%v, %v) = %v, want %v", tt.provider, tt.def, got, tt.want)
+			}
+		})
+	}
+
+	//Guilds decoded without the providers map use defaults.
+	legacy := &store.Guild{}
+	if !legacy.ProviderEnabled("pixiv", true) || legacy.ProviderEnabled("pixiv", false) {
+		t.Errorf("ProviderEnabled() of a guild without providers doesn't use defaults")
+	}
+}
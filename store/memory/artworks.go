@@ -0,0 +1,244 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/internal/arrays"
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+func (m *memoryStore) Artwork(_ context.Context, id int, url string) (*store.Artwork, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, artwork := range m.artworks {
+		if (id == 0 || artwork.ID == id) && (url == "" || artwork.URL == url) {
+			return cloneArtwork(artwork), nil
+		}
+	}
+
+	return nil, store.ErrArtworkNotFound
+}
+
+//CreateArtwork inserts an artwork with an auto-incremented ID.
+func (m *memoryStore) CreateArtwork(_ context.Context, artwork *store.Artwork) (*store.Artwork, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.counter++
+	artwork.ID = m.counter
+	artwork.CreatedAt = time.Now()
+	artwork.UpdatedAt = time.Now()
+
+	m.artworks = append(m.artworks, cloneArtwork(artwork))
+	return artwork, nil
+}
+
+func (m *memoryStore) UpdateArtwork(_ context.Context, artwork *store.Artwork) (*store.Artwork, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for ind, existing := range m.artworks {
+		if existing.ID != artwork.ID {
+			continue
+		}
+
+		updated := cloneArtwork(artwork)
+		updated.Favourites = existing.Favourites
+		updated.CreatedAt = existing.CreatedAt
+		updated.UpdatedAt = time.Now()
+
+		m.artworks[ind] = updated
+		return cloneArtwork(updated), nil
+	}
+
+	return nil, store.ErrArtworkNotFound
+}
+
+func (m *memoryStore) StaleArtworks(_ context.Context, before time.Time, limit int64) ([]*store.Artwork, error) {
+	m.mu.RLock()
+	artworks := make([]*store.Artwork, 0)
+	for _, artwork := range m.artworks {
+		if artwork.UpdatedAt.Before(before) {
+			artworks = append(artworks, cloneArtwork(artwork))
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.SliceStable(artworks, func(i, j int) bool {
+		return artworks[i].UpdatedAt.Before(artworks[j].UpdatedAt)
+	})
+
+	if int64(len(artworks)) > limit {
+		artworks = artworks[:limit]
+	}
+
+	return artworks, nil
+}
+
+//SearchArtworks filters artworks the same way Mongo store does. Zero limit means no limit.
+func (m *memoryStore) SearchArtworks(_ context.Context, filter store.ArtworkFilter, opts ...store.ArtworkSearchOptions) ([]*store.Artwork, error) {
+	opt := store.DefaultSearchOptions()
+	if len(opts) != 0 {
+		opt = opts[0]
+	}
+
+	m.mu.RLock()
+	artworks := make([]*store.Artwork, 0)
+	for _, artwork := range m.artworks {
+		if matchArtwork(artwork, filter) {
+			artworks = append(artworks, cloneArtwork(artwork))
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.SliceStable(artworks, func(i, j int) bool {
+		a, b := artworks[i], artworks[j]
+		if opt.Order == store.Ascending {
+			a, b = b, a
+		}
+
+		switch opt.Sort {
+		case store.ByFavourites:
+			if a.Favourites != b.Favourites {
+				return a.Favourites > b.Favourites
+			}
+		case store.ByTime:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.After(b.CreatedAt)
+			}
+		}
+
+		return a.ID > b.ID
+	})
+
+	if opt.Skip >= int64(len(artworks)) {
+		return make([]*store.Artwork, 0), nil
+	}
+
+	artworks = artworks[opt.Skip:]
+	if opt.Limit > 0 && opt.Limit < int64(len(artworks)) {
+		artworks = artworks[:opt.Limit]
+	}
+
+	return artworks, nil
+}
+
+func (m *memoryStore) ArtworkFacets(_ context.Context, filter store.ArtworkFilter, limit int) (*store.ArtworkFacets, error) {
+	var (
+		facets    = &store.ArtworkFacets{}
+		providers = make(map[string]int64)
+		authors   = make(map[string]int64)
+		tags      = make(map[string]int64)
+	)
+
+	m.mu.RLock()
+	for _, artwork := range m.artworks {
+		if !matchArtwork(artwork, filter) {
+			continue
+		}
+
+		facets.Total++
+		if artwork.NSFW {
+			facets.NSFW++
+		}
+
+		if artwork.Provider != "" {
+			providers[artwork.Provider]++
+		}
+
+		if artwork.Author != "" {
+			authors[artwork.Author]++
+		}
+
+		for _, tag := range artwork.Tags {
+			tags[tag]++
+		}
+	}
+	m.mu.RUnlock()
+
+	facets.Providers = topFacets(providers, limit)
+	facets.Authors = topFacets(authors, limit)
+	facets.Tags = topFacets(tags, limit)
+	return facets, nil
+}
+
+//topFacets returns up to limit values with the highest counts. Values with the same count are sorted alphabetically.
+func topFacets(counts map[string]int64, limit int) []*store.Facet {
+	facets := make([]*store.Facet, 0, len(counts))
+	for value, count := range counts {
+		facets = append(facets, &store.Facet{Value: value, Count: count})
+	}
+
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+
+		return facets[i].Value < facets[j].Value
+	})
+
+	if len(facets) > limit {
+		facets = facets[:limit]
+	}
+
+	return facets
+}
+
+func matchArtwork(artwork *store.Artwork, f store.ArtworkFilter) bool {
+	contains := func(s, substr string) bool {
+		return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+	}
+
+	switch {
+	case len(f.IDs) != 0:
+		return arrays.Any(f.IDs, artwork.ID)
+	case f.URL != "":
+		return artwork.URL == f.URL
+	default:
+		for _, word := range strings.Fields(f.Query) {
+			matchTag := func(tag string) bool { return contains(tag, word) }
+			if !contains(artwork.Author, word) && !contains(artwork.Title, word) && !arrays.AnyFunc(artwork.Tags, matchTag) {
+				return false
+			}
+		}
+
+		if f.Author != "" && !contains(artwork.Author, f.Author) {
+			return false
+		}
+
+		if f.Title != "" && !contains(artwork.Title, f.Title) {
+			return false
+		}
+
+		if f.Time != 0 && artwork.CreatedAt.Before(time.Now().Add(-f.Time)) {
+			return false
+		}
+
+		if f.Provider != "" && artwork.Provider != f.Provider {
+			return false
+		}
+
+		for _, tag := range f.Tags {
+			if !arrays.Any(artwork.Tags, tag) {
+				return false
+			}
+		}
+
+		if f.NSFW != nil && artwork.NSFW != *f.NSFW {
+			return false
+		}
+
+		return true
+	}
+}
+
+func cloneArtwork(artwork *store.Artwork) *store.Artwork {
+	var a store.Artwork
+	clone(artwork, &a)
+
+	return &a
+}
@@ -0,0 +1,201 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+func (m *memoryStore) ListBookmarks(_ context.Context, userID string, query store.BookmarkQuery, order store.Order) ([]*store.Bookmark, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	bookmarks := make([]*store.Bookmark, 0)
+	for _, bookmark := range m.bookmarks {
+		if bookmark.UserID != userID {
+			continue
+		}
+
+		if !query.Match(bookmark) {
+			continue
+		}
+
+		bookmarks = append(bookmarks, copyBookmark(bookmark))
+	}
+
+	sort.SliceStable(bookmarks, func(i, j int) bool {
+		if order == store.Ascending {
+			return bookmarks[i].CreatedAt.Before(bookmarks[j].CreatedAt)
+		}
+
+		return bookmarks[i].CreatedAt.After(bookmarks[j].CreatedAt)
+	})
+
+	return bookmarks, nil
+}
+
+func (m *memoryStore) CountBookmarks(_ context.Context, userID string) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var count int64
+	for _, bookmark := range m.bookmarks {
+		if bookmark.UserID == userID {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+//AddBookmark adds a bookmark and increments artwork's favourites. It returns false if the bookmark already exists.
+func (m *memoryStore) AddBookmark(_ context.Context, bookmark *store.Bookmark) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.bookmarkIndex(bookmark) != -1 {
+		return false, nil
+	}
+
+	m.bookmarks = append(m.bookmarks, copyBookmark(bookmark))
+	m.incFavourites(bookmark.ArtworkID, 1)
+
+	return true, nil
+}
+
+func (m *memoryStore) Bookmark(_ context.Context, userID string, artworkID int) (*store.Bookmark, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	i := m.bookmarkIndex(&store.Bookmark{UserID: userID, ArtworkID: artworkID})
+	if i == -1 {
+		return nil, store.ErrBookmarkNotFound
+	}
+
+	return copyBookmark(m.bookmarks[i]), nil
+}
+
+func (m *memoryStore) UpdateBookmark(_ context.Context, bookmark *store.Bookmark) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.bookmarkIndex(bookmark)
+	if i == -1 {
+		return store.ErrBookmarkNotFound
+	}
+
+	updated := copyBookmark(bookmark)
+	m.bookmarks[i].Collection = updated.Collection
+	m.bookmarks[i].Tags = updated.Tags
+
+	return nil
+}
+
+//DeleteBookmark deletes a bookmark and decrements artwork's favourites. It returns false if the bookmark doesn't exist.
+func (m *memoryStore) DeleteBookmark(_ context.Context, bookmark *store.Bookmark) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.bookmarkIndex(bookmark)
+	if i == -1 {
+		return false, nil
+	}
+
+	m.bookmarks = append(m.bookmarks[:i], m.bookmarks[i+1:]...)
+	m.incFavourites(bookmark.ArtworkID, -1)
+
+	return true, nil
+}
+
+func (m *memoryStore) bookmarkIndex(bookmark *store.Bookmark) int {
+	for i, b := range m.bookmarks {
+		if b.UserID == bookmark.UserID && b.ArtworkID == bookmark.ArtworkID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (m *memoryStore) incFavourites(artworkID, n int) {
+	for _, artwork := range m.artworks {
+		if artwork.ID == artworkID {
+			artwork.Favourites += n
+			return
+		}
+	}
+}
+
+//CreateCollection creates a collection. It returns false if the user already has a collection with the same name.
+func (m *memoryStore) CreateCollection(_ context.Context, col *store.Collection) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.collectionIndex(col.UserID, col.Name) != -1 {
+		return false, nil
+	}
+
+	c := *col
+	m.collections = append(m.collections, &c)
+	return true, nil
+}
+
+//DeleteCollection deletes a collection and moves its bookmarks out of it. It returns false if the collection doesn't exist.
+func (m *memoryStore) DeleteCollection(_ context.Context, userID, name string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.collectionIndex(userID, name)
+	if i == -1 {
+		return false, nil
+	}
+
+	m.collections = append(m.collections[:i], m.collections[i+1:]...)
+	for _, bookmark := range m.bookmarks {
+		if bookmark.UserID == userID && bookmark.Collection == name {
+			bookmark.Collection = ""
+		}
+	}
+
+	return true, nil
+}
+
+func (m *memoryStore) ListCollections(_ context.Context, userID string) ([]*store.Collection, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	collections := make([]*store.Collection, 0)
+	for _, col := range m.collections {
+		if col.UserID == userID {
+			c := *col
+			collections = append(collections, &c)
+		}
+	}
+
+	sort.Slice(collections, func(i, j int) bool {
+		return collections[i].Name < collections[j].Name
+	})
+
+	return collections, nil
+}
+
+func (m *memoryStore) collectionIndex(userID, name string) int {
+	for i, col := range m.collections {
+		if col.UserID == userID && col.Name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+//copyBookmark copies a bookmark with its tags, so callers can't modify stored bookmarks.
+func copyBookmark(bookmark *store.Bookmark) *store.Bookmark {
+	b := *bookmark
+	if bookmark.Tags != nil {
+		b.Tags = append([]string{}, bookmark.Tags...)
+	}
+
+	return &b
+}
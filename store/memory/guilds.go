@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/internal/arrays"
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+func (m *memoryStore) Guild(_ context.Context, id string) (*store.Guild, error) {
+	//If guild ID is empty, return DM guild settings.
+	if id == "" {
+		return store.UserGuild(), nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	guild, ok := m.guilds[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+
+	return cloneGuild(guild), nil
+}
+
+func (m *memoryStore) CreateGuild(_ context.Context, id string) (*store.Guild, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.guilds[id]; ok {
+		return nil, fmt.Errorf("guild %v already exists", id)
+	}
+
+	guild := store.DefaultGuild(id)
+	m.guilds[id] = cloneGuild(guild)
+
+	return guild, nil
+}
+
+//UpdateGuild replaces guild settings. Like in Mongo store, updating a guild that doesn't exist is a no-op.
+func (m *memoryStore) UpdateGuild(_ context.Context, guild *store.Guild) (*store.Guild, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	guild.UpdatedAt = time.Now()
+	if _, ok := m.guilds[guild.ID]; ok {
+		m.guilds[guild.ID] = cloneGuild(guild)
+	}
+
+	return guild, nil
+}
+
+//AddArtChannels adds channels to guild's art channels. If any of the channels is already an art channel, store.ErrNotFound is returned.
+func (m *memoryStore) AddArtChannels(_ context.Context, guildID string, channels []string) (*store.Guild, error) {
+	return m.modifyGuild(guildID, func(guild *store.Guild) bool {
+		for _, channel := range channels {
+			if arrays.Any(guild.ArtChannels, channel) {
+				return false
+			}
+		}
+
+		for _, channel := range channels {
+			if !arrays.Any(guild.ArtChannels, channel) {
+				guild.ArtChannels = append(guild.ArtChannels, channel)
+			}
+		}
+
+		return true
+	})
+}
+
+//DeleteArtChannels removes channels from guild's art channels. If any of the channels isn't an art channel, store.ErrNotFound is returned.
+func (m *memoryStore) DeleteArtChannels(_ context.Context, guildID string, channels []string) (*store.Guild, error) {
+	return m.modifyGuild(guildID, func(guild *store.Guild) bool {
+		for _, channel := range channels {
+			if !arrays.Any(guild.ArtChannels, channel) {
+				return false
+			}
+		}
+
+		guild.ArtChannels = arrays.Filter(guild.ArtChannels, func(channel string) bool {
+			return !arrays.Any(channels, channel)
+		})
+
+		return true
+	})
+}
+
+//modifyGuild applies fn to a stored guild. If the guild doesn't exist or fn returns false, store.ErrNotFound is returned.
+func (m *memoryStore) modifyGuild(id string, fn func(*store.Guild) bool) (*store.Guild, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	guild, ok := m.guilds[id]
+	if !ok || !fn(guild) {
+		return nil, store.ErrNotFound
+	}
+
+	return cloneGuild(guild), nil
+}
+
+func cloneGuild(guild *store.Guild) *store.Guild {
+	var g store.Guild
+	clone(guild, &g)
+
+	return &g
+}
@@ -0,0 +1,176 @@
+//Package memory implements a concurrency-safe in-memory store.Store. It emulates Mongo store semantics and is used as a reference
+//implementation in store tests and for running the bot without a database.
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+type memoryStore struct {
+	mu *sync.RWMutex
+	//path is a JSON snapshot file. If empty, data is lost on Close.
+	path string
+
+	artworks  []*store.Artwork
+	counter   int
+	guilds    map[string]*store.Guild
+	users     map[string]*store.User
+	bookmarks []*store.Bookmark
+	reposts   []*store.Repost
+
+	subscriptions []*store.Subscription
+	collections   []*store.Collection
+}
+
+func New() store.Store {
+	return &memoryStore{
+		mu:        &sync.RWMutex{},
+		artworks:  make([]*store.Artwork, 0),
+		guilds:    make(map[string]*store.Guild),
+		users:     make(map[string]*store.User),
+		bookmarks: make([]*store.Bookmark, 0),
+		reposts:   make([]*store.Repost, 0),
+
+		subscriptions: make([]*store.Subscription, 0),
+		collections:   make([]*store.Collection, 0),
+	}
+}
+
+//NewSnapshot creates a store that loads data from a JSON snapshot file on Init and saves it on Close.
+func NewSnapshot(path string) store.Store {
+	m := New().(*memoryStore)
+	m.path = path
+
+	return m
+}
+
+//snapshot is a JSON representation of the store.
+type snapshot struct {
+	Counter   int                     `json:"counter"`
+	Artworks  []*store.Artwork        `json:"artworks"`
+	Guilds    map[string]*store.Guild `json:"guilds"`
+	Users     map[string]*store.User  `json:"users"`
+	Bookmarks []*store.Bookmark       `json:"bookmarks"`
+	Reposts   []*store.Repost         `json:"reposts"`
+
+	Subscriptions []*store.Subscription `json:"subscriptions"`
+	Collections   []*store.Collection   `json:"collections"`
+}
+
+//Init loads a snapshot if the store has one. A missing snapshot file isn't an error, the store starts empty.
+func (m *memoryStore) Init(context.Context) error {
+	if m.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(m.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to read a snapshot: %w", err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("failed to decode a snapshot: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.counter = snap.Counter
+	if snap.Artworks != nil {
+		m.artworks = snap.Artworks
+	}
+
+	if snap.Guilds != nil {
+		m.guilds = snap.Guilds
+	}
+
+	if snap.Users != nil {
+		m.users = snap.Users
+	}
+
+	if snap.Bookmarks != nil {
+		m.bookmarks = snap.Bookmarks
+	}
+
+	if snap.Reposts != nil {
+		m.reposts = snap.Reposts
+	}
+
+	if snap.Subscriptions != nil {
+		m.subscriptions = snap.Subscriptions
+	}
+
+	if snap.Collections != nil {
+		m.collections = snap.Collections
+	}
+
+	return nil
+}
+
+//Close saves a snapshot if the store has one. The snapshot is written to a temporary file first, so a failed write doesn't corrupt the previous one.
+func (m *memoryStore) Close(context.Context) error {
+	if m.path == "" {
+		return nil
+	}
+
+	m.mu.RLock()
+	data, err := json.Marshal(&snapshot{
+		Counter:   m.counter,
+		Artworks:  m.artworks,
+		Guilds:    m.guilds,
+		Users:     m.users,
+		Bookmarks: m.bookmarks,
+		Reposts:   m.reposts,
+
+		Subscriptions: m.subscriptions,
+		Collections:   m.collections,
+	})
+	m.mu.RUnlock()
+
+	if err != nil {
+		return fmt.Errorf("failed to encode a snapshot: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(m.path), filepath.Base(m.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create a snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write a snapshot: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write a snapshot: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), m.path)
+}
+
+//clone deep copies src to dst through JSON, so callers can't modify stored documents. Like documents read from a database,
+//copies lose monotonic clock readings.
+func clone(src, dst interface{}) {
+	data, err := json.Marshal(src)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := json.Unmarshal(data, dst); err != nil {
+		panic(err)
+	}
+}
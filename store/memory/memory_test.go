@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/store"
+	"github.com/VTGare/boe-tea-go/store/storetest"
+)
+
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		return New()
+	})
+}
+
+func TestSnapshotStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		return NewSnapshot(filepath.Join(t.TempDir(), "store.json"))
+	})
+}
+
+func TestSnapshot(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		path = filepath.Join(t.TempDir(), "store.json")
+	)
+
+	s := NewSnapshot(path)
+	if err := s.Init(ctx); err != nil {
+		t.Fatalf("Init() without a snapshot error = %v", err)
+	}
+
+	artwork, err := s.CreateArtwork(ctx, &store.Artwork{Title: "artwork", URL: "https://example.com/1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.AddBookmark(ctx, &store.Bookmark{UserID: "user", ArtworkID: artwork.ID, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.CreateGuild(ctx, "guild"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	s = NewSnapshot(path)
+	if err := s.Init(ctx); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	if _, err := s.Guild(ctx, "guild"); err != nil {
+		t.Errorf("Guild() error = %v", err)
+	}
+
+	if count, err := s.CountBookmarks(ctx, "user"); err != nil || count != 1 {
+		t.Errorf("CountBookmarks() = %v, %v, want 1", count, err)
+	}
+
+	if artwork, err := s.Artwork(ctx, artwork.ID, ""); err != nil || artwork.Favourites != 1 {
+		t.Errorf("Artwork() = %+v, %v, want an artwork with 1 favourite", artwork, err)
+	}
+
+	//Artwork IDs keep incrementing after a restart.
+	next, err := s.CreateArtwork(ctx, &store.Artwork{Title: "next"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if next.ID != artwork.ID+1 {
+		t.Errorf("CreateArtwork() ID = %v, want %v", next.ID, artwork.ID+1)
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	var (
+		ctx = context.Background()
+		s   = New()
+		wg  sync.WaitGroup
+	)
+
+	artwork, err := s.CreateArtwork(ctx, &store.Artwork{Title: "artwork"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			s.AddBookmark(ctx, &store.Bookmark{UserID: fmt.Sprint(i), ArtworkID: artwork.ID})
+			s.CreateArtwork(ctx, &store.Artwork{Title: fmt.Sprint(i)})
+			s.SearchArtworks(ctx, store.ArtworkFilter{})
+		}(i)
+	}
+
+	wg.Wait()
+
+	if artwork, err := s.Artwork(ctx, artwork.ID, ""); err != nil || artwork.Favourites != 50 {
+		t.Errorf("Artwork() = %+v, %v, want 50 favourites", artwork, err)
+	}
+
+	artworks, err := s.SearchArtworks(ctx, store.ArtworkFilter{}, store.ArtworkSearchOptions{Order: store.Ascending, Sort: store.ByTime})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen := make(map[int]bool)
+	for _, artwork := range artworks {
+		if seen[artwork.ID] {
+			t.Fatalf("SearchArtworks() returned duplicate ID %v", artwork.ID)
+		}
+
+		seen[artwork.ID] = true
+	}
+
+	if len(seen) != 51 {
+		t.Errorf("SearchArtworks() returned %v artworks, want 51", len(seen))
+	}
+}
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+func (m *memoryStore) AddRepost(_ context.Context, repost *store.Repost) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r := *repost
+	m.reposts = append(m.reposts, &r)
+
+	return nil
+}
+
+//ListReposts returns guild's reposts, newest first.
+func (m *memoryStore) ListReposts(_ context.Context, guildID string, filter store.RepostFilter) ([]*store.Repost, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	reposts := make([]*store.Repost, 0)
+	for _, repost := range m.reposts {
+		if repost.GuildID != guildID {
+			continue
+		}
+
+		if filter.UserID != "" && repost.UserID != filter.UserID {
+			continue
+		}
+
+		if filter.Time != 0 && repost.CreatedAt.Before(time.Now().Add(-filter.Time)) {
+			continue
+		}
+
+		r := *repost
+		reposts = append(reposts, &r)
+	}
+
+	sort.SliceStable(reposts, func(i, j int) bool {
+		return reposts[i].CreatedAt.After(reposts[j].CreatedAt)
+	})
+
+	if filter.Limit > 0 && filter.Limit < int64(len(reposts)) {
+		reposts = reposts[:filter.Limit]
+	}
+
+	return reposts, nil
+}
+
+func (m *memoryStore) CountReposts(ctx context.Context, guildID string, filter store.RepostFilter) (int64, error) {
+	filter.Limit = 0
+
+	reposts, err := m.ListReposts(ctx, guildID, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(reposts)), nil
+}
@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+func (m *memoryStore) AddSubscription(_ context.Context, sub *store.Subscription) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.subscription(sub) != -1 {
+		return false, nil
+	}
+
+	m.subscriptions = append(m.subscriptions, copySubscription(sub))
+	return true, nil
+}
+
+func (m *memoryStore) DeleteSubscription(_ context.Context, sub *store.Subscription) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.subscription(sub)
+	if i == -1 {
+		return false, nil
+	}
+
+	m.subscriptions = append(m.subscriptions[:i], m.subscriptions[i+1:]...)
+	return true, nil
+}
+
+func (m *memoryStore) UpdateSubscription(_ context.Context, sub *store.Subscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.subscription(sub)
+	if i == -1 {
+		return store.ErrNotFound
+	}
+
+	m.subscriptions[i].Seen = copySubscription(sub).Seen
+	m.subscriptions[i].CheckedAt = sub.CheckedAt
+	return nil
+}
+
+func (m *memoryStore) GuildSubscriptions(_ context.Context, guildID string) ([]*store.Subscription, error) {
+	return m.findSubscriptions(func(sub *store.Subscription) bool {
+		return sub.GuildID == guildID
+	}), nil
+}
+
+func (m *memoryStore) ShardSubscriptions(_ context.Context, shardID, shardCount int) ([]*store.Subscription, error) {
+	return m.findSubscriptions(func(sub *store.Subscription) bool {
+		return sub.ShardKey%int64(shardCount) == int64(shardID)
+	}), nil
+}
+
+func (m *memoryStore) findSubscriptions(match func(*store.Subscription) bool) []*store.Subscription {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	subs := make([]*store.Subscription, 0)
+	for _, sub := range m.subscriptions {
+		if match(sub) {
+			subs = append(subs, copySubscription(sub))
+		}
+	}
+
+	return subs
+}
+
+//subscription returns an index of a subscription with the same channel, provider and author, or -1.
+func (m *memoryStore) subscription(sub *store.Subscription) int {
+	for i, s := range m.subscriptions {
+		if s.ChannelID == sub.ChannelID && s.Provider == sub.Provider && s.AuthorID == sub.AuthorID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func copySubscription(sub *store.Subscription) *store.Subscription {
+	s := *sub
+	s.Seen = append([]string{}, sub.Seen...)
+
+	return &s
+}
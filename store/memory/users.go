@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/internal/arrays"
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+//User returns a user. Like in Mongo store, a user with default settings is created if it doesn't exist.
+func (m *memoryStore) User(_ context.Context, id string) (*store.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[id]
+	if !ok {
+		user = store.DefaultUser(id)
+		m.users[id] = user
+	}
+
+	return cloneUser(user), nil
+}
+
+func (m *memoryStore) CreateUser(_ context.Context, id string) (*store.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[id]; ok {
+		return nil, fmt.Errorf("user %v already exists", id)
+	}
+
+	user := store.DefaultUser(id)
+	m.users[id] = cloneUser(user)
+
+	return user, nil
+}
+
+//UpdateUser replaces user settings. Like in Mongo store, updating a user that doesn't exist is a no-op.
+func (m *memoryStore) UpdateUser(_ context.Context, user *store.User) (*store.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user.UpdatedAt = time.Now()
+	if _, ok := m.users[user.ID]; ok {
+		m.users[user.ID] = cloneUser(user)
+	}
+
+	return user, nil
+}
+
+//CreateCrosspostGroup adds a crosspost group. If user already has a group with the same name or parent, store.ErrNotFound is returned.
+func (m *memoryStore) CreateCrosspostGroup(_ context.Context, userID string, group *store.Group) (*store.User, error) {
+	return m.modifyUser(userID, func(user *store.User) bool {
+		for _, g := range user.Groups {
+			if g.Name == group.Name || g.Parent == group.Parent {
+				return false
+			}
+		}
+
+		var g store.Group
+		clone(group, &g)
+
+		user.Groups = append(user.Groups, &g)
+		return true
+	})
+}
+
+func (m *memoryStore) DeleteCrosspostGroup(_ context.Context, userID, name string) (*store.User, error) {
+	return m.modifyUser(userID, func(user *store.User) bool {
+		if _, ok := user.FindGroupByName(name); !ok {
+			return false
+		}
+
+		user.Groups = arrays.Filter(user.Groups, func(g *store.Group) bool {
+			return g.Name != name
+		})
+
+		return true
+	})
+}
+
+func (m *memoryStore) AddCrosspostChannel(_ context.Context, userID, name, child string) (*store.User, error) {
+	return m.modifyUser(userID, func(user *store.User) bool {
+		group, ok := user.FindGroupByName(name)
+		if !ok {
+			return false
+		}
+
+		if !arrays.Any(group.Children, child) {
+			group.Children = append(group.Children, child)
+		}
+
+		return true
+	})
+}
+
+func (m *memoryStore) DeleteCrosspostChannel(_ context.Context, userID, name, child string) (*store.User, error) {
+	return m.modifyUser(userID, func(user *store.User) bool {
+		group, ok := user.FindGroupByName(name)
+		if !ok {
+			return false
+		}
+
+		group.Children = arrays.Filter(group.Children, func(c string) bool {
+			return c != child
+		})
+
+		delete(group.ChildFilters, child)
+		return true
+	})
+}
+
+func (m *memoryStore) SetCrosspostFilter(_ context.Context, userID, name, child string, filter *store.CrosspostFilter) (*store.User, error) {
+	return m.modifyUser(userID, func(user *store.User) bool {
+		group, ok := user.FindGroupByName(name)
+		if !ok {
+			return false
+		}
+
+		var f *store.CrosspostFilter
+		if !filter.IsZero() {
+			f = &store.CrosspostFilter{}
+			clone(filter, f)
+		}
+
+		return group.SetFilter(child, f)
+	})
+}
+
+//modifyUser applies fn to a stored user. If the user doesn't exist or fn returns false, store.ErrNotFound is returned.
+func (m *memoryStore) modifyUser(id string, fn func(*store.User) bool) (*store.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[id]
+	if !ok || !fn(user) {
+		return nil, store.ErrNotFound
+	}
+
+	return cloneUser(user), nil
+}
+
+func cloneUser(user *store.User) *store.User {
+	var u store.User
+	clone(user, &u)
+
+	return &u
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/VTGare/boe-tea-go/store"
@@ -124,6 +126,142 @@ func (a *artworkStore) CreateArtwork(ctx context.Context, artwork *store.Artwork
 	return res.(*store.Artwork), nil
 }
 
+func (a *artworkStore) UpdateArtwork(ctx context.Context, artwork *store.Artwork) (*store.Artwork, error) {
+	res := a.col.FindOneAndUpdate(
+		ctx,
+		bson.M{"artwork_id": artwork.ID},
+		bson.M{"$set": bson.M{
+			"title":      artwork.Title,
+			"author":     artwork.Author,
+			"images":     artwork.Images,
+			"provider":   artwork.Provider,
+			"source_id":  artwork.SourceID,
+			"tags":       artwork.Tags,
+			"nsfw":       artwork.NSFW,
+			"removed":    artwork.Removed,
+			"updated_at": time.Now(),
+		}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	updated := &store.Artwork{}
+	if err := res.Decode(updated); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, store.ErrArtworkNotFound
+		}
+
+		return nil, fmt.Errorf("failed to decode an artwork: %w", err)
+	}
+
+	return updated, nil
+}
+
+func (a *artworkStore) StaleArtworks(ctx context.Context, before time.Time, limit int64) ([]*store.Artwork, error) {
+	cur, err := a.col.Find(
+		ctx,
+		bson.M{"updated_at": bson.M{"$lt": before}},
+		options.Find().SetSort(bson.D{{Key: "updated_at", Value: 1}, {Key: "artwork_id", Value: 1}}).SetLimit(limit),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	artworks := make([]*store.Artwork, 0)
+	if err := cur.All(ctx, &artworks); err != nil {
+		return nil, err
+	}
+
+	return artworks, nil
+}
+
+func (a *artworkStore) ArtworkFacets(ctx context.Context, filter store.ArtworkFilter, limit int) (*store.ArtworkFacets, error) {
+	countBy := func(field string) bson.A {
+		return bson.A{
+			bson.M{"$group": bson.M{"_id": field, "count": bson.M{"$sum": 1}}},
+			bson.M{"$match": bson.M{"_id": bson.M{"$nin": bson.A{"", nil}}}},
+			bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+			bson.M{"$limit": limit},
+		}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filterBSON(filter)}},
+		{{Key: "$facet", Value: bson.M{
+			"total":     bson.A{bson.M{"$count": "count"}},
+			"nsfw":      bson.A{bson.M{"$match": bson.M{"nsfw": true}}, bson.M{"$count": "count"}},
+			"providers": countBy("$provider"),
+			"authors":   countBy("$author"),
+			"tags":      append(bson.A{bson.M{"$unwind": "$tags"}}, countBy("$tags")...),
+		}}},
+	}
+
+	cur, err := a.col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	type count struct {
+		Count int64 `bson:"count"`
+	}
+
+	res := make([]struct {
+		Total     []count        `bson:"total"`
+		NSFW      []count        `bson:"nsfw"`
+		Providers []*store.Facet `bson:"providers"`
+		Authors   []*store.Facet `bson:"authors"`
+		Tags      []*store.Facet `bson:"tags"`
+	}, 0, 1)
+
+	if err := cur.All(ctx, &res); err != nil {
+		return nil, fmt.Errorf("failed to decode artwork facets: %w", err)
+	}
+
+	facets := &store.ArtworkFacets{
+		Providers: make([]*store.Facet, 0),
+		Authors:   make([]*store.Facet, 0),
+		Tags:      make([]*store.Facet, 0),
+	}
+
+	if len(res) == 0 {
+		return facets, nil
+	}
+
+	if len(res[0].Total) != 0 {
+		facets.Total = res[0].Total[0].Count
+	}
+
+	if len(res[0].NSFW) != 0 {
+		facets.NSFW = res[0].NSFW[0].Count
+	}
+
+	facets.Providers = append(facets.Providers, res[0].Providers...)
+	facets.Authors = append(facets.Authors, res[0].Authors...)
+	facets.Tags = append(facets.Tags, res[0].Tags...)
+	return facets, nil
+}
+
+func (a *artworkStore) createIndexes(ctx context.Context) error {
+	_, err := a.col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "title", Value: "text"}, {Key: "author", Value: "text"}, {Key: "tags", Value: "text"}},
+			//Tags are in many languages, stemming and stop words of a single language would only get in the way.
+			Options: options.Index().SetDefaultLanguage("none"),
+		},
+		{
+			Keys: bson.D{{Key: "provider", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "tags", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "updated_at", Value: 1}},
+		},
+	})
+
+	return err
+}
+
 func findOptions(a store.ArtworkSearchOptions) *options.FindOptions {
 	sort := bson.M{a.Sort.String(): a.Order}
 
@@ -137,20 +275,27 @@ func filterBSON(f store.ArtworkFilter) bson.D {
 		return bson.E{Key: key, Value: bson.D{{Key: "$regex", Value: ".*" + value + ".*"}, {Key: "$options", Value: "i"}}}
 	}
 
-	regexM := func(key, value string) bson.M {
-		return bson.M{key: bson.D{{Key: "$regex", Value: ".*" + value + ".*"}, {Key: "$options", Value: "i"}}}
-	}
-
 	switch {
 	case len(f.IDs) != 0:
 		filter = append(filter, bson.E{Key: "artwork_id", Value: bson.M{"$in": f.IDs}})
 	case f.URL != "":
 		filter = append(filter, bson.E{Key: "url", Value: f.URL})
-	case f.Query != "":
-		filter = bson.D{
-			{Key: "$or", Value: []bson.M{regexM("author", f.Query), regexM("title", f.Query)}},
-		}
 	default:
+		//Every word has to be a substring of author, title or one of the tags, the same way it's matched by other stores.
+		if words := strings.Fields(f.Query); len(words) != 0 {
+			and := make(bson.A, 0, len(words))
+			for _, word := range words {
+				word = regexp.QuoteMeta(word)
+				and = append(and, bson.M{"$or": bson.A{
+					bson.D{regex("author", word)},
+					bson.D{regex("title", word)},
+					bson.D{regex("tags", word)},
+				}})
+			}
+
+			filter = append(filter, bson.E{Key: "$and", Value: and})
+		}
+
 		if f.Author != "" {
 			filter = append(filter, regex("author", f.Author))
 		}
@@ -162,6 +307,23 @@ func filterBSON(f store.ArtworkFilter) bson.D {
 		if f.Time != 0 {
 			filter = append(filter, bson.E{Key: "created_at", Value: bson.M{"$gte": time.Now().Add(-f.Time)}})
 		}
+
+		if f.Provider != "" {
+			filter = append(filter, bson.E{Key: "provider", Value: f.Provider})
+		}
+
+		if len(f.Tags) != 0 {
+			filter = append(filter, bson.E{Key: "tags", Value: bson.M{"$all": f.Tags}})
+		}
+
+		if f.NSFW != nil {
+			//Artworks saved before ratings were stored don't have the field.
+			if *f.NSFW {
+				filter = append(filter, bson.E{Key: "nsfw", Value: true})
+			} else {
+				filter = append(filter, bson.E{Key: "nsfw", Value: bson.M{"$ne": true}})
+			}
+		}
 	}
 
 	return filter
@@ -30,10 +30,18 @@ func BookmarksStore(client *mongo.Client, database, collection string) store.Boo
 	}
 }
 
-func (b *bookmarkStore) ListBookmarks(ctx context.Context, userID string, filter store.BookmarkFilter, order store.Order) ([]*store.Bookmark, error) {
+func (b *bookmarkStore) ListBookmarks(ctx context.Context, userID string, query store.BookmarkQuery, order store.Order) ([]*store.Bookmark, error) {
 	f := bson.M{"user_id": userID}
-	if filter != store.BookmarkFilterAll {
-		f["nsfw"] = filter == store.BookmarkFilterUnsafe
+	if query.Filter != store.BookmarkFilterAll {
+		f["nsfw"] = query.Filter == store.BookmarkFilterUnsafe
+	}
+
+	if query.Collection != "" {
+		f["collection"] = query.Collection
+	}
+
+	if len(query.Tags) != 0 {
+		f["tags"] = bson.M{"$all": query.Tags}
 	}
 
 	cur, err := b.col.Find(
@@ -63,6 +71,21 @@ func (b *bookmarkStore) CountBookmarks(ctx context.Context, userID string) (int6
 	return count, nil
 }
 
+func (b *bookmarkStore) Bookmark(ctx context.Context, userID string, artworkID int) (*store.Bookmark, error) {
+	res := b.col.FindOne(ctx, bson.M{"user_id": userID, "artwork_id": artworkID})
+
+	var bookmark store.Bookmark
+	if err := res.Decode(&bookmark); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, store.ErrBookmarkNotFound
+		}
+
+		return nil, fmt.Errorf("failed to find a bookmark: %w", err)
+	}
+
+	return &bookmark, nil
+}
+
 func (b *bookmarkStore) AddBookmark(ctx context.Context, bookmark *store.Bookmark) (bool, error) {
 	wc := writeconcern.New(writeconcern.WMajority())
 	rc := readconcern.Snapshot()
@@ -110,6 +133,26 @@ func (b *bookmarkStore) AddBookmark(ctx context.Context, bookmark *store.Bookmar
 	return added.(bool), nil
 }
 
+func (b *bookmarkStore) UpdateBookmark(ctx context.Context, bookmark *store.Bookmark) error {
+	update := bson.M{"$set": bson.M{"tags": bookmark.Tags}}
+	if bookmark.Collection != "" {
+		update["$set"].(bson.M)["collection"] = bookmark.Collection
+	} else {
+		update["$unset"] = bson.M{"collection": ""}
+	}
+
+	res, err := b.col.UpdateOne(ctx, bson.M{"user_id": bookmark.UserID, "artwork_id": bookmark.ArtworkID}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update bookmark: %w", err)
+	}
+
+	if res.MatchedCount == 0 {
+		return store.ErrBookmarkNotFound
+	}
+
+	return nil
+}
+
 func (b *bookmarkStore) DeleteBookmark(ctx context.Context, bookmark *store.Bookmark) (bool, error) {
 	wc := writeconcern.New(writeconcern.WMajority())
 	rc := readconcern.Snapshot()
@@ -160,3 +203,82 @@ func (b *bookmarkStore) DeleteBookmark(ctx context.Context, bookmark *store.Book
 func (b *bookmarkStore) artworks() *mongo.Collection {
 	return b.db.Collection("artworks")
 }
+
+func (b *bookmarkStore) CreateCollection(ctx context.Context, col *store.Collection) (bool, error) {
+	if _, err := b.collections().InsertOne(ctx, col); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to insert a collection: %w", err)
+	}
+
+	return true, nil
+}
+
+func (b *bookmarkStore) DeleteCollection(ctx context.Context, userID, name string) (bool, error) {
+	res, err := b.collections().DeleteOne(ctx, bson.M{"user_id": userID, "name": name})
+	if err != nil {
+		return false, fmt.Errorf("failed to delete a collection: %w", err)
+	}
+
+	if res.DeletedCount == 0 {
+		return false, nil
+	}
+
+	_, err = b.col.UpdateMany(
+		ctx,
+		bson.M{"user_id": userID, "collection": name},
+		bson.M{"$unset": bson.M{"collection": ""}},
+	)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to move bookmarks out of a collection: %w", err)
+	}
+
+	return true, nil
+}
+
+func (b *bookmarkStore) ListCollections(ctx context.Context, userID string) ([]*store.Collection, error) {
+	cur, err := b.collections().Find(ctx, bson.M{"user_id": userID}, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find collections: %w", err)
+	}
+
+	collections := make([]*store.Collection, 0)
+	if err := cur.All(ctx, &collections); err != nil {
+		return nil, fmt.Errorf("failed to decode to collections: %w", err)
+	}
+
+	return collections, nil
+}
+
+func (b *bookmarkStore) collections() *mongo.Collection {
+	return b.db.Collection("bookmark_collections")
+}
+
+//createIndexes creates indexes for listing user's bookmarks by collection and tags, and a unique index of collection names.
+func (b *bookmarkStore) createIndexes(ctx context.Context) error {
+	_, err := b.col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "collection", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "tags", Value: 1}},
+		},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	_, err = b.collections().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	return err
+}
@@ -30,7 +30,7 @@ func (g guildStore) Guild(ctx context.Context, id string) (*store.Guild, error)
 	var guild store.Guild
 	err := res.Decode(&guild)
 
-	return &guild, err
+	return &guild, notFound(err)
 }
 
 func (g *guildStore) CreateGuild(ctx context.Context, id string) (*store.Guild, error) {
@@ -65,7 +65,7 @@ func (g *guildStore) AddArtChannels(ctx context.Context, guildID string, channel
 	var guild store.Guild
 	err := res.Decode(&guild)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 
 	return &guild, nil
@@ -82,8 +82,51 @@ func (g *guildStore) DeleteArtChannels(ctx context.Context, guildID string, chan
 	var guild store.Guild
 	err := res.Decode(&guild)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 
 	return &guild, nil
 }
+
+//migrateProviders moves legacy provider toggles to the providers map.
+func (g *guildStore) migrateProviders(ctx context.Context) error {
+	legacy := []string{"pixiv", "twitter", "deviant", "artstation"}
+
+	providers := bson.M{}
+	for _, name := range legacy {
+		providers[name] = "$" + name
+	}
+
+	_, err := g.col.UpdateMany(
+		ctx,
+		bson.M{"providers": bson.M{"$exists": false}},
+		mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{"providers": providers}}},
+			{{Key: "$unset", Value: legacy}},
+		},
+	)
+
+	return err
+}
+
+//migrateRepost sets default repost settings for guilds created before the settings were introduced.
+func (g *guildStore) migrateRepost(ctx context.Context) error {
+	defaults := bson.M{
+		"repost_distance": store.DefaultRepostDistance,
+		"repost_scope":    "channel",
+	}
+
+	for field, value := range defaults {
+		_, err := g.col.UpdateMany(
+			ctx,
+			bson.M{field: bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{field: value}},
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -19,6 +19,8 @@ type mongoStore struct {
 	*userStore
 	*guildStore
 	*bookmarkStore
+	*repostStore
+	*subscriptionStore
 }
 
 func New(ctx context.Context, uri string, db string) (store.Store, error) {
@@ -39,11 +41,14 @@ func New(ctx context.Context, uri string, db string) (store.Store, error) {
 		userStore:     &userStore{client, database, database.Collection("users")},
 		guildStore:    &guildStore{client, database, database.Collection("guilds")},
 		bookmarkStore: &bookmarkStore{client, database, database.Collection("bookmarks")},
+		repostStore:   &repostStore{client, database, database.Collection("reposts")},
+
+		subscriptionStore: &subscriptionStore{client, database, database.Collection("subscriptions")},
 	}, nil
 }
 
 func (m *mongoStore) Init(ctx context.Context) error {
-	collections := []string{"artworks", "counters", "guilds", "users", "bookmarks"}
+	collections := []string{"artworks", "counters", "guilds", "users", "bookmarks", "reposts", "subscriptions", "bookmark_collections"}
 	for _, col := range collections {
 		err := m.database.CreateCollection(ctx, col)
 		if err != nil && !errors.As(err, &mongo.CommandError{}) {
@@ -51,10 +56,38 @@ func (m *mongoStore) Init(ctx context.Context) error {
 		}
 	}
 
-	return nil
+	if err := m.artworkStore.createIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := m.bookmarkStore.createIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := m.repostStore.createIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := m.subscriptionStore.createIndexes(ctx); err != nil {
+		return err
+	}
+
+	if err := m.guildStore.migrateProviders(ctx); err != nil {
+		return err
+	}
 
+	return m.guildStore.migrateRepost(ctx)
 }
 
 func (m *mongoStore) Close(ctx context.Context) error {
 	return m.client.Disconnect(ctx)
 }
+
+//notFound converts mongo.ErrNoDocuments to store.ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return store.ErrNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,104 @@
+package mongo
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/VTGare/boe-tea-go/store"
+	"github.com/VTGare/boe-tea-go/store/storetest"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+//TestStore runs the conformance suite against a Mongo replica set from BOETEA_TEST_MONGO environment variable,
+//e.g. "mongodb://localhost:27017/?replicaSet=rs0". Transactions require a replica set. The test database is dropped before every test.
+func TestStore(t *testing.T) {
+	uri := os.Getenv("BOETEA_TEST_MONGO")
+	if uri == "" {
+		t.Skip("BOETEA_TEST_MONGO is not set")
+	}
+
+	storetest.Run(t, func(t *testing.T) store.Store {
+		ctx := context.Background()
+
+		s, err := New(ctx, uri, "boetea-test")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := s.(*mongoStore).database.Drop(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		return s
+	})
+}
+
+//TestMigrateProviders runs against the same Mongo server as TestStore.
+func TestMigrateProviders(t *testing.T) {
+	uri := os.Getenv("BOETEA_TEST_MONGO")
+	if uri == "" {
+		t.Skip("BOETEA_TEST_MONGO is not set")
+	}
+
+	ctx := context.Background()
+	s, err := New(ctx, uri, "boetea-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close(ctx)
+
+	ms := s.(*mongoStore)
+	if err := ms.database.Drop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	//Legacy guilds have top-level provider toggles, guilds created before ArtStation support don't have its toggle.
+	_, err = ms.guildStore.col.InsertMany(ctx, []interface{}{
+		bson.M{"guild_id": "legacy", "pixiv": false, "twitter": true, "deviant": false},
+		bson.M{"guild_id": "migrated", "providers": bson.M{"pixiv": true}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Migration runs on every start, it must not change migrated guilds.
+	for i := 0; i < 2; i++ {
+		if err := ms.guildStore.migrateProviders(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		guild    string
+		provider string
+		want     bool
+	}{
+		{guild: "legacy", provider: "pixiv", want: false},
+		{guild: "legacy", provider: "twitter", want: true},
+		{guild: "legacy", provider: "deviant", want: false},
+		{guild: "legacy", provider: "artstation", want: true},
+		{guild: "migrated", provider: "pixiv", want: true},
+		{guild: "migrated", provider: "twitter", want: true},
+	}
+
+	for _, tt := range tests {
+		guild, err := s.Guild(ctx, tt.guild)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := guild.ProviderEnabled(tt.provider, true); got != tt.want {
+			t.Errorf("%v: ProviderEnabled(%v) = %v, want %v", tt.guild, tt.provider, got, tt.want)
+		}
+	}
+
+	var raw bson.M
+	if err := ms.guildStore.col.FindOne(ctx, bson.M{"guild_id": "legacy"}).Decode(&raw); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := raw["pixiv"]; ok {
+		t.Errorf("legacy toggles weren't removed: %v", raw)
+	}
+}
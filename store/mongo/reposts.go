@@ -0,0 +1,88 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type repostStore struct {
+	client *mongo.Client
+	db     *mongo.Database
+	col    *mongo.Collection
+}
+
+func RepostStore(client *mongo.Client, database, collection string) store.RepostStore {
+	db := client.Database(database)
+	col := db.Collection(collection)
+
+	return &repostStore{
+		client: client,
+		db:     db,
+		col:    col,
+	}
+}
+
+func (r *repostStore) AddRepost(ctx context.Context, repost *store.Repost) error {
+	if _, err := r.col.InsertOne(ctx, repost); err != nil {
+		return fmt.Errorf("failed to insert a repost: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repostStore) ListReposts(ctx context.Context, guildID string, filter store.RepostFilter) ([]*store.Repost, error) {
+	opts := options.Find().SetSort(bson.M{"created_at": store.Descending})
+	if filter.Limit != 0 {
+		opts.SetLimit(filter.Limit)
+	}
+
+	cur, err := r.col.Find(ctx, repostFilter(guildID, filter), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reposts: %w", err)
+	}
+
+	reposts := make([]*store.Repost, 0)
+	if err := cur.All(ctx, &reposts); err != nil {
+		return nil, fmt.Errorf("failed to decode to reposts: %w", err)
+	}
+
+	return reposts, nil
+}
+
+func (r *repostStore) CountReposts(ctx context.Context, guildID string, filter store.RepostFilter) (int64, error) {
+	count, err := r.col.CountDocuments(ctx, repostFilter(guildID, filter))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count reposts: %w", err)
+	}
+
+	return count, nil
+}
+
+//repostFilter converts a filter to a query document. Filter's limit is ignored.
+func repostFilter(guildID string, filter store.RepostFilter) bson.M {
+	f := bson.M{"guild_id": guildID}
+	if filter.UserID != "" {
+		f["user_id"] = filter.UserID
+	}
+
+	if filter.Time != 0 {
+		f["created_at"] = bson.M{"$gte": time.Now().Add(-filter.Time)}
+	}
+
+	return f
+}
+
+//createIndexes creates an index for listing guild's reposts, the log is never pruned.
+func (r *repostStore) createIndexes(ctx context.Context) error {
+	_, err := r.col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "guild_id", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+
+	return err
+}
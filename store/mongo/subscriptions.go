@@ -0,0 +1,108 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/VTGare/boe-tea-go/store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type subscriptionStore struct {
+	client *mongo.Client
+	db     *mongo.Database
+	col    *mongo.Collection
+}
+
+func SubscriptionStore(client *mongo.Client, database, collection string) store.SubscriptionStore {
+	db := client.Database(database)
+	col := db.Collection(collection)
+
+	return &subscriptionStore{
+		client: client,
+		db:     db,
+		col:    col,
+	}
+}
+
+func (s *subscriptionStore) AddSubscription(ctx context.Context, sub *store.Subscription) (bool, error) {
+	if _, err := s.col.InsertOne(ctx, sub); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to insert a subscription: %w", err)
+	}
+
+	return true, nil
+}
+
+func (s *subscriptionStore) DeleteSubscription(ctx context.Context, sub *store.Subscription) (bool, error) {
+	res, err := s.col.DeleteOne(ctx, subscriptionKey(sub))
+	if err != nil {
+		return false, fmt.Errorf("failed to delete a subscription: %w", err)
+	}
+
+	return res.DeletedCount > 0, nil
+}
+
+func (s *subscriptionStore) UpdateSubscription(ctx context.Context, sub *store.Subscription) error {
+	res, err := s.col.UpdateOne(
+		ctx,
+		subscriptionKey(sub),
+		bson.M{"$set": bson.M{"seen": sub.Seen, "checked_at": sub.CheckedAt}},
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update a subscription: %w", err)
+	}
+
+	if res.MatchedCount == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *subscriptionStore) GuildSubscriptions(ctx context.Context, guildID string) ([]*store.Subscription, error) {
+	return s.subscriptions(ctx, bson.M{"guild_id": guildID})
+}
+
+func (s *subscriptionStore) ShardSubscriptions(ctx context.Context, shardID, shardCount int) ([]*store.Subscription, error) {
+	return s.subscriptions(ctx, bson.M{"shard_key": bson.M{"$mod": bson.A{shardCount, shardID}}})
+}
+
+func (s *subscriptionStore) subscriptions(ctx context.Context, filter bson.M) ([]*store.Subscription, error) {
+	cur, err := s.col.Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": store.Ascending}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find subscriptions: %w", err)
+	}
+
+	subs := make([]*store.Subscription, 0)
+	if err := cur.All(ctx, &subs); err != nil {
+		return nil, fmt.Errorf("failed to decode to subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
+
+//createIndexes creates a unique index of subscription keys and an index for listing guild's subscriptions.
+func (s *subscriptionStore) createIndexes(ctx context.Context) error {
+	_, err := s.col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "channel_id", Value: 1}, {Key: "provider", Value: 1}, {Key: "author_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "guild_id", Value: 1}},
+		},
+	})
+
+	return err
+}
+
+func subscriptionKey(sub *store.Subscription) bson.M {
+	return bson.M{"channel_id": sub.ChannelID, "provider": sub.Provider, "author_id": sub.AuthorID}
+}
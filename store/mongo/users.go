@@ -42,7 +42,7 @@ func (u *userStore) User(ctx context.Context, userID string) (*store.User, error
 	var user store.User
 	err := res.Decode(&user)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 
 	return &user, nil
@@ -70,7 +70,7 @@ func (u *userStore) CreateCrosspostGroup(ctx context.Context, userID string, gro
 	var user store.User
 	err := res.Decode(&user)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 
 	return &user, nil
@@ -87,7 +87,7 @@ func (u *userStore) DeleteCrosspostGroup(ctx context.Context, userID, group stri
 	var user store.User
 	err := res.Decode(&user)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 
 	return &user, nil
@@ -104,7 +104,7 @@ func (u *userStore) AddCrosspostChannel(ctx context.Context, userID, group, chil
 	var user store.User
 	err := res.Decode(&user)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
 	}
 
 	return &user, nil
@@ -114,14 +114,49 @@ func (u *userStore) DeleteCrosspostChannel(ctx context.Context, userID, group, c
 	res := u.col.FindOneAndUpdate(
 		ctx,
 		bson.M{"user_id": userID, "channel_groups.name": group},
-		bson.M{"$pull": bson.M{"channel_groups.$.children": child}},
+		bson.M{
+			"$pull":  bson.M{"channel_groups.$.children": child},
+			"$unset": bson.M{"channel_groups.$.child_filters." + child: ""},
+		},
 		options.FindOneAndUpdate().SetReturnDocument(options.After),
 	)
 
 	var user store.User
 	err := res.Decode(&user)
 	if err != nil {
-		return nil, err
+		return nil, notFound(err)
+	}
+
+	return &user, nil
+}
+
+func (u *userStore) SetCrosspostFilter(ctx context.Context, userID, group, child string, filter *store.CrosspostFilter) (*store.User, error) {
+	var (
+		query = bson.M{"user_id": userID, "channel_groups.name": group}
+		field = "channel_groups.$.filter"
+	)
+
+	if child != "" {
+		query = bson.M{"user_id": userID, "channel_groups": bson.M{"$elemMatch": bson.M{"name": group, "children": child}}}
+		field = "channel_groups.$.child_filters." + child
+	}
+
+	update := bson.M{"$set": bson.M{field: filter}}
+	if filter.IsZero() {
+		update = bson.M{"$unset": bson.M{field: ""}}
+	}
+
+	res := u.col.FindOneAndUpdate(
+		ctx,
+		query,
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	var user store.User
+	err := res.Decode(&user)
+	if err != nil {
+		return nil, notFound(err)
 	}
 
 	return &user, nil
@@ -0,0 +1,80 @@
+package store
+
+import "github.com/VTGare/boe-tea-go/internal/arrays"
+
+//Permissions are guild command permissions. Targets are command or command group names.
+type Permissions struct {
+	//Roles maps role IDs to targets the role can use without default Discord permissions of the commands.
+	Roles map[string][]string `json:"roles,omitempty" bson:"roles,omitempty"`
+	//Channels maps channel IDs to targets denied in the channel.
+	Channels map[string][]string `json:"channels,omitempty" bson:"channels,omitempty"`
+}
+
+//Grant allows a role to use a target. It returns false if the role is already granted the target.
+func (p *Permissions) Grant(roleID, target string) bool {
+	return addTarget(&p.Roles, roleID, target)
+}
+
+//Revoke removes a target from a role. It returns false if the role isn't granted the target.
+func (p *Permissions) Revoke(roleID, target string) bool {
+	return removeTarget(p.Roles, roleID, target)
+}
+
+//Deny denies a target in a channel. It returns false if the target is already denied.
+func (p *Permissions) Deny(channelID, target string) bool {
+	return addTarget(&p.Channels, channelID, target)
+}
+
+//Allow removes a target from channel's denied targets. It returns false if the target isn't denied.
+func (p *Permissions) Allow(channelID, target string) bool {
+	return removeTarget(p.Channels, channelID, target)
+}
+
+//Granted reports if any of the roles is granted a command or its group.
+func (p *Permissions) Granted(roles []string, command, group string) bool {
+	for _, roleID := range roles {
+		if matchTarget(p.Roles[roleID], command, group) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//Denied reports if a command or its group is denied in a channel.
+func (p *Permissions) Denied(channelID, command, group string) bool {
+	return matchTarget(p.Channels[channelID], command, group)
+}
+
+func matchTarget(targets []string, command, group string) bool {
+	return arrays.Any(targets, command) || (group != "" && arrays.Any(targets, group))
+}
+
+func addTarget(m *map[string][]string, key, target string) bool {
+	if arrays.Any((*m)[key], target) {
+		return false
+	}
+
+	if *m == nil {
+		*m = make(map[string][]string)
+	}
+
+	(*m)[key] = append((*m)[key], target)
+	return true
+}
+
+func removeTarget(m map[string][]string, key, target string) bool {
+	if !arrays.Any(m[key], target) {
+		return false
+	}
+
+	m[key] = arrays.Filter(m[key], func(t string) bool {
+		return t != target
+	})
+
+	if len(m[key]) == 0 {
+		delete(m, key)
+	}
+
+	return true
+}
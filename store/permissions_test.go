@@ -0,0 +1,45 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+func TestPermissions(t *testing.T) {
+	var perms store.Permissions
+
+	if !perms.Grant("curator", "addchannel") || perms.Grant("curator", "addchannel") {
+		t.Fatalf("Grant() should add a target once")
+	}
+
+	if !perms.Granted([]string{"member", "curator"}, "addchannel", "general") {
+		t.Errorf("Granted(addchannel) = false, want true")
+	}
+
+	if perms.Granted([]string{"curator"}, "rmchannel", "general") {
+		t.Errorf("Granted(rmchannel) = true, want false")
+	}
+
+	perms.Grant("mod", "general")
+	if !perms.Granted([]string{"mod"}, "rmchannel", "general") {
+		t.Errorf("Granted(rmchannel) = false, want granted by group")
+	}
+
+	if !perms.Revoke("curator", "addchannel") || perms.Revoke("curator", "addchannel") {
+		t.Errorf("Revoke() should remove a target once")
+	}
+
+	if _, ok := perms.Roles["curator"]; ok {
+		t.Errorf("Revoke() didn't remove a role without targets")
+	}
+
+	perms.Deny("art", "memes")
+	if !perms.Denied("art", "borgar", "memes") || perms.Denied("general", "borgar", "memes") {
+		t.Errorf("Denied() should only deny memes in art channel")
+	}
+
+	if !perms.Allow("art", "memes") || perms.Denied("art", "borgar", "memes") {
+		t.Errorf("Allow() didn't remove a denied target")
+	}
+}
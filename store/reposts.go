@@ -0,0 +1,39 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+//RepostStore is a durable repost log. Unlike repost detector's records, log entries don't expire.
+type RepostStore interface {
+	AddRepost(ctx context.Context, repost *Repost) error
+	ListReposts(ctx context.Context, guildID string, filter RepostFilter) ([]*Repost, error)
+	//CountReposts returns the number of guild's reposts matched by the filter. Filter's limit is ignored.
+	CountReposts(ctx context.Context, guildID string, filter RepostFilter) (int64, error)
+}
+
+//Repost is a repost log entry. ArtworkID and URL are of the reposted artwork, original fields point to the message where
+//the artwork, or a similar one, was first posted.
+type Repost struct {
+	GuildID   string `json:"guild_id" bson:"guild_id"`
+	ChannelID string `json:"channel_id" bson:"channel_id"`
+	MessageID string `json:"message_id" bson:"message_id"`
+	UserID    string `json:"user_id" bson:"user_id"`
+	ArtworkID string `json:"artwork_id" bson:"artwork_id"`
+	URL       string `json:"url" bson:"url"`
+	//Similar is true if the repost was detected by image similarity.
+	Similar bool `json:"similar" bson:"similar"`
+
+	OriginalChannelID string `json:"original_channel_id" bson:"original_channel_id"`
+	OriginalMessageID string `json:"original_message_id" bson:"original_message_id"`
+
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+//RepostFilter filters repost log entries by user and time. Zero values match all entries.
+type RepostFilter struct {
+	UserID string
+	Time   time.Duration
+	Limit  int64
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"strings"
+	"unicode"
+)
+
+//ParseSearchQuery parses a search query to an artwork filter. author:, title:, tag:, provider: and nsfw: terms set filter fields,
+//other words are free text. Values with spaces can be quoted, e.g. author:"Jane Doe". Tags can be repeated.
+func ParseSearchQuery(q string) ArtworkFilter {
+	var (
+		filter ArtworkFilter
+		words  = make([]string, 0)
+	)
+
+	for _, term := range splitQuery(q) {
+		key, value, ok := strings.Cut(term, ":")
+		if !ok || value == "" {
+			words = append(words, term)
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "author":
+			filter.Author = value
+		case "title":
+			filter.Title = value
+		case "tag":
+			filter.Tags = append(filter.Tags, value)
+		case "provider":
+			filter.Provider = strings.ToLower(value)
+		case "nsfw":
+			switch strings.ToLower(value) {
+			case "true", "yes", "on":
+				nsfw := true
+				filter.NSFW = &nsfw
+			case "false", "no", "off":
+				nsfw := false
+				filter.NSFW = &nsfw
+			}
+		default:
+			words = append(words, term)
+		}
+	}
+
+	filter.Query = strings.Join(words, " ")
+	return filter
+}
+
+//splitQuery splits a query by whitespace. Double quotes group words together and are removed.
+func splitQuery(q string) []string {
+	var (
+		terms  = make([]string, 0)
+		term   strings.Builder
+		quoted bool
+	)
+
+	for _, r := range q {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			if term.Len() > 0 {
+				terms = append(terms, term.String())
+				term.Reset()
+			}
+		default:
+			term.WriteRune(r)
+		}
+	}
+
+	if term.Len() > 0 {
+		terms = append(terms, term.String())
+	}
+
+	return terms
+}
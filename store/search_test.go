@@ -0,0 +1,42 @@
+package store_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	yes, no := true, false
+
+	tests := []struct {
+		name  string
+		query string
+		want  store.ArtworkFilter
+	}{
+		{name: "free text", query: "green  tea", want: store.ArtworkFilter{Query: "green tea"}},
+		{name: "author", query: "author:hews", want: store.ArtworkFilter{Author: "hews"}},
+		{name: "quoted author", query: `author:"Jane Doe" sunset`, want: store.ArtworkFilter{Author: "Jane Doe", Query: "sunset"}},
+		{name: "tags", query: "tag:初音ミク tag:maid", want: store.ArtworkFilter{Tags: []string{"初音ミク", "maid"}}},
+		{name: "provider", query: "Provider:Pixiv", want: store.ArtworkFilter{Provider: "pixiv"}},
+		{name: "nsfw", query: "nsfw:yes", want: store.ArtworkFilter{NSFW: &yes}},
+		{name: "sfw", query: "nsfw:false", want: store.ArtworkFilter{NSFW: &no}},
+		{name: "invalid nsfw", query: "nsfw:maybe", want: store.ArtworkFilter{}},
+		{name: "unknown term", query: "https://pixiv.net title:tea", want: store.ArtworkFilter{Title: "tea", Query: "https://pixiv.net"}},
+		{name: "empty value", query: "author:", want: store.ArtworkFilter{Query: "author:"}},
+		{
+			name:  "everything",
+			query: `provider:pixiv tag:初音ミク author:"X" nsfw:no miku`,
+			want:  store.ArtworkFilter{Provider: "pixiv", Tags: []string{"初音ミク"}, Author: "X", NSFW: &no, Query: "miku"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := store.ParseSearchQuery(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSearchQuery(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,461 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+//artworkStore stores artwork tags in a separate table, so artworks can be filtered and faceted by tags.
+type artworkStore struct {
+	db *sql.DB
+}
+
+const artworkColumns = "artwork_id, title, author, url, images, favourites, provider, source_id, nsfw, removed, created_at, updated_at"
+
+func (a *artworkStore) Artwork(ctx context.Context, id int, url string) (*store.Artwork, error) {
+	var (
+		where = make([]string, 0, 2)
+		args  = make([]interface{}, 0, 2)
+	)
+
+	if id != 0 {
+		args = append(args, id)
+		where = append(where, fmt.Sprintf("artwork_id = $%v", len(args)))
+	}
+
+	if url != "" {
+		args = append(args, url)
+		where = append(where, fmt.Sprintf("url = $%v", len(args)))
+	}
+
+	query := "SELECT " + artworkColumns + " FROM artworks"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	artwork, err := scanArtwork(a.db.QueryRowContext(ctx, query+" LIMIT 1", args...))
+	if err != nil {
+		if err := notFound(err); err == store.ErrNotFound {
+			return nil, store.ErrArtworkNotFound
+		}
+
+		return nil, fmt.Errorf("failed to scan an artwork: %w", err)
+	}
+
+	tags, err := a.tags(ctx, []int{artwork.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	artwork.Tags = tags[artwork.ID]
+	return artwork, nil
+}
+
+func (a *artworkStore) SearchArtworks(ctx context.Context, filter store.ArtworkFilter, opts ...store.ArtworkSearchOptions) ([]*store.Artwork, error) {
+	opt := store.DefaultSearchOptions()
+	if len(opts) != 0 {
+		opt = opts[0]
+	}
+
+	where, args := artworkFilter(filter)
+
+	query := "SELECT " + artworkColumns + " FROM artworks"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	order := "DESC"
+	if opt.Order == store.Ascending {
+		order = "ASC"
+	}
+
+	query += fmt.Sprintf(" ORDER BY %v %v, artwork_id %v", opt.Sort.String(), order, order)
+
+	//Zero limit means no limit like in Mongo. SQLite doesn't support OFFSET without LIMIT.
+	if opt.Limit > 0 || opt.Skip > 0 {
+		limit := opt.Limit
+		if limit == 0 {
+			limit = math.MaxInt32
+		}
+
+		args = append(args, limit, opt.Skip)
+		query += fmt.Sprintf(" LIMIT $%v OFFSET $%v", len(args)-1, len(args))
+	}
+
+	rows, err := a.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	artworks := make([]*store.Artwork, 0)
+	for rows.Next() {
+		artwork, err := scanArtwork(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		artworks = append(artworks, artwork)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return artworks, a.withTags(ctx, artworks)
+}
+
+func (a *artworkStore) CreateArtwork(ctx context.Context, artwork *store.Artwork) (*store.Artwork, error) {
+	images, err := json.Marshal(artwork.Images)
+	if err != nil {
+		return nil, err
+	}
+
+	artwork.CreatedAt = now()
+	artwork.UpdatedAt = now()
+
+	err = withTx(ctx, a.db, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(
+			ctx,
+			"INSERT INTO artworks (title, author, url, images, favourites, provider, source_id, nsfw, created_at, updated_at) "+
+				"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING artwork_id",
+			artwork.Title, artwork.Author, artwork.URL, string(images), artwork.Favourites,
+			artwork.Provider, artwork.SourceID, artwork.NSFW, artwork.CreatedAt, artwork.UpdatedAt,
+		).Scan(&artwork.ID)
+
+		if err != nil {
+			return err
+		}
+
+		return insertArtworkTags(ctx, tx, artwork)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return artwork, nil
+}
+
+func (a *artworkStore) UpdateArtwork(ctx context.Context, artwork *store.Artwork) (*store.Artwork, error) {
+	images, err := json.Marshal(artwork.Images)
+	if err != nil {
+		return nil, err
+	}
+
+	err = withTx(ctx, a.db, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(
+			ctx,
+			"UPDATE artworks SET title = $1, author = $2, images = $3, provider = $4, source_id = $5, nsfw = $6, removed = $7, updated_at = $8 "+
+				"WHERE artwork_id = $9",
+			artwork.Title, artwork.Author, string(images), artwork.Provider, artwork.SourceID,
+			artwork.NSFW, artwork.Removed, now(), artwork.ID,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected == 0 {
+			return store.ErrArtworkNotFound
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM artwork_tags WHERE artwork_id = $1", artwork.ID); err != nil {
+			return fmt.Errorf("failed to delete artwork tags: %w", err)
+		}
+
+		return insertArtworkTags(ctx, tx, artwork)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return a.Artwork(ctx, artwork.ID, "")
+}
+
+func (a *artworkStore) StaleArtworks(ctx context.Context, before time.Time, limit int64) ([]*store.Artwork, error) {
+	rows, err := a.db.QueryContext(
+		ctx,
+		"SELECT "+artworkColumns+" FROM artworks WHERE updated_at < $1 ORDER BY updated_at ASC, artwork_id ASC LIMIT $2",
+		before.UTC(), limit,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	artworks := make([]*store.Artwork, 0)
+	for rows.Next() {
+		artwork, err := scanArtwork(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		artworks = append(artworks, artwork)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return artworks, a.withTags(ctx, artworks)
+}
+
+func (a *artworkStore) ArtworkFacets(ctx context.Context, filter store.ArtworkFilter, limit int) (*store.ArtworkFacets, error) {
+	where, args := artworkFilter(filter)
+
+	cond := ""
+	if len(where) > 0 {
+		cond = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	facets := &store.ArtworkFacets{}
+	err := a.db.QueryRowContext(
+		ctx,
+		"SELECT COUNT(*), COALESCE(SUM(CASE WHEN nsfw THEN 1 ELSE 0 END), 0) FROM artworks"+cond,
+		args...,
+	).Scan(&facets.Total, &facets.NSFW)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to count artworks: %w", err)
+	}
+
+	countBy := func(column string) ([]*store.Facet, error) {
+		query := fmt.Sprintf("SELECT %v, COUNT(*) FROM artworks", column)
+		if len(where) > 0 {
+			query += cond + " AND " + column + " <> ''"
+		} else {
+			query += " WHERE " + column + " <> ''"
+		}
+
+		return a.facets(ctx, query+fmt.Sprintf(" GROUP BY %v", column), limit, args)
+	}
+
+	if facets.Providers, err = countBy("provider"); err != nil {
+		return nil, err
+	}
+
+	if facets.Authors, err = countBy("author"); err != nil {
+		return nil, err
+	}
+
+	facets.Tags, err = a.facets(ctx, "SELECT tag, COUNT(*) FROM artwork_tags WHERE artwork_id IN (SELECT artwork_id FROM artworks"+cond+") GROUP BY tag", limit, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return facets, nil
+}
+
+//facets runs a query that groups artworks by a value and returns up to limit values with the highest counts.
+func (a *artworkStore) facets(ctx context.Context, query string, limit int, args []interface{}) ([]*store.Facet, error) {
+	args = append(args[:len(args):len(args)], limit)
+	rows, err := a.db.QueryContext(ctx, query+fmt.Sprintf(" ORDER BY 2 DESC, 1 ASC LIMIT $%v", len(args)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count artworks: %w", err)
+	}
+	defer rows.Close()
+
+	facets := make([]*store.Facet, 0)
+	for rows.Next() {
+		var facet store.Facet
+		if err := rows.Scan(&facet.Value, &facet.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan a facet: %w", err)
+		}
+
+		facets = append(facets, &facet)
+	}
+
+	return facets, rows.Err()
+}
+
+//withTags loads tags of artworks.
+func (a *artworkStore) withTags(ctx context.Context, artworks []*store.Artwork) error {
+	ids := make([]int, 0, len(artworks))
+	for _, artwork := range artworks {
+		ids = append(ids, artwork.ID)
+	}
+
+	tags, err := a.tags(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, artwork := range artworks {
+		artwork.Tags = tags[artwork.ID]
+	}
+
+	return nil
+}
+
+//tags returns tags of artworks by artwork ID.
+func (a *artworkStore) tags(ctx context.Context, ids []int) (map[int][]string, error) {
+	tags := make(map[int][]string)
+	if len(ids) == 0 {
+		return tags, nil
+	}
+
+	var (
+		args         = make([]interface{}, 0, len(ids))
+		placeholders = make([]string, 0, len(ids))
+	)
+
+	for _, id := range ids {
+		args = append(args, id)
+		placeholders = append(placeholders, fmt.Sprintf("$%v", len(args)))
+	}
+
+	rows, err := a.db.QueryContext(
+		ctx,
+		fmt.Sprintf("SELECT artwork_id, tag FROM artwork_tags WHERE artwork_id IN (%v) ORDER BY tag", strings.Join(placeholders, ", ")),
+		args...,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find artwork tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			artworkID int
+			tag       string
+		)
+
+		if err := rows.Scan(&artworkID, &tag); err != nil {
+			return nil, fmt.Errorf("failed to scan an artwork tag: %w", err)
+		}
+
+		tags[artworkID] = append(tags[artworkID], tag)
+	}
+
+	return tags, rows.Err()
+}
+
+//artworkFilter converts a filter to SQL conditions. Filter fields take precedence the same way they do in Mongo store.
+func artworkFilter(f store.ArtworkFilter) ([]string, []interface{}) {
+	var (
+		where = make([]string, 0)
+		args  = make([]interface{}, 0)
+	)
+
+	contains := func(column, value string) string {
+		args = append(args, escapeLike(value))
+		return fmt.Sprintf("LOWER(%v) LIKE '%%' || LOWER($%v) || '%%' ESCAPE '\\'", column, len(args))
+	}
+
+	switch {
+	case len(f.IDs) != 0:
+		placeholders := make([]string, 0, len(f.IDs))
+		for _, id := range f.IDs {
+			args = append(args, id)
+			placeholders = append(placeholders, fmt.Sprintf("$%v", len(args)))
+		}
+
+		where = append(where, fmt.Sprintf("artwork_id IN (%v)", strings.Join(placeholders, ", ")))
+	case f.URL != "":
+		args = append(args, f.URL)
+		where = append(where, fmt.Sprintf("url = $%v", len(args)))
+	default:
+		for _, word := range strings.Fields(f.Query) {
+			where = append(where, fmt.Sprintf(
+				"(%v OR %v OR EXISTS (SELECT 1 FROM artwork_tags t WHERE t.artwork_id = artworks.artwork_id AND %v))",
+				contains("author", word), contains("title", word), contains("t.tag", word),
+			))
+		}
+
+		if f.Author != "" {
+			where = append(where, contains("author", f.Author))
+		}
+
+		if f.Title != "" {
+			where = append(where, contains("title", f.Title))
+		}
+
+		if f.Time != 0 {
+			args = append(args, now().Add(-f.Time))
+			where = append(where, fmt.Sprintf("created_at >= $%v", len(args)))
+		}
+
+		if f.Provider != "" {
+			args = append(args, f.Provider)
+			where = append(where, fmt.Sprintf("provider = $%v", len(args)))
+		}
+
+		for _, tag := range f.Tags {
+			args = append(args, tag)
+			where = append(where, fmt.Sprintf(
+				"EXISTS (SELECT 1 FROM artwork_tags t WHERE t.artwork_id = artworks.artwork_id AND t.tag = $%v)", len(args),
+			))
+		}
+
+		if f.NSFW != nil {
+			args = append(args, *f.NSFW)
+			where = append(where, fmt.Sprintf("nsfw = $%v", len(args)))
+		}
+	}
+
+	return where, args
+}
+
+func insertArtworkTags(ctx context.Context, tx *sql.Tx, artwork *store.Artwork) error {
+	for _, tag := range artwork.Tags {
+		_, err := tx.ExecContext(
+			ctx,
+			"INSERT INTO artwork_tags (artwork_id, tag) VALUES ($1, $2) ON CONFLICT (artwork_id, tag) DO NOTHING",
+			artwork.ID, tag,
+		)
+
+		if err != nil {
+			return fmt.Errorf("failed to insert an artwork tag: %w", err)
+		}
+	}
+
+	return nil
+}
+
+//escapeLike escapes LIKE wildcards.
+func escapeLike(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(s)
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanArtwork(row scanner) (*store.Artwork, error) {
+	var (
+		artwork store.Artwork
+		images  string
+	)
+
+	err := row.Scan(
+		&artwork.ID, &artwork.Title, &artwork.Author, &artwork.URL,
+		&images, &artwork.Favourites, &artwork.Provider, &artwork.SourceID, &artwork.NSFW,
+		&artwork.Removed, &artwork.CreatedAt, &artwork.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(images), &artwork.Images); err != nil {
+		return nil, err
+	}
+
+	return &artwork, nil
+}
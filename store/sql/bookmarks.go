@@ -0,0 +1,317 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+const bookmarkColumns = "user_id, artwork_id, nsfw, collection, created_at"
+
+//bookmarkStore stores bookmark tags in a separate table, so bookmarks can be filtered by tags.
+type bookmarkStore struct {
+	db *sql.DB
+}
+
+func (b *bookmarkStore) ListBookmarks(ctx context.Context, userID string, query store.BookmarkQuery, order store.Order) ([]*store.Bookmark, error) {
+	var (
+		q    = "SELECT " + bookmarkColumns + " FROM bookmarks WHERE user_id = $1"
+		args = []interface{}{userID}
+	)
+
+	if query.Filter != store.BookmarkFilterAll {
+		args = append(args, query.Filter == store.BookmarkFilterUnsafe)
+		q += fmt.Sprintf(" AND nsfw = $%v", len(args))
+	}
+
+	if query.Collection != "" {
+		args = append(args, query.Collection)
+		q += fmt.Sprintf(" AND collection = $%v", len(args))
+	}
+
+	for _, tag := range query.Tags {
+		args = append(args, tag)
+		q += fmt.Sprintf(
+			" AND EXISTS (SELECT 1 FROM bookmark_tags t WHERE t.user_id = bookmarks.user_id AND t.artwork_id = bookmarks.artwork_id AND t.tag = $%v)",
+			len(args),
+		)
+	}
+
+	if order == store.Ascending {
+		q += " ORDER BY created_at ASC"
+	} else {
+		q += " ORDER BY created_at DESC"
+	}
+
+	rows, err := b.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bookmarks: %w", err)
+	}
+	defer rows.Close()
+
+	bookmarks := make([]*store.Bookmark, 0)
+	for rows.Next() {
+		bookmark, err := scanBookmark(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		bookmarks = append(bookmarks, bookmark)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tags, err := b.tags(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, bookmark := range bookmarks {
+		bookmark.Tags = tags[bookmark.ArtworkID]
+	}
+
+	return bookmarks, nil
+}
+
+func (b *bookmarkStore) CountBookmarks(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	if err := b.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM bookmarks WHERE user_id = $1", userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count bookmarks: %w", err)
+	}
+
+	return count, nil
+}
+
+func (b *bookmarkStore) Bookmark(ctx context.Context, userID string, artworkID int) (*store.Bookmark, error) {
+	row := b.db.QueryRowContext(ctx, "SELECT "+bookmarkColumns+" FROM bookmarks WHERE user_id = $1 AND artwork_id = $2", userID, artworkID)
+
+	bookmark, err := scanBookmark(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrBookmarkNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := b.db.QueryContext(ctx, "SELECT tag FROM bookmark_tags WHERE user_id = $1 AND artwork_id = $2 ORDER BY tag", userID, artworkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bookmark tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("failed to scan a bookmark tag: %w", err)
+		}
+
+		bookmark.Tags = append(bookmark.Tags, tag)
+	}
+
+	return bookmark, rows.Err()
+}
+
+//AddBookmark adds a bookmark and increments artwork's favourites. It returns false if the bookmark already exists.
+func (b *bookmarkStore) AddBookmark(ctx context.Context, bookmark *store.Bookmark) (bool, error) {
+	var added bool
+	err := withTx(ctx, b.db, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(
+			ctx,
+			"INSERT INTO bookmarks ("+bookmarkColumns+") VALUES ($1, $2, $3, $4, $5) ON CONFLICT (user_id, artwork_id) DO NOTHING",
+			bookmark.UserID, bookmark.ArtworkID, bookmark.NSFW, bookmark.Collection, bookmark.CreatedAt.UTC(),
+		)
+
+		if err != nil {
+			return fmt.Errorf("failed to insert bookmark: %w", err)
+		}
+
+		if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+			return err
+		}
+
+		if err := insertTags(ctx, tx, bookmark); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE artworks SET favourites = favourites + 1 WHERE artwork_id = $1", bookmark.ArtworkID); err != nil {
+			return fmt.Errorf("failed to increment artwork favourite count: %w", err)
+		}
+
+		added = true
+		return nil
+	})
+
+	return added, err
+}
+
+func (b *bookmarkStore) UpdateBookmark(ctx context.Context, bookmark *store.Bookmark) error {
+	return withTx(ctx, b.db, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(
+			ctx,
+			"UPDATE bookmarks SET collection = $1 WHERE user_id = $2 AND artwork_id = $3",
+			bookmark.Collection, bookmark.UserID, bookmark.ArtworkID,
+		)
+
+		if err != nil {
+			return fmt.Errorf("failed to update bookmark: %w", err)
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected == 0 {
+			return store.ErrBookmarkNotFound
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM bookmark_tags WHERE user_id = $1 AND artwork_id = $2", bookmark.UserID, bookmark.ArtworkID); err != nil {
+			return fmt.Errorf("failed to delete bookmark tags: %w", err)
+		}
+
+		return insertTags(ctx, tx, bookmark)
+	})
+}
+
+//DeleteBookmark deletes a bookmark and decrements artwork's favourites. It returns false if the bookmark doesn't exist.
+func (b *bookmarkStore) DeleteBookmark(ctx context.Context, bookmark *store.Bookmark) (bool, error) {
+	var deleted bool
+	err := withTx(ctx, b.db, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, "DELETE FROM bookmarks WHERE user_id = $1 AND artwork_id = $2", bookmark.UserID, bookmark.ArtworkID)
+		if err != nil {
+			return fmt.Errorf("failed to delete bookmark: %w", err)
+		}
+
+		if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM bookmark_tags WHERE user_id = $1 AND artwork_id = $2", bookmark.UserID, bookmark.ArtworkID); err != nil {
+			return fmt.Errorf("failed to delete bookmark tags: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE artworks SET favourites = favourites - 1 WHERE artwork_id = $1", bookmark.ArtworkID); err != nil {
+			return fmt.Errorf("failed to decrement artwork favourite count: %w", err)
+		}
+
+		deleted = true
+		return nil
+	})
+
+	return deleted, err
+}
+
+func (b *bookmarkStore) CreateCollection(ctx context.Context, col *store.Collection) (bool, error) {
+	res, err := b.db.ExecContext(
+		ctx,
+		"INSERT INTO collections (user_id, name, created_at) VALUES ($1, $2, $3) ON CONFLICT (user_id, name) DO NOTHING",
+		col.UserID, col.Name, col.CreatedAt.UTC(),
+	)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to insert a collection: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+//DeleteCollection deletes a collection and moves its bookmarks out of it. It returns false if the collection doesn't exist.
+func (b *bookmarkStore) DeleteCollection(ctx context.Context, userID, name string) (bool, error) {
+	var deleted bool
+	err := withTx(ctx, b.db, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, "DELETE FROM collections WHERE user_id = $1 AND name = $2", userID, name)
+		if err != nil {
+			return fmt.Errorf("failed to delete a collection: %w", err)
+		}
+
+		if affected, err := res.RowsAffected(); err != nil || affected == 0 {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE bookmarks SET collection = '' WHERE user_id = $1 AND collection = $2", userID, name); err != nil {
+			return fmt.Errorf("failed to move bookmarks out of a collection: %w", err)
+		}
+
+		deleted = true
+		return nil
+	})
+
+	return deleted, err
+}
+
+func (b *bookmarkStore) ListCollections(ctx context.Context, userID string) ([]*store.Collection, error) {
+	rows, err := b.db.QueryContext(ctx, "SELECT user_id, name, created_at FROM collections WHERE user_id = $1 ORDER BY name", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find collections: %w", err)
+	}
+	defer rows.Close()
+
+	collections := make([]*store.Collection, 0)
+	for rows.Next() {
+		var col store.Collection
+		if err := rows.Scan(&col.UserID, &col.Name, &col.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan a collection: %w", err)
+		}
+
+		collections = append(collections, &col)
+	}
+
+	return collections, rows.Err()
+}
+
+//tags returns tags of all user's bookmarks by artwork ID.
+func (b *bookmarkStore) tags(ctx context.Context, userID string) (map[int][]string, error) {
+	rows, err := b.db.QueryContext(ctx, "SELECT artwork_id, tag FROM bookmark_tags WHERE user_id = $1 ORDER BY tag", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bookmark tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make(map[int][]string)
+	for rows.Next() {
+		var (
+			artworkID int
+			tag       string
+		)
+
+		if err := rows.Scan(&artworkID, &tag); err != nil {
+			return nil, fmt.Errorf("failed to scan a bookmark tag: %w", err)
+		}
+
+		tags[artworkID] = append(tags[artworkID], tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func insertTags(ctx context.Context, tx *sql.Tx, bookmark *store.Bookmark) error {
+	for _, tag := range bookmark.Tags {
+		_, err := tx.ExecContext(
+			ctx,
+			"INSERT INTO bookmark_tags (user_id, artwork_id, tag) VALUES ($1, $2, $3) ON CONFLICT (user_id, artwork_id, tag) DO NOTHING",
+			bookmark.UserID, bookmark.ArtworkID, tag,
+		)
+
+		if err != nil {
+			return fmt.Errorf("failed to insert a bookmark tag: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func scanBookmark(row scanner) (*store.Bookmark, error) {
+	var bookmark store.Bookmark
+	if err := row.Scan(&bookmark.UserID, &bookmark.ArtworkID, &bookmark.NSFW, &bookmark.Collection, &bookmark.CreatedAt); err != nil {
+		return nil, fmt.Errorf("failed to scan a bookmark: %w", err)
+	}
+
+	return &bookmark, nil
+}
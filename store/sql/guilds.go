@@ -0,0 +1,146 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/VTGare/boe-tea-go/internal/arrays"
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+//guildStore stores guild settings as JSON documents, new settings don't require schema migrations.
+type guildStore struct {
+	db *sql.DB
+}
+
+func (g *guildStore) Guild(ctx context.Context, id string) (*store.Guild, error) {
+	//If guild ID is empty, return DM guild settings.
+	if id == "" {
+		return store.UserGuild(), nil
+	}
+
+	return g.guild(ctx, g.db, id)
+}
+
+func (g *guildStore) CreateGuild(ctx context.Context, id string) (*store.Guild, error) {
+	guild := store.DefaultGuild(id)
+	guild.CreatedAt = now()
+	guild.UpdatedAt = now()
+
+	settings, err := json.Marshal(guild)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = g.db.ExecContext(
+		ctx,
+		"INSERT INTO guilds (guild_id, settings, created_at, updated_at) VALUES ($1, $2, $3, $4)",
+		guild.ID, string(settings), guild.CreatedAt, guild.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return guild, nil
+}
+
+func (g *guildStore) UpdateGuild(ctx context.Context, guild *store.Guild) (*store.Guild, error) {
+	guild.UpdatedAt = now()
+	if err := g.update(ctx, g.db, guild); err != nil {
+		return nil, err
+	}
+
+	return guild, nil
+}
+
+//AddArtChannels adds channels to guild's art channels. If any of the channels is already an art channel, store.ErrNotFound is returned.
+func (g *guildStore) AddArtChannels(ctx context.Context, guildID string, channels []string) (*store.Guild, error) {
+	return g.modify(ctx, guildID, func(guild *store.Guild) bool {
+		for _, channel := range channels {
+			if arrays.Any(guild.ArtChannels, channel) {
+				return false
+			}
+		}
+
+		for _, channel := range channels {
+			if !arrays.Any(guild.ArtChannels, channel) {
+				guild.ArtChannels = append(guild.ArtChannels, channel)
+			}
+		}
+
+		return true
+	})
+}
+
+//DeleteArtChannels removes channels from guild's art channels. If any of the channels isn't an art channel, store.ErrNotFound is returned.
+func (g *guildStore) DeleteArtChannels(ctx context.Context, guildID string, channels []string) (*store.Guild, error) {
+	return g.modify(ctx, guildID, func(guild *store.Guild) bool {
+		for _, channel := range channels {
+			if !arrays.Any(guild.ArtChannels, channel) {
+				return false
+			}
+		}
+
+		guild.ArtChannels = arrays.Filter(guild.ArtChannels, func(channel string) bool {
+			return !arrays.Any(channels, channel)
+		})
+
+		return true
+	})
+}
+
+//modify reads a guild and updates it in a transaction. If fn returns false, the guild isn't updated and store.ErrNotFound is returned.
+func (g *guildStore) modify(ctx context.Context, guildID string, fn func(*store.Guild) bool) (*store.Guild, error) {
+	var guild *store.Guild
+	err := withTx(ctx, g.db, func(tx *sql.Tx) error {
+		var err error
+		guild, err = g.guild(ctx, tx, guildID)
+		if err != nil {
+			return err
+		}
+
+		if !fn(guild) {
+			return store.ErrNotFound
+		}
+
+		guild.UpdatedAt = now()
+		return g.update(ctx, tx, guild)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return guild, nil
+}
+
+func (g *guildStore) guild(ctx context.Context, q querier, id string) (*store.Guild, error) {
+	var settings string
+	if err := q.QueryRowContext(ctx, "SELECT settings FROM guilds WHERE guild_id = $1", id).Scan(&settings); err != nil {
+		return nil, notFound(err)
+	}
+
+	var guild store.Guild
+	if err := json.Unmarshal([]byte(settings), &guild); err != nil {
+		return nil, err
+	}
+
+	return &guild, nil
+}
+
+func (g *guildStore) update(ctx context.Context, q querier, guild *store.Guild) error {
+	settings, err := json.Marshal(guild)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.ExecContext(
+		ctx,
+		"UPDATE guilds SET settings = $1, updated_at = $2 WHERE guild_id = $3",
+		string(settings), guild.UpdatedAt, guild.ID,
+	)
+
+	return err
+}
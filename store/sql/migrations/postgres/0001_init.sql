@@ -0,0 +1,49 @@
+CREATE TABLE guilds (
+    guild_id   TEXT PRIMARY KEY,
+    settings   TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE users (
+    user_id    TEXT PRIMARY KEY,
+    settings   TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE artworks (
+    artwork_id SERIAL PRIMARY KEY,
+    title      TEXT NOT NULL,
+    author     TEXT NOT NULL,
+    url        TEXT NOT NULL,
+    images     TEXT NOT NULL,
+    favourites INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX artworks_url ON artworks (url);
+
+CREATE TABLE bookmarks (
+    user_id    TEXT NOT NULL,
+    artwork_id INTEGER NOT NULL,
+    nsfw       BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, artwork_id)
+);
+
+CREATE TABLE reposts (
+    guild_id            TEXT NOT NULL,
+    channel_id          TEXT NOT NULL,
+    message_id          TEXT NOT NULL,
+    user_id             TEXT NOT NULL,
+    artwork_id          TEXT NOT NULL,
+    url                 TEXT NOT NULL,
+    image_match         BOOLEAN NOT NULL,
+    original_channel_id TEXT NOT NULL,
+    original_message_id TEXT NOT NULL,
+    created_at          TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX reposts_guild_created_at ON reposts (guild_id, created_at);
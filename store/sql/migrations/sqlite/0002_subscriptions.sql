@@ -0,0 +1,14 @@
+CREATE TABLE subscriptions (
+    guild_id   TEXT NOT NULL,
+    channel_id TEXT NOT NULL,
+    provider   TEXT NOT NULL,
+    author_id  TEXT NOT NULL,
+    url        TEXT NOT NULL,
+    shard_key  BIGINT NOT NULL,
+    seen       TEXT NOT NULL,
+    checked_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (channel_id, provider, author_id)
+);
+
+CREATE INDEX subscriptions_guild_id ON subscriptions (guild_id);
@@ -0,0 +1,19 @@
+ALTER TABLE bookmarks ADD COLUMN collection TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX bookmarks_user_id_collection ON bookmarks (user_id, collection);
+
+CREATE TABLE bookmark_tags (
+    user_id    TEXT NOT NULL,
+    artwork_id INTEGER NOT NULL,
+    tag        TEXT NOT NULL,
+    PRIMARY KEY (user_id, artwork_id, tag)
+);
+
+CREATE INDEX bookmark_tags_user_id_tag ON bookmark_tags (user_id, tag);
+
+CREATE TABLE collections (
+    user_id    TEXT NOT NULL,
+    name       TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, name)
+);
@@ -0,0 +1,14 @@
+ALTER TABLE artworks ADD COLUMN provider TEXT NOT NULL DEFAULT '';
+ALTER TABLE artworks ADD COLUMN source_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE artworks ADD COLUMN nsfw BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX artworks_provider ON artworks (provider);
+CREATE INDEX artworks_author ON artworks (author);
+
+CREATE TABLE artwork_tags (
+    artwork_id INTEGER NOT NULL,
+    tag        TEXT NOT NULL,
+    PRIMARY KEY (artwork_id, tag)
+);
+
+CREATE INDEX artwork_tags_tag ON artwork_tags (tag);
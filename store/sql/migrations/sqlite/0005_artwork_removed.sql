@@ -0,0 +1,3 @@
+ALTER TABLE artworks ADD COLUMN removed BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX artworks_updated_at ON artworks (updated_at);
@@ -0,0 +1,93 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+type repostStore struct {
+	db *sql.DB
+}
+
+func (r *repostStore) AddRepost(ctx context.Context, repost *store.Repost) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO reposts (guild_id, channel_id, message_id, user_id, artwork_id, url, image_match, original_channel_id, original_message_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		repost.GuildID, repost.ChannelID, repost.MessageID, repost.UserID, repost.ArtworkID, repost.URL,
+		repost.Similar, repost.OriginalChannelID, repost.OriginalMessageID, repost.CreatedAt.UTC(),
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to insert a repost: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repostStore) ListReposts(ctx context.Context, guildID string, filter store.RepostFilter) ([]*store.Repost, error) {
+	where, args := repostWhere(guildID, filter)
+	query := `SELECT guild_id, channel_id, message_id, user_id, artwork_id, url, image_match, original_channel_id, original_message_id, created_at
+		FROM reposts WHERE ` + where + " ORDER BY created_at DESC"
+	if filter.Limit != 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%v", len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find reposts: %w", err)
+	}
+	defer rows.Close()
+
+	reposts := make([]*store.Repost, 0)
+	for rows.Next() {
+		var rep store.Repost
+		err := rows.Scan(
+			&rep.GuildID, &rep.ChannelID, &rep.MessageID, &rep.UserID, &rep.ArtworkID, &rep.URL,
+			&rep.Similar, &rep.OriginalChannelID, &rep.OriginalMessageID, &rep.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan a repost: %w", err)
+		}
+
+		reposts = append(reposts, &rep)
+	}
+
+	return reposts, rows.Err()
+}
+
+func (r *repostStore) CountReposts(ctx context.Context, guildID string, filter store.RepostFilter) (int64, error) {
+	where, args := repostWhere(guildID, filter)
+
+	var count int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM reposts WHERE "+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count reposts: %w", err)
+	}
+
+	return count, nil
+}
+
+//repostWhere converts a filter to a WHERE clause. Filter's limit is ignored.
+func repostWhere(guildID string, filter store.RepostFilter) (string, []interface{}) {
+	var (
+		where = "guild_id = $1"
+		args  = []interface{}{guildID}
+	)
+
+	if filter.UserID != "" {
+		args = append(args, filter.UserID)
+		where += fmt.Sprintf(" AND user_id = $%v", len(args))
+	}
+
+	if filter.Time != 0 {
+		args = append(args, now().Add(-filter.Time))
+		where += fmt.Sprintf(" AND created_at >= $%v", len(args))
+	}
+
+	return where, args
+}
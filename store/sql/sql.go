@@ -0,0 +1,171 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/store"
+	_ "modernc.org/sqlite"
+)
+
+//Supported database/sql drivers. SQLite driver is pure Go and is always registered, Postgres requires importing pgx's stdlib package.
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "pgx"
+)
+
+//go:embed migrations
+var migrations embed.FS
+
+type sqlStore struct {
+	db     *sql.DB
+	driver string
+
+	*artworkStore
+	*userStore
+	*guildStore
+	*bookmarkStore
+	*repostStore
+	*subscriptionStore
+}
+
+//New opens a SQL database. Queries use $n placeholders that are supported by both SQLite and Postgres.
+func New(ctx context.Context, driver, dsn string) (store.Store, error) {
+	if driver != DriverSQLite && driver != DriverPostgres {
+		return nil, fmt.Errorf("unsupported sql driver: %v", driver)
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open a database: %w", err)
+	}
+
+	//SQLite doesn't support concurrent writes.
+	if driver == DriverSQLite {
+		db.SetMaxOpenConns(1)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ping a database: %w", err)
+	}
+
+	return &sqlStore{
+		db:            db,
+		driver:        driver,
+		artworkStore:  &artworkStore{db},
+		userStore:     &userStore{db},
+		guildStore:    &guildStore{db},
+		bookmarkStore: &bookmarkStore{db},
+		repostStore:   &repostStore{db},
+
+		subscriptionStore: &subscriptionStore{db},
+	}, nil
+}
+
+//Init applies schema migrations that haven't been applied yet. Every migration runs in a transaction.
+func (s *sqlStore) Init(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER PRIMARY KEY)"); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	dir := path.Join("migrations", "sqlite")
+	if s.driver == DriverPostgres {
+		dir = path.Join("migrations", "postgres")
+	}
+
+	entries, err := fs.ReadDir(migrations, dir)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	for _, entry := range entries {
+		version, err := strconv.Atoi(strings.SplitN(entry.Name(), "_", 2)[0])
+		if err != nil {
+			return fmt.Errorf("invalid migration name %v: %w", entry.Name(), err)
+		}
+
+		query, err := migrations.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		if err := s.migrate(ctx, version, string(query)); err != nil {
+			return fmt.Errorf("failed to apply migration %v: %w", entry.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+func (s *sqlStore) migrate(ctx context.Context, version int, query string) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		var applied int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM schema_migrations WHERE version = $1", version).Scan(&applied); err != nil {
+			return err
+		}
+
+		if applied > 0 {
+			return nil
+		}
+
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", version)
+		return err
+	})
+}
+
+func (s *sqlStore) Close(context.Context) error {
+	return s.db.Close()
+}
+
+//querier is implemented by both *sql.DB and *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+//withTx runs a function in a transaction. The transaction is rolled back if the function fails.
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+//notFound converts sql.ErrNoRows to store.ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return store.ErrNotFound
+	}
+
+	return err
+}
+
+//now returns current time in UTC. SQLite compares timestamps as strings, all of them must be in the same time zone.
+func now() time.Time {
+	return time.Now().UTC()
+}
@@ -0,0 +1,53 @@
+package sql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/VTGare/boe-tea-go/store"
+	"github.com/VTGare/boe-tea-go/store/storetest"
+	_ "github.com/jackc/pgx/v4/stdlib"
+)
+
+func newSQLite(t *testing.T) store.Store {
+	t.Helper()
+
+	s, err := New(context.Background(), DriverSQLite, filepath.Join(t.TempDir(), "boetea.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+//newPostgres connects to a Postgres database from BOETEA_TEST_POSTGRES environment variable. All tables are dropped before every test.
+func newPostgres(t *testing.T) store.Store {
+	t.Helper()
+
+	dsn := os.Getenv("BOETEA_TEST_POSTGRES")
+	if dsn == "" {
+		t.Skip("BOETEA_TEST_POSTGRES is not set")
+	}
+
+	s, err := New(context.Background(), DriverPostgres, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = s.(*sqlStore).db.Exec("DROP TABLE IF EXISTS schema_migrations, guilds, users, artworks, artwork_tags, bookmarks, bookmark_tags, collections, reposts, subscriptions")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestSQLite(t *testing.T) {
+	storetest.Run(t, newSQLite)
+}
+
+func TestPostgres(t *testing.T) {
+	storetest.Run(t, newPostgres)
+}
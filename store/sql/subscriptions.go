@@ -0,0 +1,130 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+const subscriptionColumns = "guild_id, channel_id, provider, author_id, url, shard_key, seen, checked_at, created_at"
+
+//subscriptionStore stores seen artwork IDs as a JSON array, they're never queried.
+type subscriptionStore struct {
+	db *sql.DB
+}
+
+func (s *subscriptionStore) AddSubscription(ctx context.Context, sub *store.Subscription) (bool, error) {
+	seen, err := json.Marshal(seenIDs(sub))
+	if err != nil {
+		return false, err
+	}
+
+	res, err := s.db.ExecContext(
+		ctx,
+		"INSERT INTO subscriptions ("+subscriptionColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT (channel_id, provider, author_id) DO NOTHING",
+		sub.GuildID, sub.ChannelID, sub.Provider, sub.AuthorID, sub.URL, sub.ShardKey, string(seen), sub.CheckedAt.UTC(), sub.CreatedAt.UTC(),
+	)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to insert a subscription: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+func (s *subscriptionStore) DeleteSubscription(ctx context.Context, sub *store.Subscription) (bool, error) {
+	res, err := s.db.ExecContext(
+		ctx,
+		"DELETE FROM subscriptions WHERE channel_id = $1 AND provider = $2 AND author_id = $3",
+		sub.ChannelID, sub.Provider, sub.AuthorID,
+	)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to delete a subscription: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+func (s *subscriptionStore) UpdateSubscription(ctx context.Context, sub *store.Subscription) error {
+	seen, err := json.Marshal(seenIDs(sub))
+	if err != nil {
+		return err
+	}
+
+	res, err := s.db.ExecContext(
+		ctx,
+		"UPDATE subscriptions SET seen = $1, checked_at = $2 WHERE channel_id = $3 AND provider = $4 AND author_id = $5",
+		string(seen), sub.CheckedAt.UTC(), sub.ChannelID, sub.Provider, sub.AuthorID,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update a subscription: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return store.ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *subscriptionStore) GuildSubscriptions(ctx context.Context, guildID string) ([]*store.Subscription, error) {
+	return s.subscriptions(ctx, "WHERE guild_id = $1", guildID)
+}
+
+func (s *subscriptionStore) ShardSubscriptions(ctx context.Context, shardID, shardCount int) ([]*store.Subscription, error) {
+	return s.subscriptions(ctx, "WHERE shard_key % $1 = $2", shardCount, shardID)
+}
+
+func (s *subscriptionStore) subscriptions(ctx context.Context, where string, args ...interface{}) ([]*store.Subscription, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+subscriptionColumns+" FROM subscriptions "+where+" ORDER BY created_at", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := make([]*store.Subscription, 0)
+	for rows.Next() {
+		var (
+			sub  store.Subscription
+			seen string
+		)
+
+		err := rows.Scan(
+			&sub.GuildID, &sub.ChannelID, &sub.Provider, &sub.AuthorID, &sub.URL,
+			&sub.ShardKey, &seen, &sub.CheckedAt, &sub.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan a subscription: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(seen), &sub.Seen); err != nil {
+			return nil, fmt.Errorf("failed to decode seen artworks: %w", err)
+		}
+
+		subs = append(subs, &sub)
+	}
+
+	return subs, rows.Err()
+}
+
+//seenIDs returns subscription's seen IDs. Nil slice is encoded as an empty JSON array.
+func seenIDs(sub *store.Subscription) []string {
+	if sub.Seen == nil {
+		return []string{}
+	}
+
+	return sub.Seen
+}
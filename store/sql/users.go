@@ -0,0 +1,199 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/VTGare/boe-tea-go/internal/arrays"
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+//userStore stores user settings and crosspost groups as JSON documents.
+type userStore struct {
+	db *sql.DB
+}
+
+//User returns user's settings. A user with default settings is created if it doesn't exist.
+func (u *userStore) User(ctx context.Context, userID string) (*store.User, error) {
+	user := store.DefaultUser(userID)
+	user.CreatedAt = now()
+	user.UpdatedAt = now()
+
+	settings, err := json.Marshal(user)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = u.db.ExecContext(
+		ctx,
+		"INSERT INTO users (user_id, settings, created_at, updated_at) VALUES ($1, $2, $3, $4) ON CONFLICT (user_id) DO NOTHING",
+		user.ID, string(settings), user.CreatedAt, user.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return u.user(ctx, u.db, userID)
+}
+
+func (u *userStore) CreateUser(ctx context.Context, id string) (*store.User, error) {
+	user := store.DefaultUser(id)
+	user.CreatedAt = now()
+	user.UpdatedAt = now()
+
+	settings, err := json.Marshal(user)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = u.db.ExecContext(
+		ctx,
+		"INSERT INTO users (user_id, settings, created_at, updated_at) VALUES ($1, $2, $3, $4)",
+		user.ID, string(settings), user.CreatedAt, user.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (u *userStore) UpdateUser(ctx context.Context, user *store.User) (*store.User, error) {
+	user.UpdatedAt = now()
+	if err := u.update(ctx, u.db, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+//CreateCrosspostGroup adds a new group. If user already has a group with the same name or parent, store.ErrNotFound is returned.
+func (u *userStore) CreateCrosspostGroup(ctx context.Context, userID string, group *store.Group) (*store.User, error) {
+	return u.modify(ctx, userID, func(user *store.User) bool {
+		for _, g := range user.Groups {
+			if g.Name == group.Name || g.Parent == group.Parent {
+				return false
+			}
+		}
+
+		user.Groups = append(user.Groups, group)
+		return true
+	})
+}
+
+func (u *userStore) DeleteCrosspostGroup(ctx context.Context, userID, group string) (*store.User, error) {
+	return u.modify(ctx, userID, func(user *store.User) bool {
+		if _, ok := user.FindGroupByName(group); !ok {
+			return false
+		}
+
+		user.Groups = arrays.Filter(user.Groups, func(g *store.Group) bool {
+			return g.Name != group
+		})
+
+		return true
+	})
+}
+
+func (u *userStore) AddCrosspostChannel(ctx context.Context, userID, group, child string) (*store.User, error) {
+	return u.modify(ctx, userID, func(user *store.User) bool {
+		g, ok := user.FindGroupByName(group)
+		if !ok {
+			return false
+		}
+
+		if !arrays.Any(g.Children, child) {
+			g.Children = append(g.Children, child)
+		}
+
+		return true
+	})
+}
+
+func (u *userStore) DeleteCrosspostChannel(ctx context.Context, userID, group, child string) (*store.User, error) {
+	return u.modify(ctx, userID, func(user *store.User) bool {
+		g, ok := user.FindGroupByName(group)
+		if !ok {
+			return false
+		}
+
+		g.Children = arrays.Filter(g.Children, func(c string) bool {
+			return c != child
+		})
+
+		delete(g.ChildFilters, child)
+		return true
+	})
+}
+
+func (u *userStore) SetCrosspostFilter(ctx context.Context, userID, group, child string, filter *store.CrosspostFilter) (*store.User, error) {
+	return u.modify(ctx, userID, func(user *store.User) bool {
+		g, ok := user.FindGroupByName(group)
+		if !ok {
+			return false
+		}
+
+		if filter.IsZero() {
+			filter = nil
+		}
+
+		return g.SetFilter(child, filter)
+	})
+}
+
+//modify reads a user and updates it in a transaction. If fn returns false, the user isn't updated and store.ErrNotFound is returned.
+func (u *userStore) modify(ctx context.Context, userID string, fn func(*store.User) bool) (*store.User, error) {
+	var user *store.User
+	err := withTx(ctx, u.db, func(tx *sql.Tx) error {
+		var err error
+		user, err = u.user(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		if !fn(user) {
+			return store.ErrNotFound
+		}
+
+		user.UpdatedAt = now()
+		return u.update(ctx, tx, user)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (u *userStore) user(ctx context.Context, q querier, id string) (*store.User, error) {
+	var settings string
+	if err := q.QueryRowContext(ctx, "SELECT settings FROM users WHERE user_id = $1", id).Scan(&settings); err != nil {
+		return nil, notFound(err)
+	}
+
+	var user store.User
+	if err := json.Unmarshal([]byte(settings), &user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (u *userStore) update(ctx context.Context, q querier, user *store.User) error {
+	settings, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.ExecContext(
+		ctx,
+		"UPDATE users SET settings = $1, updated_at = $2 WHERE user_id = $3",
+		string(settings), user.UpdatedAt, user.ID,
+	)
+
+	return err
+}
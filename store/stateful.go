@@ -78,7 +78,9 @@ func (s *StatefulStore) DeleteArtChannels(ctx context.Context, guildID string, c
 func (s *StatefulStore) Artwork(ctx context.Context, id int, url string) (*Artwork, error) {
 	if a, ok := s.cache.Get("artworks:" + strconv.Itoa(id)); ok {
 		artwork := a.(*Artwork)
-		return artwork, nil
+		if url == "" || artwork.URL == url {
+			return artwork, nil
+		}
 	}
 
 	artwork, err := s.Store.Artwork(ctx, id, url)
@@ -100,6 +102,38 @@ func (s *StatefulStore) CreateArtwork(ctx context.Context, a *Artwork) (*Artwork
 	return artwork, nil
 }
 
+func (s *StatefulStore) UpdateArtwork(ctx context.Context, a *Artwork) (*Artwork, error) {
+	artwork, err := s.Store.UpdateArtwork(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.Set("artworks:"+strconv.Itoa(artwork.ID), artwork, 0)
+	return artwork, nil
+}
+
+//AddBookmark invalidates the cached artwork, its favourites count is changed.
+func (s *StatefulStore) AddBookmark(ctx context.Context, bookmark *Bookmark) (bool, error) {
+	added, err := s.Store.AddBookmark(ctx, bookmark)
+	if err != nil {
+		return false, err
+	}
+
+	s.cache.Delete("artworks:" + strconv.Itoa(bookmark.ArtworkID))
+	return added, nil
+}
+
+//DeleteBookmark invalidates the cached artwork, its favourites count is changed.
+func (s *StatefulStore) DeleteBookmark(ctx context.Context, bookmark *Bookmark) (bool, error) {
+	deleted, err := s.Store.DeleteBookmark(ctx, bookmark)
+	if err != nil {
+		return false, err
+	}
+
+	s.cache.Delete("artworks:" + strconv.Itoa(bookmark.ArtworkID))
+	return deleted, nil
+}
+
 func (s *StatefulStore) SearchArtworks(ctx context.Context, filter ArtworkFilter, opts ...ArtworkSearchOptions) ([]*Artwork, error) {
 	if len(filter.IDs) == 0 {
 		return s.Store.SearchArtworks(ctx, filter, opts...)
@@ -0,0 +1,17 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/store"
+	"github.com/VTGare/boe-tea-go/store/memory"
+	"github.com/VTGare/boe-tea-go/store/storetest"
+	cache "github.com/patrickmn/go-cache"
+)
+
+func TestStatefulStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		return store.NewStatefulStore(memory.New(), cache.New(time.Minute, time.Minute))
+	})
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"errors"
+	"fmt"
 )
 
 type Store interface {
@@ -10,10 +11,14 @@ type Store interface {
 	GuildStore
 	UserStore
 	BookmarkStore
+	RepostStore
+	SubscriptionStore
 	Init(context.Context) error
 	Close(context.Context) error
 }
 
 var (
-	ErrArtworkNotFound = errors.New("artwork not found")
+	//ErrNotFound is returned by all store implementations when a document doesn't exist or doesn't match an update's conditions.
+	ErrNotFound        = errors.New("not found")
+	ErrArtworkNotFound = fmt.Errorf("artwork %w", ErrNotFound)
 )
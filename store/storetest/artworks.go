@@ -0,0 +1,364 @@
+package storetest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+//createArtworks creates artworks with given titles and authors "Author #n". Artworks are created a few milliseconds apart,
+//so sorting by time is deterministic even in stores with millisecond precision.
+func createArtworks(t *testing.T, s store.Store, titles ...string) []*store.Artwork {
+	t.Helper()
+
+	artworks := make([]*store.Artwork, 0, len(titles))
+	for i, title := range titles {
+		artwork, err := s.CreateArtwork(context.Background(), &store.Artwork{
+			Title:  title,
+			Author: "Author #" + string(rune('A'+i)),
+			URL:    "https://example.com/" + title,
+			Images: []string{"https://example.com/" + title + ".png"},
+		})
+
+		noErr(t, "CreateArtwork()", err)
+		artworks = append(artworks, artwork)
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	return artworks
+}
+
+//ids returns artwork IDs in order.
+func ids(artworks []*store.Artwork) []int {
+	ids := make([]int, 0, len(artworks))
+	for _, artwork := range artworks {
+		ids = append(ids, artwork.ID)
+	}
+
+	return ids
+}
+
+func equalIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func testArtwork(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	_, err := s.Artwork(ctx, 1, "")
+	wantErr(t, "Artwork(unknown)", err, store.ErrArtworkNotFound)
+
+	artworks := createArtworks(t, s, "sunset", "tea")
+	first, second := artworks[0], artworks[1]
+
+	//IDs are auto-incremented.
+	if first.ID == 0 || second.ID <= first.ID {
+		t.Fatalf("CreateArtwork() IDs = %v, %v, want increasing non-zero IDs", first.ID, second.ID)
+	}
+
+	if first.CreatedAt.IsZero() {
+		t.Errorf("CreateArtwork().CreatedAt is zero")
+	}
+
+	tests := []struct {
+		name string
+		id   int
+		url  string
+		want int
+		err  error
+	}{
+		{name: "by id", id: second.ID, want: second.ID},
+		{name: "by url", url: first.URL, want: first.ID},
+		{name: "by id and url", id: first.ID, url: first.URL, want: first.ID},
+		{name: "unknown url", url: "https://example.com/unknown", err: store.ErrArtworkNotFound},
+		{name: "id and url mismatch", id: first.ID, url: second.URL, err: store.ErrArtworkNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artwork, err := s.Artwork(ctx, tt.id, tt.url)
+			if tt.err != nil {
+				wantErr(t, "Artwork()", err, tt.err)
+				return
+			}
+
+			noErr(t, "Artwork()", err)
+			if artwork.ID != tt.want {
+				t.Fatalf("Artwork() ID = %v, want %v", artwork.ID, tt.want)
+			}
+
+			if len(artwork.Images) != 1 || artwork.Favourites != 0 {
+				t.Errorf("Artwork() = %+v, want 1 image and no favourites", artwork)
+			}
+		})
+	}
+}
+
+func testSearchArtworks(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	artworks := createArtworks(t, s, "Sunset", "Tea", "Green Tea")
+	sunset, tea, green := artworks[0].ID, artworks[1].ID, artworks[2].ID
+
+	tests := []struct {
+		name   string
+		filter store.ArtworkFilter
+		want   []int
+	}{
+		{name: "all", filter: store.ArtworkFilter{}, want: []int{green, tea, sunset}},
+		{name: "ids", filter: store.ArtworkFilter{IDs: []int{sunset, green}}, want: []int{green, sunset}},
+		{name: "url", filter: store.ArtworkFilter{URL: "https://example.com/Tea"}, want: []int{tea}},
+		{name: "query matches title", filter: store.ArtworkFilter{Query: "TEA"}, want: []int{green, tea}},
+		{name: "query matches author", filter: store.ArtworkFilter{Query: "author #a"}, want: []int{sunset}},
+		{name: "query matches partial words", filter: store.ArtworkFilter{Query: "sun"}, want: []int{sunset}},
+		{name: "query is literal", filter: store.ArtworkFilter{Query: ".*"}, want: []int{}},
+		{name: "title", filter: store.ArtworkFilter{Title: "green"}, want: []int{green}},
+		{name: "title and author", filter: store.ArtworkFilter{Title: "tea", Author: "#B"}, want: []int{tea}},
+		{name: "time", filter: store.ArtworkFilter{Time: time.Hour}, want: []int{green, tea, sunset}},
+		{name: "no match", filter: store.ArtworkFilter{Title: "coffee"}, want: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artworks, err := s.SearchArtworks(ctx, tt.filter)
+			noErr(t, "SearchArtworks()", err)
+
+			if got := ids(artworks); !equalIDs(got, tt.want) {
+				t.Errorf("SearchArtworks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func testSearchArtworksOptions(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	artworks := createArtworks(t, s, "first", "second", "third")
+	first, second, third := artworks[0].ID, artworks[1].ID, artworks[2].ID
+
+	//Favourites: second has 2, first has 1, third has none.
+	for _, bookmark := range []*store.Bookmark{
+		{UserID: "1", ArtworkID: second, CreatedAt: time.Now()},
+		{UserID: "2", ArtworkID: second, CreatedAt: time.Now()},
+		{UserID: "1", ArtworkID: first, CreatedAt: time.Now()},
+	} {
+		_, err := s.AddBookmark(ctx, bookmark)
+		noErr(t, "AddBookmark()", err)
+	}
+
+	tests := []struct {
+		name string
+		opts store.ArtworkSearchOptions
+		want []int
+	}{
+		{name: "newest", opts: store.ArtworkSearchOptions{Order: store.Descending, Sort: store.ByTime}, want: []int{third, second, first}},
+		{name: "oldest", opts: store.ArtworkSearchOptions{Order: store.Ascending, Sort: store.ByTime}, want: []int{first, second, third}},
+		{name: "most favourites", opts: store.ArtworkSearchOptions{Order: store.Descending, Sort: store.ByFavourites}, want: []int{second, first, third}},
+		{name: "least favourites", opts: store.ArtworkSearchOptions{Order: store.Ascending, Sort: store.ByFavourites}, want: []int{third, first, second}},
+		{name: "limit", opts: store.ArtworkSearchOptions{Limit: 2, Order: store.Descending, Sort: store.ByTime}, want: []int{third, second}},
+		{name: "skip", opts: store.ArtworkSearchOptions{Skip: 1, Order: store.Descending, Sort: store.ByTime}, want: []int{second, first}},
+		{name: "limit and skip", opts: store.ArtworkSearchOptions{Limit: 1, Skip: 1, Order: store.Ascending, Sort: store.ByTime}, want: []int{second}},
+		{name: "skip everything", opts: store.ArtworkSearchOptions{Skip: 3, Order: store.Descending, Sort: store.ByTime}, want: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artworks, err := s.SearchArtworks(ctx, store.ArtworkFilter{}, tt.opts)
+			noErr(t, "SearchArtworks()", err)
+
+			if got := ids(artworks); !equalIDs(got, tt.want) {
+				t.Errorf("SearchArtworks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+//createTaggedArtworks creates artworks from two providers with tags and ratings.
+func createTaggedArtworks(t *testing.T, s store.Store) []*store.Artwork {
+	t.Helper()
+
+	artworks := make([]*store.Artwork, 0, 3)
+	for _, artwork := range []*store.Artwork{
+		{Title: "Miku", Author: "hews", Provider: "pixiv", SourceID: "1", Tags: []string{"miku", "vocaloid"}},
+		{Title: "Beach", Author: "hews", Provider: "pixiv", SourceID: "2", Tags: []string{"miku", "swimsuit"}, NSFW: true},
+		{Title: "Sunset", Author: "kaede", Provider: "twitter", SourceID: "3"},
+	} {
+		artwork.URL = "https://example.com/" + artwork.SourceID
+		artwork.Images = []string{artwork.URL + ".png"}
+
+		artwork, err := s.CreateArtwork(context.Background(), artwork)
+		noErr(t, "CreateArtwork()", err)
+		artworks = append(artworks, artwork)
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	return artworks
+}
+
+func testArtworkSearch(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	artworks := createTaggedArtworks(t, s)
+	miku, beach, sunset := artworks[0].ID, artworks[1].ID, artworks[2].ID
+	yes, no := true, false
+
+	artwork, err := s.Artwork(ctx, beach, "")
+	noErr(t, "Artwork()", err)
+
+	if artwork.Provider != "pixiv" || artwork.SourceID != "2" || !artwork.NSFW || len(artwork.Tags) != 2 {
+		t.Errorf("Artwork() = %+v, want provider, source ID, tags and rating", artwork)
+	}
+
+	tests := []struct {
+		name   string
+		filter store.ArtworkFilter
+		want   []int
+	}{
+		{name: "provider", filter: store.ArtworkFilter{Provider: "pixiv"}, want: []int{beach, miku}},
+		{name: "tag", filter: store.ArtworkFilter{Tags: []string{"miku"}}, want: []int{beach, miku}},
+		{name: "all tags", filter: store.ArtworkFilter{Tags: []string{"miku", "swimsuit"}}, want: []int{beach}},
+		{name: "nsfw", filter: store.ArtworkFilter{NSFW: &yes}, want: []int{beach}},
+		{name: "sfw", filter: store.ArtworkFilter{NSFW: &no}, want: []int{sunset, miku}},
+		{name: "query matches tags", filter: store.ArtworkFilter{Query: "vocaloid"}, want: []int{miku}},
+		{name: "query matches every word", filter: store.ArtworkFilter{Query: "hews swimsuit"}, want: []int{beach}},
+		{name: "query matches partial tags", filter: store.ArtworkFilter{Query: "voca"}, want: []int{miku}},
+		{name: "query matches every partial word", filter: store.ArtworkFilter{Query: "swim hew"}, want: []int{beach}},
+		{name: "query and fields", filter: store.ArtworkFilter{Query: "miku", Provider: "pixiv", NSFW: &no}, want: []int{miku}},
+		{name: "no match", filter: store.ArtworkFilter{Provider: "twitter", Tags: []string{"miku"}}, want: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artworks, err := s.SearchArtworks(ctx, tt.filter)
+			noErr(t, "SearchArtworks()", err)
+
+			if got := ids(artworks); !equalIDs(got, tt.want) {
+				t.Errorf("SearchArtworks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func testArtworkFacets(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	createTaggedArtworks(t, s)
+
+	facets, err := s.ArtworkFacets(ctx, store.ArtworkFilter{}, 1)
+	noErr(t, "ArtworkFacets()", err)
+
+	want := &store.ArtworkFacets{
+		Total:     3,
+		NSFW:      1,
+		Providers: []*store.Facet{{Value: "pixiv", Count: 2}},
+		Authors:   []*store.Facet{{Value: "hews", Count: 2}},
+		Tags:      []*store.Facet{{Value: "miku", Count: 2}},
+	}
+
+	if !reflect.DeepEqual(facets, want) {
+		t.Errorf("ArtworkFacets() = %+v, want %+v", facets, want)
+	}
+
+	facets, err = s.ArtworkFacets(ctx, store.ArtworkFilter{Tags: []string{"miku"}}, 5)
+	noErr(t, "ArtworkFacets(miku)", err)
+
+	wantTags := []*store.Facet{{Value: "miku", Count: 2}, {Value: "swimsuit", Count: 1}, {Value: "vocaloid", Count: 1}}
+	if facets.Total != 2 || !reflect.DeepEqual(facets.Tags, wantTags) {
+		t.Errorf("ArtworkFacets(miku) = %+v, want 2 artworks and tags %+v", facets, wantTags)
+	}
+
+	facets, err = s.ArtworkFacets(ctx, store.ArtworkFilter{Provider: "deviant"}, 5)
+	noErr(t, "ArtworkFacets(none)", err)
+
+	if facets.Total != 0 || facets.NSFW != 0 || len(facets.Providers) != 0 || len(facets.Authors) != 0 || len(facets.Tags) != 0 {
+		t.Errorf("ArtworkFacets(none) = %+v, want no artworks", facets)
+	}
+}
+
+func testUpdateArtwork(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	_, err := s.UpdateArtwork(ctx, &store.Artwork{ID: 100, Title: "unknown"})
+	wantErr(t, "UpdateArtwork(unknown)", err, store.ErrArtworkNotFound)
+
+	artworks := createTaggedArtworks(t, s)
+	_, err = s.AddBookmark(ctx, &store.Bookmark{UserID: "1", ArtworkID: artworks[0].ID, CreatedAt: time.Now()})
+	noErr(t, "AddBookmark()", err)
+
+	artwork, err := s.Artwork(ctx, artworks[0].ID, "")
+	noErr(t, "Artwork()", err)
+
+	artwork.Title = "Miku (edited)"
+	artwork.Images = []string{"https://example.com/new.png"}
+	artwork.Tags = []string{"vocaloid"}
+	artwork.Removed = true
+
+	updated, err := s.UpdateArtwork(ctx, artwork)
+	noErr(t, "UpdateArtwork()", err)
+
+	if updated.Title != "Miku (edited)" || len(updated.Images) != 1 || !updated.Removed || updated.Favourites != 1 {
+		t.Errorf("UpdateArtwork() = %+v, want new metadata and favourites kept", updated)
+	}
+
+	if !updated.UpdatedAt.After(artworks[0].UpdatedAt) || !updated.CreatedAt.Equal(artwork.CreatedAt) {
+		t.Errorf("UpdateArtwork() times = %v, %v, want only UpdatedAt changed", updated.CreatedAt, updated.UpdatedAt)
+	}
+
+	found, err := s.SearchArtworks(ctx, store.ArtworkFilter{Tags: []string{"miku"}})
+	noErr(t, "SearchArtworks()", err)
+
+	if got := ids(found); !equalIDs(got, []int{artworks[1].ID}) {
+		t.Errorf("SearchArtworks(miku) = %v, want old tags replaced", got)
+	}
+}
+
+func testStaleArtworks(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	artworks := createArtworks(t, s, "first", "second", "third")
+	first, second, third := artworks[0], artworks[1], artworks[2]
+
+	//Third is the most recently updated and removed, removed artworks are still refreshed.
+	_, err := s.UpdateArtwork(ctx, second)
+	noErr(t, "UpdateArtwork(second)", err)
+
+	third.Removed = true
+	_, err = s.UpdateArtwork(ctx, third)
+	noErr(t, "UpdateArtwork(third)", err)
+
+	stale, err := s.StaleArtworks(ctx, time.Now().Add(time.Minute), 10)
+	noErr(t, "StaleArtworks()", err)
+
+	if got := ids(stale); !equalIDs(got, []int{first.ID, second.ID, third.ID}) {
+		t.Errorf("StaleArtworks() = %v, want %v", got, []int{first.ID, second.ID, third.ID})
+	}
+
+	stale, err = s.StaleArtworks(ctx, time.Now().Add(time.Minute), 1)
+	noErr(t, "StaleArtworks(limit)", err)
+
+	if got := ids(stale); !equalIDs(got, []int{first.ID}) {
+		t.Errorf("StaleArtworks(limit) = %v, want %v", got, []int{first.ID})
+	}
+
+	stale, err = s.StaleArtworks(ctx, first.CreatedAt.Add(-time.Minute), 10)
+	noErr(t, "StaleArtworks(past)", err)
+
+	if len(stale) != 0 {
+		t.Errorf("StaleArtworks(past) = %v, want none", ids(stale))
+	}
+}
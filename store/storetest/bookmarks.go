@@ -0,0 +1,256 @@
+package storetest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+func testBookmarks(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	artworks := createArtworks(t, s, "safe", "unsafe", "other")
+	now := time.Now().Truncate(time.Millisecond)
+
+	for _, bookmark := range []*store.Bookmark{
+		{UserID: "user", ArtworkID: artworks[0].ID, NSFW: false, CreatedAt: now.Add(-2 * time.Minute)},
+		{UserID: "user", ArtworkID: artworks[1].ID, NSFW: true, CreatedAt: now.Add(-time.Minute)},
+		{UserID: "other", ArtworkID: artworks[2].ID, CreatedAt: now},
+	} {
+		added, err := s.AddBookmark(ctx, bookmark)
+		noErr(t, "AddBookmark()", err)
+
+		if !added {
+			t.Fatalf("AddBookmark(%+v) = false, want true", bookmark)
+		}
+	}
+
+	count, err := s.CountBookmarks(ctx, "user")
+	noErr(t, "CountBookmarks()", err)
+
+	if count != 2 {
+		t.Errorf("CountBookmarks() = %v, want 2", count)
+	}
+
+	tests := []struct {
+		name  string
+		query store.BookmarkQuery
+		order store.Order
+		want  []int
+	}{
+		{name: "all newest", order: store.Descending, want: []int{artworks[1].ID, artworks[0].ID}},
+		{name: "all oldest", order: store.Ascending, want: []int{artworks[0].ID, artworks[1].ID}},
+		{name: "safe", query: store.BookmarkQuery{Filter: store.BookmarkFilterSafe}, order: store.Descending, want: []int{artworks[0].ID}},
+		{name: "unsafe", query: store.BookmarkQuery{Filter: store.BookmarkFilterUnsafe}, order: store.Descending, want: []int{artworks[1].ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bookmarks, err := s.ListBookmarks(ctx, "user", tt.query, tt.order)
+			noErr(t, "ListBookmarks()", err)
+
+			got := make([]int, 0, len(bookmarks))
+			for _, bookmark := range bookmarks {
+				if bookmark.UserID != "user" {
+					t.Fatalf("ListBookmarks() returned a bookmark of user %v", bookmark.UserID)
+				}
+
+				got = append(got, bookmark.ArtworkID)
+			}
+
+			if !equalIDs(got, tt.want) {
+				t.Errorf("ListBookmarks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	bookmarks, err := s.ListBookmarks(ctx, "nobody", store.BookmarkQuery{}, store.Descending)
+	noErr(t, "ListBookmarks(nobody)", err)
+
+	if len(bookmarks) != 0 {
+		t.Errorf("ListBookmarks(nobody) = %v, want none", bookmarks)
+	}
+}
+
+func testCollections(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	artworks := createArtworks(t, s, "maid", "maid nsfw", "other")
+	now := time.Now().Truncate(time.Millisecond)
+
+	for _, name := range []string{"waifus", "landscapes"} {
+		for i, want := range []bool{true, false} {
+			created, err := s.CreateCollection(ctx, &store.Collection{UserID: "user", Name: name, CreatedAt: now})
+			noErr(t, "CreateCollection()", err)
+
+			if created != want {
+				t.Fatalf("CreateCollection(%v) #%v = %v, want %v", name, i+1, created, want)
+			}
+		}
+	}
+
+	_, err := s.CreateCollection(ctx, &store.Collection{UserID: "other", Name: "waifus", CreatedAt: now})
+	noErr(t, "CreateCollection()", err)
+
+	collections, err := s.ListCollections(ctx, "user")
+	noErr(t, "ListCollections()", err)
+
+	if len(collections) != 2 || collections[0].Name != "landscapes" || collections[1].Name != "waifus" {
+		t.Fatalf("ListCollections() = %+v, want landscapes and waifus", collections)
+	}
+
+	for i, bookmark := range []*store.Bookmark{
+		{UserID: "user", ArtworkID: artworks[0].ID, Collection: "waifus", Tags: []string{"maid"}, CreatedAt: now.Add(-2 * time.Minute)},
+		{UserID: "user", ArtworkID: artworks[1].ID, NSFW: true, CreatedAt: now.Add(-time.Minute)},
+		{UserID: "user", ArtworkID: artworks[2].ID, CreatedAt: now},
+	} {
+		if _, err := s.AddBookmark(ctx, bookmark); err != nil {
+			t.Fatalf("AddBookmark() #%v error = %v", i+1, err)
+		}
+	}
+
+	_, err = s.Bookmark(ctx, "user", artworks[0].ID+1000)
+	wantErr(t, "Bookmark(unknown)", err, store.ErrBookmarkNotFound)
+
+	err = s.UpdateBookmark(ctx, &store.Bookmark{UserID: "other", ArtworkID: artworks[0].ID, Collection: "waifus"})
+	wantErr(t, "UpdateBookmark(unknown)", err, store.ErrBookmarkNotFound)
+
+	bookmark, err := s.Bookmark(ctx, "user", artworks[1].ID)
+	noErr(t, "Bookmark()", err)
+
+	bookmark.Collection = "waifus"
+	bookmark.Tags = []string{"maid", "swimsuit"}
+	noErr(t, "UpdateBookmark()", s.UpdateBookmark(ctx, bookmark))
+
+	bookmark, err = s.Bookmark(ctx, "user", artworks[1].ID)
+	noErr(t, "Bookmark()", err)
+
+	if bookmark.Collection != "waifus" || !reflect.DeepEqual(bookmark.Tags, []string{"maid", "swimsuit"}) || !bookmark.NSFW {
+		t.Errorf("Bookmark() = %+v, want an NSFW bookmark in waifus tagged maid and swimsuit", bookmark)
+	}
+
+	tests := []struct {
+		name  string
+		query store.BookmarkQuery
+		want  []int
+	}{
+		{name: "collection", query: store.BookmarkQuery{Collection: "waifus"}, want: []int{artworks[1].ID, artworks[0].ID}},
+		{name: "empty collection", query: store.BookmarkQuery{Collection: "landscapes"}, want: []int{}},
+		{name: "tag", query: store.BookmarkQuery{Tags: []string{"maid"}}, want: []int{artworks[1].ID, artworks[0].ID}},
+		{name: "all tags", query: store.BookmarkQuery{Tags: []string{"maid", "swimsuit"}}, want: []int{artworks[1].ID}},
+		{name: "unknown tag", query: store.BookmarkQuery{Tags: []string{"maid", "unknown"}}, want: []int{}},
+		{
+			name:  "collection, tag and mode",
+			query: store.BookmarkQuery{Filter: store.BookmarkFilterSafe, Collection: "waifus", Tags: []string{"maid"}},
+			want:  []int{artworks[0].ID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bookmarks, err := s.ListBookmarks(ctx, "user", tt.query, store.Descending)
+			noErr(t, "ListBookmarks()", err)
+
+			got := make([]int, 0, len(bookmarks))
+			for _, bookmark := range bookmarks {
+				got = append(got, bookmark.ArtworkID)
+			}
+
+			if !equalIDs(got, tt.want) {
+				t.Errorf("ListBookmarks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	//Deleted bookmarks lose their tags.
+	_, err = s.DeleteBookmark(ctx, &store.Bookmark{UserID: "user", ArtworkID: artworks[0].ID})
+	noErr(t, "DeleteBookmark()", err)
+
+	_, err = s.AddBookmark(ctx, &store.Bookmark{UserID: "user", ArtworkID: artworks[0].ID, CreatedAt: now})
+	noErr(t, "AddBookmark()", err)
+
+	bookmark, err = s.Bookmark(ctx, "user", artworks[0].ID)
+	noErr(t, "Bookmark()", err)
+
+	if len(bookmark.Tags) != 0 || bookmark.Collection != "" {
+		t.Errorf("Bookmark() = %+v, want a new bookmark without tags", bookmark)
+	}
+
+	for i, want := range []bool{true, false} {
+		deleted, err := s.DeleteCollection(ctx, "user", "waifus")
+		noErr(t, "DeleteCollection()", err)
+
+		if deleted != want {
+			t.Fatalf("DeleteCollection() #%v = %v, want %v", i+1, deleted, want)
+		}
+	}
+
+	bookmark, err = s.Bookmark(ctx, "user", artworks[1].ID)
+	noErr(t, "Bookmark()", err)
+
+	if bookmark.Collection != "" || len(bookmark.Tags) != 2 {
+		t.Errorf("Bookmark() = %+v, want a bookmark moved out of the collection with its tags", bookmark)
+	}
+
+	collections, err = s.ListCollections(ctx, "other")
+	noErr(t, "ListCollections(other)", err)
+
+	if len(collections) != 1 {
+		t.Errorf("ListCollections(other) = %+v, want other user's collection intact", collections)
+	}
+}
+
+func testFavourites(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	artwork := createArtworks(t, s, "artwork")[0]
+	favourites := func(want int) {
+		t.Helper()
+
+		artwork, err := s.Artwork(ctx, artwork.ID, "")
+		noErr(t, "Artwork()", err)
+
+		if artwork.Favourites != want {
+			t.Fatalf("Artwork().Favourites = %v, want %v", artwork.Favourites, want)
+		}
+	}
+
+	bookmark := &store.Bookmark{UserID: "user", ArtworkID: artwork.ID, CreatedAt: time.Now()}
+
+	//Duplicate bookmarks are ignored and don't change favourites.
+	for i, want := range []bool{true, false} {
+		added, err := s.AddBookmark(ctx, bookmark)
+		noErr(t, "AddBookmark()", err)
+
+		if added != want {
+			t.Fatalf("AddBookmark() #%v = %v, want %v", i+1, added, want)
+		}
+
+		favourites(1)
+	}
+
+	_, err := s.AddBookmark(ctx, &store.Bookmark{UserID: "other", ArtworkID: artwork.ID, CreatedAt: time.Now()})
+	noErr(t, "AddBookmark()", err)
+	favourites(2)
+
+	for i, want := range []bool{true, false} {
+		deleted, err := s.DeleteBookmark(ctx, bookmark)
+		noErr(t, "DeleteBookmark()", err)
+
+		if deleted != want {
+			t.Fatalf("DeleteBookmark() #%v = %v, want %v", i+1, deleted, want)
+		}
+
+		favourites(1)
+	}
+
+	count, err := s.CountBookmarks(ctx, "user")
+	noErr(t, "CountBookmarks()", err)
+
+	if count != 0 {
+		t.Errorf("CountBookmarks() = %v, want 0", count)
+	}
+}
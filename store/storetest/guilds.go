@@ -0,0 +1,135 @@
+package storetest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+func testGuild(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	_, err := s.Guild(ctx, "guild")
+	wantErr(t, "Guild(unknown)", err, store.ErrNotFound)
+
+	dm, err := s.Guild(ctx, "")
+	noErr(t, "Guild(dm)", err)
+
+	if want := store.UserGuild(); dm.Prefix != want.Prefix || dm.NSFW != want.NSFW {
+		t.Errorf("Guild(dm) = %+v, want user guild settings", dm)
+	}
+
+	_, err = s.CreateGuild(ctx, "guild")
+	noErr(t, "CreateGuild()", err)
+
+	guild, err := s.Guild(ctx, "guild")
+	noErr(t, "Guild()", err)
+
+	want := store.DefaultGuild("guild")
+	if guild.ID != "guild" || guild.Prefix != want.Prefix || guild.Limit != want.Limit || guild.RepostScope != want.RepostScope {
+		t.Errorf("Guild() = %+v, want default guild settings", guild)
+	}
+}
+
+func testUpdateGuild(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	guild, err := s.CreateGuild(ctx, "guild")
+	noErr(t, "CreateGuild()", err)
+
+	guild.Prefix = "bt?"
+	guild.NSFW = false
+	guild.SetProvider("pixiv", false)
+	guild.Permissions.Grant("curator", "addchannel")
+
+	_, err = s.UpdateGuild(ctx, guild)
+	noErr(t, "UpdateGuild()", err)
+
+	guild, err = s.Guild(ctx, "guild")
+	noErr(t, "Guild()", err)
+
+	if guild.Prefix != "bt?" || guild.NSFW || guild.ProviderEnabled("pixiv", true) || !guild.Permissions.Granted([]string{"curator"}, "addchannel", "") {
+		t.Errorf("Guild() = %+v, want updated settings", guild)
+	}
+}
+
+func testChannelSettings(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	guild, err := s.CreateGuild(ctx, "guild")
+	noErr(t, "CreateGuild()", err)
+
+	limit, tags := 3, false
+	guild.SetChannel("art", &store.ChannelSettings{
+		Limit:     &limit,
+		Tags:      &tags,
+		Providers: map[string]bool{"twitter": false},
+	})
+
+	_, err = s.UpdateGuild(ctx, guild)
+	noErr(t, "UpdateGuild()", err)
+
+	guild, err = s.Guild(ctx, "guild")
+	noErr(t, "Guild()", err)
+
+	eff := guild.Effective("art")
+	if eff.Limit != 3 || eff.Tags || eff.ProviderEnabled("twitter", true) || !eff.Reactions {
+		t.Errorf("Effective(art) = %+v, want overridden limit, tags and twitter", eff)
+	}
+
+	if other := guild.Effective("other"); other.Limit != guild.Limit || !other.Tags {
+		t.Errorf("Effective(other) = %+v, want guild settings", other)
+	}
+
+	guild.SetChannel("art", &store.ChannelSettings{})
+	_, err = s.UpdateGuild(ctx, guild)
+	noErr(t, "UpdateGuild(reset)", err)
+
+	guild, err = s.Guild(ctx, "guild")
+	noErr(t, "Guild(reset)", err)
+
+	if len(guild.Channels) != 0 {
+		t.Errorf("Guild(reset).Channels = %v, want no overrides", guild.Channels)
+	}
+}
+
+func testArtChannels(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	_, err := s.AddArtChannels(ctx, "guild", []string{"1"})
+	wantErr(t, "AddArtChannels(unknown guild)", err, store.ErrNotFound)
+
+	_, err = s.CreateGuild(ctx, "guild")
+	noErr(t, "CreateGuild()", err)
+
+	guild, err := s.AddArtChannels(ctx, "guild", []string{"1", "2"})
+	noErr(t, "AddArtChannels()", err)
+
+	if want := []string{"1", "2"}; !reflect.DeepEqual(guild.ArtChannels, want) {
+		t.Errorf("AddArtChannels() = %v, want %v", guild.ArtChannels, want)
+	}
+
+	//Adding channels fails if any of them is already an art channel, nothing is added.
+	_, err = s.AddArtChannels(ctx, "guild", []string{"2", "3"})
+	wantErr(t, "AddArtChannels(duplicate)", err, store.ErrNotFound)
+
+	//Deleting channels fails if any of them isn't an art channel, nothing is deleted.
+	_, err = s.DeleteArtChannels(ctx, "guild", []string{"1", "3"})
+	wantErr(t, "DeleteArtChannels(missing)", err, store.ErrNotFound)
+
+	guild, err = s.Guild(ctx, "guild")
+	noErr(t, "Guild()", err)
+
+	if want := []string{"1", "2"}; !reflect.DeepEqual(guild.ArtChannels, want) {
+		t.Errorf("Guild().ArtChannels = %v, want %v after failed updates", guild.ArtChannels, want)
+	}
+
+	guild, err = s.DeleteArtChannels(ctx, "guild", []string{"1"})
+	noErr(t, "DeleteArtChannels()", err)
+
+	if want := []string{"2"}; !reflect.DeepEqual(guild.ArtChannels, want) {
+		t.Errorf("DeleteArtChannels() = %v, want %v", guild.ArtChannels, want)
+	}
+}
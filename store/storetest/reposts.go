@@ -0,0 +1,75 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+func testReposts(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	now := time.Now().Truncate(time.Millisecond)
+	for _, repost := range []*store.Repost{
+		{GuildID: "guild", UserID: "1", MessageID: "old", ArtworkID: "a", CreatedAt: now.Add(-48 * time.Hour)},
+		{GuildID: "guild", UserID: "1", MessageID: "first", ArtworkID: "a", Similar: true, CreatedAt: now.Add(-time.Hour)},
+		{GuildID: "guild", UserID: "2", MessageID: "second", ArtworkID: "b", CreatedAt: now.Add(-time.Minute)},
+		{GuildID: "guild", UserID: "1", MessageID: "third", ArtworkID: "c", CreatedAt: now},
+		{GuildID: "other", UserID: "1", MessageID: "other", ArtworkID: "a", CreatedAt: now},
+	} {
+		noErr(t, "AddRepost()", s.AddRepost(ctx, repost))
+	}
+
+	tests := []struct {
+		name   string
+		filter store.RepostFilter
+		want   []string
+		//count is the number of matched reposts regardless of the limit.
+		count int64
+	}{
+		{name: "all", filter: store.RepostFilter{}, want: []string{"third", "second", "first", "old"}, count: 4},
+		{name: "user", filter: store.RepostFilter{UserID: "1"}, want: []string{"third", "first", "old"}, count: 3},
+		{name: "time", filter: store.RepostFilter{Time: 24 * time.Hour}, want: []string{"third", "second", "first"}, count: 3},
+		{name: "limit", filter: store.RepostFilter{Limit: 2}, want: []string{"third", "second"}, count: 4},
+		{name: "everything", filter: store.RepostFilter{UserID: "1", Time: 24 * time.Hour, Limit: 1}, want: []string{"third"}, count: 2},
+		{name: "unknown user", filter: store.RepostFilter{UserID: "3"}, want: []string{}, count: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reposts, err := s.ListReposts(ctx, "guild", tt.filter)
+			noErr(t, "ListReposts()", err)
+
+			got := make([]string, 0, len(reposts))
+			for _, repost := range reposts {
+				got = append(got, repost.MessageID)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("ListReposts() = %v, want %v", got, tt.want)
+			}
+
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("ListReposts() = %v, want %v", got, tt.want)
+				}
+			}
+
+			count, err := s.CountReposts(ctx, "guild", tt.filter)
+			noErr(t, "CountReposts()", err)
+
+			if count != tt.count {
+				t.Errorf("CountReposts() = %v, want %v", count, tt.count)
+			}
+		})
+	}
+
+	reposts, err := s.ListReposts(ctx, "guild", store.RepostFilter{UserID: "1", Time: 2 * time.Hour})
+	noErr(t, "ListReposts()", err)
+
+	if len(reposts) != 2 || !reposts[1].Similar || !reposts[1].CreatedAt.Equal(now.Add(-time.Hour)) {
+		t.Errorf("ListReposts() = %+v, want the first repost to be similar and keep its time", reposts)
+	}
+}
@@ -0,0 +1,82 @@
+//Package storetest is a conformance test suite for store.Store implementations. Every implementation must behave like Mongo store,
+//the suite documents behaviours the callers rely on.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+//Factory creates an empty store. The suite calls Init and Close.
+type Factory func(t *testing.T) store.Store
+
+//Run runs the conformance suite. Every test gets a new store from the factory.
+func Run(t *testing.T, factory Factory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s store.Store)
+	}{
+		{"Guild", testGuild},
+		{"UpdateGuild", testUpdateGuild},
+		{"ArtChannels", testArtChannels},
+		{"ChannelSettings", testChannelSettings},
+		{"User", testUser},
+		{"UpdateUser", testUpdateUser},
+		{"CrosspostGroups", testCrosspostGroups},
+		{"CrosspostChannels", testCrosspostChannels},
+		{"CrosspostFilters", testCrosspostFilters},
+		{"Artwork", testArtwork},
+		{"SearchArtworks", testSearchArtworks},
+		{"SearchArtworksOptions", testSearchArtworksOptions},
+		{"ArtworkSearch", testArtworkSearch},
+		{"ArtworkFacets", testArtworkFacets},
+		{"UpdateArtwork", testUpdateArtwork},
+		{"StaleArtworks", testStaleArtworks},
+		{"Bookmarks", testBookmarks},
+		{"Favourites", testFavourites},
+		{"Collections", testCollections},
+		{"Reposts", testReposts},
+		{"Subscriptions", testSubscriptions},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			s := factory(t)
+			if err := s.Init(ctx); err != nil {
+				t.Fatalf("Init() error = %v", err)
+			}
+
+			defer func() {
+				if err := s.Close(ctx); err != nil {
+					t.Errorf("Close() error = %v", err)
+				}
+			}()
+
+			tt.test(t, s)
+		})
+	}
+}
+
+//wantErr fails the test if err isn't target.
+func wantErr(t *testing.T, method string, err, target error) {
+	t.Helper()
+
+	if !errors.Is(err, target) {
+		t.Fatalf("%v error = %v, want %v", method, err, target)
+	}
+}
+
+//noErr fails the test if err isn't nil.
+func noErr(t *testing.T, method string, err error) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatalf("%v error = %v", method, err)
+	}
+}
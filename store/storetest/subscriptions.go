@@ -0,0 +1,95 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+func testSubscriptions(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	now := time.Now().Truncate(time.Millisecond)
+	for i, sub := range []*store.Subscription{
+		{GuildID: "guild", ChannelID: "art", Provider: "pixiv", AuthorID: "1", ShardKey: 4},
+		{GuildID: "guild", ChannelID: "art", Provider: "twitter", AuthorID: "1", ShardKey: 4},
+		{GuildID: "guild", ChannelID: "other", Provider: "pixiv", AuthorID: "1", ShardKey: 4},
+		{GuildID: "other", ChannelID: "channel", Provider: "pixiv", AuthorID: "2", ShardKey: 5},
+	} {
+		sub.CreatedAt = now.Add(time.Duration(i) * time.Second)
+		added, err := s.AddSubscription(ctx, sub)
+		noErr(t, "AddSubscription()", err)
+
+		if !added {
+			t.Fatalf("AddSubscription(%+v) = false, want true", sub)
+		}
+	}
+
+	added, err := s.AddSubscription(ctx, &store.Subscription{GuildID: "guild", ChannelID: "art", Provider: "pixiv", AuthorID: "1", CreatedAt: now})
+	noErr(t, "AddSubscription(duplicate)", err)
+
+	if added {
+		t.Errorf("AddSubscription(duplicate) = true, want false")
+	}
+
+	subs, err := s.GuildSubscriptions(ctx, "guild")
+	noErr(t, "GuildSubscriptions()", err)
+
+	if len(subs) != 3 || subs[0].Provider != "pixiv" || subs[1].Provider != "twitter" || subs[2].ChannelID != "other" {
+		t.Fatalf("GuildSubscriptions() = %+v, want 3 subscriptions in creation order", subs)
+	}
+
+	for _, tt := range []struct {
+		shardID, shardCount, want int
+	}{
+		{shardID: 0, shardCount: 1, want: 4},
+		{shardID: 0, shardCount: 2, want: 3},
+		{shardID: 1, shardCount: 2, want: 1},
+		{shardID: 2, shardCount: 3, want: 1},
+	} {
+		subs, err := s.ShardSubscriptions(ctx, tt.shardID, tt.shardCount)
+		noErr(t, "ShardSubscriptions()", err)
+
+		if len(subs) != tt.want {
+			t.Errorf("ShardSubscriptions(%v, %v) = %v subscriptions, want %v", tt.shardID, tt.shardCount, len(subs), tt.want)
+		}
+	}
+
+	sub := subs[0]
+	sub.MarkSeen("a", "b")
+	sub.CheckedAt = now
+	noErr(t, "UpdateSubscription()", s.UpdateSubscription(ctx, sub))
+
+	subs, err = s.GuildSubscriptions(ctx, "guild")
+	noErr(t, "GuildSubscriptions()", err)
+
+	if got := subs[0]; !got.HasSeen("a") || !got.HasSeen("b") || !got.CheckedAt.Equal(now) {
+		t.Errorf("GuildSubscriptions()[0] = %+v, want updated seen artworks and check time", got)
+	}
+
+	err = s.UpdateSubscription(ctx, &store.Subscription{ChannelID: "unknown", Provider: "pixiv", AuthorID: "1"})
+	wantErr(t, "UpdateSubscription(unknown)", err, store.ErrNotFound)
+
+	deleted, err := s.DeleteSubscription(ctx, sub)
+	noErr(t, "DeleteSubscription()", err)
+
+	if !deleted {
+		t.Errorf("DeleteSubscription() = false, want true")
+	}
+
+	deleted, err = s.DeleteSubscription(ctx, sub)
+	noErr(t, "DeleteSubscription(deleted)", err)
+
+	if deleted {
+		t.Errorf("DeleteSubscription(deleted) = true, want false")
+	}
+
+	subs, err = s.GuildSubscriptions(ctx, "guild")
+	noErr(t, "GuildSubscriptions()", err)
+
+	if len(subs) != 2 {
+		t.Errorf("GuildSubscriptions() = %v subscriptions, want 2", len(subs))
+	}
+}
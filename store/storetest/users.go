@@ -0,0 +1,208 @@
+package storetest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/VTGare/boe-tea-go/store"
+)
+
+func testUser(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	//User creates a user with default settings if it doesn't exist.
+	user, err := s.User(ctx, "user")
+	noErr(t, "User()", err)
+
+	if user.ID != "user" || !user.DM || !user.Crosspost || user.Ignore || len(user.Groups) != 0 {
+		t.Errorf("User() = %+v, want default user settings", user)
+	}
+
+	user, err = s.CreateUser(ctx, "other")
+	noErr(t, "CreateUser()", err)
+
+	if user.ID != "other" || !user.DM {
+		t.Errorf("CreateUser() = %+v, want default user settings", user)
+	}
+
+	user, err = s.User(ctx, "other")
+	noErr(t, "User()", err)
+
+	if user.ID != "other" || !user.DM || !user.Crosspost {
+		t.Errorf("User() = %+v, want default user settings", user)
+	}
+}
+
+func testUpdateUser(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	user, err := s.User(ctx, "user")
+	noErr(t, "User()", err)
+
+	user.DM = false
+	user.Ignore = true
+
+	_, err = s.UpdateUser(ctx, user)
+	noErr(t, "UpdateUser()", err)
+
+	user, err = s.User(ctx, "user")
+	noErr(t, "User()", err)
+
+	if user.DM || !user.Ignore {
+		t.Errorf("User() = %+v, want updated settings", user)
+	}
+}
+
+func testCrosspostGroups(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	_, err := s.User(ctx, "user")
+	noErr(t, "User()", err)
+
+	user, err := s.CreateCrosspostGroup(ctx, "user", &store.Group{Name: "group", Parent: "1", Children: []string{"2"}})
+	noErr(t, "CreateCrosspostGroup()", err)
+
+	group, ok := user.FindGroup("1")
+	if !ok || group.Name != "group" || !reflect.DeepEqual(group.Children, []string{"2"}) {
+		t.Fatalf("CreateCrosspostGroup() = %+v, want group with parent 1 and child 2", user.Groups)
+	}
+
+	//Group names and parents are unique.
+	_, err = s.CreateCrosspostGroup(ctx, "user", &store.Group{Name: "group", Parent: "3", Children: []string{}})
+	wantErr(t, "CreateCrosspostGroup(duplicate name)", err, store.ErrNotFound)
+
+	_, err = s.CreateCrosspostGroup(ctx, "user", &store.Group{Name: "other", Parent: "1", Children: []string{}})
+	wantErr(t, "CreateCrosspostGroup(duplicate parent)", err, store.ErrNotFound)
+
+	user, err = s.CreateCrosspostGroup(ctx, "user", &store.Group{Name: "other", Parent: "3", Children: []string{}})
+	noErr(t, "CreateCrosspostGroup()", err)
+
+	if len(user.Groups) != 2 {
+		t.Fatalf("CreateCrosspostGroup() = %+v, want 2 groups", user.Groups)
+	}
+
+	_, err = s.DeleteCrosspostGroup(ctx, "user", "unknown")
+	wantErr(t, "DeleteCrosspostGroup(unknown)", err, store.ErrNotFound)
+
+	user, err = s.DeleteCrosspostGroup(ctx, "user", "group")
+	noErr(t, "DeleteCrosspostGroup()", err)
+
+	if _, ok := user.FindGroupByName("group"); ok || len(user.Groups) != 1 {
+		t.Errorf("DeleteCrosspostGroup() = %+v, want only group other", user.Groups)
+	}
+
+	user, err = s.User(ctx, "user")
+	noErr(t, "User()", err)
+
+	if _, ok := user.FindGroupByName("other"); !ok || len(user.Groups) != 1 {
+		t.Errorf("User().Groups = %+v, want only group other", user.Groups)
+	}
+}
+
+func testCrosspostChannels(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	_, err := s.User(ctx, "user")
+	noErr(t, "User()", err)
+
+	_, err = s.CreateCrosspostGroup(ctx, "user", &store.Group{Name: "group", Parent: "1", Children: []string{}})
+	noErr(t, "CreateCrosspostGroup()", err)
+
+	_, err = s.AddCrosspostChannel(ctx, "user", "unknown", "2")
+	wantErr(t, "AddCrosspostChannel(unknown group)", err, store.ErrNotFound)
+
+	//Children are a set, adding a channel twice is a no-op.
+	for i := 0; i < 2; i++ {
+		_, err = s.AddCrosspostChannel(ctx, "user", "group", "2")
+		noErr(t, "AddCrosspostChannel()", err)
+	}
+
+	user, err := s.AddCrosspostChannel(ctx, "user", "group", "3")
+	noErr(t, "AddCrosspostChannel()", err)
+
+	group, _ := user.FindGroupByName("group")
+	if want := []string{"2", "3"}; group == nil || !reflect.DeepEqual(group.Children, want) {
+		t.Fatalf("AddCrosspostChannel() = %+v, want children %v", group, want)
+	}
+
+	_, err = s.DeleteCrosspostChannel(ctx, "user", "unknown", "2")
+	wantErr(t, "DeleteCrosspostChannel(unknown group)", err, store.ErrNotFound)
+
+	user, err = s.DeleteCrosspostChannel(ctx, "user", "group", "2")
+	noErr(t, "DeleteCrosspostChannel()", err)
+
+	group, _ = user.FindGroupByName("group")
+	if want := []string{"3"}; group == nil || !reflect.DeepEqual(group.Children, want) {
+		t.Fatalf("DeleteCrosspostChannel() = %+v, want children %v", group, want)
+	}
+
+	user, err = s.User(ctx, "user")
+	noErr(t, "User()", err)
+
+	group, _ = user.FindGroupByName("group")
+	if want := []string{"3"}; group == nil || !reflect.DeepEqual(group.Children, want) {
+		t.Errorf("User() group = %+v, want children %v", group, want)
+	}
+}
+
+func testCrosspostFilters(t *testing.T, s store.Store) {
+	ctx := context.Background()
+
+	_, err := s.User(ctx, "user")
+	noErr(t, "User()", err)
+
+	_, err = s.CreateCrosspostGroup(ctx, "user", &store.Group{Name: "group", Parent: "1", Children: []string{"2", "3"}})
+	noErr(t, "CreateCrosspostGroup()", err)
+
+	sfw := false
+	_, err = s.SetCrosspostFilter(ctx, "user", "unknown", "", &store.CrosspostFilter{NSFW: &sfw})
+	wantErr(t, "SetCrosspostFilter(unknown group)", err, store.ErrNotFound)
+
+	_, err = s.SetCrosspostFilter(ctx, "user", "group", "4", &store.CrosspostFilter{NSFW: &sfw})
+	wantErr(t, "SetCrosspostFilter(unknown child)", err, store.ErrNotFound)
+
+	_, err = s.SetCrosspostFilter(ctx, "user", "group", "", &store.CrosspostFilter{Providers: []string{"pixiv"}, NSFW: &sfw})
+	noErr(t, "SetCrosspostFilter()", err)
+
+	_, err = s.SetCrosspostFilter(ctx, "user", "group", "2", &store.CrosspostFilter{Tags: []string{"maid"}, MinLikes: 100})
+	noErr(t, "SetCrosspostFilter(child)", err)
+
+	_, err = s.SetCrosspostFilter(ctx, "user", "group", "3", &store.CrosspostFilter{ExcludeTags: []string{"gore"}})
+	noErr(t, "SetCrosspostFilter(child)", err)
+
+	user, err := s.User(ctx, "user")
+	noErr(t, "User()", err)
+
+	group, _ := user.FindGroupByName("group")
+	if group == nil || group.Filter == nil || !reflect.DeepEqual(group.Filter.Providers, []string{"pixiv"}) || group.Filter.NSFW == nil || *group.Filter.NSFW {
+		t.Fatalf("User() group = %+v, want group filter", group)
+	}
+
+	if f := group.ChildFilters["2"]; f == nil || !reflect.DeepEqual(f.Tags, []string{"maid"}) || f.MinLikes != 100 {
+		t.Fatalf("User() child filters = %+v, want a filter of channel 2", group.ChildFilters)
+	}
+
+	//Deleting a channel deletes its filter, empty filter removes it.
+	_, err = s.DeleteCrosspostChannel(ctx, "user", "group", "2")
+	noErr(t, "DeleteCrosspostChannel()", err)
+
+	_, err = s.SetCrosspostFilter(ctx, "user", "group", "", &store.CrosspostFilter{})
+	noErr(t, "SetCrosspostFilter(empty)", err)
+
+	user, err = s.User(ctx, "user")
+	noErr(t, "User()", err)
+
+	group, _ = user.FindGroupByName("group")
+	if group == nil || group.Filter != nil || len(group.ChildFilters) != 1 || group.ChildFilters["3"] == nil {
+		t.Errorf("User() group = %+v, want only a filter of channel 3", group)
+	}
+
+	user, err = s.SetCrosspostFilter(ctx, "user", "group", "3", nil)
+	noErr(t, "SetCrosspostFilter(nil)", err)
+
+	group, _ = user.FindGroupByName("group")
+	if group == nil || len(group.ChildFilters) != 0 {
+		t.Errorf("SetCrosspostFilter(nil) = %+v, want no filters", group)
+	}
+}
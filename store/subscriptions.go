@@ -0,0 +1,66 @@
+package store
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/VTGare/boe-tea-go/internal/arrays"
+)
+
+//SubscriptionHistory is the number of recently posted artwork IDs a subscription remembers to avoid posting them twice.
+const SubscriptionHistory = 100
+
+//SubscriptionStore stores artist feed subscriptions. A subscription is identified by a channel, a provider and an author.
+type SubscriptionStore interface {
+	//AddSubscription adds a subscription. It returns false if the channel is already subscribed to the author.
+	AddSubscription(ctx context.Context, sub *Subscription) (bool, error)
+	//DeleteSubscription deletes a subscription. It returns false if the channel isn't subscribed to the author.
+	DeleteSubscription(ctx context.Context, sub *Subscription) (bool, error)
+	//UpdateSubscription saves seen artworks and check time of a subscription.
+	UpdateSubscription(ctx context.Context, sub *Subscription) error
+	GuildSubscriptions(ctx context.Context, guildID string) ([]*Subscription, error)
+	//ShardSubscriptions returns subscriptions of guilds served by a shard.
+	ShardSubscriptions(ctx context.Context, shardID, shardCount int) ([]*Subscription, error)
+}
+
+//Subscription subscribes a channel to new artworks of an author.
+type Subscription struct {
+	GuildID   string `json:"guild_id" bson:"guild_id"`
+	ChannelID string `json:"channel_id" bson:"channel_id"`
+	Provider  string `json:"provider" bson:"provider"`
+	AuthorID  string `json:"author_id" bson:"author_id"`
+	//URL is author's profile URL.
+	URL string `json:"url" bson:"url"`
+	//ShardKey is guild ID shifted by 22 bits. Guild's shard ID is ShardKey modulo shard count.
+	ShardKey int64 `json:"shard_key" bson:"shard_key"`
+	//Seen stores IDs of recent artworks that were posted or existed before the subscription, oldest first.
+	Seen []string `json:"seen" bson:"seen"`
+
+	CheckedAt time.Time `json:"checked_at" bson:"checked_at"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+//ShardKey returns a shard key of a guild.
+func ShardKey(guildID string) int64 {
+	id, _ := strconv.ParseInt(guildID, 10, 64)
+	return id >> 22
+}
+
+//HasSeen reports if an artwork was already posted or existed before the subscription.
+func (s *Subscription) HasSeen(id string) bool {
+	return arrays.Any(s.Seen, id)
+}
+
+//MarkSeen remembers artwork IDs. Only last SubscriptionHistory IDs are kept.
+func (s *Subscription) MarkSeen(ids ...string) {
+	for _, id := range ids {
+		if !s.HasSeen(id) {
+			s.Seen = append(s.Seen, id)
+		}
+	}
+
+	if len(s.Seen) > SubscriptionHistory {
+		s.Seen = s.Seen[len(s.Seen)-SubscriptionHistory:]
+	}
+}
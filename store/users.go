@@ -3,6 +3,8 @@ package store
 import (
 	"context"
 	"time"
+
+	"github.com/VTGare/boe-tea-go/internal/arrays"
 )
 
 type UserStore interface {
@@ -14,6 +16,9 @@ type UserStore interface {
 	DeleteCrosspostGroup(ctx context.Context, userID string, group string) (*User, error)
 	AddCrosspostChannel(ctx context.Context, userID string, group string, child string) (*User, error)
 	DeleteCrosspostChannel(ctx context.Context, userID string, group string, child string) (*User, error)
+	//SetCrosspostFilter replaces a filter of a group if child is empty or a filter of group's child channel otherwise.
+	//Nil or empty filter removes it. If the group or the child doesn't exist, ErrNotFound is returned.
+	SetCrosspostFilter(ctx context.Context, userID string, group string, child string, filter *CrosspostFilter) (*User, error)
 }
 
 type User struct {
@@ -30,6 +35,29 @@ type Group struct {
 	Name     string   `json:"name" bson:"name"`
 	Parent   string   `json:"parent" bson:"parent"`
 	Children []string `json:"children" bson:"children"`
+	//Filter applies to artworks crossposted to every child channel.
+	Filter *CrosspostFilter `json:"filter,omitempty" bson:"filter,omitempty"`
+	//ChildFilters are filters of individual child channels. They apply on top of group's filter.
+	ChildFilters map[string]*CrosspostFilter `json:"child_filters,omitempty" bson:"child_filters,omitempty"`
+}
+
+//CrosspostFilter limits artworks that are crossposted. Empty fields don't filter anything.
+type CrosspostFilter struct {
+	//Providers are names of providers which artworks are crossposted.
+	Providers []string `json:"providers,omitempty" bson:"providers,omitempty"`
+	//NSFW crossposts only NSFW artworks if true and only safe artworks if false.
+	NSFW *bool `json:"nsfw,omitempty" bson:"nsfw,omitempty"`
+	//Tags crossposts artworks that have at least one of the tags.
+	Tags []string `json:"tags,omitempty" bson:"tags,omitempty"`
+	//ExcludeTags crossposts artworks that have none of the tags.
+	ExcludeTags []string `json:"exclude_tags,omitempty" bson:"exclude_tags,omitempty"`
+	//MinLikes crossposts artworks with at least this many likes. Artworks without a like count aren't crossposted.
+	MinLikes int `json:"min_likes,omitempty" bson:"min_likes,omitempty"`
+}
+
+//IsZero reports if a filter doesn't filter anything.
+func (f *CrosspostFilter) IsZero() bool {
+	return f == nil || len(f.Providers) == 0 && f.NSFW == nil && len(f.Tags) == 0 && len(f.ExcludeTags) == 0 && f.MinLikes == 0
 }
 
 func DefaultUser(id string) *User {
@@ -43,6 +71,31 @@ func DefaultUser(id string) *User {
 	}
 }
 
+//SetFilter replaces a filter of the group if child is empty or a filter of a child channel otherwise. Nil filter removes it.
+//It returns false if the child isn't in the group.
+func (g *Group) SetFilter(child string, filter *CrosspostFilter) bool {
+	if child == "" {
+		g.Filter = filter
+		return true
+	}
+
+	if !arrays.Any(g.Children, child) {
+		return false
+	}
+
+	if filter == nil {
+		delete(g.ChildFilters, child)
+		return true
+	}
+
+	if g.ChildFilters == nil {
+		g.ChildFilters = make(map[string]*CrosspostFilter)
+	}
+
+	g.ChildFilters[child] = filter
+	return true
+}
+
 func (u *User) FindGroup(parentID string) (*Group, bool) {
 	for _, group := range u.Groups {
 		if group.Parent == parentID {